@@ -23,9 +23,12 @@
 ;;; Code:
 
 {{- template "utils" -}}
+;; <Runtime support>
+{{- template "rt" -}}
 ;; <Public section>
 {{- template "public/customization" -}}
 {{- template "public/commands" -}}
+{{- template "public/debug" -}}
 ;; <IR compilation>
 {{- template "ir/ir" -}}
 
@@ -1,25 +1,504 @@
 ;;; -*- lexical-binding: t -*-
+;; Default FFI spec for "emacs/lisp" package.
+;; Regenerate "src/emacs/lisp/ffi.go" with `make ffi' after editing.
+;;
+;; Each form declares single Emacs Lisp function:
+;;   (sym GoName (:type arg ...) :ret "Documentation.")
+;; Parameter names that start with "&" are variadic.
+;; Function types are written as [(:type arg ...) :ret].
 
-(goism-declare
- ;;; <RT>
- ;;; Functions that are required by emacs/rt:
- (error Error (:string format :any &args) :void)
- (eq Eq (:any obj1 :any obj2) :bool)
- (mapconcat MapConcat
-            (:any function :object sequence :string separator)
-            :string)
- (not Not (:any object) :bool)
- (concat Concat (:any &sequences) :string)
- (aref ArefString (:string array :int idx) :char)
- (aset Aset (:object array :int idx :any newElt) :void)
- (length Length (:any sequence) :int)
- (string-bytes StringBytes (:string s) :int)
- (min MinInt (:int &xs) :int)
- (multibyte-string-p IsMultibyteString (:string object) :bool)
- (booleanp IsBool (:object object) :bool)
- (integerp IsInt (:object object) :bool)
- (floatp IsFloat (:object object) :bool)
- (stringp IsString (:object object) :bool)
- (symbolp IsSymbol (:object object) :bool)
- (prin1-to-string Prin1ToString (:object object) :string)
- )
+;;; <RT>
+;;; Functions that are required by emacs/rt:
+(error Error (:string format :any &args) :void
+ "Signal an error, making a message by passing args to ‘format-message’.
+In Emacs, the convention is that error messages start with a capital
+letter but *do not* end with a period.  Please follow this convention
+for the sake of consistency.
+
+Note: (error \"%s\" VALUE) makes the message VALUE without
+interpreting format characters like ‘%’, ‘`’, and ‘'’.")
+(eq Eq (:any obj1 :any obj2) :bool
+ "Return t if the two args are the same Lisp object.")
+(mapconcat MapConcat (:any function :object sequence :string separator) :string
+ "Apply FUNCTION to each element of SEQUENCE, and concat the results as strings.
+In between each pair of results, stick in SEPARATOR.  Thus, \" \" as
+SEPARATOR results in spaces between the values returned by FUNCTION.
+SEQUENCE may be a list, a vector, a bool-vector, or a string.")
+(not Not (:any object) :bool
+ "Return t if OBJECT is nil, and return nil otherwise.")
+(concat Concat (:any &sequences) :string
+ "Concatenate all the arguments and make the result a string.
+The result is a string whose elements are the elements of all the arguments.
+Each argument may be a string or a list or vector of characters (integers).")
+(aref ArefString (:string array :int idx) :char
+ "Return the element of ARRAY at index IDX.
+ARRAY may be a vector, a string, a char-table, a bool-vector,
+or a byte-code object.  IDX starts at 0.")
+(aset Aset (:object array :int idx :any newElt) :void
+ "Store into the element of ARRAY at index IDX the value NEWELT.
+Return NEWELT.  ARRAY may be a vector, a string, a char-table or a
+bool-vector.  IDX starts at 0.")
+(length Length (:any sequence) :int
+ "Return the length of vector, list or string SEQUENCE.
+A byte-code function object is also allowed.
+If the string contains multibyte characters, this is not necessarily
+the number of bytes in the string; it is the number of characters.
+To get the number of bytes, use ‘string-bytes’.")
+(string-bytes StringBytes (:string s) :int
+ "Return the number of bytes in STRING.
+If STRING is multibyte, this may be greater than the length of STRING.")
+(min MinInt (:int &xs) :int
+ "Return smallest of all the arguments (which must be numbers or markers).
+The value is always a number; markers are converted to numbers.")
+(multibyte-string-p IsMultibyteString (:string object) :bool
+ "Return t if OBJECT is a multibyte string.
+Return nil if OBJECT is either a unibyte string, or not a string.")
+(booleanp IsBool (:object object) :bool
+ "Return t if OBJECT is one of the two canonical boolean values: t or nil.
+Otherwise, return nil.")
+(integerp IsInt (:object object) :bool
+ "Return t if OBJECT is an integer.")
+(floatp IsFloat (:object object) :bool
+ "Return t if OBJECT is a floating point number.")
+(stringp IsString (:object object) :bool
+ "Return t if OBJECT is a string.")
+(symbolp IsSymbol (:object object) :bool
+ "Return t if OBJECT is a symbol.")
+(consp IsCons (:any object) :bool
+ "Return t if OBJECT is a cons cell.")
+(listp IsList (:any object) :bool
+ "Return t if OBJECT is a list, that is, a cons cell or nil.
+Otherwise, return nil.")
+(hash-table-p IsHashTable (:any object) :bool
+ "Return t if OBJ is a Lisp hash table object.")
+(bufferp IsBuffer (:any object) :bool
+ "Return t if OBJECT is an editor buffer.")
+(markerp IsMarker (:any object) :bool
+ "Return t if OBJECT is a marker (editor pointer).")
+(windowp IsWindow (:any object) :bool
+ "Return t if OBJECT is a window and nil otherwise.")
+(processp IsProcess (:any object) :bool
+ "Return t if OBJECT is a process.")
+(prin1-to-string Prin1ToString (:object object) :string
+ "Return a string containing the printed representation of OBJECT.
+OBJECT can be any Lisp object.  This function outputs quoting characters
+when necessary to make output that ‘read’ can handle, whenever possible,
+unless the optional second argument NOESCAPE is non-nil.  For complex objects,
+the behavior is controlled by ‘print-level’ and ‘print-length’, which see.
+
+OBJECT is any of the Lisp data types: a number, a string, a symbol,
+a list, a buffer, a window, a frame, etc.
+
+A printed representation of an object is text which describes that object.")
+
+;;; <Buffers>
+(current-buffer CurrentBuffer () :buffer
+ "Return the current buffer as a Lisp object.")
+(set-buffer SetBuffer (:any bufferOrName) :buffer
+ "Make buffer BUFFER-OR-NAME current for editing operations.
+BUFFER-OR-NAME may be a buffer or the name of an existing buffer.
+This function does not display the buffer, so its effect ends when
+the current command terminates.")
+(get-buffer GetBuffer (:any bufferOrName) :object
+ "Return the buffer named BUFFER-OR-NAME.
+BUFFER-OR-NAME must be either a string or a buffer.  If BUFFER-OR-NAME
+is a string and there is no buffer with that name, return nil.")
+(get-buffer-create GetBufferCreate (:any bufferOrName) :buffer
+ "Return the buffer specified by BUFFER-OR-NAME, creating a new one if needed.")
+(generate-new-buffer GenerateNewBuffer (:string name) :buffer
+ "Create and return a buffer with a name based on NAME.
+Choose the buffer's name using `generate-new-buffer-name'.")
+(generate-new-buffer-name GenerateNewBufferName (:string name) :string
+ "Return a string that is the name of no existing buffer based on NAME.")
+(buffer-name BufferName (:any buffer) :object
+ "Return the name of BUFFER, as a string.
+BUFFER defaults to the current buffer.
+Return nil if BUFFER has been killed.")
+(buffer-file-name BufferFileName (:any buffer) :object
+ "Return name of file BUFFER is visiting, or nil if none.
+No argument or nil as argument means use the current buffer.")
+(buffer-list BufferList () :list
+ "Return a list of all live buffers.")
+(buffer-live-p IsBufferLive (:any object) :bool
+ "Return t if OBJECT is a buffer which has not been killed.")
+(kill-buffer KillBuffer (:any bufferOrName) :bool
+ "Kill the buffer specified by BUFFER-OR-NAME.
+Return t if the buffer is actually killed, nil otherwise.")
+(rename-buffer RenameBuffer (:string newname) :string
+ "Change current buffer's name to NEWNAME (a string).")
+(buffer-string BufferString () :string
+ "Return the contents of the current buffer as a string.
+If narrowing is in effect, this function returns only the visible part
+of the buffer.")
+(buffer-substring BufferSubstring (:int start :int end) :string
+ "Return the contents of part of the current buffer as a string.
+The two arguments START and END are character positions.")
+(buffer-substring-no-properties BufferSubstringNoProperties (:int start :int end) :string
+ "Return the characters of part of the buffer, without the text properties.")
+(buffer-size BufferSize () :int
+ "Return the number of characters in the current buffer.")
+(set-buffer-modified-p SetBufferModified (:any flag) :void
+ "Mark current buffer as modified or unmodified according to FLAG.")
+(erase-buffer EraseBuffer () :void
+ "Delete the entire contents of the current buffer.
+Any narrowing restriction in effect (see `narrow-to-region') is removed,
+so the buffer is truly empty after this.")
+(insert Insert (:any &args) :void
+ "Insert the arguments, either strings or characters, at point.
+Point and after-insertion markers move forward to end up
+after the inserted text.")
+(insert-buffer-substring InsertBufferSubstring (:any buffer :int start :int end) :void
+ "Insert before point a substring of the contents of BUFFER.
+BUFFER may be a buffer or a buffer name.")
+(delete-region DeleteRegion (:int start :int end) :void
+ "Delete the text between START and END.")
+(delete-char DeleteChar (:int n) :void
+ "Delete the following N characters (previous if N is negative).")
+(char-after CharAfter (:int pos) :object
+ "Return character in current buffer at position POS.
+If POS is out of range, the value is nil.")
+(char-before CharBefore (:int pos) :object
+ "Return character in current buffer preceding position POS.
+If POS is out of range, the value is nil.")
+
+;;; <Positions>
+(point Point () :int
+ "Return value of point, as an integer.
+Beginning of buffer is position (point-min).")
+(point-min PointMin () :int
+ "Return the minimum permissible value of point in the current buffer.
+This is 1, unless narrowing (a buffer restriction) is in effect.")
+(point-max PointMax () :int
+ "Return the maximum permissible value of point in the current buffer.
+This is (1+ (buffer-size)), unless narrowing (a buffer restriction)
+is in effect, in which case it is less.")
+(goto-char GotoChar (:int position) :void
+ "Set point to POSITION, a number or marker.
+Beginning of buffer is position (point-min), end is (point-max).")
+(forward-char ForwardChar (:int n) :void
+ "Move point N characters forward (backward if N is negative).")
+(backward-char BackwardChar (:int n) :void
+ "Move point N characters backward (forward if N is negative).")
+(forward-line ForwardLine (:int n) :int
+ "Move N lines forward (backward if N is negative).
+Returns the count of lines left to move.")
+(beginning-of-line BeginningOfLine () :void
+ "Move point to beginning of current line (in the logical order).")
+(end-of-line EndOfLine () :void
+ "Move point to end of current line (in the logical order).")
+(line-beginning-position LineBeginningPosition () :int
+ "Return the position of the first character in the current line.")
+(line-end-position LineEndPosition () :int
+ "Return the position of the last character in the current line.")
+(line-number-at-pos LineNumberAtPos (:int pos) :int
+ "Return buffer line number at position POS.")
+(count-lines CountLines (:int start :int end) :int
+ "Return number of lines between START and END.")
+(bobp IsBeginningOfBuffer () :bool
+ "Return t if point is at the beginning of the buffer.")
+(eobp IsEndOfBuffer () :bool
+ "Return t if point is at the end of the buffer.")
+(bolp IsBeginningOfLine () :bool
+ "Return t if point is at the beginning of a line.")
+(eolp IsEndOfLine () :bool
+ "Return t if point is at the end of a line.")
+(narrow-to-region NarrowToRegion (:int start :int end) :void
+ "Restrict editing in this buffer to the current region.")
+(widen Widen () :void
+ "Remove restrictions (narrowing) from current buffer.")
+
+;;; <Markers>
+(make-marker MakeMarker () :marker
+ "Return a newly allocated marker which does not point at any place.")
+(point-marker PointMarker () :marker
+ "Return value of point, as a marker object.")
+(copy-marker CopyMarker (:any marker) :marker
+ "Return a new marker pointing at the same place as MARKER.
+If argument is a number, makes a new marker pointing
+at that position in the current buffer.")
+(set-marker SetMarker (:marker marker :any position :any buffer) :marker
+ "Position MARKER before character number POSITION in BUFFER.
+If POSITION is nil, makes marker point nowhere so it no longer
+slows down editing in any buffer.")
+(marker-position MarkerPosition (:marker marker) :object
+ "Return the position of MARKER, or nil if it points nowhere.")
+(marker-buffer MarkerBuffer (:marker marker) :object
+ "Return the buffer that MARKER points into, or nil if none.")
+(set-marker-insertion-type SetMarkerInsertionType (:marker marker :any insertionType) :void
+ "Set the insertion-type of MARKER to INSERTION-TYPE.
+If INSERTION-TYPE is nil, the marker does not advance when text is
+inserted at its position.")
+(mark Mark () :object
+ "Return this buffer's mark value as integer, or nil if never set.")
+(set-mark SetMark (:any pos) :void
+ "Set this buffer's mark to POS.  Don't use this function!")
+(region-beginning RegionBeginning () :int
+ "Return the integer value of point or mark, whichever is smaller.")
+(region-end RegionEnd () :int
+ "Return the integer value of point or mark, whichever is larger.")
+
+;;; <Windows>
+(selected-window SelectedWindow () :window
+ "Return the selected window.")
+(select-window SelectWindow (:window window) :window
+ "Select WINDOW which must be a live window.
+Also make WINDOW's frame the selected frame and WINDOW that frame's
+selected window.  In addition, make WINDOW's buffer current.")
+(window-live-p IsWindowLive (:any object) :bool
+ "Return t if OBJECT is a live window and nil otherwise.")
+(window-list WindowList () :list
+ "Return a list of windows on the selected frame.")
+(window-buffer WindowBuffer (:any window) :buffer
+ "Return the buffer displayed in window WINDOW.
+WINDOW must be a live window and defaults to the selected one.")
+(set-window-buffer SetWindowBuffer (:any window :any bufferOrName) :void
+ "Make WINDOW display BUFFER-OR-NAME.")
+(get-buffer-window GetBufferWindow (:any bufferOrName) :object
+ "Return a window currently displaying BUFFER-OR-NAME, or nil if none.")
+(window-point WindowPoint (:any window) :int
+ "Return current value of point in WINDOW.")
+(set-window-point SetWindowPoint (:any window :int pos) :void
+ "Make point value in WINDOW be at position POS in WINDOW's buffer.")
+(window-start WindowStart (:any window) :int
+ "Return position at which display currently starts in WINDOW.")
+(window-width WindowWidth (:any window) :int
+ "Return the width of WINDOW in columns.")
+(window-height WindowHeight (:any window) :int
+ "Return the height of WINDOW in lines.")
+(split-window SplitWindow (:any window :any size :any side) :window
+ "Make a new window adjacent to WINDOW.
+WINDOW must be a valid window and defaults to the selected one.
+Return the new window which is always a live window.")
+(delete-window DeleteWindow (:any window) :void
+ "Delete WINDOW.
+WINDOW must be a valid window and defaults to the selected one.")
+(delete-other-windows DeleteOtherWindows () :void
+ "Make the selected window fill its frame.")
+(switch-to-buffer SwitchToBuffer (:any bufferOrName) :buffer
+ "Display buffer BUFFER-OR-NAME in the selected window.
+Return the buffer switched to.")
+(pop-to-buffer PopToBuffer (:any bufferOrName) :buffer
+ "Display buffer specified by BUFFER-OR-NAME and select its window.
+Return the buffer switched to.")
+(display-buffer DisplayBuffer (:any bufferOrName) :object
+ "Display BUFFER-OR-NAME in some window, without selecting it.
+Return the window chosen for displaying the buffer, or nil if
+no such window is found.")
+
+;;; <Processes>
+(make-process MakeProcess (:any &args) :process
+ "Start a program in a subprocess.  Return the process object for it.
+This is similar to `start-process', but arguments are specified as
+keyword/argument pairs.")
+(start-process StartProcess (:string name :any buffer :string program :string &programArgs) :process
+ "Start a program in a subprocess.  Return the process object for it.
+NAME is name for process.  It is modified if necessary to make it unique.
+BUFFER is the buffer (or buffer name) to associate with the process.")
+(call-process CallProcess (:string program :any infile :any destination :any display :string &args) :object
+ "Call PROGRAM synchronously in separate process.
+The remaining arguments are optional.
+The value is normally an exit status integer.")
+(shell-command-to-string ShellCommandToString (:string command) :string
+ "Execute shell command COMMAND and return its output as a string.")
+(get-process GetProcess (:string name) :object
+ "Return the process named NAME, or nil if there is none.")
+(process-list ProcessList () :list
+ "Return a list of all processes that are Emacs sub-processes.")
+(process-name ProcessName (:process process) :string
+ "Return the name of PROCESS, as a string.")
+(process-buffer ProcessBuffer (:process process) :object
+ "Return the buffer PROCESS is associated with.
+The default process filter inserts output from PROCESS into this buffer.")
+(process-id ProcessID (:process process) :object
+ "Return the process id of PROCESS.
+This is the pid of the external process which PROCESS uses or talks to.
+For a network, serial, and pipe connections, this value is nil.")
+(process-status ProcessStatus (:any process) :object
+ "Return the status of PROCESS.
+The returned value is one of the following symbols:
+run, stop, exit, signal, open, closed, connect, failed, listen.
+The value is nil if PROCESS does not exist.")
+(process-exit-status ProcessExitStatus (:process process) :int
+ "Return the exit status of PROCESS or the signal number that killed it.
+If PROCESS has not yet exited or died, return 0.")
+(process-send-string ProcessSendString (:any process :string s) :void
+ "Send PROCESS the contents of STRING as input.
+PROCESS may be a process, a buffer, the name of a process or buffer, or
+nil, indicating the current buffer's process.")
+(process-send-eof ProcessSendEOF (:any process) :void
+ "Make PROCESS see end-of-file in its input.")
+(accept-process-output AcceptProcessOutput (:any process :any seconds) :bool
+ "Allow any pending output from subprocesses to be read by Emacs.
+Return non-nil if we received any output from PROCESS (or from any
+process, if PROCESS is nil) before the timeout expired.")
+(set-process-filter SetProcessFilter (:process process [(:process proc :string output) :void] filter) :void
+ "Give PROCESS the filter function FILTER; nil means default.
+The filter gets two arguments: the process and the string of output.")
+(set-process-sentinel SetProcessSentinel (:process process [(:process proc :string event) :void] sentinel) :void
+ "Give PROCESS the sentinel SENTINEL; nil for default.
+The sentinel is called as a function when the process changes state.
+It gets two arguments: the process, and a string describing the change.")
+(process-get ProcessGet (:process process :symbol prop) :object
+ "Return the value of PROCESS' PROP property.")
+(process-put ProcessPut (:process process :symbol prop :any val) :void
+ "Change PROCESS' property PROP to VAL.")
+(delete-process DeleteProcess (:any process) :void
+ "Delete PROCESS: kill it and forget about it immediately.")
+(kill-process KillProcess (:any process) :void
+ "Kill process PROCESS.  May be process or name of one.")
+
+;;; <Conses>
+(cons MakeCons (:any car :any cdr) :cons
+ "Create a new cons, give it CAR and CDR as components, and return it.")
+(car Car (:cons cell) :object
+ "Return the car of CELL.")
+(cdr Cdr (:cons cell) :object
+ "Return the cdr of CELL.")
+(setcar SetCar (:cons cell :any newcar) :void
+ "Set the car of CELL to be NEWCAR.  Returns NEWCAR.")
+(setcdr SetCdr (:cons cell :any newcdr) :void
+ "Set the cdr of CELL to be NEWCDR.  Returns NEWCDR.")
+
+;;; <Hash tables>
+(make-hash-table MakeHashTable (:any &keywordArgs) :hash-table
+ "Create and return a new hash table.
+Arguments are specified as keyword/argument pairs.  The following
+arguments are defined: :test, :size, :rehash-size,
+:rehash-threshold and :weakness.")
+(gethash Gethash (:any key :hash-table table :any dflt) :object
+ "Look up KEY in TABLE and return its associated value.
+If KEY is not found, return DFLT which defaults to nil.")
+(puthash Puthash (:any key :any value :hash-table table) :void
+ "Associate KEY with VALUE in hash table TABLE.
+If KEY is already present in table, replace its current value with
+VALUE.  In any case, return VALUE.")
+(remhash Remhash (:any key :hash-table table) :void
+ "Remove KEY from TABLE.")
+(clrhash Clrhash (:hash-table table) :void
+ "Clear hash table TABLE and return it.")
+(hash-table-count HashTableCount (:hash-table table) :int
+ "Return the number of elements in TABLE.")
+(maphash Maphash ([(:object key :object value) :void] function :hash-table table) :void
+ "Call FUNCTION for all entries in hash table TABLE.
+FUNCTION is called with two arguments, KEY and VALUE.")
+
+;;; <Text properties>
+(propertize Propertize (:string s :any &properties) :string
+ "Return a copy of STRING with text properties added.
+First argument is the string to copy.
+Remaining arguments form a sequence of PROPERTY VALUE pairs for text
+properties to add to the result.")
+(get-text-property GetTextProperty (:int pos :symbol prop :any object) :object
+ "Return the value of POSITION's property PROP, in OBJECT.
+OBJECT should be a buffer or a string; if omitted or nil, it defaults
+to the current buffer.")
+(get-char-property GetCharProperty (:int pos :symbol prop) :object
+ "Return the value of POSITION's property PROP, in OBJECT.
+Both overlay properties and text properties are checked.")
+(text-properties-at TextPropertiesAt (:int pos :any object) :list
+ "Return the list of properties of the character at POSITION in OBJECT.")
+(put-text-property PutTextProperty (:int start :int end :symbol prop :any value :any object) :void
+ "Set one property of the text from START to END.
+The third and fourth arguments PROPERTY and VALUE
+specify the property to add.")
+(add-text-properties AddTextProperties (:int start :int end :list props) :bool
+ "Add properties to the text from START to END.
+The third argument PROPERTIES is a property list
+specifying the property values to add.
+Return t if any property value actually changed, nil otherwise.")
+(set-text-properties SetTextProperties (:int start :int end :list props) :bool
+ "Completely replace properties of text from START to END.
+The third argument PROPERTIES is the new property list.")
+(remove-text-properties RemoveTextProperties (:int start :int end :list props) :bool
+ "Remove some properties from text from START to END.
+The third argument PROPERTIES is a property list
+whose property names specify the properties to remove.
+Return t if any property was actually removed, nil otherwise.")
+(remove-list-of-text-properties RemoveListOfTextProperties (:int start :int end :list props) :bool
+ "Remove some properties from text from START to END.
+The third argument LIST-OF-PROPERTIES is a list of properties to remove.
+Return t if any property was actually removed, nil otherwise.")
+(next-property-change NextPropertyChange (:int pos) :object
+ "Return the position of next property change.
+Scans characters forward from POSITION till it finds a change in some
+text property, then returns the position of the change.
+Return nil if it runs to the end of the buffer.")
+(next-single-property-change NextSinglePropertyChange (:int pos :symbol prop) :object
+ "Return the position of next property change for a specific property.
+Scans characters forward from POSITION till it finds
+a change in the PROP property, then returns the position of the change.")
+(previous-single-property-change PreviousSinglePropertyChange (:int pos :symbol prop) :object
+ "Return the position of previous property change for a specific property.
+Scans characters backward from POSITION till it finds
+a change in the PROP property, then returns the position of the change.")
+(text-property-any TextPropertyAny (:int start :int end :symbol prop :any value) :object
+ "Check text from START to END for property PROPERTY equaling VALUE.
+If so, return the position of the first character whose property PROPERTY
+is `eq' to VALUE.  Otherwise return nil.")
+(make-overlay MakeOverlay (:int start :int end) :object
+ "Create a new overlay with range BEG to END in BUFFER and return it.")
+(overlay-put OverlayPut (:object overlay :symbol prop :any value) :void
+ "Set one property of overlay OVERLAY: give property PROP value VALUE.")
+(overlay-get OverlayGet (:object overlay :symbol prop) :object
+ "Get the property of overlay OVERLAY with property name PROP.")
+(overlays-at OverlaysAt (:int pos) :list
+ "Return a list of the overlays that contain the character at POS.")
+(delete-overlay DeleteOverlay (:object overlay) :void
+ "Delete the overlay OVERLAY from its buffer.")
+
+;;; <Regexps>
+(string-match StringMatch (:string regexp :string s) :object
+ "Return index of start of first match for REGEXP in STRING, or nil.
+Matching ignores case if `case-fold-search' is non-nil.")
+(looking-at LookingAt (:string regexp) :bool
+ "Return t if text after point matches regular expression REGEXP.")
+(looking-back LookingBack (:string regexp :any limit) :bool
+ "Return non-nil if text before point matches regular expression REGEXP.")
+(re-search-forward ReSearchForward (:string regexp :any bound :any noerror) :object
+ "Search forward from point for regular expression REGEXP.
+Set point to the end of the occurrence found, and return point.")
+(re-search-backward ReSearchBackward (:string regexp :any bound :any noerror) :object
+ "Search backward from point for regular expression REGEXP.
+Set point to the beginning of the occurrence found, and return point.")
+(search-forward SearchForward (:string s :any bound :any noerror) :object
+ "Search forward from point for STRING.
+Set point to the end of the occurrence found, and return point.")
+(search-backward SearchBackward (:string s :any bound :any noerror) :object
+ "Search backward from point for STRING.
+Set point to the beginning of the occurrence found, and return point.")
+(match-beginning MatchBeginning (:int subexp) :object
+ "Return position of start of text matched by last search.
+SUBEXP, a number, specifies which parenthesized expression in the last
+regexp.  Value is nil if SUBEXPth pair didn't match, or there were less
+than SUBEXP pairs.")
+(match-end MatchEnd (:int subexp) :object
+ "Return position of end of text matched by last search.
+SUBEXP, a number, specifies which parenthesized expression in the last
+regexp.  Value is nil if SUBEXPth pair didn't match, or there were less
+than SUBEXP pairs.")
+(match-string MatchString (:int num :any s) :object
+ "Return the string of text matched by the previous search or regexp operation.
+NUM specifies the number of the parenthesized sub-expression in the last
+regexp whose match to return.  Zero means the entire text matched by the
+whole regexp or whole string.")
+(match-string-no-properties MatchStringNoProperties (:int num :any s) :object
+ "Return string of text matched by last search, without text properties.")
+(match-data MatchData () :list
+ "Return a list of positions that record text matched by the last search.")
+(set-match-data SetMatchData (:list list) :void
+ "Set internal data on last search match from elements of LIST.")
+(replace-match ReplaceMatch (:string newtext :any fixedcase :any literal :any s) :object
+ "Replace text matched by last search with NEWTEXT.
+If optional fourth arg STRING is non-nil, it should be a string
+to act on; this should be the string on which the previous match was done
+via `string-match'.  In this case, `replace-match' creates and returns
+a new string, made by copying STRING and replacing the part of STRING
+that was matched.")
+(replace-regexp-in-string ReplaceRegexpInString (:string regexp :any rep :string s) :string
+ "Replace all matches for REGEXP with REP in STRING.")
+(regexp-quote RegexpQuote (:string s) :string
+ "Return a regexp string which matches exactly STRING and nothing else.")
+(regexp-opt RegexpOpt (:list strings :any paren) :string
+ "Return a regexp to match a string in the list STRINGS.")
+(split-string SplitString (:string s :any separators :any omitNulls) :list
+ "Split STRING into substrings bounded by matches for SEPARATORS.")
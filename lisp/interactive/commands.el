@@ -10,6 +10,7 @@
 (defun goism-translate (pkg-path)
   "Read Go package PKG-PATH and translate it into Emacs Lisp package.
 Generated code is shown in temporary buffer.
+Output format is selected by `goism-backend'.
 Note that this method depends on GOPATH environment variables.
 Requires `goism_translate_package' to be available.
 
@@ -18,8 +19,20 @@ Example: `(goism-translate \"example\")'"
   (let* ((pkg-path (concat "emacs/" pkg-path))
          (res (goism--exec
                "goism_translate_package"
+               (format "-output=%s" goism-backend)
                (format "-pkgPath=%s" pkg-path))))
-    (goism--ir-pkg-compile (read (goism--cmd-output res)))))
+    (if (eq goism-backend 'el)
+        (goism--el-pkg-show (goism--cmd-output res))
+      (goism--ir-pkg-compile (read (goism--cmd-output res))))))
+
+;; Output translated Emacs Lisp source to temp buffer.
+(defun goism--el-pkg-show (src)
+  (with-output-to-temp-buffer goism-output-buffer-name
+    (princ src)
+    (with-current-buffer standard-output
+      (emacs-lisp-mode)
+      (setq-local lexical-binding t)
+      (setq buffer-read-only t))))
 
 (defun goism-load (pkg-path)
   "Calls `goism-translate', evaluates output buffer and then closes it.
@@ -21,4 +21,12 @@ If remain empty, system PATH is used."
   :group 'goism
   :type 'buffer-name)
 
+(defcustom goism-backend 'pkg
+  "Translator backend that is used by `goism-translate'.
+`pkg' produces IR package that is compiled into bytecode by Emacs;
+`el' produces readable Emacs Lisp source code."
+  :group 'goism
+  :type '(choice (const :tag "IR package" pkg)
+                 (const :tag "Emacs Lisp source" el)))
+
 ;; {{ end }}
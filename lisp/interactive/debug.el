@@ -0,0 +1,30 @@
+;; {{ define "public/debug" }}
+;; Mapping of translated code back to Go sources.
+
+(defun goism-source-position (fn offset)
+  "Return \"file.go:line\" of bytecode OFFSET inside function FN.
+FN is a function symbol as it is shown in backtrace frame,
+like `goism-pkg.fn'.
+Position tables are produced for both IR packages and
+`goism_translate_package -output=elc' files;
+nil is returned if FN has no table."
+  (interactive
+   (list (intern (completing-read "Function: " obarray #'fboundp t))
+         (read-number "Bytecode offset: ")))
+  (let ((table (get fn 'goism-positions))
+        (i 0)
+        found)
+    ;; Table is [offset file line ...] sorted by offset.
+    (while (and (< i (length table))
+                (<= (aref table i) offset))
+      (setq found i
+            i (+ i 3)))
+    (let ((pos (when found
+                 (format "%s:%d"
+                         (aref table (+ found 1))
+                         (aref table (+ found 2))))))
+      (when (called-interactively-p 'interactive)
+        (message "%s" (or pos "No position information")))
+      pos)))
+
+;; {{ end }}
@@ -3,37 +3,61 @@
 
 ;; Output IR package PKG to temp buffer.
 ;; Caller can decide to inspect/eval/save generated contents.
+;; Output is a single-file package that can be saved
+;; as FEATURE.el and installed by package.el.
 ;; PKG is consumed.
 (defun goism--ir-pkg-compile (pkg)
   (with-output-to-temp-buffer goism-output-buffer-name
-    (let ((pkg-name (pop! pkg))
-          (pkg-comment (pop! pkg)))
-      (goism--ir-pkg-write-header pkg-name)
+    (let* ((_pkg-name (pop! pkg))
+           (pkg-comment (pop! pkg))
+           (feature (pop! pkg))
+           (summary (pop! pkg))
+           (headers (pop! pkg))
+           (requires (pop! pkg)))
+      (goism--ir-pkg-write-header feature summary headers)
       (when (not (string= "" pkg-comment))
         (goism--ir-pkg-write-comment pkg-comment))
-      (goism--ir-pkg-write-body pkg)
-      (goism--ir-pkg-write-footer pkg-name))
+      (princ "\n;;; Code:\n\n")
+      (dolist (x requires)
+        (prin1 `(require ',x))
+        (terpri))
+      (goism--ir-pkg-write-body pkg feature)
+      (goism--ir-pkg-write-footer feature))
     (with-current-buffer standard-output
       (emacs-lisp-mode)
       (setq buffer-read-only t))))
 
-(defun goism--ir-pkg-write-header (pkg-name)
-  (princ ";;; -*- lexical-binding: t -*-\n")
-  (princ (format ";;; %s --- translated Go package\n" pkg-name))
-  (princ ";; THIS CODE IS GENERATED, AVOID MANUAL EDITING!\n"))
+(defun goism--ir-pkg-write-header (feature summary headers)
+  (princ (format ";;; %s.el --- %s  -*- lexical-binding: t -*-\n"
+                 feature summary))
+  (princ ";; THIS CODE IS GENERATED, AVOID MANUAL EDITING!\n")
+  (when headers
+    (terpri)
+    (dolist (header headers)
+      (princ (format ";; %s: %s\n" (car header) (cdr header))))))
 
 (defun goism--ir-pkg-write-comment (pkg-comment)
   (princ "\n;;; Commentary:\n")
   (princ pkg-comment)
-  (princ "\n\n;;; Code:\n"))
+  (terpri))
 
-(defun goism--ir-pkg-write-footer (pkg-name)
-  (princ (format "\n;;; %s ends here" pkg-name)))
+(defun goism--ir-pkg-write-footer (feature)
+  (prin1 `(provide ',feature))
+  (princ (format "\n\n;;; %s.el ends here\n" feature)))
 
-(defun goism--ir-pkg-write-body (pkg)
+;; Bytecode functions are not autoloaded by the cookie
+;; itself, so cookie carries explicit `autoload' form.
+(defun goism--ir-pkg-write-autoload (pkg feature)
+  (let ((name (pop! pkg)))
+    (princ ";;;###autoload ")
+    (prin1 `(autoload ',name ,(symbol-name feature) nil t))
+    (terpri)))
+
+(defun goism--ir-pkg-write-body (pkg feature)
   (let (token)
     (while (setq token (pop! pkg))
       (pcase token
+        (`autoload (goism--ir-pkg-write-autoload pkg feature))
         (`fn (goism--ir-pkg-write-fn pkg))
         (`vars (goism--ir-pkg-write-vars pkg))
         (`expr (goism--ir-pkg-write-expr pkg))
@@ -41,9 +65,32 @@
 
 (defun goism--ir-pkg-write-fn (pkg)
   (let* ((name (pop! pkg))
-         (body (goism--ir-fn-body pkg)))
+         (positions (make-hash-table :test #'eq))
+         (body (goism--ir-fn-body pkg positions)))
     (prin1 `(defalias ',name ,body))
-    (terpri)))
+    (terpri)
+    (when (> (hash-table-count positions) 0)
+      (prin1 `(put ',name 'goism-positions
+                   ,(goism--ir-line-table positions)))
+      (terpri))))
+
+;; Convert POSITIONS (bytecode offset -> (file . line))
+;; into `goism-positions' table: [offset file line ...]
+;; sorted by offset. Consecutive entries that refer to
+;; the same line are merged.
+(defun goism--ir-line-table (positions)
+  (let (entries
+        table)
+    (maphash (lambda (pc pos) (push (cons pc pos) entries))
+             positions)
+    (dolist (entry (sort entries (lambda (a b) (< (car a) (car b)))))
+      (let ((file (car (cdr entry)))
+            (line (cdr (cdr entry))))
+        (unless (and table
+                     (equal file (nth 1 table))
+                     (= line (nth 0 table)))
+          (setq table (nconc (list line file (car entry)) table)))))
+    (vconcat (nreverse table))))
 
 (defun goism--ir-pkg-write-vars (pkg)
   (let (name)
@@ -58,38 +105,64 @@
     (prin1 `(byte-code ,bytecode ,cvec ,stack-cap))
     (terpri)))
 
-(defun goism--ir-to-bytecode (pkg cvec)
-  (byte-compile-lapcode
-   (byte-optimize-lapcode
-    (goism--ir-to-lapcode pkg cvec))))
+;; SOURCE is a vector of file and line pairs that is indexed
+;; by `position' operands; position instructions are dropped
+;; if it is nil. Otherwise, bytecode offset of every position
+;; is stored into POSITIONS hash table as (file . line).
+(defun goism--ir-to-bytecode (pkg cvec &optional source positions)
+  ;; Filled by `goism--ir-to-lapcode'.
+  (let* ((byte-compile-jump-tables nil)
+         (bytecode (byte-compile-lapcode
+                    (byte-optimize-lapcode
+                     (goism--ir-to-lapcode pkg cvec source)))))
+    (when source
+      ;; Position table is pushed after all other tables.
+      ;; Positions are visited in code order, so the last
+      ;; position wins if several of them share the same offset.
+      (maphash (lambda (i pc)
+                 (puthash pc
+                          (cons (aref source (* 2 i))
+                                (aref source (1+ (* 2 i))))
+                          positions))
+               (car byte-compile-jump-tables)))
+    bytecode))
 
-(defun goism--ir-fn-body (pkg)
+(defun goism--ir-fn-body (pkg positions)
   (let* ((args-desc (pop! pkg))
          (cvec (pop! pkg))
          (stack-cap (pop! pkg))
-         (doc-string (pop! pkg)))
-    (make-byte-code args-desc
-                    (goism--ir-to-bytecode pkg cvec)
-                    cvec
-                    stack-cap
-                    doc-string)))
+         (doc-string (pop! pkg))
+         ;; Either nil or a list of single interactive spec.
+         (interactive (pop! pkg))
+         ;; Either nil or a vector of file and line pairs.
+         (source (pop! pkg)))
+    (apply #'make-byte-code
+           args-desc
+           (goism--ir-to-bytecode pkg cvec source positions)
+           cvec
+           stack-cap
+           doc-string
+           interactive)))
 
 (defsubst goism--ir-make-info (kind data) (cons kind data))
 (defsubst goism--ir-info-kind (info) (car info))
 (defsubst goism--ir-info-data (info) (cdr info))
 
-(defsubst goism--ir-make-env (cvec)
+(defsubst goism--ir-make-env (cvec source)
   (vector (make-hash-table :test #'eq)
           (make-hash-table :test #'eq)
           (let ((refs (make-vector (length cvec) nil)))
             (dotimes (i (length cvec))
               (aset refs i (cons (aref cvec i) i)))
             refs)
-          cvec))
+          cvec
+          (when source
+            (make-hash-table :test #'eq))))
 (defsubst goism--ir-env-tags (env) (aref env 0))
 (defsubst goism--ir-env-vars (env) (aref env 1))
 (defsubst goism--ir-env-consts (env) (aref env 2))
 (defsubst goism--ir-env-cvec (env) (aref env 3))
+(defsubst goism--ir-env-positions (env) (aref env 4))
 (defun goism--ir-env-tag-ref (env id)
   (let ((tags (goism--ir-env-tags env)))
     (or (gethash id tags)
@@ -102,13 +175,40 @@
                  vars))))
 (defsubst goism--ir-env-const-ref (env id)
   (aref (goism--ir-env-consts env) id))
+;; Jump table values are label names; they are replaced
+;; by tags, so `byte-compile-lapcode' can patch them with
+;; bytecode offsets.
+(defun goism--ir-env-bind-jump-tables (env)
+  (let ((cvec (goism--ir-env-cvec env)))
+    (dotimes (i (length cvec))
+      (let ((table (aref cvec i)))
+        (when (hash-table-p table)
+          (maphash (lambda (key label)
+                     (puthash key (goism--ir-env-tag-ref env label) table))
+                   table)
+          (push table byte-compile-jump-tables))))))
+;; Position tags are not referenced by any jump, so they
+;; are kept in a fake jump table. This prevents their removal
+;; by `byte-optimize-lapcode' and makes `byte-compile-lapcode'
+;; replace them with bytecode offsets.
+(defun goism--ir-env-bind-positions (env)
+  (let ((positions (goism--ir-env-positions env)))
+    (when positions
+      (push positions byte-compile-jump-tables))))
+;; Negative tag numbers do not collide with label tags.
+(defun goism--ir-env-position-ref (env id)
+  (let ((positions (goism--ir-env-positions env)))
+    (when positions
+      (puthash id (list 'TAG (- -1 id)) positions))))
 
 (defconst goism--ir-table
   (let ((table (make-hash-table :test #'eq)))
     (dolist (x '(;; - Special instructions -
                  (label label ir-label)
+                 (position position ir-position)
                  (var-ref var-ref byte-varref)
                  (var-set var-set byte-varset)
+                 (var-bind var-bind byte-varbind)
                  (constant constant byte-constant)
                  ;; - Combined instructions -
                  (stack-ref stack-ref)
@@ -129,9 +229,11 @@
                  (goto-if-not-nil jmp)
                  (goto-if-nil-else-pop jmp)
                  (goto-if-not-nil-else-pop jmp)
+                 (switch op0)
                  ;; - Instructions with argument -
                  (call op1)
                  (stack-set op1)
+                 (unbind op1)
                  ;; - Instructions without argument -
                  (setcar op0)
                  (setcdr op0)
@@ -170,7 +272,11 @@
                  (not op0)
                  (cons op0)
                  (length op0)
-                 (return op0)))
+                 (return op0)
+                 (save-excursion op0)
+                 (save-restriction op0)
+                 (save-current-buffer op0)
+                 (unwind-protect op0)))
       (let* ((instr (nth 0 x))
              (kind (nth 1 x))
              (data (or (nth 2 x)
@@ -178,18 +284,22 @@
         (puthash instr (goism--ir-make-info kind data) table)))
     table))
 
-(defun goism--ir-to-lapcode (pkg cvec)
-  (let ((env (goism--ir-make-env cvec))
+(defun goism--ir-to-lapcode (pkg cvec &optional source)
+  (let ((env (goism--ir-make-env cvec source))
         op
         op-info
         arg
+        instr
         output)
+    (goism--ir-env-bind-jump-tables env)
+    (goism--ir-env-bind-positions env)
     (while (not-eq 'end (setq op (pop! pkg)))
       (setq op-info (gethash op goism--ir-table)
             arg (if (eq 'op0 (goism--ir-info-kind op-info))
                     nil
                   (pop! pkg)))
-      (push (goism--ir-lap-instr env op-info op arg) output))
+      (when (setq instr (goism--ir-lap-instr env op-info op arg))
+        (push instr output)))
     (nreverse output)))
 
 (defun goism--ir-lap-instr (env op-info op arg)
@@ -206,6 +316,8 @@
                   (list 'byte-discard)
                 (cons 'byte-discardN arg)))
     (`label (goism--ir-env-tag-ref env arg))
+    ;; Nil if positions are not collected.
+    (`position (goism--ir-env-position-ref env arg))
     (`jmp (let* ((lap-op (goism--ir-info-data op-info))
                  (tag (goism--ir-env-tag-ref env arg)))
             (cons lap-op tag)))
@@ -213,6 +325,8 @@
                 (cons 'byte-varref var)))
     (`var-set (let ((var (goism--ir-env-var-ref env arg)))
                 (cons 'byte-varset var)))
+    (`var-bind (let ((var (goism--ir-env-var-ref env arg)))
+                 (cons 'byte-varbind var)))
     (`concat (if (and (<= arg 4) (/= 1 arg))
                  (list (aref (goism--ir-info-data op-info) arg))
                (cons 'byte-concatN arg)))
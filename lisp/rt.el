@@ -0,0 +1,28 @@
+;;; -*- lexical-binding: t -*-
+;; {{ define "rt" }}
+;; Helpers that are called by translated Go packages.
+;; They implement things that can not be expressed in Go.
+
+(defun goism--rt-map-keys (table)
+  "Return a list of TABLE keys in unspecified order."
+  (let ((keys nil))
+    (maphash (lambda (key _val) (push key keys)) table)
+    keys))
+
+;; Go `error' values that are returned to Emacs Lisp.
+
+(defun goism-error-message (err)
+  "Return message of ERR that is a Go `error' interface value.
+Returns nil if ERR is a nil error."
+  (unless (eq err 'goism-rt.NilInterface)
+    ;; The only method of `error' interface is `Error'.
+    (funcall (aref (car err) 1) (cdr err))))
+
+(defun goism-check-error (err)
+  "Signal an `error' with ERR message, unless ERR is a nil Go error.
+ERR is a Go `error' interface value returned by translated function."
+  (let ((msg (goism-error-message err)))
+    (when msg
+      (error "%s" msg))))
+
+;; {{ end }}
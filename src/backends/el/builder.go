@@ -0,0 +1,100 @@
+package el
+
+import (
+	"sexp"
+	"strings"
+	"tu"
+)
+
+// Builder creates Emacs Lisp source file for translated package.
+// This object is not reusable.
+type Builder struct {
+	p   printer
+	pkg *tu.Package
+}
+
+// NewBuilder returns fresh ".el" file builder.
+func NewBuilder(pkg *tu.Package) *Builder {
+	b := &Builder{pkg: pkg}
+	p := &b.p
+
+	// Header follows package.el conventions,
+	// so generated file is a single-file package.
+	p.write(";;; " + pkg.Feature + ".el --- " + pkg.Summary + "  -*- lexical-binding: t -*-\n")
+	p.write(";; THIS CODE IS GENERATED, AVOID MANUAL EDITING!\n")
+	if len(pkg.Headers) != 0 {
+		p.write("\n")
+		for _, h := range pkg.Headers {
+			p.write(";; " + h.Key + ": " + h.Val + "\n")
+		}
+	}
+	if pkg.Comment != "" {
+		// Package comment is already formatted,
+		// but quotes are escaped for IR string literal.
+		p.write("\n;;; Commentary:\n")
+		p.write(strings.Replace(pkg.Comment, `\"`, `"`, -1))
+		p.write("\n")
+	}
+	p.write("\n;;; Code:\n\n")
+	for _, feature := range append([]string{"cl-lib"}, pkg.Requires...) {
+		p.print(call("require", quoted(feature)))
+		p.write("\n")
+	}
+	p.write("\n")
+
+	return b
+}
+
+// Build finalizes file being built.
+// File bytes returned.
+func (b *Builder) Build() []byte {
+	b.p.printTop(call("provide", quoted(b.pkg.Feature)))
+	b.p.write(";;; " + b.pkg.Feature + ".el ends here\n")
+	return b.p.buf.Bytes()
+}
+
+// AddVars pushes package variables declarations.
+func (b *Builder) AddVars(names []string) {
+	for _, name := range names {
+		b.p.print(call("defvar", symbol(name), atom("nil")))
+		b.p.write("\n")
+	}
+	b.p.write("\n")
+}
+
+// AddFunc pushes "defun" for given function.
+func (b *Builder) AddFunc(fn *sexp.Func) {
+	g := newGenerator()
+	fn.Body = Simplify(fn.Body).(sexp.Block)
+
+	defun := call("defun", symbol(fn.Name), g.params(fn))
+	if doc := strings.TrimRight(fn.DocString, "\n"); doc != "" {
+		defun = append(defun, docAtom(doc))
+	}
+	if fn.IsInteractive() {
+		if fn.InteractiveSpec == "" {
+			defun = append(defun, call("interactive"))
+		} else {
+			defun = append(defun, call("interactive", strAtom(fn.InteractiveSpec)))
+		}
+	}
+	body := g.funcBody(fn.Body)
+	if len(body) == 0 {
+		// Otherwise documentation string becomes a return value.
+		body = []node{atom("nil")}
+	}
+	if fn.IsAutoload() {
+		b.p.write(";;;###autoload\n")
+	}
+	b.p.printTop(defun.body(body))
+}
+
+// AddInit pushes package initializer body as top level forms.
+func (b *Builder) AddInit(fn *sexp.Func) {
+	g := newGenerator()
+	fn.Body = Simplify(fn.Body).(sexp.Block)
+
+	for _, form := range g.funcBody(fn.Body) {
+		b.p.printTop(form)
+	}
+}
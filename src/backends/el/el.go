@@ -0,0 +1,16 @@
+// Package el implements Emacs Lisp source code backend.
+//
+// Unlike lapc, it produces readable code that is
+// evaluated (or byte compiled) by Emacs itself.
+package el
+
+import (
+	"backends/gen"
+)
+
+// NewBackend returns Emacs Lisp source backend.
+func NewBackend() *gen.Backend {
+	return gen.NewBackend(gen.BackendCfg{
+		Name: "el",
+	})
+}
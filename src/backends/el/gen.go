@@ -0,0 +1,83 @@
+package el
+
+import (
+	"fmt"
+	"go/types"
+	"sexp"
+	"xtypes"
+)
+
+// generator converts simplified sexp forms into Emacs Lisp nodes.
+//
+// Go control flow that has no structured Emacs Lisp counterpart
+// is expressed with "cl-block": non-tail "return" becomes
+// "cl-return", "break" and "continue" exit named blocks
+// around the loop and its body.
+type generator struct {
+	// Set when current function (or inlined lambda)
+	// needs "cl-block" to return from.
+	returnUsed bool
+
+	loops []*loopInfo // Innermost loop is the last
+
+	labels    map[string]atom // Label name -> dispatcher block name
+	ndispatch int
+}
+
+type loopInfo struct {
+	breakUsed    bool
+	continueUsed bool
+}
+
+func newGenerator() *generator {
+	return &generator{labels: make(map[string]atom)}
+}
+
+// funcBody returns function body forms.
+// Last form value is the function result.
+func (g *generator) funcBody(body []sexp.Form) []node {
+	forms := g.stmtList(body, true)
+	if g.returnUsed {
+		return []node{call("cl-block", atom("nil")).body(forms)}
+	}
+	return forms
+}
+
+func (g *generator) params(fn *sexp.Func) list {
+	res := make(list, 0, len(fn.Params)+1)
+	nblank := 0
+	for i, param := range fn.Params {
+		if fn.Variadic && i == len(fn.Params)-1 {
+			res = append(res, atom("&rest"))
+		}
+		// Blank params are never referenced, but
+		// duplicated argument names are reported by Emacs.
+		if param == "_" {
+			nblank++
+			if nblank > 1 {
+				param = fmt.Sprintf("_%d", nblank)
+			}
+		}
+		res = append(res, localName(param))
+	}
+	return res
+}
+
+// localName returns variable symbol for Go local name.
+// Emacs Lisp constants can not be bound, so they are renamed.
+func localName(name string) atom {
+	switch name {
+	case "t", "nil":
+		return symbol(name + "_")
+	default:
+		return symbol(name)
+	}
+}
+
+// rawForm wraps already generated code, so it
+// can be a part of generated statement list.
+type rawForm struct{ node node }
+
+func (form rawForm) Type() types.Type { return xtypes.TypVoid }
+func (form rawForm) Copy() sexp.Form  { return form }
+func (form rawForm) Cost() int        { return 0 }
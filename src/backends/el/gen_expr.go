@@ -0,0 +1,159 @@
+package el
+
+import (
+	"exn"
+	"go/types"
+	"sexp"
+	"vmm"
+)
+
+func (g *generator) expr(form sexp.Form) node {
+	switch form := form.(type) {
+	case sexp.Int:
+		return intAtom(int64(form))
+	case sexp.Float:
+		return floatAtom(float64(form))
+	case sexp.Str:
+		return strAtom(string(form))
+	case sexp.Symbol:
+		return quoted(form.Val)
+	case sexp.Bool:
+		if form {
+			return atom("t")
+		}
+		return atom("nil")
+	case sexp.Var:
+		return symbol(form.Name)
+	case sexp.Local:
+		return localName(form.Name)
+
+	case *sexp.SparseArrayLit:
+		return g.sparseArrayLit(form)
+	case *sexp.ArrayIndex:
+		return call("aref", g.expr(form.Array), g.expr(form.Index))
+	case *sexp.Call:
+		return call(string(symbol(form.Fn.Name)), g.exprList(form.Args)...)
+	case *sexp.LispCall:
+		return call(string(symbol(form.Fn.Sym)), g.exprList(form.Args)...)
+	case *sexp.LambdaCall:
+		return g.lambdaCall(form)
+	case *sexp.DynCall:
+		args := append([]node{g.expr(form.Callable)}, g.exprList(form.Args)...)
+		return call("funcall", args...)
+	case *sexp.StructLit:
+		return g.structLit(form)
+	case *sexp.StructIndex:
+		return g.structIndex(form)
+	case *sexp.Let:
+		return call(letName(len(form.Bindings)),
+			g.bindings(form.Bindings), g.expr(form.Expr))
+	case *sexp.TypeCast:
+		return g.expr(form.Form)
+	case *sexp.And:
+		return call("and", g.expr(form.X), g.expr(form.Y))
+	case *sexp.Or:
+		return call("or", g.expr(form.X), g.expr(form.Y))
+
+	default:
+		panic(exn.Logic("unexpected expr: %#v", form))
+	}
+}
+
+func (g *generator) exprList(forms []sexp.Form) []node {
+	res := make([]node, len(forms))
+	for i, form := range forms {
+		res[i] = g.expr(form)
+	}
+	return res
+}
+
+func (g *generator) sparseArrayLit(form *sexp.SparseArrayLit) node {
+	ctor := g.expr(form.Ctor)
+	if len(form.Vals) == 0 {
+		return ctor
+	}
+	array := atom("_array")
+	res := call("let", list{list{array, ctor}})
+	for i, val := range form.Vals {
+		res = append(res, call("aset",
+			array, intAtom(int64(form.Indexes[i])), g.expr(val),
+		))
+	}
+	return append(res, array)
+}
+
+// lambdaCall generates inlined function body.
+// Function parameters become "let" bindings.
+func (g *generator) lambdaCall(form *sexp.LambdaCall) node {
+	returnUsed, labels := g.returnUsed, g.labels
+	g.returnUsed, g.labels = false, make(map[string]atom)
+	defer func() {
+		g.returnUsed, g.labels = returnUsed, labels
+	}()
+
+	binds := g.bindings(form.Args)
+	body := g.stmtList(form.Body, true)
+	var res node
+	if len(binds) == 0 {
+		res = progn(body)
+	} else {
+		res = call(letName(len(binds)), binds).body(body)
+	}
+	if g.returnUsed {
+		return call("cl-block", atom("nil"), res)
+	}
+	return res
+}
+
+func (g *generator) structLit(form *sexp.StructLit) node {
+	structTyp := form.Type().Underlying().(*types.Struct)
+	vals := g.exprList(form.Vals)
+	switch vmm.StructReprOf(structTyp) {
+	case vmm.StructCons:
+		res := vals[len(vals)-1]
+		for i := len(vals) - 2; i >= 0; i-- {
+			res = call("cons", vals[i], res)
+		}
+		return res
+	case vmm.StructVec:
+		return call("vector", vals...)
+	default: // vmm.StructUnit
+		return call("list", vals[0])
+	}
+}
+
+func (g *generator) structIndex(form *sexp.StructIndex) node {
+	s := g.expr(form.Struct)
+	switch vmm.StructReprOf(form.Typ) {
+	case vmm.StructCons:
+		if form.Typ.NumFields() == form.Index+1 { // Last member
+			return nthcdr(form.Index, s)
+		}
+		switch form.Index {
+		case 0:
+			return call("car", s)
+		case 1:
+			return call("cadr", s)
+		default:
+			return call("nth", intAtom(int64(form.Index)), s)
+		}
+	case vmm.StructVec:
+		return call("aref", s, intAtom(int64(form.Index)))
+	default: // vmm.StructUnit
+		return call("car", s)
+	}
+}
+
+// nthcdr returns shortest form that takes n-th cdr of the list.
+func nthcdr(n int, l node) node {
+	switch n {
+	case 0:
+		return l
+	case 1:
+		return call("cdr", l)
+	case 2:
+		return call("cddr", l)
+	default:
+		return call("nthcdr", intAtom(int64(n)), l)
+	}
+}
@@ -0,0 +1,540 @@
+package el
+
+import (
+	"exn"
+	"fmt"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"sexp"
+	"vmm"
+)
+
+// stmtList generates statement sequence.
+// If tail is set, value of the last generated form
+// is used as a result of enclosing function.
+func (g *generator) stmtList(forms []sexp.Form, tail bool) []node {
+	forms = flatten(forms)
+	for _, form := range forms {
+		if _, ok := form.(*sexp.Label); ok {
+			return []node{g.dispatch(forms)}
+		}
+	}
+	return g.seq(forms, tail)
+}
+
+// seq generates flat statement sequence.
+// Bindings wrap the rest of the sequence into "let".
+func (g *generator) seq(forms []sexp.Form, tail bool) []node {
+	var res []node
+	for i, form := range forms {
+		if _, ok := form.(*sexp.Bind); ok {
+			var binds list
+			j := i
+			for ; j < len(forms); j++ {
+				bind, ok := forms[j].(*sexp.Bind)
+				if !ok {
+					break
+				}
+				binds = append(binds, g.binding(bind))
+			}
+			rest := g.seq(forms[j:], tail)
+			return append(res, call(letName(len(binds)), binds).body(rest))
+		}
+		res = append(res, g.stmt(form, tail && i == len(forms)-1)...)
+	}
+	return res
+}
+
+// flatten splices FormList contents and drops empty forms.
+// Blocks are kept intact: their bindings must not leak.
+func flatten(forms []sexp.Form) []sexp.Form {
+	res := make([]sexp.Form, 0, len(forms))
+	for _, form := range forms {
+		if list, ok := form.(sexp.FormList); ok {
+			res = append(res, flatten(list)...)
+		} else if !sexp.IsEmptyForm(form) {
+			res = append(res, form)
+		}
+	}
+	return res
+}
+
+func letName(nbinds int) string {
+	if nbinds > 1 {
+		return "let*"
+	}
+	return "let"
+}
+
+func (g *generator) binding(bind *sexp.Bind) list {
+	return list{localName(bind.Name), g.expr(bind.Init)}
+}
+
+func (g *generator) bindings(binds []*sexp.Bind) list {
+	res := make(list, len(binds))
+	for i, bind := range binds {
+		res[i] = g.binding(bind)
+	}
+	return res
+}
+
+func (g *generator) stmt(form sexp.Form, tail bool) []node {
+	switch form := form.(type) {
+	case rawForm:
+		return []node{form.node}
+	case *sexp.Return:
+		if tail && len(form.Results) == 0 {
+			return nil // Void function result is never used
+		}
+		return []node{g.ret(form, tail)}
+	case *sexp.If:
+		return []node{g.ifStmt(form, tail)}
+	case sexp.Block:
+		return g.stmtList(form, tail)
+	case sexp.FormList:
+		return g.stmtList(form, tail)
+	case *sexp.Bind:
+		return g.seq([]sexp.Form{form}, tail)
+	case *sexp.Rebind:
+		return []node{call("setq", localName(form.Name), g.expr(form.Expr))}
+	case *sexp.VarUpdate:
+		return []node{call("setq", symbol(form.Name), g.expr(form.Expr))}
+	case *sexp.ExprStmt:
+		return []node{g.expr(form.Expr)}
+	case *sexp.Repeat:
+		return []node{g.repeat(form)}
+	case *sexp.DoTimes:
+		return []node{g.doTimes(form)}
+	case *sexp.Loop:
+		return g.loop(form)
+	case *sexp.While:
+		return g.while(form)
+	case *sexp.ArrayUpdate:
+		return []node{call("aset",
+			g.expr(form.Array), g.expr(form.Index), g.expr(form.Expr),
+		)}
+	case *sexp.StructUpdate:
+		return []node{g.structUpdate(form)}
+	case *sexp.Goto:
+		return []node{g.gotoStmt(form)}
+	case *sexp.Let:
+		return []node{g.letStmt(form, tail)}
+	case *sexp.SpecBind:
+		return []node{g.specBind(form)}
+	case *sexp.Scope:
+		return []node{g.scope(form)}
+	case *sexp.Switch:
+		return []node{g.switchStmt(form, tail)}
+	case *sexp.SwitchTrue:
+		return []node{g.switchTrue(form, tail)}
+
+	default:
+		panic(exn.Logic("unexpected stmt: %#v", form))
+	}
+}
+
+func (g *generator) ret(form *sexp.Return, tail bool) node {
+	var res node = atom("nil")
+	if len(form.Results) > 0 {
+		res = g.expr(form.Results[0])
+	}
+	if len(form.Results) > 1 {
+		setq := call("setq")
+		for i, result := range form.Results[1:] {
+			setq = append(setq, symbol(rt.RetVars[i+1]), g.expr(result))
+		}
+		res = call("prog1", res, setq)
+	}
+
+	if tail {
+		return res
+	}
+	g.returnUsed = true
+	if len(form.Results) == 0 {
+		return call("cl-return")
+	}
+	return call("cl-return", res)
+}
+
+func (g *generator) ifStmt(form *sexp.If, tail bool) node {
+	if _, ok := form.Else.(*sexp.If); ok {
+		return g.condChain(form, tail)
+	}
+
+	cond := g.expr(form.Cond)
+	then := g.stmtList(form.Then, tail)
+	els := g.stmtList([]sexp.Form{form.Else}, tail)
+	switch {
+	case len(els) == 0:
+		return call("when", cond).body(then)
+	case len(then) == 0:
+		return call("unless", cond).body(els)
+	default:
+		return call("if", cond, progn(then)).body(els)
+	}
+}
+
+// condChain generates "if ... else if ..." sequence as "cond".
+func (g *generator) condChain(form *sexp.If, tail bool) node {
+	res := call("cond")
+	for {
+		res = append(res, clause(g.expr(form.Cond), g.stmtList(form.Then, tail)))
+		next, ok := form.Else.(*sexp.If)
+		if !ok {
+			break
+		}
+		form = next
+	}
+	if els := g.stmtList([]sexp.Form{form.Else}, tail); len(els) != 0 {
+		res = append(res, clause(atom("t"), els))
+	}
+	return res
+}
+
+// clause returns "cond" clause.
+// Empty body would make clause return the test value.
+func clause(test node, body []node) list {
+	if len(body) == 0 {
+		return list{test, atom("nil")}
+	}
+	return list{test}.body(body)
+}
+
+func (g *generator) letStmt(form *sexp.Let, tail bool) node {
+	body := g.stmtList([]sexp.Form{form.Stmt}, tail)
+	return call(letName(len(form.Bindings)), g.bindings(form.Bindings)).body(body)
+}
+
+// specBind declares variable special inside a new scope,
+// so "let" binds it dynamically even if it has no "defvar".
+func (g *generator) specBind(form *sexp.SpecBind) node {
+	sym := symbol(form.Sym)
+	bind := call("let", list{list{sym, g.expr(form.Init)}})
+	return call("let", list{}).body([]node{
+		call("defvar", sym),
+		bind.body(g.stmtList(form.Body, false)),
+	})
+}
+
+// scopeForms maps scope kinds to Emacs Lisp forms.
+var scopeForms = [...]string{
+	sexp.ScopeExcursion:     "save-excursion",
+	sexp.ScopeRestriction:   "save-restriction",
+	sexp.ScopeCurrentBuffer: "with-current-buffer",
+	sexp.ScopeMatchData:     "save-match-data",
+	sexp.ScopeTempBuffer:    "with-temp-buffer",
+}
+
+func (g *generator) scope(form *sexp.Scope) node {
+	res := call(scopeForms[form.Kind])
+	if form.Kind == sexp.ScopeCurrentBuffer {
+		res = append(res, g.expr(form.Buffer))
+	}
+	return res.body(g.stmtList(form.Body, false))
+}
+
+func (g *generator) structUpdate(form *sexp.StructUpdate) node {
+	s := g.expr(form.Struct)
+	val := g.expr(form.Expr)
+	switch vmm.StructReprOf(form.Typ) {
+	case vmm.StructCons:
+		switch {
+		case form.Index == 0:
+			return call("setcar", s, val)
+		case form.Typ.NumFields() == form.Index+1: // Last member
+			return call("setcdr", nthcdr(form.Index-1, s), val)
+		default:
+			return call("setcar", nthcdr(form.Index, s), val)
+		}
+	case vmm.StructVec:
+		return call("aset", s, intAtom(int64(form.Index)), val)
+	default: // vmm.StructUnit
+		return call("setcar", s, val)
+	}
+}
+
+// Loops.
+
+func (g *generator) pushLoop() {
+	g.loops = append(g.loops, &loopInfo{})
+}
+
+func (g *generator) popLoop() *loopInfo {
+	info := g.loops[len(g.loops)-1]
+	g.loops = g.loops[:len(g.loops)-1]
+	return info
+}
+
+// wrapLoop adds "cl-block" forms that are
+// needed for "break" and "continue" statements.
+func wrapLoop(info *loopInfo, head list, body, post []node) node {
+	if info.continueUsed {
+		body = []node{call("cl-block", atom("continue")).body(body)}
+	}
+	loop := head.body(body).body(post)
+	if info.breakUsed {
+		return call("cl-block", atom("break"), loop)
+	}
+	return loop
+}
+
+// loopScope makes loop initializer bindings visible to the loop.
+func (g *generator) loopScope(init sexp.Form, loop node) []node {
+	forms := flatten([]sexp.Form{init})
+	return g.seq(append(forms, rawForm{node: loop}), false)
+}
+
+func (g *generator) repeat(form *sexp.Repeat) node {
+	g.pushLoop()
+	body := g.stmtList(form.Body, false)
+	head := call("dotimes", list{atom("_"), intAtom(form.N)})
+	return wrapLoop(g.popLoop(), head, body, nil)
+}
+
+func (g *generator) doTimes(form *sexp.DoTimes) node {
+	n := g.expr(form.N)
+	g.pushLoop()
+	body := g.stmtList(form.Body, false)
+	head := call("dotimes", list{localName(form.Iter.Name), n})
+	return wrapLoop(g.popLoop(), head, body, nil)
+}
+
+func (g *generator) loop(form *sexp.Loop) []node {
+	g.pushLoop()
+	body := g.stmtList(form.Body, false)
+	post := g.stmtList([]sexp.Form{form.Post}, false)
+	loop := wrapLoop(g.popLoop(), call("while", atom("t")), body, post)
+	return g.loopScope(form.Init, loop)
+}
+
+func (g *generator) while(form *sexp.While) []node {
+	cond := g.expr(form.Cond)
+	g.pushLoop()
+	body := g.stmtList(form.Body, false)
+	post := g.stmtList([]sexp.Form{form.Post}, false)
+	loop := wrapLoop(g.popLoop(), call("while", cond), body, post)
+	return g.loopScope(form.Init, loop)
+}
+
+// Switches.
+
+func (g *generator) switchStmt(form *sexp.Switch, tail bool) node {
+	typ := form.Expr.Type()
+	tag := g.expr(form.Expr)
+	groups := groupClauses(form.Clauses)
+
+	if isIntSwitch(form) {
+		res := call("cl-case", tag)
+		for _, group := range groups {
+			keys := make(list, len(group))
+			for i, cc := range group {
+				keys[i] = g.expr(cc.Expr)
+			}
+			var key node = keys
+			if len(keys) == 1 {
+				key = keys[0]
+			}
+			res = append(res, list{key}.body(g.stmtList(group[0].Body, tail)))
+		}
+		if len(form.DefaultBody) != 0 {
+			res = append(res, list{atom("t")}.body(g.stmtList(form.DefaultBody, tail)))
+		}
+		return res
+	}
+
+	cmp := comparatorEq(typ)
+	if cmp == "" {
+		panic(exn.NoImpl("can not switch over `%s'", typ))
+	}
+	it := atom("_it")
+	cond := g.switchCond(form.SwitchBody, groups, tail, func(x sexp.Form) node {
+		return call(cmp, it, g.expr(x))
+	})
+	return call("let", list{list{it, tag}}, cond)
+}
+
+func (g *generator) switchTrue(form *sexp.SwitchTrue, tail bool) node {
+	groups := groupClauses(form.Clauses)
+	return g.switchCond(form.SwitchBody, groups, tail, g.expr)
+}
+
+func (g *generator) switchCond(b sexp.SwitchBody, groups [][]sexp.CaseClause, tail bool, test func(sexp.Form) node) node {
+	res := call("cond")
+	for _, group := range groups {
+		tests := make([]node, len(group))
+		for i, cc := range group {
+			tests[i] = test(cc.Expr)
+		}
+		var cond node = tests[0]
+		if len(tests) > 1 {
+			cond = call("or", tests...)
+		}
+		res = append(res, clause(cond, g.stmtList(group[0].Body, tail)))
+	}
+	if len(b.DefaultBody) != 0 {
+		res = append(res, clause(atom("t"), g.stmtList(b.DefaultBody, tail)))
+	}
+	return res
+}
+
+// groupClauses joins adjacent clauses that share the body,
+// like "case 1, 2:" does.
+func groupClauses(clauses []sexp.CaseClause) [][]sexp.CaseClause {
+	var groups [][]sexp.CaseClause
+	for i, cc := range clauses {
+		if i != 0 && sameBlock(clauses[i-1].Body, cc.Body) {
+			last := len(groups) - 1
+			groups[last] = append(groups[last], cc)
+		} else {
+			groups = append(groups, []sexp.CaseClause{cc})
+		}
+	}
+	return groups
+}
+
+func sameBlock(a, b sexp.Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// isIntSwitch reports whether switch can be expressed with "cl-case".
+func isIntSwitch(form *sexp.Switch) bool {
+	typ, ok := form.Expr.Type().Underlying().(*types.Basic)
+	if !ok || typ.Info()&types.IsInteger == 0 {
+		return false
+	}
+	for _, cc := range form.Clauses {
+		if _, ok := cc.Expr.(sexp.Int); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// comparatorEq returns equality predicate name for switch tag type.
+// Returns empty string if comparison is not supported.
+func comparatorEq(typ types.Type) string {
+	switch typ := typ.(type) {
+	case *types.Basic:
+		return basicComparatorEq(typ)
+	case *types.Named:
+		if typ == lisp.TypSymbol {
+			return "eq"
+		}
+		if typ, ok := typ.Underlying().(*types.Basic); ok {
+			return basicComparatorEq(typ)
+		}
+		return ""
+	default:
+		return "eq"
+	}
+}
+
+func basicComparatorEq(typ *types.Basic) string {
+	if typ.Info()&types.IsNumeric != 0 {
+		return "="
+	} else if typ.Kind() == types.String {
+		return "string="
+	}
+	return ""
+}
+
+// Jumps.
+
+func (g *generator) gotoStmt(form *sexp.Goto) node {
+	switch form.LabelName {
+	case "continue":
+		g.innerLoop().continueUsed = true
+		return call("cl-return-from", atom("continue"))
+	case "break":
+		g.innerLoop().breakUsed = true
+		return call("cl-return-from", atom("break"))
+	default:
+		block, ok := g.labels[form.LabelName]
+		if !ok {
+			panic(exn.Logic("goto to undefined label `%s'", form.LabelName))
+		}
+		return call("cl-return-from", block, labelKey(form.LabelName))
+	}
+}
+
+func (g *generator) innerLoop() *loopInfo {
+	if len(g.loops) == 0 {
+		panic(exn.NoImpl("break/continue outside of loop"))
+	}
+	return g.loops[len(g.loops)-1]
+}
+
+func labelKey(name string) atom {
+	return symbol(":" + name)
+}
+
+// dispatch generates statement list that contains labels.
+//
+// Labels split the list into segments; each segment
+// becomes "cl-case" clause that evaluates to the next
+// segment key. "goto" returns the key of its target.
+// Bindings are hoisted, so they are visible in all segments.
+func (g *generator) dispatch(forms []sexp.Form) node {
+	g.ndispatch++
+	block := atom(fmt.Sprintf("goto-%d", g.ndispatch))
+
+	keys := []atom{":entry-point"}
+	segments := [][]sexp.Form{nil}
+	var vars list
+	for _, form := range forms {
+		switch form := form.(type) {
+		case *sexp.Label:
+			// Registered before segments are generated:
+			// forward jumps are permitted.
+			g.labels[form.Name] = block
+			if len(segments[0]) == 0 && len(keys) == 1 {
+				keys[0] = labelKey(form.Name)
+			} else {
+				keys = append(keys, labelKey(form.Name))
+				segments = append(segments, nil)
+			}
+		case *sexp.Bind:
+			vars = append(vars, localName(form.Name))
+			seg := &segments[len(segments)-1]
+			*seg = append(*seg, &sexp.Rebind{Name: form.Name, Expr: form.Init})
+		default:
+			seg := &segments[len(segments)-1]
+			*seg = append(*seg, form)
+		}
+	}
+
+	label := atom("goto-label")
+	dispatch := call("cl-case", label)
+	for i, seg := range segments {
+		body := g.seq(seg, false)
+		if !endsWithJump(seg) {
+			if i+1 < len(segments) {
+				body = append(body, keys[i+1])
+			} else {
+				body = append(body, atom("nil"))
+			}
+		}
+		dispatch = append(dispatch, list{keys[i]}.body(body))
+	}
+
+	vars = append(vars, list{label, keys[0]})
+	loop := call("while", label,
+		call("setq", label, call("cl-block", block, dispatch)))
+	return call("let", vars, loop)
+}
+
+func endsWithJump(forms []sexp.Form) bool {
+	if len(forms) == 0 {
+		return false
+	}
+	switch forms[len(forms)-1].(type) {
+	case *sexp.Goto, *sexp.Return:
+		return true
+	default:
+		return false
+	}
+}
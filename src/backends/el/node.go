@@ -0,0 +1,106 @@
+package el
+
+import (
+	"bytes"
+	"magic_pkg/emacs/lisp"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// node is a printable Emacs Lisp object: atom or list.
+type node interface{}
+
+// atom is printed as is.
+type atom string
+
+// list is printed as parenthesized sequence of nodes.
+type list []node
+
+func call(fn string, args ...node) list {
+	return append(list{atom(fn)}, args...)
+}
+
+// body appends forms to the list.
+// Used for special forms like "let" and "while".
+func (l list) body(forms []node) list {
+	return append(l, forms...)
+}
+
+func symbol(name string) atom {
+	return atom(lisp.Symbol(name).Literal())
+}
+
+func quoted(name string) atom {
+	switch {
+	case name == "nil" || name == "t":
+		return atom(name)
+	case strings.HasPrefix(name, ":"):
+		return symbol(name)
+	default:
+		return atom("'" + lisp.Symbol(name).Literal())
+	}
+}
+
+func intAtom(x int64) atom {
+	return atom(strconv.FormatInt(x, 10))
+}
+
+// floatAtom never produces literal that is read back as integer.
+func floatAtom(x float64) atom {
+	switch {
+	case math.IsNaN(x):
+		return "0.0e+NaN"
+	case math.IsInf(x, 1):
+		return "1.0e+INF"
+	case math.IsInf(x, -1):
+		return "-1.0e+INF"
+	}
+	s := strconv.FormatFloat(x, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return atom(s)
+}
+
+func strAtom(s string) atom {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+	return atom(buf.String())
+}
+
+// docAtom is like strAtom, but keeps newlines as is.
+// Open parenthesis at the line start is escaped, so it
+// does not confuse Emacs defun navigation.
+func docAtom(s string) atom {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n(", "\n\\(", -1)
+	return atom(`"` + s + `"`)
+}
+
+// progn wraps multiple forms into single form.
+func progn(forms []node) node {
+	switch len(forms) {
+	case 0:
+		return atom("nil")
+	case 1:
+		return forms[0]
+	default:
+		return call("progn").body(forms)
+	}
+}
@@ -0,0 +1,177 @@
+package el
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// lineWidth is a column limit for printed code.
+// Forms that do not fit are split into multiple lines.
+const lineWidth = 79
+
+// Number of arguments that are printed on the same
+// line with special form name; others are treated as body
+// and indented by 2 spaces.
+var specialForms = map[atom]int{
+	"defun":    2,
+	"defvar":   1,
+	"let":      1,
+	"let*":     1,
+	"when":     1,
+	"unless":   1,
+	"while":    1,
+	"dotimes":  1,
+	"cl-block": 1,
+	"cl-case":  1,
+	"prog1":    1,
+	"progn":    0,
+	"if":       1, // Then branch is indented by 4 spaces
+
+	"save-excursion":      0,
+	"save-restriction":    0,
+	"save-match-data":     0,
+	"with-temp-buffer":    0,
+	"with-current-buffer": 1,
+}
+
+// printer formats nodes in a way Emacs would indent them.
+type printer struct {
+	buf bytes.Buffer
+	col int // Current column
+}
+
+func (p *printer) write(s string) {
+	p.buf.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i != -1 {
+		p.col = len(s) - i - 1
+	} else {
+		p.col += len(s)
+	}
+}
+
+func (p *printer) newline(indent int) {
+	p.buf.WriteByte('\n')
+	p.buf.WriteString(strings.Repeat(" ", indent))
+	p.col = indent
+}
+
+// printTop prints top level form followed by blank line.
+func (p *printer) printTop(x node) {
+	p.print(x)
+	p.write("\n\n")
+}
+
+func (p *printer) print(x node) {
+	flat := flatString(x)
+	l, ok := x.(list)
+	if !ok || len(l) == 0 || (p.fits(flat) && !hasDocString(l)) {
+		p.write(flat)
+		return
+	}
+
+	col := p.col
+	head, ok := l[0].(atom)
+	if !ok || !isSymbol(head) {
+		// Data list: elements are aligned.
+		p.write("(")
+		p.print(l[0])
+		for _, x := range l[1:] {
+			p.newline(col + 1)
+			p.print(x)
+		}
+		p.write(")")
+		return
+	}
+
+	p.write("(" + string(head))
+	if n, ok := specialForms[head]; ok {
+		i := 1
+		for ; i <= n && i < len(l); i++ {
+			p.write(" ")
+			p.print(l[i])
+		}
+		if head == "if" && i < len(l) {
+			p.newline(col + 4)
+			p.print(l[i])
+			i++
+		}
+		for ; i < len(l); i++ {
+			p.newline(col + 2)
+			p.print(l[i])
+		}
+	} else if len(l) > 1 {
+		// Function call: arguments are aligned with the first one.
+		p.write(" ")
+		argCol := p.col
+		p.print(l[1])
+		fill := allAtoms(l[2:])
+		for _, x := range l[2:] {
+			// Atom arguments are filled, so long
+			// constructor calls do not take a line per element.
+			if fill && p.fits(" "+string(x.(atom))) {
+				p.write(" ")
+			} else {
+				p.newline(argCol)
+			}
+			p.print(x)
+		}
+	}
+	p.write(")")
+}
+
+func (p *printer) fits(flat string) bool {
+	return p.col+len(flat) <= lineWidth && !strings.Contains(flat, "\n")
+}
+
+func flatString(x node) string {
+	var buf bytes.Buffer
+	writeFlat(&buf, x)
+	return buf.String()
+}
+
+func writeFlat(buf *bytes.Buffer, x node) {
+	switch x := x.(type) {
+	case atom:
+		buf.WriteString(string(x))
+	case list:
+		buf.WriteByte('(')
+		for i, x := range x {
+			if i != 0 {
+				buf.WriteByte(' ')
+			}
+			writeFlat(buf, x)
+		}
+		buf.WriteByte(')')
+	}
+}
+
+// hasDocString reports whether l is a documented "defun".
+// Documentation is always printed on its own line.
+func hasDocString(l list) bool {
+	if len(l) < 4 || l[0] != atom("defun") {
+		return false
+	}
+	doc, ok := l[3].(atom)
+	return ok && doc[0] == '"'
+}
+
+func allAtoms(nodes []node) bool {
+	for _, x := range nodes {
+		if _, ok := x.(atom); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isSymbol reports whether atom is a non-keyword symbol.
+// Lists that start with other atoms are printed as data.
+func isSymbol(x atom) bool {
+	switch x[0] {
+	case ':', '"', '\'':
+		return false
+	}
+	_, err := strconv.ParseFloat(string(x), 64)
+	return err != nil
+}
@@ -0,0 +1,110 @@
+package el
+
+import (
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"opt"
+	"sexp"
+	"sexpconv"
+	"tu/symbols"
+	"xtypes"
+)
+
+// Simplify lowers forms that have no direct Emacs Lisp
+// counterpart into runtime calls.
+//
+// Unlike lapc.Simplify, it keeps control flow forms
+// (switches and counting loops) intact, because Emacs Lisp
+// has readable equivalents for them.
+// Type casts are kept too: switch generation depends on tag type.
+func Simplify(form sexp.Form) sexp.Form {
+	return sexp.Rewrite(form, simplify)
+}
+
+func simplify(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case *sexp.SliceLit:
+		return simplifiedCall(
+			rt.FnArrayToSlice,
+			sexp.NewLispCall(lisp.FnVector, form.Vals...),
+		)
+
+	case *sexp.ArrayLit:
+		return sexp.NewLispCall(lisp.FnVector, simplifyList(form.Vals)...)
+
+	case *sexp.ArraySlice:
+		array := form.Array
+		switch form.Kind() {
+		case sexp.SpanLowOnly:
+			return simplifiedCall(rt.FnArraySliceLow, array, form.Low)
+		case sexp.SpanHighOnly:
+			return simplifiedCall(rt.FnArraySliceHigh, array, form.High)
+		case sexp.SpanBoth:
+			return simplifiedCall(rt.FnArraySlice2, array, form.Low, form.High)
+		case sexp.SpanWhole:
+			return simplifiedCall(rt.FnArrayToSlice, array)
+		}
+
+	case *sexp.SliceSlice:
+		slice := form.Slice
+		switch form.Kind() {
+		case sexp.SpanLowOnly:
+			return simplifiedCall(rt.FnSliceSliceLow, slice, form.Low)
+		case sexp.SpanHighOnly:
+			return simplifiedCall(rt.FnSliceSliceHigh, slice, form.High)
+		case sexp.SpanBoth:
+			return simplifiedCall(rt.FnSliceSlice2, slice, form.Low, form.High)
+		case sexp.SpanWhole:
+			return Simplify(form.Slice)
+		}
+
+	case *sexp.TypeAssert:
+		typ := sexp.Symbol{Val: symbols.MangleType(form.Typ)}
+		if form.CommaOk {
+			zv := sexpconv.ZeroValue(form.Typ)
+			if isGoInterface(form.Typ) {
+				return simplifiedCall(rt.FnTypeAssertIfaceOk, form.Expr, typ, zv)
+			}
+			return simplifiedCall(rt.FnTypeAssertOk, form.Expr, typ, zv)
+		}
+		if isGoInterface(form.Typ) {
+			return simplifiedCall(rt.FnTypeAssertIface, form.Expr, typ)
+		}
+		return simplifiedCall(rt.FnTypeAssert, form.Expr, typ)
+
+	// sexp.Rewrite does not visit these parts.
+	case *sexp.DoTimes:
+		form.N = Simplify(form.N)
+	case *sexp.While:
+		form.Init = Simplify(form.Init)
+	case *sexp.Loop:
+		form.Init = Simplify(form.Init)
+		form.Post = Simplify(form.Post)
+	}
+
+	return nil
+}
+
+func simplifyList(forms []sexp.Form) []sexp.Form {
+	for i, form := range forms {
+		forms[i] = Simplify(form)
+	}
+	return forms
+}
+
+func simplifiedCall(fn *sexp.Func, args ...sexp.Form) sexp.Form {
+	call := sexp.NewCall(fn, args...)
+	inlinedCall := opt.TryInline(call)
+	return Simplify(inlinedCall)
+}
+
+// isGoInterface reports whether typ is an interface type
+// that is not defined in "emacs/lisp" package.
+func isGoInterface(typ types.Type) bool {
+	named := xtypes.AsNamedType(typ)
+	if named != nil && named.Obj().Pkg() == lisp.Package {
+		return false
+	}
+	return types.IsInterface(typ)
+}
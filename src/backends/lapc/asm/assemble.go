@@ -2,6 +2,7 @@ package asm
 
 import (
 	"backends/lapc/ir"
+	"go/token"
 )
 
 func emit(as *Assembler, ins *ir.Instr) {
@@ -16,10 +17,29 @@ func emit(as *Assembler, ins *ir.Instr) {
 
 func assembleList(as *Assembler, u yUnit) {
 	for cur := u; cur != nil; cur = cur.Next {
+		if cur.Kind != ir.Empty && cur.Kind != ir.Label {
+			assemblePos(as, cur.Pos)
+		}
 		assembleInstr(as, cur)
 	}
 }
 
+// assemblePos writes "position" pseudo instruction if pos
+// differs from the position of previous instruction.
+// Its operand is an index inside Object.CodePositions.
+func assemblePos(as *Assembler, pos token.Pos) {
+	if !pos.IsValid() {
+		return
+	}
+	if n := len(as.positions); n != 0 && as.positions[n-1] == pos {
+		return
+	}
+	writeOp1(&as.buf, positionOp, int32(len(as.positions)))
+	as.positions = append(as.positions, pos)
+}
+
+var positionOp = []byte("position")
+
 func assembleInstr(as *Assembler, ins *ir.Instr) {
 	switch ins.Kind {
 	case ir.Empty:
@@ -40,14 +60,17 @@ func assembleInstr(as *Assembler, ins *ir.Instr) {
 	}
 }
 
+// Variable references are lowered in place,
+// so bytecode encoder sees only VM instructions.
+
 func assembleXvarRef(as *Assembler, ins *ir.Instr) {
-	cvIndex := as.cvec.InsertSym(ins.Meta)
-	emit(as, &ir.Instr{Kind: ir.VarRef, Data: int32(cvIndex)})
+	ins.Kind, ins.Data = ir.VarRef, int32(as.cvec.InsertSym(ins.Meta))
+	emit(as, ins)
 }
 
 func assembleXvarSet(as *Assembler, ins *ir.Instr) {
-	cvIndex := as.cvec.InsertSym(ins.Meta)
-	emit(as, &ir.Instr{Kind: ir.VarSet, Data: int32(cvIndex)})
+	ins.Kind, ins.Data = ir.VarSet, int32(as.cvec.InsertSym(ins.Meta))
+	emit(as, ins)
 }
 
 func assembleLabel(as *Assembler, ins *ir.Instr) {
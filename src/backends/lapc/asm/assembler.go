@@ -1,25 +1,35 @@
 package asm
 
 import (
+	"backends/lapc/bytecode"
 	"backends/lapc/ir"
 	"bytes"
+	"cfg"
 	"dt"
+	"go/token"
 )
 
 type Assembler struct {
 	buf  bytes.Buffer
 	unit *ir.Unit
 	cvec *dt.ConstPool
+	enc  *bytecode.Encoder
+
+	positions []token.Pos // Operands of "position" IR instructions
 }
 
 type Object struct {
-	Code       []byte
-	StackUsage int
+	Code          []byte
+	CodePositions []token.Pos // Indexed by "position" operands of Code
+	Bytecode      []byte
+	Positions     []bytecode.PosEntry
+	StackUsage    int
 }
 
 func NewAssembler(cvec *dt.ConstPool) *Assembler {
 	return &Assembler{
 		cvec: cvec,
+		enc:  bytecode.NewEncoder(),
 	}
 }
 
@@ -27,20 +37,44 @@ func (as *Assembler) Assemble(params []string, u *ir.Unit) Object {
 	as.reset(params, u)
 
 	xUnit := xUnit(u.Result())
-	optimizeX(xUnit)
+	if cfg.ClOptimizeBytecode {
+		optimizeX(xUnit, as.cvec)
+	}
 
-	yUnit, maxStackLen := makeY(params, xUnit)
-	optimizeY(yUnit)
+	yUnit := makeY(params, xUnit)
+	if cfg.ClOptimizeBytecode {
+		optimizeY(yUnit, as.cvec)
+	}
 
 	assembleList(as, yUnit)
 
+	code := as.enc.Encode(yUnit)
+	as.resolveJumpTables()
+
 	return Object{
-		Code:       as.buf.Bytes(),
-		StackUsage: maxStackLen,
+		Code:          as.buf.Bytes(),
+		CodePositions: as.positions,
+		Bytecode:      code,
+		Positions:     as.enc.Positions(),
+		StackUsage:    stackUsage(params, yUnit, as.cvec),
+	}
+}
+
+// resolveJumpTables assigns bytecode offsets to jump table targets.
+func (as *Assembler) resolveJumpTables() {
+	for i := 0; i < as.cvec.Len(); i++ {
+		table, ok := as.cvec.Get(uint16(i)).(*dt.JumpTable)
+		if !ok {
+			continue
+		}
+		for j := range table.Targets {
+			table.Targets[j].PC = as.enc.LabelOffset(table.Targets[j].Label)
+		}
 	}
 }
 
 func (as *Assembler) reset(params []string, u *ir.Unit) {
 	as.unit = u
 	as.buf.Truncate(0)
+	as.positions = nil
 }
@@ -2,10 +2,180 @@ package asm
 
 import (
 	"backends/lapc/ir"
+	"dt"
+	"magic_pkg/emacs/lisp"
 )
 
 type xUnit *ir.Instr
 
-func optimizeX(ins xUnit) {
-	// #TODO.
+// optimizeX performs control flow optimizations.
+//
+// Stack depths are not known at this phase: makeY computes
+// them by linear simulation, so rewrites must preserve the
+// stack effect of every instruction sequence they replace.
+// Stack-aware rewrites are performed by optimizeY.
+func optimizeX(u xUnit, cvec *dt.ConstPool) {
+	opt := optimizerX{cvec: cvec}
+	opt.Optimize(u)
+}
+
+type optimizerX struct {
+	cvec    *dt.ConstPool
+	changed bool
+}
+
+func (opt *optimizerX) Optimize(u xUnit) {
+	for opt.changed = true; opt.changed; {
+		opt.changed = false
+		labels := collectLabels(u)
+		for ins := (*ir.Instr)(u); ins != nil; ins = ins.Next {
+			switch ins.Kind {
+			case ir.JmpNil, ir.JmpNotNil, ir.JmpNilElsePop, ir.JmpNotNilElsePop:
+				if opt.foldBranch(ins) {
+					continue
+				}
+				opt.threadJump(labels, ins)
+				opt.changed = removeJmpToNext(ins) || opt.changed
+			case ir.Jmp, ir.Xgoto:
+				opt.threadJump(labels, ins)
+				opt.changed = removeJmpToNext(ins) || opt.changed
+			}
+		}
+		opt.changed = removeUnusedLabels(u, opt.cvec) || opt.changed
+	}
+}
+
+// foldBranch simplifies conditional jump which operand
+// is a constant or a "not" result.
+// Returns true if ins was removed or converted to "goto".
+func (opt *optimizerX) foldBranch(ins *ir.Instr) bool {
+	switch prev := ins.Prev; prev.Kind {
+	case ir.ConstRef:
+		isNil := opt.cvec.Get(uint16(prev.Data)) == lisp.Symbol("nil")
+		taken := isNil == (ins.Kind == ir.JmpNil || ins.Kind == ir.JmpNilElsePop)
+		switch {
+		case !taken:
+			prev.Remove()
+			ins.Remove()
+		case ins.Kind == ir.JmpNil || ins.Kind == ir.JmpNotNil:
+			prev.Remove()
+			ins.Kind = ir.Jmp
+		default:
+			// Taken "else-pop" jump leaves value on the stack;
+			// it can not be expressed without changing depths.
+			return false
+		}
+		opt.changed = true
+		return true
+
+	case ir.Not:
+		switch ins.Kind {
+		case ir.JmpNil:
+			ins.Kind = ir.JmpNotNil
+		case ir.JmpNotNil:
+			ins.Kind = ir.JmpNil
+		default:
+			return false
+		}
+		prev.Remove()
+		opt.changed = true
+	}
+	return false
+}
+
+// threadJump retargets jump that leads to another jump.
+func (opt *optimizerX) threadJump(labels map[int32]*ir.Instr, ins *ir.Instr) {
+	// Limit is needed to stop on jump cycles.
+	for i := 0; i < 8; i++ {
+		dst := jumpDest(labels, ins)
+		if dst == nil || dst == ins {
+			return
+		}
+		switch {
+		case dst.Kind == ir.Jmp:
+			// Jump without discards has the same depth
+			// at the source and at the destination.
+		case dst.Kind == ir.Xgoto && (ins.Kind == ir.Jmp || ins.Kind == ir.Xgoto):
+			// Destination discards are computed for the source instead.
+			ins.Kind = ir.Xgoto
+		case dst.Kind == ins.Kind && (ins.Kind == ir.JmpNilElsePop || ins.Kind == ir.JmpNotNilElsePop):
+			// Destination sees the same value, so it is taken too.
+		default:
+			return
+		}
+		ins.Data, ins.Meta = dst.Data, dst.Meta
+		opt.changed = true
+	}
+}
+
+// collectLabels maps label IDs to label instructions.
+func collectLabels(ins *ir.Instr) map[int32]*ir.Instr {
+	labels := make(map[int32]*ir.Instr)
+	for ; ins != nil; ins = ins.Next {
+		if ins.Kind == ir.Label {
+			labels[ins.Data] = ins
+		}
+	}
+	return labels
+}
+
+// jumpDest returns first non-label instruction that is executed
+// after ins jump is taken.
+func jumpDest(labels map[int32]*ir.Instr, ins *ir.Instr) *ir.Instr {
+	dst := labels[ins.Data]
+	for dst != nil && dst.Kind == ir.Label {
+		dst = dst.Next
+	}
+	return dst
+}
+
+// removeJmpToNext removes jump to the label that immediately
+// follows it; conditional jump is replaced by a discard.
+// Returns true if ins was changed.
+func removeJmpToNext(ins *ir.Instr) bool {
+	if ins.Kind == ir.JmpNilElsePop || ins.Kind == ir.JmpNotNilElsePop {
+		return false
+	}
+	for next := ins.Next; next != nil && next.Kind == ir.Label; next = next.Next {
+		if next.Data != ins.Data {
+			continue
+		}
+		switch ins.Kind {
+		case ir.JmpNil, ir.JmpNotNil:
+			ins.Kind, ins.Data, ins.Meta = ir.Discard, 1, ""
+		default:
+			ins.Remove()
+		}
+		return true
+	}
+	return false
+}
+
+// jumpTableOf returns jump table of "switch" instruction.
+func jumpTableOf(cvec *dt.ConstPool, ins *ir.Instr) *dt.JumpTable {
+	return cvec.Get(uint16(ins.Data)).(*dt.JumpTable)
+}
+
+// removeUnusedLabels removes labels that are not jump targets.
+// Returns true if any label was removed.
+func removeUnusedLabels(u *ir.Instr, cvec *dt.ConstPool) bool {
+	used := make(map[int32]bool)
+	for ins := u; ins != nil; ins = ins.Next {
+		switch ins.Kind {
+		case ir.Jmp, ir.Xgoto, ir.JmpNil, ir.JmpNotNil, ir.JmpNilElsePop, ir.JmpNotNilElsePop:
+			used[ins.Data] = true
+		case ir.Switch:
+			for _, target := range jumpTableOf(cvec, ins).Targets {
+				used[target.Label] = true
+			}
+		}
+	}
+	removed := false
+	for ins := u; ins != nil; ins = ins.Next {
+		if ins.Kind == ir.Label && !used[ins.Data] {
+			ins.Remove()
+			removed = true
+		}
+	}
+	return removed
 }
@@ -27,7 +27,7 @@ type labelInfo struct {
 	depth int
 }
 
-func makeY(params []string, u xUnit) (yUnit, int) {
+func makeY(params []string, u xUnit) yUnit {
 	conv := converterY{
 		st:           dt.NewDataStack(params),
 		scopes:       &dt.ScopeStack{},
@@ -37,10 +37,10 @@ func makeY(params []string, u xUnit) (yUnit, int) {
 	return conv.Convert(u)
 }
 
-func (cy *converterY) Convert(u xUnit) (yUnit, int) {
+func (cy *converterY) Convert(u xUnit) yUnit {
 	cy.convert(u)
 	cy.fixBranches()
-	return yUnit(u), cy.st.MaxLen()
+	return yUnit(u)
 }
 
 func (cy *converterY) convert(u xUnit) {
@@ -2,33 +2,94 @@ package asm
 
 import (
 	"backends/lapc/ir"
+	"dt"
 	"vmm"
 )
 
-func optimizeY(u yUnit) {
-	opt := optimizerY{}
+// optimizeY performs peephole optimizations.
+//
+// At this phase all stack operations are explicit and branches
+// are already adjusted, so code can be removed freely.
+func optimizeY(u yUnit, cvec *dt.ConstPool) {
+	opt := optimizerY{cvec: cvec}
 	opt.Optimize(u)
 }
 
-type optimizerY struct{}
+type optimizerY struct {
+	cvec    *dt.ConstPool
+	changed bool
+}
 
 func (opt *optimizerY) Optimize(u yUnit) {
-	for ins := u; ins != nil; ins = ins.Next {
-		switch ins.Kind {
-		case ir.Call:
-			opt.optimizeCall(ins)
-		case ir.Discard:
-			opt.optimizeDiscard(ins)
+	for opt.changed = true; opt.changed; {
+		opt.changed = false
+		labels := collectLabels(u)
+		for ins := (*ir.Instr)(u); ins != nil; ins = ins.Next {
+			switch ins.Kind {
+			case ir.Return:
+				opt.removeUnreachable(ins)
+			case ir.Jmp:
+				opt.optimizeJmp(labels, ins)
+			case ir.JmpNil, ir.JmpNotNil:
+				opt.changed = removeJmpToNext(ins) || opt.changed
+			case ir.Switch:
+				opt.threadSwitch(labels, ins)
+			case ir.Call:
+				opt.optimizeCall(ins)
+			case ir.Discard:
+				opt.optimizeDiscard(ins)
+			case ir.StackSet:
+				opt.optimizeStackSet(ins)
+			}
+		}
+		opt.changed = removeUnusedLabels(u, opt.cvec) || opt.changed
+	}
+}
+
+// removeUnreachable removes instructions that follow ins
+// up to the next label.
+func (opt *optimizerY) removeUnreachable(ins *ir.Instr) {
+	for next := ins.Next; next.Kind != ir.Label && next.Kind != ir.Empty; next = next.Next {
+		next.Remove()
+		opt.changed = true
+	}
+}
+
+func (opt *optimizerY) optimizeJmp(labels map[int32]*ir.Instr, ins *ir.Instr) {
+	if removeJmpToNext(ins) {
+		opt.changed = true
+		return
+	}
+	// Jump to "return" is replaced by "return".
+	if dst := jumpDest(labels, ins); dst != nil && dst.Kind == ir.Return {
+		ins.Kind, ins.Data, ins.Meta = ir.Return, 0, ""
+		opt.changed = true
+	}
+	opt.removeUnreachable(ins)
+}
+
+// threadSwitch retargets jump table entries that lead to "goto".
+// Stack depth of "goto" source and destination is the same.
+func (opt *optimizerY) threadSwitch(labels map[int32]*ir.Instr, ins *ir.Instr) {
+	table := jumpTableOf(opt.cvec, ins)
+	for i := range table.Targets {
+		target := &table.Targets[i]
+		// Limit is needed to stop on jump cycles.
+		for j := 0; j < 8; j++ {
+			dst := jumpDest(labels, &ir.Instr{Data: target.Label})
+			if dst == nil || dst.Kind != ir.Jmp {
+				break
+			}
+			target.Label, target.Name = dst.Data, dst.Meta
+			opt.changed = true
 		}
 	}
 }
 
 func (opt *optimizerY) optimizeCall(ins *ir.Instr) {
-	// If function is "throw", remove next instruction if it is Discard(1).
+	// Code after "throwing" function call is never executed.
 	if vmm.FuncIsThrowing(ins.Meta) {
-		if ins.Next.Kind == ir.Discard && ins.Next.Data == 1 {
-			ins.Next.Remove()
-		}
+		opt.removeUnreachable(ins)
 	}
 }
 
@@ -36,5 +97,38 @@ func (opt *optimizerY) optimizeDiscard(ins *ir.Instr) {
 	// Remove Discard(0).
 	if ins.Data == 0 {
 		ins.Remove()
+		opt.changed = true
+		return
+	}
+	// Merge adjacent discards.
+	if ins.Next.Kind == ir.Discard {
+		ins.Next.Data += ins.Data
+		ins.Remove()
+		opt.changed = true
+		return
+	}
+	// Values without side effects are not pushed at all.
+	switch ins.Prev.Kind {
+	case ir.StackRef, ir.ConstRef:
+		ins.Prev.Remove()
+		ins.Data--
+		opt.changed = true
+	}
+}
+
+func (opt *optimizerY) optimizeStackSet(ins *ir.Instr) {
+	if ins.Data != 1 {
+		return
+	}
+	switch {
+	case ins.Next.Kind == ir.Return:
+		// "return" takes the same value that was stored.
+		ins.Remove()
+		opt.changed = true
+	case ins.Prev.Kind == ir.StackRef && ins.Prev.Data == 0:
+		// Value is stored into its own slot.
+		ins.Prev.Remove()
+		ins.Remove()
+		opt.changed = true
 	}
 }
@@ -0,0 +1,87 @@
+package asm
+
+import (
+	"backends/lapc/ir"
+	"dt"
+	"vmm"
+)
+
+// stackUsage returns max stack depth of Y unit.
+//
+// makeY simulates instructions in linear order; after optimizeY
+// the result may be too pessimistic, so the depth is recomputed
+// by following branches; unreachable code is not counted.
+func stackUsage(params []string, u yUnit, cvec *dt.ConstPool) int {
+	type path struct {
+		ins   *ir.Instr
+		depth int
+	}
+
+	labels := collectLabels(u)
+	visited := make(map[*ir.Instr]bool)
+	maxDepth := len(params)
+	paths := []path{{ins: u, depth: len(params)}}
+	for len(paths) != 0 {
+		p := paths[len(paths)-1]
+		paths = paths[:len(paths)-1]
+
+		depth := p.depth
+	walk:
+		for ins := p.ins; ins != nil && !visited[ins]; ins = ins.Next {
+			visited[ins] = true
+			switch ins.Kind {
+			case ir.Return:
+				break walk
+			case ir.Jmp:
+				paths = append(paths, path{ins: labels[ins.Data], depth: depth})
+				break walk
+			case ir.JmpNilElsePop, ir.JmpNotNilElsePop:
+				// Value is popped only if jump is not taken.
+				paths = append(paths, path{ins: labels[ins.Data], depth: depth})
+			case ir.JmpNil, ir.JmpNotNil:
+				paths = append(paths, path{ins: labels[ins.Data], depth: depth - 1})
+			case ir.Switch:
+				// Both value and jump table are popped.
+				for _, target := range jumpTableOf(cvec, ins).Targets {
+					paths = append(paths, path{ins: labels[target.Label], depth: depth - 2})
+				}
+			}
+
+			depth += stackEffect(ins)
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			if ins.Kind == ir.Call && vmm.FuncIsThrowing(ins.Meta) {
+				break walk
+			}
+		}
+	}
+
+	return maxDepth
+}
+
+// stackEffect returns stack depth change caused by ins.
+func stackEffect(ins *ir.Instr) int {
+	enc := ir.EncodingOf(ins.Kind)
+	delta := 0
+
+	switch enc.Input {
+	case ir.AttrTake1:
+		delta = -1
+	case ir.AttrTake2:
+		delta = -2
+	case ir.AttrTake3:
+		delta = -3
+	case ir.AttrTakeN:
+		delta = -int(ins.Data)
+	case ir.AttrTakeNplus1:
+		delta = -int(ins.Data + 1)
+	}
+
+	switch enc.Output {
+	case ir.AttrDupNth, ir.AttrPushTmp, ir.AttrPushConst, ir.AttrPushAndDiscard:
+		delta++
+	}
+
+	return delta
+}
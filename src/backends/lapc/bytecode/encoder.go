@@ -0,0 +1,243 @@
+// Package bytecode converts lapc IR into Emacs VM bytecode.
+//
+// It does the same job as "byte-compile-lapcode", so
+// translated packages can be compiled without running Emacs.
+package bytecode
+
+import (
+	"backends/lapc/ir"
+	"exn"
+	"go/token"
+)
+
+// Encoder produces Emacs bytecode out of finalized IR.
+// Only Emacs VM instructions are accepted; all X-phase
+// pseudo instructions must be already lowered.
+//
+// Encoder is reusable. Returned bytecode is valid until the next Encode call.
+type Encoder struct {
+	buf       []byte
+	labels    map[int32]int // Label ID -> bytecode offset
+	patches   []jumpPatch
+	positions []PosEntry
+}
+
+// PosEntry maps bytecode offset to the source position.
+// Position is valid until the offset of the next entry.
+type PosEntry struct {
+	PC  int
+	Pos token.Pos
+}
+
+// jumpPatch is a jump target that is resolved after all labels are known.
+type jumpPatch struct {
+	pos   int   // Offset of 2 byte jump operand
+	label int32 // Jump target label ID
+}
+
+// NewEncoder returns fresh bytecode encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{labels: make(map[int32]int)}
+}
+
+// Encode returns bytecode for given instruction list.
+func (enc *Encoder) Encode(code *ir.Instr) []byte {
+	enc.reset()
+
+	for ins := code; ins != nil; ins = ins.Next {
+		enc.encodeInstr(ins)
+	}
+	enc.resolveJumps()
+
+	return enc.buf
+}
+
+// Positions returns position table of the last encoded bytecode.
+// Entries are sorted by PC; instructions without source position
+// are covered by preceding entry.
+// Valid only after Encode call.
+func (enc *Encoder) Positions() []PosEntry {
+	return enc.positions
+}
+
+// LabelOffset returns bytecode offset of label with specified ID.
+// Valid only after Encode call.
+func (enc *Encoder) LabelOffset(id int32) int {
+	pc, ok := enc.labels[id]
+	if !ok {
+		panic(exn.Logic("reference to undefined label %d", id))
+	}
+	return pc
+}
+
+func (enc *Encoder) reset() {
+	enc.buf = enc.buf[:0]
+	enc.patches = enc.patches[:0]
+	enc.positions = enc.positions[:0]
+	for id := range enc.labels {
+		delete(enc.labels, id)
+	}
+}
+
+func (enc *Encoder) encodeInstr(ins *ir.Instr) {
+	if ins.Kind != ir.Empty && ins.Kind != ir.Label {
+		enc.trackPos(ins.Pos)
+	}
+
+	switch ins.Kind {
+	case ir.Empty:
+		// Do nothing
+	case ir.Label:
+		enc.labels[ins.Data] = len(enc.buf)
+	case ir.Jmp, ir.JmpNil, ir.JmpNotNil, ir.JmpNilElsePop, ir.JmpNotNilElsePop:
+		enc.encodeJmp(jumpOps[ins.Kind], ins.Data)
+
+	case ir.ConstRef:
+		enc.encodeConstRef(int(ins.Data))
+	case ir.StackRef:
+		enc.encodeStackRef(int(ins.Data))
+	case ir.StackSet:
+		enc.encodeStackSet(int(ins.Data))
+	case ir.Discard:
+		enc.encodeDiscard(int(ins.Data))
+	case ir.VarRef:
+		enc.encodeOperand(opVarRef, int(ins.Data))
+	case ir.VarSet:
+		enc.encodeOperand(opVarSet, int(ins.Data))
+	case ir.VarBind:
+		enc.encodeOperand(opVarBind, int(ins.Data))
+	case ir.Call:
+		enc.encodeOperand(opCall, int(ins.Data))
+	case ir.Unbind:
+		enc.encodeOperand(opUnbind, int(ins.Data))
+	case ir.List:
+		enc.encodeN(opList1, 1, opListN, int(ins.Data))
+	case ir.Concat:
+		enc.encodeN(opConcat2, 2, opConcatN, int(ins.Data))
+
+	default:
+		if int(ins.Kind) >= len(plainOps) || plainOps[ins.Kind] == 0 {
+			panic(exn.Logic("can not encode `%s' instruction",
+				ir.EncodingOf(ins.Kind).Name))
+		}
+		enc.emit(plainOps[ins.Kind])
+	}
+}
+
+// trackPos adds position table entry if pos differs
+// from the position of previous instruction.
+func (enc *Encoder) trackPos(pos token.Pos) {
+	if !pos.IsValid() {
+		return
+	}
+	pc := len(enc.buf)
+	if n := len(enc.positions); n != 0 {
+		last := &enc.positions[n-1]
+		if last.Pos == pos {
+			return
+		}
+		if last.PC == pc {
+			last.Pos = pos
+			return
+		}
+	}
+	enc.positions = append(enc.positions, PosEntry{PC: pc, Pos: pos})
+}
+
+func (enc *Encoder) emit(bytes ...byte) {
+	enc.buf = append(enc.buf, bytes...)
+}
+
+func (enc *Encoder) emit2(op byte, operand int) {
+	if operand > 0xffff {
+		panic(exn.User("bytecode operand overflow (%d)", operand))
+	}
+	enc.emit(op, byte(operand), byte(operand>>8))
+}
+
+func (enc *Encoder) encodeJmp(op byte, label int32) {
+	enc.emit(op, 0, 0)
+	enc.patches = append(enc.patches, jumpPatch{
+		pos:   len(enc.buf) - 2,
+		label: label,
+	})
+}
+
+// encodeOperand handles instructions that have short form
+// for small operands: stack-ref, var-ref, var-set, var-bind,
+// call and unbind.
+func (enc *Encoder) encodeOperand(op byte, operand int) {
+	switch {
+	case operand < opOperand1:
+		enc.emit(op + byte(operand))
+	case operand <= 0xff:
+		enc.emit(op+opOperand1, byte(operand))
+	default:
+		enc.emit2(op+opOperand2, operand)
+	}
+}
+
+// encodeN handles instructions that have specialized
+// opcodes for arities in [minN, 4] range: list and concat.
+func (enc *Encoder) encodeN(op byte, minN int, opN byte, n int) {
+	switch {
+	case n >= minN && n <= 4:
+		enc.emit(op + byte(n-minN))
+	case n <= 0xff:
+		enc.emit(opN, byte(n))
+	default:
+		panic(exn.User("too many arguments (%d)", n))
+	}
+}
+
+func (enc *Encoder) encodeConstRef(cvIndex int) {
+	if cvIndex < constantLimit {
+		enc.emit(opConstant + byte(cvIndex))
+	} else {
+		enc.emit2(opConstant2, cvIndex)
+	}
+}
+
+func (enc *Encoder) encodeStackRef(stIndex int) {
+	if stIndex == 0 {
+		enc.emit(opDup) // There is no "stack-ref 0" opcode
+	} else {
+		enc.encodeOperand(opStackRef, stIndex)
+	}
+}
+
+func (enc *Encoder) encodeStackSet(stIndex int) {
+	if stIndex <= 0xff {
+		enc.emit(opStackSet, byte(stIndex))
+	} else {
+		enc.emit2(opStackSet2, stIndex)
+	}
+}
+
+func (enc *Encoder) encodeDiscard(n int) {
+	if n == 1 {
+		enc.emit(opDiscard)
+		return
+	}
+	for n > discardNLimit {
+		enc.emit(opDiscardN, discardNLimit)
+		n -= discardNLimit
+	}
+	if n > 0 {
+		enc.emit(opDiscardN, byte(n))
+	}
+}
+
+func (enc *Encoder) resolveJumps() {
+	for _, patch := range enc.patches {
+		pc, ok := enc.labels[patch.label]
+		if !ok {
+			panic(exn.Logic("jump to undefined label %d", patch.label))
+		}
+		if pc > 0xffff {
+			panic(exn.User("bytecode overflow: function is too big"))
+		}
+		enc.buf[patch.pos] = byte(pc)
+		enc.buf[patch.pos+1] = byte(pc >> 8)
+	}
+}
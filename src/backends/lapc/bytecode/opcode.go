@@ -0,0 +1,106 @@
+package bytecode
+
+import (
+	"backends/lapc/ir"
+)
+
+// Emacs VM opcodes; see "bytecode.c" in Emacs sources.
+const (
+	opStackRef = 0
+	opVarRef   = 8
+	opVarSet   = 16
+	opVarBind  = 24
+	opCall     = 32
+	opUnbind   = 40
+
+	opList1   = 67
+	opConcat2 = 80
+
+	opConstant2 = 129
+	opGoto      = 130
+	opDiscard   = 136
+	opDup       = 137
+
+	opListN     = 175
+	opConcatN   = 176
+	opStackSet  = 178
+	opStackSet2 = 179
+	opDiscardN  = 182
+
+	opConstant = 192
+)
+
+// Instructions with 0-5 operand are encoded inside opcode byte;
+// larger operands are stored in 1 or 2 extra bytes.
+const (
+	opOperand1 = 6
+	opOperand2 = 7
+)
+
+// constantLimit is a number of constant vector slots
+// that are accessible by single byte "constant" instruction.
+const constantLimit = 64
+
+// discardNLimit is a max operand of "discardN" instruction.
+// Highest bit of its operand is reserved for "preserve-tos" flag.
+const discardNLimit = 0x7f
+
+// Opcodes of instructions that take no operand.
+// Zero value means that instruction needs special encoding.
+var plainOps = [...]byte{
+	ir.Return: 135,
+	ir.Switch: 183,
+
+	ir.Eq:        61,
+	ir.Equal:     154,
+	ir.Substring: 79,
+	ir.Length:    71,
+
+	ir.NumEq:  85,
+	ir.NumLt:  87,
+	ir.NumGt:  86,
+	ir.NumLte: 88,
+	ir.NumGte: 89,
+	ir.Add:    92,
+	ir.Sub:    90,
+	ir.Mul:    95,
+	ir.Quo:    165,
+	ir.Add1:   84,
+	ir.Sub1:   83,
+	ir.Min:    94,
+	ir.Neg:    91,
+
+	ir.StrEq: 152,
+	ir.StrLt: 153,
+
+	ir.Aref: 72,
+	ir.Aset: 73,
+
+	ir.Car:    64,
+	ir.Cdr:    65,
+	ir.SetCar: 160,
+	ir.SetCdr: 161,
+	ir.Cons:   66,
+	ir.Memq:   62,
+	ir.Member: 157,
+
+	ir.Stringp:  59,
+	ir.Integerp: 168,
+	ir.Symbolp:  57,
+	ir.Not:      63,
+
+	ir.SaveExcursion:     138,
+	ir.SaveRestriction:   140,
+	ir.SaveCurrentBuffer: 114,
+	ir.UnwindProtect:     142,
+}
+
+// Opcodes of jump instructions.
+// Jump operand is 2 byte absolute bytecode offset.
+var jumpOps = [...]byte{
+	ir.Jmp:              opGoto,
+	ir.JmpNil:           131,
+	ir.JmpNotNil:        132,
+	ir.JmpNilElsePop:    133,
+	ir.JmpNotNilElsePop: 134,
+}
@@ -5,6 +5,9 @@ import (
 	"backends/lapc"
 	"backends/lapc/ir"
 	"cfg"
+	"dt"
+	"exn"
+	"magic_pkg/emacs/lisp"
 	"magic_pkg/emacs/rt"
 	"sexp"
 	"vmm"
@@ -60,6 +63,50 @@ func compileIf(cl *Compiler, form *sexp.If) {
 	cl.push().Label(endifLabel)
 }
 
+func compileSwitchTable(cl *Compiler, form *lapc.SwitchTable) {
+	endLabel := cl.unit.NewLabel("switch-end")
+	labels := make([]ir.Instr, len(form.Bodies))
+	for i := range labels {
+		labels[i] = cl.unit.NewLabel("case")
+	}
+	table := &dt.JumpTable{
+		Test:    lisp.Symbol(form.Test),
+		Keys:    make([]interface{}, len(form.Keys)),
+		Targets: make([]dt.JumpTarget, len(form.Keys)),
+	}
+	for i, key := range form.Keys {
+		table.Keys[i] = switchTableKey(key)
+		label := labels[form.Targets[i]]
+		table.Targets[i] = dt.JumpTarget{Label: label.Data, Name: label.Meta}
+	}
+
+	cvIndex := cl.cvec.InsertJumpTable(table)
+	compileExpr(cl, form.Expr)
+	cl.push().ConstRef(cvIndex)
+	cl.push().Switch(cvIndex)
+	compileBlock(cl, form.Default)
+	cl.push().Jmp(endLabel)
+	for i, body := range form.Bodies {
+		cl.push().Label(labels[i])
+		compileBlock(cl, body)
+		cl.push().Jmp(endLabel)
+	}
+	cl.push().Label(endLabel)
+}
+
+func switchTableKey(form sexp.Form) interface{} {
+	switch form := form.(type) {
+	case sexp.Int:
+		return int64(form)
+	case sexp.Str:
+		return string(form)
+	case sexp.Symbol:
+		return lisp.Symbol(form.Val)
+	default:
+		panic(exn.Logic("unexpected switch key: %#v", form))
+	}
+}
+
 func compileRepeat(cl *Compiler, form *sexp.Repeat) {
 	assert.True(form.N <= cfg.ClUnrollHardLimit)
 	for i := int64(0); i < form.N; i++ {
@@ -123,6 +170,52 @@ func compileWhile(cl *Compiler, form *sexp.While) {
 	cl.innerContinue = prevContinue
 }
 
+func compileSpecBind(cl *Compiler, form *sexp.SpecBind) {
+	compileExpr(cl, form.Init)
+	cl.push().VarBind(cl.cvec.InsertSym(form.Sym))
+	compileBlock(cl, form.Body)
+	cl.push().Unbind(1)
+}
+
+// compileScope pushes binding stack entries that restore
+// editor state; they are undone by "unbind" after the body.
+// Unwind handlers are partially applied functions.
+func compileScope(cl *Compiler, form *sexp.Scope) {
+	depth := 1
+	switch form.Kind {
+	case sexp.ScopeExcursion:
+		cl.push().SaveExcursion()
+	case sexp.ScopeRestriction:
+		cl.push().SaveRestriction()
+	case sexp.ScopeCurrentBuffer:
+		cl.push().SaveCurrentBuffer()
+		compileCall(cl, "set-buffer", []sexp.Form{form.Buffer})
+		cl.push().Discard(1)
+	case sexp.ScopeMatchData:
+		compileCall(cl, "apply-partially", []sexp.Form{
+			sexp.Symbol{Val: "set-match-data"},
+			sexp.NewLispCall(lisp.InternFunc("match-data")),
+			sexp.Bool(true),
+		})
+		cl.push().UnwindProtect()
+	case sexp.ScopeTempBuffer:
+		// "set-buffer" returns its argument, so
+		// new buffer is both selected and passed to handler.
+		cl.push().SaveCurrentBuffer()
+		newBuf := sexp.NewLispCall(lisp.InternFunc("generate-new-buffer"), sexp.Str(" *temp*"))
+		compileCall(cl, "apply-partially", []sexp.Form{
+			sexp.Symbol{Val: "kill-buffer"},
+			sexp.NewLispCall(lisp.InternFunc("set-buffer"), newBuf),
+		})
+		cl.push().UnwindProtect()
+		depth = 2
+	default:
+		panic(exn.Logic("unexpected scope kind: %d", form.Kind))
+	}
+	compileBlock(cl, form.Body)
+	cl.push().Unbind(depth)
+}
+
 func compileBind(cl *Compiler, form *sexp.Bind) {
 	compileExpr(cl, form.Init)
 	cl.push().Xbind(form.Name)
@@ -177,7 +270,8 @@ func compileStructUpdate(cl *Compiler, form *sexp.StructUpdate) {
 			compileExpr(cl, form.Expr)
 			cl.push().SetCdr()
 		} else {
-			cl.pushN(ir.Instr{Kind: ir.Cdr}, form.Index-1)
+			// Middle member; its cell is Index cdrs away.
+			cl.pushN(ir.Instr{Kind: ir.Cdr}, form.Index)
 			compileExpr(cl, form.Expr)
 			cl.push().SetCar()
 		}
@@ -193,7 +287,7 @@ func compileStructUpdate(cl *Compiler, form *sexp.StructUpdate) {
 
 func compileLetStmt(cl *Compiler, form *sexp.Let) {
 	for _, bind := range form.Bindings {
-		compileBind(cl, bind)
+		compileStmt(cl, bind)
 	}
 	compileStmt(cl, form.Stmt)
 	cl.push().Discard(len(form.Bindings))
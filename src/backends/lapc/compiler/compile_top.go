@@ -11,6 +11,19 @@ func compileStmt(cl *Compiler, form sexp.Form) {
 		return
 	}
 
+	// Instructions that are emitted after nested statement
+	// belong to the enclosing statement.
+	if p, ok := form.(sexp.Positioned); ok && p.Position().IsValid() {
+		outer := cl.unit.Pos()
+		cl.unit.SetPos(p.Position())
+		compileStmtForm(cl, form)
+		cl.unit.SetPos(outer)
+		return
+	}
+	compileStmtForm(cl, form)
+}
+
+func compileStmtForm(cl *Compiler, form sexp.Form) {
 	switch form := form.(type) {
 	case *sexp.Return:
 		compileReturn(cl, form)
@@ -42,9 +55,15 @@ func compileStmt(cl *Compiler, form sexp.Form) {
 		compileGoto(cl, form)
 	case *sexp.Label:
 		compileLabel(cl, form)
+	case *sexp.SpecBind:
+		compileSpecBind(cl, form)
+	case *sexp.Scope:
+		compileScope(cl, form)
 
 	case *sexp.Let:
 		compileLetStmt(cl, form)
+	case *lapc.SwitchTable:
+		compileSwitchTable(cl, form)
 
 	default:
 		panic(exn.Logic("unexpected stmt: %#v", form))
@@ -37,9 +37,12 @@ func (cl *Compiler) CompileFunc(fn *sexp.Func) *lapc.Object {
 	asmObject := cl.as.Assemble(fn.Params, cl.unit)
 
 	return &lapc.Object{
-		StackUsage: asmObject.StackUsage,
-		Code:       asmObject.Code,
-		ConstVec:   cl.cvec,
+		StackUsage:    asmObject.StackUsage,
+		Code:          asmObject.Code,
+		CodePositions: asmObject.CodePositions,
+		Bytecode:      asmObject.Bytecode,
+		Positions:     asmObject.Positions,
+		ConstVec:      cl.cvec,
 	}
 }
 
@@ -2,6 +2,8 @@ package export
 
 import (
 	"backends/lapc"
+	"go/token"
+	"path/filepath"
 	"sexp"
 	"tu"
 )
@@ -9,12 +11,13 @@ import (
 // Builder allows to create exportable package.
 // This object is not reusable.
 type Builder struct {
-	w writer
+	w    writer
+	fset *token.FileSet
 }
 
 // NewBuilder returns fresh export package builder.
 func NewBuilder(pkg *tu.Package) *Builder {
-	b := &Builder{}
+	b := &Builder{fset: pkg.FileSet}
 	w := &b.w
 
 	w.WriteByte('(') // Open list (closed in Build method)
@@ -22,10 +25,32 @@ func NewBuilder(pkg *tu.Package) *Builder {
 	// Write mandatory header.
 	w.WriteSymbol(pkg.Name)
 	w.WriteString(pkg.Comment)
+	w.WriteSymbol(pkg.Feature)
+	w.WriteString(escapeString(pkg.Summary))
+	writeHeaders(w, pkg.Headers)
+	w.WriteByte('(')
+	for _, feature := range pkg.Requires {
+		w.WriteSymbol(feature)
+	}
+	w.WriteByte(')')
 
 	return b
 }
 
+// writeHeaders writes alist of library header keywords.
+func writeHeaders(w *writer, headers []tu.Header) {
+	w.WriteByte('(')
+	for _, h := range headers {
+		w.WriteByte('(')
+		w.WriteString(h.Key)
+		w.WriteByte('.')
+		w.WriteByte(' ')
+		w.WriteString(escapeString(h.Val))
+		w.WriteByte(')')
+	}
+	w.WriteByte(')')
+}
+
 // Build finalizes package being built.
 // Package bytes returned.
 // It is illegal to call Build method twice one the same builder.
@@ -38,17 +63,57 @@ func (b *Builder) Build() []byte {
 func (b *Builder) AddFunc(fn *sexp.Func, obj *lapc.Object) {
 	w := &b.w
 
+	if fn.IsAutoload() {
+		w.WriteSymbol("autoload")
+		w.WriteSymbol(fn.Name)
+	}
 	w.WriteSymbol("fn")
 	w.WriteSymbol(fn.Name)
-	w.WriteInt(argsDescriptor(fn))
+	w.WriteInt(ArgsDescriptor(fn))
 	w.Write(obj.ConstVec.Bytes())
 	w.WriteInt(obj.StackUsage)
 	w.WriteString(docString(fn))
+	writeInteractive(w, fn)
+	b.writePositions(obj)
 	w.Write(obj.Code)
 
 	w.WriteSymbol("end")
 }
 
+// writePositions writes nil if fset is not available and
+// a vector of {file line} pairs otherwise.
+// Vector is indexed by "position" instructions operands.
+func (b *Builder) writePositions(obj *lapc.Object) {
+	w := &b.w
+	if b.fset == nil {
+		w.WriteSymbol("nil")
+		return
+	}
+	w.WriteByte('[')
+	for _, p := range obj.CodePositions {
+		pos := b.fset.Position(p)
+		w.WriteString(escapeString(filepath.Base(pos.Filename)))
+		w.WriteInt(pos.Line)
+	}
+	w.WriteByte(']')
+	w.WriteByte(' ')
+}
+
+// writeInteractive writes nil for ordinary functions and
+// a list of interactive spec for commands.
+func writeInteractive(w *writer, fn *sexp.Func) {
+	switch {
+	case !fn.IsInteractive():
+		w.WriteSymbol("nil")
+	case fn.InteractiveSpec == "":
+		w.Write([]byte("(nil)"))
+	default:
+		w.WriteByte('(')
+		w.WriteString(escapeString(fn.InteractiveSpec))
+		w.WriteByte(')')
+	}
+}
+
 func (b *Builder) AddExpr(obj *lapc.Object) {
 	w := &b.w
 
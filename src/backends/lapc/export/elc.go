@@ -0,0 +1,176 @@
+package export
+
+import (
+	"backends/lapc"
+	"bytes"
+	"dt"
+	"go/token"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"strconv"
+	"strings"
+	"tu"
+)
+
+// elcVersion is Emacs major version that is written into ".elc" header.
+// Translated code requires Emacs 28 or newer.
+const elcVersion = 28
+
+// ElcBuilder is like Builder, but it produces
+// loadable ".elc" file instead of IR package.
+// This object is not reusable.
+type ElcBuilder struct {
+	buf     bytes.Buffer
+	fset    *token.FileSet
+	feature string
+}
+
+// NewElcBuilder returns fresh ".elc" file builder.
+func NewElcBuilder(pkg *tu.Package) *ElcBuilder {
+	b := &ElcBuilder{fset: pkg.FileSet, feature: pkg.Feature}
+	buf := &b.buf
+
+	buf.WriteString(";ELC")
+	buf.Write([]byte{elcVersion, 0, 0, 0})
+	buf.WriteString("\n;;; " + pkg.Feature + ".el --- " + pkg.Summary + "\n")
+	buf.WriteString(";; THIS CODE IS GENERATED, AVOID MANUAL EDITING!\n")
+	if pkg.Comment != "" {
+		// Package comment is already formatted,
+		// but quotes are escaped for IR string literal.
+		buf.WriteString("\n;;; Commentary:\n")
+		buf.WriteString(strings.Replace(pkg.Comment, `\"`, `"`, -1))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	// Autoload cookies are not written: autoloads
+	// are extracted from sources, not from ".elc" files.
+	for _, feature := range pkg.Requires {
+		buf.WriteString("(require '" + lisp.Symbol(feature).Literal() + ")\n")
+	}
+
+	return b
+}
+
+// Build finalizes file being built.
+// File bytes returned.
+func (b *ElcBuilder) Build() []byte {
+	b.buf.WriteString("(provide '" + lisp.Symbol(b.feature).Literal() + ")\n")
+	return b.buf.Bytes()
+}
+
+// AddFunc pushes "defalias" of byte-code function object.
+func (b *ElcBuilder) AddFunc(fn *sexp.Func, obj *lapc.Object) {
+	buf := &b.buf
+
+	buf.WriteString("(defalias '")
+	buf.WriteString(lisp.Symbol(fn.Name).Literal())
+	buf.WriteString(" #[")
+	buf.WriteString(strconv.Itoa(ArgsDescriptor(fn)))
+	buf.WriteByte(' ')
+	writeBytecode(buf, obj.Bytecode)
+	buf.WriteByte(' ')
+	writeConstVec(buf, obj.ConstVec)
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(obj.StackUsage))
+	buf.WriteByte(' ')
+	dt.WriteString(buf, RawDocString(fn))
+	if fn.IsInteractive() {
+		// Interactive slot makes function a command.
+		buf.WriteByte(' ')
+		if fn.InteractiveSpec == "" {
+			buf.WriteString("nil")
+		} else {
+			dt.WriteString(buf, fn.InteractiveSpec)
+		}
+	}
+	buf.WriteString("])\n")
+
+	b.writePositions(fn, obj)
+}
+
+// writePositions pushes line table of fn as a symbol property.
+// Table is a flat vector of {offset file line} triples
+// that are sorted by bytecode offset.
+func (b *ElcBuilder) writePositions(fn *sexp.Func, obj *lapc.Object) {
+	table := LineTable(b.fset, obj.Positions)
+	if len(table) == 0 {
+		return
+	}
+
+	buf := &b.buf
+	buf.WriteString("(put '")
+	buf.WriteString(lisp.Symbol(fn.Name).Literal())
+	buf.WriteString(" 'goism-positions [")
+	for i, x := range table {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(strconv.Itoa(x.PC))
+		buf.WriteByte(' ')
+		dt.WriteString(buf, x.File)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.Itoa(x.Line))
+	}
+	buf.WriteString("])\n")
+}
+
+// AddExpr pushes top level "byte-code" form.
+func (b *ElcBuilder) AddExpr(obj *lapc.Object) {
+	buf := &b.buf
+
+	buf.WriteString("(byte-code ")
+	writeBytecode(buf, obj.Bytecode)
+	buf.WriteByte(' ')
+	writeConstVec(buf, obj.ConstVec)
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(obj.StackUsage))
+	buf.WriteString(")\n")
+}
+
+// AddVars pushes "defvar" for each variable.
+func (b *ElcBuilder) AddVars(names []string) {
+	for _, name := range names {
+		b.buf.WriteString("(defvar ")
+		b.buf.WriteString(lisp.Symbol(name).Literal())
+		b.buf.WriteString(" nil \"\")\n")
+	}
+}
+
+// writeBytecode writes unibyte string literal.
+// Non-printable bytes are written as octal escapes;
+// they are always 3 digits long, so next byte can be a digit.
+func writeBytecode(buf *bytes.Buffer, code []byte) {
+	buf.WriteByte('"')
+	for _, c := range code {
+		switch {
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c >= ' ' && c < 0x7f:
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte('0' + c>>6)
+			buf.WriteByte('0' + (c>>3)&7)
+			buf.WriteByte('0' + c&7)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func writeConstVec(buf *bytes.Buffer, cvec *dt.ConstPool) {
+	buf.WriteByte('[')
+	for i := 0; i < cvec.Len(); i++ {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		dt.WriteConst(buf, cvec.Get(uint16(i)), jumpOffset)
+	}
+	buf.WriteByte(']')
+}
+
+// jumpOffset returns bytecode offset of jump target.
+func jumpOffset(target dt.JumpTarget) string {
+	return strconv.Itoa(target.PC)
+}
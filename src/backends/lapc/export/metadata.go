@@ -1,13 +1,16 @@
 package export
 
 import (
+	"backends/lapc/bytecode"
 	"exn"
+	"go/token"
+	"path/filepath"
 	"sexp"
 	"strings"
 )
 
-// Return properly encoded bytecode function argument descriptor.
-func argsDescriptor(fn *sexp.Func) int {
+// ArgsDescriptor returns properly encoded bytecode function argument descriptor.
+func ArgsDescriptor(fn *sexp.Func) int {
 	arity := len(fn.Params)
 	if arity > 127 {
 		panic(exn.User("can not have more than 127 positional parameters"))
@@ -18,14 +21,29 @@ func argsDescriptor(fn *sexp.Func) int {
 	totalArgs := uint32(arity << 8) // Other bits
 
 	if fn.Variadic {
+		// "rest" arg is not counted as positional.
+		positionalArgs--
+		totalArgs = uint32((arity - 1) << 8)
 		return int(positionalArgs + variadicBit + totalArgs)
 	}
 	return int(positionalArgs + totalArgs)
 }
 
 // Return extended function documentation string.
+// Double quotes are escaped.
 func docString(fn *sexp.Func) string {
-	docString := strings.Replace(fn.DocString, `"`, `\"`, -1)
+	return strings.Replace(RawDocString(fn), `"`, `\"`, -1)
+}
+
+// escapeString escapes backslashes and double quotes.
+func escapeString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, `"`, `\"`, -1)
+}
+
+// RawDocString is like docString, but without escaping.
+func RawDocString(fn *sexp.Func) string {
+	docString := fn.DocString
 	if len(fn.Params) == 0 {
 		return docString
 	}
@@ -42,3 +60,35 @@ func docString(fn *sexp.Func) string {
 	}
 	return "\n" + eldocSig
 }
+
+// LinePos maps bytecode offset to the Go source line.
+type LinePos struct {
+	PC   int
+	File string // Base name of the source file
+	Line int
+}
+
+// LineTable converts position table into line table.
+// Consecutive entries that refer to the same line are merged.
+// Returns nil if fset is nil.
+func LineTable(fset *token.FileSet, positions []bytecode.PosEntry) []LinePos {
+	if fset == nil {
+		return nil
+	}
+	var table []LinePos
+	for _, entry := range positions {
+		pos := fset.Position(entry.Pos)
+		if !pos.IsValid() {
+			continue
+		}
+		file := filepath.Base(pos.Filename)
+		if n := len(table); n != 0 {
+			last := table[n-1]
+			if last.File == file && last.Line == pos.Line {
+				continue
+			}
+		}
+		table = append(table, LinePos{PC: entry.PC, File: file, Line: pos.Line})
+	}
+	return table
+}
@@ -54,6 +54,7 @@ var kindToEncoding = [...]Encoding{
 	JmpNotNil:        jump("goto-if-not-nil"),
 	JmpNilElsePop:    jump("goto-if-nil-else-pop"),
 	JmpNotNilElsePop: jump("goto-if-not-nil-else-pop"),
+	Switch:           switchEnc,
 
 	Return: returnEnc,
 	Call:   callEnc,
@@ -105,6 +106,13 @@ var kindToEncoding = [...]Encoding{
 	Discard:  discardEnc,
 	VarRef:   varRefEnc,
 	VarSet:   varSetEnc,
+	VarBind:  varBindEnc,
+	Unbind:   unbindEnc,
+
+	SaveExcursion:     Encoding{Name: []byte("save-excursion")},
+	SaveRestriction:   Encoding{Name: []byte("save-restriction")},
+	SaveCurrentBuffer: Encoding{Name: []byte("save-current-buffer")},
+	UnwindProtect:     unwindProtectEnc,
 }
 
 var (
@@ -115,6 +123,14 @@ var (
 		Output: AttrPushTmp,
 	}
 
+	// Switch pops jump table and the value that is looked up.
+	// Instruction Data is jump table constant vector index;
+	// it is not encoded because table is pushed by "constant".
+	switchEnc = Encoding{
+		Name:  []byte("switch"),
+		Input: AttrTake2,
+	}
+
 	returnEnc = Encoding{
 		Name:  []byte("return"),
 		Input: AttrTake1,
@@ -194,6 +210,25 @@ var (
 		HasArg: true,
 		Input:  AttrTake1,
 	}
+
+	// Dynamic bindings are kept outside of the stack;
+	// "unbind" operand is a number of bindings to undo.
+	varBindEnc = Encoding{
+		Name:   []byte("var-bind"),
+		HasArg: true,
+		Input:  AttrTake1,
+	}
+
+	unbindEnc = Encoding{
+		Name:   []byte("unbind"),
+		HasArg: true,
+	}
+
+	// Handler function is popped and called by "unbind".
+	unwindProtectEnc = Encoding{
+		Name:  []byte("unwind-protect"),
+		Input: AttrTake1,
+	}
 )
 
 func op1(name string) Encoding {
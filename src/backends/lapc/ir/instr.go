@@ -1,5 +1,9 @@
 package ir
 
+import (
+	"go/token"
+)
+
 type InstrKind int32
 
 const (
@@ -27,6 +31,7 @@ const (
 	JmpNotNil        // "gotoifnonnil"
 	JmpNilElsePop    // "gotoifnilelsepop"
 	JmpNotNilElsePop // "gotoifnonnilelsepop"
+	Switch           // "switch"
 
 	Return
 	Call
@@ -77,6 +82,13 @@ const (
 	Discard
 	VarRef
 	VarSet
+	VarBind // "varbind"
+	Unbind
+
+	SaveExcursion
+	SaveRestriction
+	SaveCurrentBuffer
+	UnwindProtect
 )
 
 // Instr is a single IR instruction.
@@ -84,6 +96,7 @@ type Instr struct {
 	Kind InstrKind // Determines the instruction kind
 	Data int32     // Instruction direct argument (optional)
 	Meta string    // Additional instruction data (optional)
+	Pos  token.Pos // Source position of originating statement (optional)
 	Prev *Instr    // Previous instruction
 	Next *Instr    // Next instruction
 }
@@ -1,8 +1,13 @@
 package ir
 
+import (
+	"go/token"
+)
+
 type Unit struct {
 	first *Instr
 	cur   *Instr
+	pos   token.Pos // Assigned to pushed instructions
 
 	lastLabelID int32
 	userLabels  map[string]Instr
@@ -25,8 +30,16 @@ func (u *Unit) Init() {
 	u.cur = u.first
 	u.lastLabelID = -1
 	u.userLabels = make(map[string]Instr)
+	u.pos = token.NoPos
 }
 
+// Pos returns source position that is assigned to pushed instructions.
+func (u *Unit) Pos() token.Pos { return u.pos }
+
+// SetPos changes source position that is recorded
+// for all subsequently pushed instructions.
+func (u *Unit) SetPos(pos token.Pos) { u.pos = pos }
+
 func (u *Unit) Result() *Instr { return u.first }
 
 func (u *Unit) InstrPusher() *InstrPusher {
@@ -53,6 +66,9 @@ func (u *Unit) NewUserLabel(name string) Instr {
 }
 
 func (p *InstrPusher) PushInstr(ins Instr) {
+	if ins.Pos == token.NoPos {
+		ins.Pos = p.pos
+	}
 	next := &ins
 	p.cur.Next = next
 	ins.Prev = p.cur
@@ -94,6 +110,7 @@ func (p *InstrPusher) JmpNil(label Instr)           { p.pushLabel(JmpNil, label)
 func (p *InstrPusher) JmpNotNil(label Instr)        { p.pushLabel(JmpNotNil, label) }
 func (p *InstrPusher) JmpNilElsePop(label Instr)    { p.pushLabel(JmpNilElsePop, label) }
 func (p *InstrPusher) JmpNotNilElsePop(label Instr) { p.pushLabel(JmpNotNilElsePop, label) }
+func (p *InstrPusher) Switch(cvIndex int)           { p.pushData(Switch, cvIndex) }
 
 func (p *InstrPusher) Return() { p.push(Return) }
 func (p *InstrPusher) Call(argc int, name string) {
@@ -146,3 +163,10 @@ func (p *InstrPusher) StackSet(stIndex int) { p.pushData(StackSet, stIndex) }
 func (p *InstrPusher) Discard(n int)        { p.pushData(Discard, n) }
 func (p *InstrPusher) VarRef(cvIndex int)   { p.pushData(VarRef, cvIndex) }
 func (p *InstrPusher) VarSet(cvIndex int)   { p.pushData(VarSet, cvIndex) }
+func (p *InstrPusher) VarBind(cvIndex int)  { p.pushData(VarBind, cvIndex) }
+func (p *InstrPusher) Unbind(n int)         { p.pushData(Unbind, n) }
+
+func (p *InstrPusher) SaveExcursion()     { p.push(SaveExcursion) }
+func (p *InstrPusher) SaveRestriction()   { p.push(SaveRestriction) }
+func (p *InstrPusher) SaveCurrentBuffer() { p.push(SaveCurrentBuffer) }
+func (p *InstrPusher) UnwindProtect()     { p.push(UnwindProtect) }
@@ -1,12 +1,17 @@
 package lapc
 
 import (
+	"backends/lapc/bytecode"
 	"dt"
+	"go/token"
 )
 
 // Object is a compiled IR unit.
 type Object struct {
-	StackUsage int
-	Code       []byte
-	ConstVec   *dt.ConstPool
+	StackUsage    int
+	Code          []byte              // Textual IR
+	CodePositions []token.Pos         // Code "position" operand -> source position
+	Bytecode      []byte              // Emacs VM bytecode
+	Positions     []bytecode.PosEntry // Bytecode offset -> source position
+	ConstVec      *dt.ConstPool
 }
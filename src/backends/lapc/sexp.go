@@ -28,3 +28,40 @@ func (call *InstrCall) Cost() int {
 }
 
 func (call *InstrCall) Type() types.Type { return xtypes.TypVoid }
+
+// SwitchTable is a switch over constant keys that
+// is dispatched by a single "switch" instruction.
+//
+// Bodies of "case" clauses with several keys are shared:
+// Keys[i] body is Bodies[Targets[i]].
+type SwitchTable struct {
+	sexp.StmtPos
+	Expr    sexp.Form
+	Test    string // "eq" or "equal"
+	Keys    []sexp.Form
+	Targets []int
+	Bodies  []sexp.Block
+	Default sexp.Block
+}
+
+func (form *SwitchTable) Copy() sexp.Form {
+	bodies := make([]sexp.Block, len(form.Bodies))
+	for i, body := range form.Bodies {
+		bodies[i] = sexp.CopyList(body)
+	}
+	return &SwitchTable{
+		Expr:    form.Expr.Copy(),
+		Test:    form.Test,
+		Keys:    sexp.CopyList(form.Keys),
+		Targets: append([]int(nil), form.Targets...),
+		Bodies:  bodies,
+		Default: sexp.CopyList(form.Default),
+	}
+}
+
+func (form *SwitchTable) Cost() int {
+	// Same as sexp.Switch cost.
+	return -1
+}
+
+func (form *SwitchTable) Type() types.Type { return xtypes.TypVoid }
@@ -2,6 +2,7 @@ package lapc
 
 import (
 	"backends/lapc/ir"
+	"cfg"
 	"exn"
 	"go/types"
 	"magic_pkg/emacs/lisp"
@@ -9,6 +10,8 @@ import (
 	"opt"
 	"sexp"
 	"sexpconv"
+	"tu/symbols"
+	"xtypes"
 )
 
 var funcToInstr map[*lisp.Func]ir.Instr
@@ -102,13 +105,18 @@ func simplify(form sexp.Form) sexp.Form {
 		return form
 
 	case *sexp.SwitchTrue:
-		return simplifySwitch(
+		stmt := simplifySwitch(
 			form.SwitchBody,
 			func(x sexp.Form) sexp.Form { return x },
 			0,
 		)
+		sexp.SetPos(stmt, form.Pos)
+		return stmt
 
 	case *sexp.Switch:
+		if table := switchTable(form); table != nil {
+			return table
+		}
 		typ := form.Expr.Type()
 		tag := sexp.Local{Name: "_it", Typ: typ}
 		mkCond := func(rhs sexp.Form) sexp.Form {
@@ -119,9 +127,15 @@ func simplify(form sexp.Form) sexp.Form {
 			return cmp
 		}
 		expr := Simplify(form.Expr)
+		stmt := simplifySwitch(form.SwitchBody, mkCond, 0)
+		sexp.SetPos(stmt, form.Pos)
 		return &sexp.Let{
-			Bindings: []*sexp.Bind{&sexp.Bind{Name: "_it", Init: expr}},
-			Stmt:     simplifySwitch(form.SwitchBody, mkCond, 0),
+			Bindings: []*sexp.Bind{&sexp.Bind{
+				StmtPos: form.StmtPos,
+				Name:    "_it",
+				Init:    expr,
+			}},
+			Stmt: stmt,
 		}
 
 	case *sexp.SliceLit:
@@ -162,6 +176,20 @@ func simplify(form sexp.Form) sexp.Form {
 	case *sexp.TypeCast:
 		return Simplify(form.Form)
 
+	case *sexp.TypeAssert:
+		typ := sexp.Symbol{Val: symbols.MangleType(form.Typ)}
+		if form.CommaOk {
+			zv := sexpconv.ZeroValue(form.Typ)
+			if isGoInterface(form.Typ) {
+				return simplifiedCall(rt.FnTypeAssertIfaceOk, form.Expr, typ, zv)
+			}
+			return simplifiedCall(rt.FnTypeAssertOk, form.Expr, typ, zv)
+		}
+		if isGoInterface(form.Typ) {
+			return simplifiedCall(rt.FnTypeAssertIface, form.Expr, typ)
+		}
+		return simplifiedCall(rt.FnTypeAssert, form.Expr, typ)
+
 	case *sexp.DoTimes:
 		form.Body = simplifyList(form.Body)
 		form.N = Simplify(form.N)
@@ -175,10 +203,11 @@ func simplify(form sexp.Form) sexp.Form {
 			Expr: sexp.NewAdd1(form.Iter),
 		}
 		return &sexp.While{
-			Init: init,
-			Cond: sexp.NewNumLt(form.Iter, form.N),
-			Post: post,
-			Body: form.Body,
+			StmtPos: form.StmtPos,
+			Init:    init,
+			Cond:    sexp.NewNumLt(form.Iter, form.N),
+			Post:    post,
+			Body:    form.Body,
 		}
 	}
 
@@ -205,19 +234,106 @@ func simplifySwitch(b sexp.SwitchBody, mkCond func(sexp.Form) sexp.Form, i int)
 	}
 }
 
+// switchTable returns jump table lowering of form.
+// Returns nil if target Emacs has no "switch" instruction
+// or when some of the case expressions are not suitable keys.
+func switchTable(form *sexp.Switch) *SwitchTable {
+	if cfg.TargetEmacsVersion < 26 || len(form.Clauses) < cfg.ClSwitchTableMinKeys {
+		return nil
+	}
+	test := switchTableTest(form.Expr.Type())
+	if test == "" {
+		return nil
+	}
+	for _, cc := range form.Clauses {
+		if !isSwitchTableKey(cc.Expr) {
+			return nil
+		}
+	}
+
+	table := &SwitchTable{
+		StmtPos: form.StmtPos,
+		Expr:    Simplify(form.Expr),
+		Test:    test,
+		Default: simplifyList(form.DefaultBody),
+	}
+	// First matching clause wins, so repeated keys
+	// (like two "lisp.Intern" of the same name) are skipped.
+	seen := make(map[sexp.Form]bool, len(form.Clauses))
+	for _, cc := range form.Clauses {
+		if seen[cc.Expr] {
+			continue
+		}
+		seen[cc.Expr] = true
+		target := -1
+		for j, body := range table.Bodies {
+			if isSameBlock(body, cc.Body) {
+				target = j
+				break
+			}
+		}
+		if target == -1 {
+			target = len(table.Bodies)
+			table.Bodies = append(table.Bodies, simplifyList(cc.Body))
+		}
+		table.Keys = append(table.Keys, cc.Expr)
+		table.Targets = append(table.Targets, target)
+	}
+	return table
+}
+
+// switchTableTest returns hash table test that is
+// suitable for typ values. Returns empty string
+// for types that can not be used as jump table keys.
+func switchTableTest(typ types.Type) string {
+	if types.Identical(typ, lisp.TypSymbol) {
+		return "eq"
+	}
+	if typ, ok := typ.Underlying().(*types.Basic); ok {
+		switch {
+		case typ.Info()&types.IsInteger != 0:
+			return "eq" // Keys are checked to be fixnums
+		case typ.Kind() == types.String:
+			return "equal"
+		}
+	}
+	return ""
+}
+
+// isSwitchTableKey reports whether form is a constant
+// that can be compared by switchTableTest result.
+func isSwitchTableKey(form sexp.Form) bool {
+	switch form := form.(type) {
+	case sexp.Int:
+		// Bignums are not "eq"; 2^61 is Emacs fixnum limit
+		// on 64-bit platforms.
+		return form > -(1<<61) && form < (1<<61)
+	case sexp.Str, sexp.Symbol:
+		return true
+	}
+	return false
+}
+
+// isSameBlock reports whether a and b are the same statement list.
+// Case clauses with several expressions share their body.
+func isSameBlock(a, b sexp.Block) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
 // Returns a form which is a equallity comparator for two given forms.
 // Returns nil when comparison over {"a", "b"} is undefined (or unimplemented).
 func comparatorEq(a, b sexp.Form) sexp.Form {
 	switch typ := a.Type(); typ := typ.(type) {
 	case *types.Basic:
-		if typ.Info()&types.IsNumeric != 0 {
-			return sexp.NewNumEq(a, b)
-		} else if typ.Kind() == types.String {
-			return sexp.NewStrEq(a, b)
-		}
-		return nil
+		return basicComparatorEq(typ, a, b)
 
 	case *types.Named:
+		if typ == lisp.TypSymbol {
+			return sexp.NewLispCall(lisp.FnEq, a, b)
+		}
+		if typ, ok := typ.Underlying().(*types.Basic); ok {
+			return basicComparatorEq(typ, a, b)
+		}
 		// #REFS: 60.
 		return nil
 
@@ -227,3 +343,23 @@ func comparatorEq(a, b sexp.Form) sexp.Form {
 		return sexp.NewLispCall(lisp.FnEq, a, b)
 	}
 }
+
+func basicComparatorEq(typ *types.Basic, a, b sexp.Form) sexp.Form {
+	if typ.Info()&types.IsNumeric != 0 {
+		return sexp.NewNumEq(a, b)
+	} else if typ.Kind() == types.String {
+		return sexp.NewStrEq(a, b)
+	}
+	return nil
+}
+
+// isGoInterface reports whether typ is an interface type
+// that is not defined in "emacs/lisp" package.
+// Emacs Lisp interfaces are asserted like concrete types.
+func isGoInterface(typ types.Type) bool {
+	named := xtypes.AsNamedType(typ)
+	if named != nil && named.Obj().Pkg() == lisp.Package {
+		return false
+	}
+	return types.IsInterface(typ)
+}
@@ -0,0 +1,104 @@
+package lapc
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"cfg"
+	"sexp"
+	"strings"
+	"sync"
+	"testing"
+	"tu/load"
+)
+
+// packages are translated runtime, standard packages
+// and conformance tests.
+var packages = []string{
+	"emacs/rt",
+	"emacs/reflect",
+	"emacs/errors",
+	"emacs/unicode/utf8",
+	"emacs/strconv",
+	"emacs/strings",
+	"emacs/sort",
+	"emacs/container/list",
+	"emacs/container/heap",
+	"emacs/fmt",
+	"emacs/emacs",
+	"emacs/conformance",
+}
+
+var translated struct {
+	sync.Once
+	funcs []*sexp.Func
+	err   error
+}
+
+func loadFuncs(b *testing.B) []*sexp.Func {
+	translated.Do(func() {
+		if translated.err = load.Runtime(); translated.err != nil {
+			return
+		}
+		for _, pkgPath := range packages {
+			pkg, err := load.Package(pkgPath, true)
+			if err != nil {
+				translated.err = err
+				return
+			}
+			for _, fn := range pkg.Funcs {
+				if !fn.IsSubst() {
+					lapc.Simplify(fn.Body)
+					translated.funcs = append(translated.funcs, fn)
+				}
+			}
+		}
+	})
+	if translated.err != nil {
+		b.Fatal(translated.err)
+	}
+	return translated.funcs
+}
+
+// countInstrs returns number of instructions inside asm code.
+func countInstrs(code []byte) int {
+	n := 0
+	for _, line := range strings.Split(string(code), "\n") {
+		if line != "" && !strings.HasPrefix(line, "label") &&
+			!strings.HasPrefix(line, "position") {
+			n++
+		}
+	}
+	return n
+}
+
+// BenchmarkSavings compiles all functions with and without
+// bytecode optimizations. Reported metrics are total
+// instruction count and summed max stack depth:
+//
+//	go test -bench Savings bench/lapc
+func BenchmarkSavings(b *testing.B) {
+	funcs := loadFuncs(b)
+	for _, optimize := range []bool{false, true} {
+		name := "noopt"
+		if optimize {
+			name = "opt"
+		}
+		b.Run(name, func(b *testing.B) {
+			defer func(old bool) { cfg.ClOptimizeBytecode = old }(cfg.ClOptimizeBytecode)
+			cfg.ClOptimizeBytecode = optimize
+
+			var instrs, stack int
+			for i := 0; i < b.N; i++ {
+				instrs, stack = 0, 0
+				cl := compiler.New()
+				for _, fn := range funcs {
+					obj := cl.CompileFunc(fn)
+					instrs += countInstrs(obj.Code)
+					stack += obj.StackUsage
+				}
+			}
+			b.ReportMetric(float64(instrs), "instrs")
+			b.ReportMetric(float64(stack), "max-stack")
+		})
+	}
+}
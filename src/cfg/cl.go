@@ -4,4 +4,17 @@ package cfg
 const (
 	// ClUnrollHardLimit - upper limit for loop unrolling.
 	ClUnrollHardLimit = 256
+
+	// ClSwitchTableMinKeys - minimal number of switch keys
+	// that makes jump table dispatch profitable.
+	// Switches with less keys are compiled to comparisons.
+	ClSwitchTableMinKeys = 3
+)
+
+// Compiler options.
+var (
+	// ClOptimizeBytecode - enables lapc control flow and
+	// peephole optimizations. Disabled only to measure
+	// their effect (see "bench/lapc").
+	ClOptimizeBytecode = true
 )
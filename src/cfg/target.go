@@ -0,0 +1,16 @@
+package cfg
+
+// Target configuration.
+var (
+	// TargetEmacsVersion - major version of Emacs that
+	// translated code is compiled for.
+	// Instructions that are missing in older versions
+	// are replaced by equivalent instruction sequences:
+	//	26 - "switch" (jump table dispatch)
+	TargetEmacsVersion = 28
+
+	// PackageVersion - "Version" header of translated packages
+	// that do not specify it inside package documentation.
+	// Required goism packages are expected to have the same version.
+	PackageVersion = "0.1"
+)
@@ -2,12 +2,16 @@ package dt
 
 import (
 	"bytes"
+	"exn"
 	"magic_pkg/emacs/lisp"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // ConstPool is a set of distincs constant values.
-// It stores atoms of int, float, string and symbol types.
+// It stores atoms of int, float, string and symbol types
+// and jump tables.
 //
 // Serves as a builder for Emacs function constant vector.
 type ConstPool struct {
@@ -72,6 +76,18 @@ func (cp *ConstPool) InsertSym(x string) int {
 	return len(cp.vals) - 1
 }
 
+// InsertJumpTable appends given jump table.
+// Tables are never shared. Returns constant vector index.
+func (cp *ConstPool) InsertJumpTable(x *JumpTable) int {
+	cp.vals = append(cp.vals, x)
+	return len(cp.vals) - 1
+}
+
+// Len returns the number of stored elements.
+func (cp *ConstPool) Len() int {
+	return len(cp.vals)
+}
+
 // Get extracts constant vector value stored at specified index.
 func (cp *ConstPool) Get(index uint16) interface{} {
 	return cp.vals[index]
@@ -98,24 +114,88 @@ func (cp *ConstPool) GetSym(index int) lisp.Symbol {
 }
 
 // Bytes returns printed representation of Emacs Lisp constant vector.
+// Jump table values are printed as label names.
 func (cp *ConstPool) Bytes() []byte {
 	buf := bytes.Buffer{}
 	buf.WriteByte('[')
 	for _, x := range cp.vals {
-		switch x := x.(type) {
-		case string:
-			buf.WriteByte('"')
-			buf.WriteString(x)
-			buf.WriteByte('"')
-		case int64:
-			buf.WriteString(strconv.FormatInt(x, 10))
-		case float64:
-			buf.WriteString(strconv.FormatFloat(x, 'f', -1, 64))
-		case lisp.Symbol:
-			buf.WriteString(string(x))
-		}
+		WriteConst(&buf, x, labelName)
 		buf.WriteByte(' ')
 	}
 	buf.WriteByte(']')
 	return buf.Bytes()
 }
+
+// WriteConst writes printed representation of constant x.
+// Jump table is written as a hash table that maps keys
+// to jump targets; target returns printed target.
+func WriteConst(buf *bytes.Buffer, x interface{}, target func(JumpTarget) string) {
+	table, ok := x.(*JumpTable)
+	if !ok {
+		writeAtom(buf, x)
+		return
+	}
+	buf.WriteString(table.hashTableHeader())
+	for i, key := range table.Keys {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		writeAtom(buf, key)
+		buf.WriteByte(' ')
+		buf.WriteString(target(table.Targets[i]))
+	}
+	buf.WriteString("))")
+}
+
+// writeAtom writes printed representation of constant x.
+// Strings are escaped and floats always have a fraction or
+// exponent, so x is read back with the same type and value.
+func writeAtom(buf *bytes.Buffer, x interface{}) {
+	switch x := x.(type) {
+	case string:
+		WriteString(buf, x)
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case float64:
+		writeFloat(buf, x)
+	case lisp.Symbol:
+		buf.WriteString(x.Literal())
+	default:
+		panic(exn.Logic("unexpected constant type %T", x))
+	}
+}
+
+// WriteString writes (possibly multibyte) string literal.
+func WriteString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(s[i])
+	}
+	buf.WriteByte('"')
+}
+
+// writeFloat writes float literal that is never read back as integer.
+func writeFloat(buf *bytes.Buffer, x float64) {
+	switch {
+	case math.IsNaN(x):
+		buf.WriteString("0.0e+NaN")
+	case math.IsInf(x, 1):
+		buf.WriteString("1.0e+INF")
+	case math.IsInf(x, -1):
+		buf.WriteString("-1.0e+INF")
+	default:
+		s := strconv.FormatFloat(x, 'g', -1, 64)
+		buf.WriteString(s)
+		if !strings.ContainsAny(s, ".e") {
+			buf.WriteString(".0")
+		}
+	}
+}
+
+// labelName returns label name of jump target.
+func labelName(target JumpTarget) string {
+	return target.Name + "-" + strconv.FormatInt(int64(target.Label), 10)
+}
@@ -0,0 +1,33 @@
+package dt
+
+import (
+	"magic_pkg/emacs/lisp"
+	"strconv"
+)
+
+// JumpTable is a constant vector element that is used
+// by "switch" instruction. It maps keys to jump targets.
+//
+// Emacs stores it as a hash table which values are
+// bytecode offsets.
+type JumpTable struct {
+	Test    lisp.Symbol   // "eq" or "equal"
+	Keys    []interface{} // int64, string or lisp.Symbol values
+	Targets []JumpTarget  // Keys[i] jumps to Targets[i]
+}
+
+// JumpTarget is a label that is referenced from jump table.
+type JumpTarget struct {
+	Label int32  // Label ID
+	Name  string // Label name; printed as "Name-Label"
+	PC    int    // Bytecode offset; assigned after encoding
+}
+
+// hashTableHeader returns printed hash table prefix
+// that is followed by "data" key-value pairs.
+// Caller must write closing "))" after the pairs.
+func (x *JumpTable) hashTableHeader() string {
+	return "#s(hash-table size " + strconv.Itoa(len(x.Keys)) +
+		" test " + string(x.Test) +
+		" rehash-size 1.5 rehash-threshold 0.8125 purecopy t data ("
+}
@@ -7,3 +7,73 @@ func sumArray1() int {
 	}
 	return sum
 }
+
+func sumSlice1() int {
+	sum, xs := 0, []int{1, 2, 3}
+	for i := range xs {
+		sum += xs[i]
+	}
+	return sum
+}
+
+func sumSlice2() int {
+	sum, xs := 0, []int{1, 2, 3}
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+
+func rangeSubslice() int {
+	sum, xs := 0, []int{1, 2, 3, 4, 5}[1:4]
+	for i, x := range xs {
+		sum += i * x
+	}
+	return sum
+}
+
+func rangeSliceCount() int {
+	n := 0
+	for range sliceOf3 {
+		n++
+	}
+	return n
+}
+
+// Range expression is evaluated once.
+func rangeSliceAppend() int {
+	xs := make([]int, 3, 10)
+	for _, x := range xs {
+		xs = append(xs, x+1)
+	}
+	return len(xs)
+}
+
+type rangeInts []int
+
+func rangeNamedSlice() int {
+	sum, xs := 0, rangeInts([]int{5, 6})
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+
+// Assignment to key does not change the iteration.
+func rangeSliceModKey() int {
+	sum, xs := 0, []int{1, 2, 3}
+	for i, x := range xs {
+		sum += i*10 + x
+		i += 5
+	}
+	return sum
+}
+
+func rangeArrayModKey() int {
+	sum, xs := 0, [...]int{1, 2, 3}
+	for i := range xs {
+		sum += xs[i]
+		i++
+	}
+	return sum
+}
@@ -0,0 +1,172 @@
+package conformance
+
+import (
+	"emacs/reflect"
+)
+
+type reflectPoint struct {
+	X, Y  int
+	Label string
+}
+
+func (p reflectPoint) Sum() int { return p.X + p.Y }
+
+func reflectKindOfInt(n int) int {
+	return int(reflect.TypeOf(n).Kind())
+}
+
+func reflectKindOfStruct() int {
+	return int(reflect.TypeOf(reflectPoint{X: 1, Y: 2}).Kind())
+}
+
+func reflectTypeString() string {
+	return reflect.TypeOf(reflectPoint{}).String()
+}
+
+func reflectTypeName() string {
+	return reflect.TypeOf(reflectPoint{}).Name()
+}
+
+func reflectPtrElemName() string {
+	p := &reflectPoint{}
+	return reflect.TypeOf(p).Elem().Name()
+}
+
+func reflectNilTypeOf() bool {
+	return reflect.TypeOf(nil) == nil
+}
+
+func reflectNumField() int {
+	return reflect.TypeOf(reflectPoint{}).NumField()
+}
+
+func reflectFieldName(i int) string {
+	return reflect.TypeOf(reflectPoint{}).Field(i).Name
+}
+
+func reflectFieldType(i int) string {
+	return reflect.TypeOf(reflectPoint{}).Field(i).Type.String()
+}
+
+func reflectFieldValue(x, y int) int {
+	v := reflect.ValueOf(reflectPoint{X: x, Y: y, Label: "p"})
+	return int(v.Field(0).Int() + v.Field(1).Int())
+}
+
+func reflectFieldString() string {
+	v := reflect.ValueOf(reflectPoint{Label: "xyz"})
+	return v.Field(2).String()
+}
+
+func reflectMethodByName(name string) bool {
+	_, ok := reflect.TypeOf(reflectPoint{}).MethodByName(name)
+	return ok
+}
+
+func reflectInterface(n int) int {
+	x := reflect.ValueOf(n).Interface()
+	return x.(int)
+}
+
+func reflectTypeAssertOk(n int) bool {
+	var x interface{} = n
+	_, ok := x.(string)
+	return ok
+}
+
+func reflectSliceIndex(i int) int {
+	v := reflect.ValueOf([]int{10, 20, 30})
+	return int(v.Index(i).Int()) + v.Len()
+}
+
+type reflectLabeled struct {
+	reflectPoint
+	Tag string
+}
+
+type reflectCounter struct{ n int }
+
+func (c *reflectCounter) Inc() int {
+	c.n++
+	return c.n
+}
+
+type reflectNested struct {
+	*reflectCounter
+	reflectLabeled
+}
+
+type reflectSummer interface {
+	Sum() int
+}
+
+type reflectIncer interface {
+	Inc() int
+}
+
+type reflectWrapped struct {
+	reflectSummer
+}
+
+func reflectPromotedCall(x, y int) int {
+	l := reflectLabeled{reflectPoint: reflectPoint{X: x, Y: y}}
+	return l.Sum()
+}
+
+func reflectPromotedIface(x, y int) int {
+	var s reflectSummer = reflectLabeled{reflectPoint: reflectPoint{X: x, Y: y}}
+	return s.Sum()
+}
+
+func reflectPromotedNested() int {
+	n := reflectNested{
+		reflectCounter: &reflectCounter{},
+		reflectLabeled: reflectLabeled{reflectPoint: reflectPoint{X: 1, Y: 2}},
+	}
+	n.Inc()
+	var inc reflectIncer = n
+	return inc.Inc()*10 + n.Sum()
+}
+
+func reflectPromotedEmbeddedIface(x, y int) int {
+	w := reflectWrapped{reflectSummer: reflectPoint{X: x, Y: y}}
+	var s reflectSummer = w
+	return s.Sum() + w.Sum()
+}
+
+func reflectPromotedMethods() string {
+	typ := reflect.TypeOf(reflectNested{})
+	names := ""
+	for i := 0; i < typ.NumMethod(); i++ {
+		names += typ.Method(i).Name + " "
+	}
+	return names
+}
+
+// reflectSumFloat has Sum method with other signature
+// than reflectPoint.Sum.
+type reflectSumFloat interface {
+	Sum() float64
+}
+
+func reflectImplements() string {
+	typ := reflect.TypeOf(reflectPoint{})
+	var summerPtr *reflectSummer
+	var sumFloatPtr *reflectSumFloat
+	summer := reflect.TypeOf(summerPtr).Elem()
+	sumFloat := reflect.TypeOf(sumFloatPtr).Elem()
+	res := ""
+	for _, ok := range []bool{
+		typ.Implements(summer),
+		typ.AssignableTo(summer),
+		typ.Implements(sumFloat),
+		typ.AssignableTo(sumFloat),
+	} {
+		if ok {
+			res += "t"
+		} else {
+			res += "f"
+		}
+	}
+	return res
+}
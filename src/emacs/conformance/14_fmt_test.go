@@ -0,0 +1,108 @@
+package conformance
+
+import (
+	"emacs/fmt"
+	"emacs/lisp"
+)
+
+type fmtPoint struct {
+	X, Y int
+}
+
+type fmtName struct {
+	first, last string
+}
+
+func (n fmtName) String() string { return n.first + " " + n.last }
+
+func fmtSprintfInt(n int) string {
+	return fmt.Sprintf("%d|%5d|%-5d|%05d|%x|%X|%+d", n, n, n, n, n, n, n)
+}
+
+func fmtSprintfString(s string) string {
+	return fmt.Sprintf("%s|%q|%6s|%-6s|%.2s|%x", s, s, s, s, s, s)
+}
+
+func fmtSprintfFloat(x float64) string {
+	return fmt.Sprintf("%v|%.2f|%8.3f", x, x, x)
+}
+
+func fmtSprintfStruct(x, y int) string {
+	p := fmtPoint{X: x, Y: y}
+	return fmt.Sprintf("%v|%+v|%v", p, p, &p)
+}
+
+func fmtSprintfSlice() string {
+	return fmt.Sprintf("%v|%v|%d", []int{1, 2, 3}, []string{"a", "b"}, []int{})
+}
+
+func fmtSprintfMap() string {
+	m := make(map[string]int)
+	m["b"] = 2
+	m["a"] = 1
+	m["c"] = 3
+	return fmt.Sprintf("%v", m)
+}
+
+func fmtSprintfType() string {
+	return fmt.Sprintf("%T|%T|%T|%T", 1, "s", fmtPoint{}, []int{})
+}
+
+func fmtSprintfStringer(first, last string) string {
+	return fmt.Sprintf("%v|%s|%q", fmtName{first: first, last: last}, fmtName{first: first, last: last}, fmtName{first: first, last: last})
+}
+
+func fmtSprintfError(msg string) string {
+	err := fmt.Errorf("wrapped: %s", msg)
+	return fmt.Sprintf("%v", err)
+}
+
+func fmtErrorf(n int) string {
+	return fmt.Errorf("code %d", n).Error()
+}
+
+func fmtSprintfBad() string {
+	return fmt.Sprintf("%d %z|%d", 1, 2)
+}
+
+func fmtSprintfExtra() string {
+	return fmt.Sprintf("%d", 1, "x")
+}
+
+func fmtSprintfNil() string {
+	var err error
+	return fmt.Sprintf("%v", err)
+}
+
+func fmtSprint() string {
+	return fmt.Sprint("a", 1, 2, "b", 3.5)
+}
+
+func fmtSprintln() string {
+	return fmt.Sprintln("a", 1, true)
+}
+
+func fmtFprintf(n int) string {
+	var s string
+	lisp.WithTempBuffer(func() {
+		fmt.Fprintf(fmt.StreamWriter{Stream: lisp.Call("current-buffer")}, "n=%d", n)
+		s = lisp.Call("buffer-string").String()
+	})
+	return s
+}
+
+type fmtWriter struct {
+	data string
+}
+
+func (w *fmtWriter) Write(p []byte) (int, error) {
+	w.data += string(p)
+	return len(p), nil
+}
+
+func fmtFprintfWriter() string {
+	w := &fmtWriter{}
+	n, _ := fmt.Fprintf(w, "%s=%d;", "x", 1)
+	fmt.Fprintf(w, "n=%d", n)
+	return w.data
+}
@@ -0,0 +1,131 @@
+package conformance
+
+import (
+	"emacs/errors"
+	"emacs/fmt"
+	"emacs/lisp"
+)
+
+var errNotFound = errors.New("not found")
+
+type codeError struct {
+	Code int
+	Msg  string
+}
+
+func (e codeError) Error() string { return e.Msg }
+
+func errorsNewMessage(msg string) string {
+	return errors.New(msg).Error()
+}
+
+func errorsNewDistinct() bool {
+	return errors.New("x") == errors.New("x")
+}
+
+func errorsSameValue() bool {
+	err := errors.New("x")
+	return err == err
+}
+
+func errorsNilCompare() bool {
+	var err error
+	return err == nil && errNotFound != nil
+}
+
+func errorsWrapMessage(key string) string {
+	return fmt.Errorf("lookup %s: %w", key, errNotFound).Error()
+}
+
+func errorsIsWrapped() bool {
+	err := fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", errNotFound))
+	return errors.Is(err, errNotFound)
+}
+
+func errorsIsOther() bool {
+	err := fmt.Errorf("outer: %w", errNotFound)
+	return errors.Is(err, errors.New("not found"))
+}
+
+func errorsIsNotWrapped() bool {
+	err := fmt.Errorf("outer: %v", errNotFound)
+	return errors.Is(err, errNotFound)
+}
+
+func errorsUnwrapNil() bool {
+	return errors.Unwrap(errNotFound) == nil
+}
+
+func errorsUnwrap() bool {
+	return errors.Unwrap(fmt.Errorf("x: %w", errNotFound)) == errNotFound
+}
+
+func errorsAs(code int) int {
+	err := fmt.Errorf("wrapped: %w", codeError{Code: code, Msg: "failed"})
+	var target codeError
+	if errors.As(err, &target) {
+		return target.Code
+	}
+	return -1
+}
+
+func errorsAsMiss() bool {
+	var target codeError
+	return errors.As(errNotFound, &target)
+}
+
+func errorsStructEqual(code int) bool {
+	var a error = codeError{Code: code, Msg: "x"}
+	var b error = codeError{Code: code, Msg: "x"}
+	return a == b
+}
+
+func errorsBadWrap() string {
+	return fmt.Sprintf("%w", errNotFound)
+}
+
+func errorsPrint() string {
+	return fmt.Sprintf("%v|%s|%q", errNotFound, errNotFound, errNotFound)
+}
+
+func errorsLispMessage(msg string) string {
+	return lisp.Call("goism-error-message", errors.New(msg)).String()
+}
+
+type ptrError struct{ code int }
+
+func (e *ptrError) Error() string { return "ptr" }
+
+func errorsAsPtr(code int) int {
+	err := fmt.Errorf("wrapped: %w", &ptrError{code: code})
+	var target *ptrError
+	if errors.As(err, &target) {
+		return target.code
+	}
+	return -1
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+type tempError struct{ msg string }
+
+func (e tempError) Error() string   { return e.msg }
+func (e tempError) Temporary() bool { return true }
+
+func errorsAsIface() lisp.Object {
+	var target temporary
+	miss := errors.As(errNotFound, &target)
+	err := fmt.Errorf("wrapped: %w", tempError{msg: "busy"})
+	hit := errors.As(err, &target)
+	return lisp.Call("list", miss, hit, target.Temporary(), target.(error).Error())
+}
+
+func errorsAsError() string {
+	var target error
+	if errors.As(fmt.Errorf("wrapped: %w", errNotFound), &target) {
+		return target.Error()
+	}
+	return ""
+}
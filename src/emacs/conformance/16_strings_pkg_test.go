@@ -0,0 +1,131 @@
+package conformance
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func stringsIndex(s, substr string) int {
+	return strings.Index(s, substr)
+}
+
+func stringsLastIndex(s, substr string) int {
+	return strings.LastIndex(s, substr)
+}
+
+func stringsIndexByte(s string, c byte) int {
+	return strings.IndexByte(s, c)
+}
+
+func stringsContains(s, substr string) bool {
+	return strings.Contains(s, substr)
+}
+
+func stringsPrefixSuffix(s string) bool {
+	return strings.HasPrefix(s, "ab") && strings.HasSuffix(s, "yz")
+}
+
+func stringsSplitJoin(s, sep string) string {
+	parts := strings.Split(s, sep)
+	return strings.Join(parts, "|") + ";" + strconv.Itoa(len(parts))
+}
+
+func stringsSplitN(s, sep string, n int) string {
+	return strings.Join(strings.SplitN(s, sep, n), "|")
+}
+
+func stringsFields(s string) string {
+	return strings.Join(strings.Fields(s), "|")
+}
+
+func stringsReplace(s, old, new string, n int) string {
+	return strings.Replace(s, old, new, n)
+}
+
+func stringsTrim(s string) string {
+	return strings.TrimSpace(s) + "|" + strings.Trim(s, " xy") + "|" +
+		strings.TrimPrefix(s, " x") + "|" + strings.TrimSuffix(s, "y ")
+}
+
+func stringsCase(s string) string {
+	return strings.ToUpper(s) + strings.ToLower(s)
+}
+
+func stringsRepeat(s string, n int) string {
+	return strings.Repeat(s, n)
+}
+
+func stringsCount(s, substr string) int {
+	return strings.Count(s, substr)
+}
+
+func stringsBuilder(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString(strconv.Itoa(i))
+		b.WriteByte(',')
+	}
+	b.WriteRune('♞')
+	return b.String() + strconv.Itoa(b.Len())
+}
+
+func strconvAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func strconvAtoiError(s string) string {
+	_, err := strconv.Atoi(s)
+	return err.Error()
+}
+
+func strconvParseInt(s string, base, bitSize int) int {
+	n, err := strconv.ParseInt(s, base, bitSize)
+	if err != nil {
+		return -1
+	}
+	return int(n)
+}
+
+func strconvParseFloat(s string) float64 {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return -1
+	}
+	return x
+}
+
+func strconvParseBool(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+func strconvFormat(n int) string {
+	return strconv.FormatInt(int64(n), 2) + "|" + strconv.FormatInt(int64(n), 16) + "|" +
+		strconv.FormatInt(int64(n), 36) + "|" + strconv.FormatBool(n > 0)
+}
+
+func strconvFormatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', 2, 64) + "|" + strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+func strconvQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+func utf8RuneCount(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func utf8RuneLen(r rune) int {
+	return utf8.RuneLen(r)
+}
+
+func utf8Decode(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	return int(r)*10 + size
+}
@@ -0,0 +1,161 @@
+package conformance
+
+import (
+	"container/heap"
+	"container/list"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func intsString(xs []int) string {
+	parts := make([]string, len(xs))
+	for i := 0; i < len(xs); i++ {
+		parts[i] = strconv.Itoa(xs[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortInts() string {
+	xs := []int{5, 2, 8, 1, 9, 3}
+	sort.Ints(xs)
+	return intsString(xs)
+}
+
+func sortSubslice() string {
+	xs := []int{5, 4, 3, 2, 1}
+	sort.Ints(xs[1:4])
+	return intsString(xs)
+}
+
+func sortStrings() string {
+	xs := []string{"pear", "apple", "fig"}
+	sort.Strings(xs)
+	return strings.Join(xs, ",")
+}
+
+func sortSlice() string {
+	xs := []int{5, 2, 8, 1, 9, 3}
+	sort.Slice(xs, func(i, j int) bool { return xs[i] > xs[j] })
+	return intsString(xs)
+}
+
+type sortPerson struct {
+	name string
+	age  int
+}
+
+func sortSliceStable() string {
+	people := []sortPerson{
+		{name: "a", age: 30},
+		{name: "b", age: 20},
+		{name: "c", age: 30},
+		{name: "d", age: 20},
+	}
+	sort.SliceStable(people, func(i, j int) bool { return people[i].age < people[j].age })
+	s := ""
+	for i := 0; i < len(people); i++ {
+		s += people[i].name
+	}
+	return s
+}
+
+type byLen []string
+
+func (x byLen) Len() int           { return len(x) }
+func (x byLen) Less(i, j int) bool { return len(x[i]) < len(x[j]) }
+func (x byLen) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+func sortSort(n int) bool {
+	xs := make([]int, n)
+	for i := 0; i < n; i++ {
+		xs[i] = (i * 7919) % n
+	}
+	sort.Sort(sort.IntSlice(xs))
+	return sort.IntsAreSorted(xs) && xs[0] == 0 && xs[n-1] == n-1
+}
+
+func sortSortInterface() string {
+	xs := []string{"ccc", "a", "bb", "dddd"}
+	sort.Sort(byLen(xs))
+	return strings.Join(xs, ",")
+}
+
+func sortReverse() string {
+	xs := []int{1, 3, 2}
+	sort.Sort(sort.Reverse(sort.IntSlice(xs)))
+	return intsString(xs)
+}
+
+func sortStable() string {
+	xs := []string{"bb", "a", "cc", "d", "ee"}
+	sort.Stable(byLen(xs))
+	return strings.Join(xs, ",")
+}
+
+func sortSearch(x int) int {
+	xs := []int{1, 3, 5, 7}
+	return sort.SearchInts(xs, x)*10 + sort.Search(len(xs), func(i int) bool { return xs[i] >= x })
+}
+
+func listPushIterate() string {
+	l := list.New()
+	l.PushBack(2)
+	l.PushBack(3)
+	l.PushFront(1)
+	s := ""
+	for e := l.Front(); e != nil; e = e.Next() {
+		s += strconv.Itoa(e.Value.(int))
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		s += strconv.Itoa(e.Value.(int))
+	}
+	return s + ";" + strconv.Itoa(l.Len())
+}
+
+func listRemoveMove() string {
+	var l list.List
+	a := l.PushBack("a")
+	b := l.PushBack("b")
+	l.PushBack("c")
+	l.Remove(b)
+	l.MoveToBack(a)
+	s := ""
+	for e := l.Front(); e != nil; e = e.Next() {
+		s += e.Value.(string)
+	}
+	return s + ";" + strconv.Itoa(l.Len())
+}
+
+// Pointers to slices are not supported, so heap
+// data is wrapped into a struct.
+type intHeap struct {
+	xs []int
+}
+
+func (h *intHeap) Len() int           { return len(h.xs) }
+func (h *intHeap) Less(i, j int) bool { return h.xs[i] < h.xs[j] }
+func (h *intHeap) Swap(i, j int)      { h.xs[i], h.xs[j] = h.xs[j], h.xs[i] }
+
+func (h *intHeap) Push(x interface{}) {
+	h.xs = append(h.xs, x.(int))
+}
+
+func (h *intHeap) Pop() interface{} {
+	n := len(h.xs)
+	x := h.xs[n-1]
+	h.xs = h.xs[0 : n-1]
+	return x
+}
+
+func heapOrder() string {
+	h := &intHeap{xs: []int{5, 2, 8}}
+	heap.Init(h)
+	heap.Push(h, 3)
+	heap.Push(h, 1)
+	var xs []int
+	for h.Len() > 0 {
+		xs = append(xs, heap.Pop(h).(int))
+	}
+	return intsString(xs)
+}
@@ -0,0 +1,61 @@
+package conformance
+
+// Self tail calls are compiled to jumps, so deep
+// recursion does not exceed "max-lisp-eval-depth".
+
+func tailSum(n, acc int) int {
+	if n == 0 {
+		return acc
+	}
+	return tailSum(n-1, acc+n)
+}
+
+// All arguments are evaluated before parameters are updated.
+func tailFib(n, a, b int) int {
+	if n == 0 {
+		return a
+	}
+	return tailFib(n-1, b, a+b)
+}
+
+var tailCounter int
+
+func tailCount(n int) {
+	if n == 0 {
+		return
+	}
+	tailCounter++
+	tailCount(n - 1)
+}
+
+func testTailCount(n int) int {
+	tailCounter = 0
+	tailCount(n)
+	return tailCounter
+}
+
+// Tail call from inside a loop.
+func tailSkipNegative(xs []int, i, acc int) int {
+	for ; i < len(xs); i++ {
+		if xs[i] < 0 {
+			return tailSkipNegative(xs, i+1, acc*2)
+		}
+		acc += xs[i]
+	}
+	return acc
+}
+
+func testTailSkipNegative() int {
+	return tailSkipNegative([]int{1, -1, 2, -1, 3}, 0, 0)
+}
+
+// Parameter is shadowed: call is not eliminated.
+func tailShadowed(n int) int {
+	if n <= 0 {
+		return n
+	}
+	{
+		n := n - 1
+		return tailShadowed(n - 1)
+	}
+}
@@ -0,0 +1,101 @@
+package conformance
+
+// Constant propagation and dead store elimination
+// must not change observable behaviour.
+
+func constPropStraight() int {
+	x := 10
+	y := x * 4
+	return y + x
+}
+
+func constPropBranches(cond bool) int {
+	x := 1
+	y := 2
+	if cond {
+		x = 5
+	} else {
+		y = 3
+	}
+	return x*10 + y
+}
+
+func constPropEarlyReturn(n int) int {
+	x := 7
+	if n < 0 {
+		x = 100
+		return x
+	}
+	return x + n
+}
+
+func constPropLoop(n int) int {
+	x := 0
+	step := 3
+	for i := 0; i < n; i++ {
+		x = x + step
+	}
+	return x
+}
+
+func constPropGoto(n int) int {
+	x := 1
+loop:
+	if n > 0 {
+		x = x * 2
+		n--
+		goto loop
+	}
+	return x
+}
+
+func constPropSwitch(n int) int {
+	x := 1
+	switch n {
+	case 1:
+		x = 10
+	case 2:
+		if n > 5 {
+			x = 20
+		} else {
+			x = 30
+		}
+	}
+	return x
+}
+
+func constPropShadow(n int) int {
+	x := 1
+	if n > 0 {
+		x := 2
+		n += x
+	}
+	return n + x
+}
+
+var deadStoreCalls int
+
+func deadStoreCall() int {
+	deadStoreCalls++
+	return deadStoreCalls
+}
+
+func testDeadStoreSideEffects() int {
+	deadStoreCalls = 0
+	x := deadStoreCall()
+	x = deadStoreCall()
+	unused := deadStoreCall()
+	unused = 5
+	_ = unused
+	var y int
+	y = deadStoreCall()
+	return x*10 + y + deadStoreCalls*100
+}
+
+func testDeadStoreMultiValue() int {
+	a, b := multiResult2()
+	a = 0
+	return a + b
+}
+
+func multiResult2() (int, int) { return 7, 9 }
@@ -0,0 +1,106 @@
+package conformance
+
+// Structs that do not escape are replaced with field locals.
+// Escaping structs must keep their identity.
+
+type scalarPoint struct {
+	x, y int
+}
+
+type scalarRect struct {
+	min, max scalarPoint
+}
+
+func (p scalarPoint) add(q scalarPoint) scalarPoint {
+	return scalarPoint{x: p.x + q.x, y: p.y + q.y}
+}
+
+func (p *scalarPoint) scale(k int) {
+	p.x *= k
+	p.y *= k
+}
+
+func scalarDist(x1, y1, x2, y2 int) int {
+	a := scalarPoint{x: x1, y: y1}
+	b := scalarPoint{x: x2, y: y2}
+	dx := b.x - a.x
+	dy := b.y - a.y
+	return dx*dx + dy*dy
+}
+
+func scalarUpdate(n int) int {
+	var p scalarPoint
+	for i := 0; i < n; i++ {
+		p.x += i
+		p.y++
+	}
+	return p.x*100 + p.y
+}
+
+func scalarSwap(x, y int) int {
+	p := scalarPoint{x: x, y: y}
+	p = scalarPoint{x: p.y, y: p.x}
+	return p.x*10 + p.y
+}
+
+func scalarCopy(x int) int {
+	p := scalarPoint{x: x, y: 1}
+	q := p
+	q.x = 0
+	return p.x + q.x + q.y
+}
+
+func scalarNested(x int) int {
+	r := scalarRect{max: scalarPoint{x: x, y: x * 2}}
+	r.min.y = 3
+	return r.max.x + r.max.y - r.min.y
+}
+
+func scalarMethods(x int) int {
+	p := scalarPoint{x: x, y: 2}
+	q := p.add(scalarPoint{x: 1, y: 1})
+	return q.x * q.y
+}
+
+func scalarEscapePointer(x int) int {
+	p := scalarPoint{x: x, y: x}
+	p.scale(3)
+	return p.x + p.y
+}
+
+func scalarEscapeReturn(x int) scalarPoint {
+	p := scalarPoint{x: x}
+	p.y = x + 1
+	return p
+}
+
+func testScalarEscapeReturn() int {
+	p := scalarEscapeReturn(4)
+	return p.x*10 + p.y
+}
+
+func scalarEscapeIface(x int) interface{} {
+	p := scalarPoint{x: x, y: 1}
+	return p
+}
+
+func testScalarEscapeIface() int {
+	p := scalarEscapeIface(7).(scalarPoint)
+	return p.x + p.y
+}
+
+func scalarEscapeClosure(x int) int {
+	p := scalarPoint{x: x, y: 1}
+	f := func() int { return p.x + p.y }
+	return f() + p.x
+}
+
+type triple struct {
+	a, b, c int
+}
+
+func structMiddleField() int {
+	t := triple{a: 1, b: 2, c: 3}
+	t.b = 20
+	return t.a*100 + t.b*10 + t.c
+}
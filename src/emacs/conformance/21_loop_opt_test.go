@@ -0,0 +1,55 @@
+package conformance
+
+// Loop-invariant code motion must not change observable behaviour.
+
+func loopSliceFill(n, k int) int {
+	xs := make([]int, n)
+	for i := 0; i < len(xs); i++ {
+		xs[i] = i * k
+	}
+	sum := 0
+	for i := 0; i < len(xs); i++ {
+		sum += xs[i]
+	}
+	return sum
+}
+
+func loopInvariantExpr(a, b, n int) int {
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += a*b + i
+	}
+	return sum
+}
+
+func loopNestedSlices() int {
+	rows := [][]int{{1, 2}, {3, 4}, {5, 6}}
+	sum := 0
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
+		for j := 0; j < len(row); j++ {
+			sum += row[j] * (i + 1)
+		}
+	}
+	return sum
+}
+
+func loopSliceReassigned() int {
+	xs := []int{1, 2, 3}
+	ys := []int{10, 20, 30}
+	sum := 0
+	for i := 0; i < 3; i++ {
+		sum += xs[i]
+		xs = ys
+	}
+	return sum
+}
+
+func loopSubsliceWrite() int {
+	xs := []int{0, 0, 0, 0, 0}
+	ys := xs[2:4]
+	for i := 0; i < len(ys); i++ {
+		ys[i] = i + 7
+	}
+	return xs[2]*10 + xs[3]
+}
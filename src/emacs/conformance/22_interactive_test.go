@@ -0,0 +1,42 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Interactive functions are Emacs commands.
+// Their interactive spec is either explicit or
+// derived from parameter types.
+
+//goism:interactive
+func cmdNoArgs() int { return 1 }
+
+//goism:interactive "sGreeting: \nnCount: "
+func cmdExplicit(greeting string, count int) int {
+	return len(greeting) * count
+}
+
+//goism:interactive
+func cmdDerived(name string, n int, sym lisp.Symbol, x lisp.Object) string {
+	return name
+}
+
+//goism:interactive
+func cmdPrefix(n int) int { return n * 2 }
+
+func interactiveForm(name string) lisp.Object {
+	return lisp.Call("interactive-form", lisp.Intern("goism-conformance."+name))
+}
+
+func isCommand(name string) bool {
+	return lisp.Call("commandp", lisp.Intern("goism-conformance."+name)).Bool()
+}
+
+func testCmdNoArgs() lisp.Object      { return interactiveForm("cmdNoArgs") }
+func testCmdExplicit() lisp.Object    { return interactiveForm("cmdExplicit") }
+func testCmdDerived() lisp.Object     { return interactiveForm("cmdDerived") }
+func testCmdPrefix() lisp.Object      { return interactiveForm("cmdPrefix") }
+func testNotCommand() bool            { return isCommand("isCommand") }
+func testIsCommand() bool             { return isCommand("cmdPrefix") }
+func testCmdCall() int                { return cmdExplicit("ab", 3) + cmdPrefix(4) }
+func testNotCommandForm() lisp.Object { return interactiveForm("testCmdCall") }
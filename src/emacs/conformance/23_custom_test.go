@@ -0,0 +1,106 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Package variables that are exported as user options.
+
+// customFlag enables something.
+//
+//goism:custom
+var customFlag = true
+
+// customLimit is a number of things.
+//
+//goism:custom
+var customLimit = 10 * 2
+
+//goism:custom goism-conformance-extra
+var customName string
+
+// customWords are customized as a list.
+//
+//goism:custom
+var customWords = []string{"a", "b"}
+
+// customAliases are customized as an alist.
+//
+//goism:custom
+var customAliases = newCustomAliases()
+
+func newCustomAliases() map[string]int {
+	m := make(map[string]int)
+	m["x"] = 1
+	return m
+}
+
+func customSymbol(name string) lisp.Symbol {
+	return lisp.Intern("goism-conformance." + name)
+}
+
+func customProp(name, prop string) lisp.Object {
+	return lisp.Call("get", customSymbol(name), lisp.Intern(prop))
+}
+
+func customSet(name string, val lisp.Object) {
+	lisp.Call("funcall", customProp(name, "custom-set"), customSymbol(name), val)
+}
+
+func customGet(name string) lisp.Object {
+	return lisp.Call("funcall", customProp(name, "custom-get"), customSymbol(name))
+}
+
+func testCustomValues() int {
+	n := len(customWords) + len(customAliases) + customLimit + len(customName)
+	if customFlag {
+		n += 100
+	}
+	return n
+}
+
+func testCustomTypes() lisp.Object {
+	return lisp.Call("list",
+		customProp("customFlag", "custom-type"),
+		customProp("customLimit", "custom-type"),
+		customProp("customName", "custom-type"),
+		customProp("customWords", "custom-type"),
+		customProp("customAliases", "custom-type"))
+}
+
+func testCustomStandard() lisp.Object {
+	return lisp.Call("list",
+		customProp("customLimit", "standard-value"),
+		customProp("customWords", "standard-value"),
+		customProp("customAliases", "standard-value"))
+}
+
+func testCustomDoc() lisp.Object {
+	return customProp("customFlag", "variable-documentation")
+}
+
+func testCustomSetWords() int {
+	old := customGet("customWords")
+	customSet("customWords", lisp.Call("list", "x", "y", "z"))
+	n := 0
+	for _, w := range customWords {
+		n += len(w)
+	}
+	n += len(customWords) * 10
+	customSet("customWords", old)
+	return n + len(customWords)*100
+}
+
+func testCustomSetAliases() int {
+	old := customGet("customAliases")
+	alist := lisp.Call("list",
+		lisp.Call("cons", "y", 2),
+		lisp.Call("cons", "z", 3),
+		lisp.Call("cons", "y", 4))
+	customSet("customAliases", alist)
+	n := customAliases["y"]*10 + customAliases["z"] + len(customAliases)*100
+	customSet("customAliases", old)
+	return n + customAliases["x"]*1000
+}
+
+func testCustomGetWords() lisp.Object { return customGet("customWords") }
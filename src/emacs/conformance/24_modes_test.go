@@ -0,0 +1,180 @@
+package conformance
+
+import (
+	"emacs/emacs"
+	"emacs/lisp"
+)
+
+// Minor modes, keymaps and hooks that are defined
+// by package variables of "emacs/emacs" types.
+
+var modeCounter int
+
+//goism:interactive
+func modeCmd() int { return 1 }
+
+func modeEnable()  { modeCounter += 10 }
+func modeDisable() { modeCounter-- }
+func modeHookFn()  { modeCounter += 100 }
+
+var modeTest = emacs.MinorMode{
+	Name:    "goism-conformance-test-mode",
+	Doc:     "Conformance test mode.",
+	Lighter: " Test",
+	Keymap: emacs.Keymap{
+		Bindings: []emacs.KeyBinding{
+			{Key: "C-c t", Command: modeCmd},
+		},
+	},
+	OnEnable:  modeEnable,
+	OnDisable: modeDisable,
+}
+
+var modeGlobalMap = emacs.Keymap{
+	Name: "goism-conformance-global-map",
+	Bindings: []emacs.KeyBinding{
+		{Key: "C-c g", Command: modeCmd},
+	},
+}
+
+var modeGlobal = emacs.MinorMode{
+	Name:   "goism-conformance-global-mode",
+	Global: true,
+	Keymap: modeGlobalMap,
+}
+
+var modeHook = emacs.Hook{
+	Name:  "goism-conformance-test-mode-hook",
+	Funcs: []any{modeHookFn},
+}
+
+func modeValue(name string) lisp.Object {
+	return lisp.Call("symbol-value", lisp.Intern(name))
+}
+
+func testModeToggle() int {
+	modeCounter = 0
+	lisp.Call("goism-conformance-test-mode")
+	lisp.Call("goism-conformance-test-mode", lisp.Intern("toggle"))
+	lisp.Call("goism-conformance-test-mode", 1)
+	lisp.Call("goism-conformance-test-mode", -1)
+	return modeCounter
+}
+
+func testModeState() lisp.Object {
+	return lisp.Call("list",
+		lisp.Call("goism-conformance-test-mode", 1),
+		modeValue("goism-conformance-test-mode"),
+		lisp.Call("goism-conformance-test-mode", 0),
+		modeValue("goism-conformance-test-mode"))
+}
+
+func testModeKeymap() lisp.Object {
+	return lisp.Call("lookup-key", modeValue("goism-conformance-test-mode-map"), "C-c t")
+}
+
+func testModeGlobalKeymap() lisp.Object {
+	return lisp.Call("lookup-key", modeValue("goism-conformance-global-map"), "C-c g")
+}
+
+func testModeCommand() bool {
+	return lisp.Call("commandp", lisp.Intern("goism-conformance-global-mode")).Bool()
+}
+
+// testModeDoc returns the first line of mode command documentation;
+// the rest of it is a signature.
+func testModeDoc() string {
+	doc := lisp.Call("documentation", lisp.Intern("goism-conformance-test-mode")).String()
+	for i := 0; i < len(doc); i++ {
+		if doc[i] == '\n' {
+			return doc[:i]
+		}
+	}
+	return doc
+}
+
+func testModeLighter() lisp.Object {
+	return lisp.Call("assq", lisp.Intern("goism-conformance-test-mode"), modeValue("minor-mode-alist"))
+}
+
+func testModeVars() string {
+	return modeTest.Name + modeGlobal.Name + modeHook.Name
+}
+
+// Major modes record the order in which their parts run.
+var modeTrace string
+
+func modeBaseEnable()  { modeTrace += "b" }
+func modeMajorEnable() { modeTrace += "m" }
+func modeBaseHookFn()  { modeTrace += "B" }
+func modeMajorHookFn() { modeTrace += "M" }
+
+var modeBase = emacs.MajorMode{
+	Name:     "goism-conformance-base-mode",
+	OnEnable: modeBaseEnable,
+}
+
+var modeMajor = emacs.MajorMode{
+	Name:     "goism-conformance-major-mode",
+	Parent:   "goism-conformance-base-mode",
+	Doc:      "Conformance major mode.",
+	ModeName: "Major",
+	Keymap: emacs.Keymap{
+		Bindings: []emacs.KeyBinding{
+			{Key: "C-c m", Command: modeCmd},
+		},
+	},
+	OnEnable: modeMajorEnable,
+}
+
+var modeBaseHook = emacs.Hook{
+	Name:  "goism-conformance-base-mode-hook",
+	Funcs: []any{modeBaseHookFn},
+}
+
+var modeMajorHook = emacs.Hook{
+	Name:  "goism-conformance-major-mode-hook",
+	Funcs: []any{modeMajorHookFn},
+}
+
+// testMajorMode enters derived mode in a buffer that has
+// a local variable; it is killed by the base mode.
+func testMajorMode() lisp.Object {
+	var res lisp.Object
+	lisp.WithTempBuffer(func() {
+		modeTrace = ""
+		lisp.Call("set", lisp.Call("make-local-variable", lisp.Intern("goism-conformance-local")), 1)
+		lisp.Call("goism-conformance-major-mode")
+		res = lisp.Call("list",
+			modeTrace,
+			modeValue("major-mode"),
+			modeValue("mode-name"),
+			lisp.Call("local-variable-p", lisp.Intern("goism-conformance-local")))
+	})
+	return res
+}
+
+// testMajorModeKeymap returns bindings of mode keymap and its parent.
+func testMajorModeKeymap() lisp.Object {
+	var res lisp.Object
+	lisp.WithTempBuffer(func() {
+		lisp.Call("define-key", modeValue("goism-conformance-base-mode-map"), "C-c b", lisp.Intern("ignore"))
+		lisp.Call("goism-conformance-major-mode")
+		keymap := lisp.Call("current-local-map")
+		res = lisp.Call("list",
+			lisp.Eq(keymap, modeValue("goism-conformance-major-mode-map")),
+			lisp.Call("lookup-key", keymap, "C-c m"),
+			lisp.Call("lookup-key", keymap, "C-c b"))
+	})
+	return res
+}
+
+func testMajorModeBase() lisp.Object {
+	var res lisp.Object
+	lisp.WithTempBuffer(func() {
+		modeTrace = ""
+		lisp.Call("goism-conformance-base-mode")
+		res = lisp.Call("list", modeTrace, modeValue("mode-name"))
+	})
+	return res
+}
@@ -0,0 +1,57 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Opaque Emacs Lisp object types.
+
+func testLispTypesBuffer() string {
+	buf := lisp.Call("generate-new-buffer", "typed").Buffer()
+	lisp.SetBuffer(buf)
+	lisp.Insert("text")
+	return lisp.BufferString()
+}
+
+func testLispTypesBufferEq() bool {
+	buf := lisp.GenerateNewBuffer("typed")
+	lisp.SetBuffer(buf)
+	return buf == lisp.CurrentBuffer() && buf != nil
+}
+
+func testLispTypesZero() bool {
+	var buf lisp.Buffer
+	var cell lisp.Cons
+	return buf == nil && cell == nil
+}
+
+func testLispTypesCons() int {
+	cell := lisp.Call("list", 1, 2).Cons()
+	lisp.SetCar(cell, 10)
+	return lisp.Car(cell).Int()*10 + lisp.Car(lisp.Cdr(cell).Cons()).Int()
+}
+
+func testLispTypesHashTable() int {
+	table := lisp.MakeHashTable(lisp.Intern(":test"), lisp.Intern("equal"))
+	lisp.Puthash("a", 1, table)
+	lisp.Puthash("b", 2, table)
+	lisp.Remhash("a", table)
+	return lisp.Gethash("b", table, 0).Int() + lisp.HashTableCount(table)
+}
+
+func testLispTypesCoerce() bool {
+	x := lisp.Call("make-hash-table")
+	return lisp.IsHashTable(x.HashTable())
+}
+
+func testLispTypesArg(cell lisp.Cons) lisp.Object {
+	return lisp.Car(cell)
+}
+
+func testLispTypesBadMarker() lisp.Marker {
+	return lisp.Call("generate-new-buffer", "typed").Marker()
+}
+
+func testLispTypesBadWindow() lisp.Window {
+	return lisp.Call("list", 1).Window()
+}
@@ -0,0 +1,92 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Conversions between Go and Emacs Lisp sequences.
+
+func testSeqSliceToVector() lisp.Object {
+	xs := []int{1, 2, 3}
+	return lisp.Call("list", lisp.SliceToVector(xs), lisp.SliceToVector(xs[1:]))
+}
+
+func testSeqVectorToSlice() int {
+	vec := lisp.Call("vector", 1, 2, 3)
+	xs := lisp.VectorToSlice(vec).([]int)
+	xs[0] = 10
+	return lisp.Call("aref", vec, 0).Int() + len(xs)
+}
+
+func testSeqSliceToList() lisp.List {
+	return lisp.SliceToList([]string{"a", "b"})
+}
+
+func testSeqListToSlice() int {
+	xs := lisp.ListToSlice(lisp.Call("list", 1, 2, 3).List()).([]int)
+	return xs[0]*100 + xs[1]*10 + xs[2]
+}
+
+func testSeqMapToAlist() lisp.List {
+	m := make(map[string]int)
+	m["a"] = 1
+	return lisp.MapToAlist(m)
+}
+
+func testSeqAlistToMap(alist lisp.List) int {
+	m := lisp.AlistToMap(alist).(map[string]int)
+	return m["a"]*10 + len(m)
+}
+
+func testSeqMapToPlist() lisp.List {
+	m := make(map[string]int)
+	m["a"] = 1
+	return lisp.MapToPlist(m)
+}
+
+func testSeqPlistToMap(plist lisp.List) int {
+	m := lisp.PlistToMap(plist).(map[string]int)
+	return m["a"]*10 + len(m)
+}
+
+func testSeqRange(list lisp.List) int {
+	sum := 0
+	for i, x := range list {
+		if i == 1 {
+			continue
+		}
+		sum += x.Int()
+	}
+	return sum
+}
+
+func testSeqRangeIndex(list lisp.List) int {
+	n := 0
+	for i := range list {
+		n += i
+	}
+	return n
+}
+
+// Assignment to key does not change the iteration.
+func testSeqRangeModKey(list lisp.List) int {
+	sum := 0
+	for i, x := range list {
+		sum += i*100 + x.Int()
+		i += 10
+	}
+	return sum
+}
+
+func testSeqListOps(list lisp.List) lisp.List {
+	return lisp.List{list[len(list)-1], list[0]}
+}
+
+func testSeqEmptyList() bool {
+	var list lisp.List
+	n := 0
+	for range list {
+		n++
+	}
+	return list == nil && len(list) == 0 && n == 0
+}
@@ -0,0 +1,78 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Go functions that are passed to Emacs Lisp as callables.
+
+var lambdaCounter int
+
+func lambdaConcat(a, b string) string { return a + b }
+
+func lambdaDivMod(x, y int) (int, int) { return x / y, x % y }
+
+func testLambdaMapcar(list lisp.List) lisp.Object {
+	return lisp.Call("mapcar", lisp.Lambda(func(x int) int { return x * 2 }), list)
+}
+
+func testLambdaClosure(k int) lisp.Object {
+	add := func(x int) int { return x + k }
+	return lisp.Call("mapcar", lisp.Lambda(add), lisp.List{lisp.Call("identity", 1)})
+}
+
+func testLambdaFuncValue() lisp.Object {
+	return lisp.Call("funcall", lisp.Lambda(lambdaConcat), "foo", "bar")
+}
+
+func testLambdaMultiResult() lisp.Object {
+	return lisp.Call("funcall", lisp.Lambda(lambdaDivMod), 7, 2)
+}
+
+func testLambdaVoid() lisp.Object {
+	lambdaCounter = 0
+	res := lisp.Call("funcall", lisp.Lambda(func() { lambdaCounter++ }))
+	return lisp.Call("list", res, lambdaCounter)
+}
+
+func testLambdaSlice() lisp.Object {
+	rev := func(xs []int) []int {
+		res := make([]int, len(xs))
+		for i, x := range xs {
+			res[len(xs)-i-1] = x
+		}
+		return res
+	}
+	return lisp.Call("funcall", lisp.Lambda(rev), lisp.Call("vector", 1, 2, 3))
+}
+
+func testLambdaVariadic() lisp.Object {
+	sum := func(xs ...int) int {
+		n := 0
+		for _, x := range xs {
+			n += x
+		}
+		return n
+	}
+	return lisp.Call("funcall", lisp.Lambda(sum), 1, 2, 3)
+}
+
+func testLambdaCoerce() lisp.Object {
+	return lisp.Call("funcall", lisp.Lambda(lambdaConcat), "foo", 1)
+}
+
+func testLambdaImplicit(list lisp.List) lisp.Object {
+	return lisp.Call("mapcar", func(x int) int { return x + 1 }, list)
+}
+
+func testLambdaImplicitFFI() lisp.Object {
+	table := lisp.MakeHashTable()
+	lisp.Puthash(1, 10, table)
+	sum := 0
+	lisp.Maphash(func(key, value lisp.Object) { sum += key.Int() + value.Int() }, table)
+	return lisp.Call("identity", sum)
+}
+
+func testLambdaImplicitCoerce() lisp.Object {
+	return lisp.Call("funcall", lambdaConcat, "foo", 1)
+}
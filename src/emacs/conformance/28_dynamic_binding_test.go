@@ -0,0 +1,74 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Dynamic binding and buffer-local variables.
+
+//goism:local
+var dynLocal = 1
+
+var dynSeen lisp.Object
+
+func dynValue() lisp.Object {
+	return lisp.Call("symbol-value", lisp.Intern("conformance--dyn"))
+}
+
+func dynBound() bool {
+	return lisp.Call("boundp", lisp.Intern("conformance--dyn")).Bool()
+}
+
+func dynCapture() { dynSeen = dynValue() }
+
+func testLetVisible() lisp.Object {
+	var inner lisp.Object
+	lisp.Let("conformance--dyn", 10, func() {
+		inner = dynValue()
+	})
+	return lisp.Call("list", inner, dynBound())
+}
+
+func testLetNested() lisp.Object {
+	var inner, outer lisp.Object
+	lisp.Let("conformance--dyn", 1, func() {
+		lisp.Let("conformance--dyn", 2, func() {
+			inner = dynValue()
+		})
+		outer = dynValue()
+	})
+	return lisp.Call("list", inner, outer)
+}
+
+func testLetFuncValue() lisp.Object {
+	lisp.Let("conformance--dyn", "x", dynCapture)
+	return dynSeen
+}
+
+func testLetPanic() {
+	lisp.Let("conformance--dyn", 1, func() {
+		panic("unwound")
+	})
+}
+
+// testLetUnbound is called after testLetPanic;
+// it reports whether panic leaked the binding.
+func testLetUnbound() bool { return dynBound() }
+
+func testBufferLocal() lisp.Object {
+	a := lisp.GenerateNewBuffer("dyn")
+	b := lisp.GenerateNewBuffer("dyn")
+	lisp.SetBuffer(a)
+	dynLocal = 2
+	lisp.SetBuffer(b)
+	dynLocal = 3
+	lisp.SetBuffer(a)
+	inA := dynLocal
+	lisp.SetBuffer(b)
+	inB := dynLocal
+	lisp.SetBuffer(lisp.GenerateNewBuffer("dyn"))
+	inNew := dynLocal
+	lisp.KillBuffer(a)
+	lisp.KillBuffer(b)
+	return lisp.Call("list", inA, inB, inNew)
+}
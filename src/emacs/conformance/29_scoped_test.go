@@ -0,0 +1,156 @@
+package conformance
+
+import (
+	"emacs/lisp"
+)
+
+// Scoped editor state helpers.
+
+// scopePanicBuffer is set by "Panic" tests and
+// checked by matching "Unwound" tests.
+var scopePanicBuffer lisp.Buffer
+
+func scopeBuffer(text string) lisp.Buffer {
+	buf := lisp.GenerateNewBuffer("scope")
+	lisp.SetBuffer(buf)
+	lisp.Insert(text)
+	return buf
+}
+
+func scopeInsertAtStart() {
+	lisp.GotoChar(lisp.PointMin())
+	lisp.Insert("x")
+}
+
+func testSaveExcursion() lisp.Object {
+	scopeBuffer("abc")
+	inner := 0
+	lisp.SaveExcursion(func() {
+		scopeInsertAtStart()
+		inner = lisp.Point()
+	})
+	return lisp.Call("list", inner, lisp.Point(), lisp.BufferString())
+}
+
+func testSaveExcursionFuncValue() lisp.Object {
+	scopeBuffer("abc")
+	lisp.SaveExcursion(scopeInsertAtStart)
+	return lisp.Call("list", lisp.Point(), lisp.BufferString())
+}
+
+func testSaveExcursionBuffer() bool {
+	a := scopeBuffer("a")
+	b := scopeBuffer("b")
+	lisp.SetBuffer(a)
+	lisp.SaveExcursion(func() {
+		lisp.SetBuffer(b)
+	})
+	return lisp.CurrentBuffer() == a
+}
+
+func testSaveRestriction() lisp.Object {
+	scopeBuffer("hello world")
+	inner := ""
+	lisp.SaveRestriction(func() {
+		lisp.NarrowToRegion(1, 6)
+		inner = lisp.BufferString()
+	})
+	return lisp.Call("list", inner, lisp.BufferString())
+}
+
+func testWithCurrentBuffer() lisp.Object {
+	a := scopeBuffer("a")
+	b := scopeBuffer("b")
+	lisp.SetBuffer(a)
+	text := ""
+	lisp.WithCurrentBuffer(b, func() {
+		lisp.Insert("c")
+		text = lisp.BufferString()
+	})
+	return lisp.Call("list", text, lisp.CurrentBuffer() == a)
+}
+
+func testSaveMatchData() lisp.Object {
+	lisp.StringMatch("b", "abc")
+	lisp.SaveMatchData(func() {
+		lisp.StringMatch("c", "abc")
+	})
+	return lisp.MatchBeginning(0)
+}
+
+func testWithTempBuffer() lisp.Object {
+	buf := scopeBuffer("")
+	var tmp lisp.Buffer
+	text := ""
+	lisp.WithTempBuffer(func() {
+		tmp = lisp.CurrentBuffer()
+		lisp.Insert("tmp")
+		text = lisp.BufferString()
+	})
+	live := lisp.Call("buffer-live-p", tmp)
+	return lisp.Call("list", text, live, lisp.CurrentBuffer() == buf)
+}
+
+func testSaveExcursionPanic() {
+	scopePanicBuffer = scopeBuffer("abc")
+	other := lisp.GenerateNewBuffer("scope")
+	lisp.SaveExcursion(func() {
+		lisp.GotoChar(lisp.PointMin())
+		lisp.SetBuffer(other)
+		panic("unwound")
+	})
+}
+
+func testSaveExcursionUnwound() bool {
+	return lisp.CurrentBuffer() == scopePanicBuffer && lisp.Point() == 4
+}
+
+func testSaveRestrictionPanic() {
+	scopePanicBuffer = scopeBuffer("hello world")
+	lisp.SaveRestriction(func() {
+		lisp.NarrowToRegion(1, 6)
+		panic("unwound")
+	})
+}
+
+func testSaveRestrictionUnwound() string {
+	lisp.SetBuffer(scopePanicBuffer)
+	return lisp.BufferString()
+}
+
+func testWithCurrentBufferPanic() {
+	scopePanicBuffer = scopeBuffer("a")
+	b := scopeBuffer("b")
+	lisp.SetBuffer(scopePanicBuffer)
+	lisp.WithCurrentBuffer(b, func() {
+		panic("unwound")
+	})
+}
+
+func testWithCurrentBufferUnwound() bool {
+	return lisp.CurrentBuffer() == scopePanicBuffer
+}
+
+func testSaveMatchDataPanic() {
+	lisp.StringMatch("b", "abc")
+	lisp.SaveMatchData(func() {
+		lisp.StringMatch("c", "abc")
+		panic("unwound")
+	})
+}
+
+func testSaveMatchDataUnwound() lisp.Object {
+	return lisp.MatchBeginning(0)
+}
+
+func testWithTempBufferPanic() {
+	scopePanicBuffer = nil
+	lisp.WithTempBuffer(func() {
+		scopePanicBuffer = lisp.CurrentBuffer()
+		panic("unwound")
+	})
+}
+
+func testWithTempBufferUnwound() bool {
+	return scopePanicBuffer != nil && !lisp.Call("buffer-live-p", scopePanicBuffer).Bool()
+}
@@ -13,3 +13,12 @@ var (
 	var5       = c1 + c2 + c2
 	var6       = c1 + var5
 )
+
+// Variables without initializers get zero values,
+// even if they are referenced by functions.
+var (
+	var7 int
+	var8 string
+)
+
+func readVar7() int { return var7 }
@@ -0,0 +1,43 @@
+package conformance
+
+// Pointers to local variables of non-struct types.
+
+func ptrIncr(p *int) { *p = *p + 1 }
+
+func testPtrLocal() int {
+	x := 1
+	p := &x
+	*p = 10
+	ptrIncr(&x)
+	x++
+	return x + *p
+}
+
+func testPtrParam(x int) int {
+	ptrIncr(&x)
+	return x
+}
+
+func testPtrShared() string {
+	var s string
+	p, q := &s, &s
+	*p = "a"
+	*q += "b"
+	return s
+}
+
+func testPtrToPtr() int {
+	x, y := 1, 2
+	p := &x
+	pp := &p
+	*pp = &y
+	return *p
+}
+
+func testPtrTupleAssign() int {
+	xs := []int{0}
+	old := xs
+	p := &xs
+	*p, xs[0] = []int{7}, 9
+	return xs[0]*10 + old[0]
+}
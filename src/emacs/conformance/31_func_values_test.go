@@ -0,0 +1,76 @@
+package conformance
+
+// Function values and closures.
+
+func funcValueApply(f func(int) int, x int) int { return f(x) }
+
+func funcValueDouble(x int) int { return x * 2 }
+
+func funcValueNamed(x int) int {
+	return funcValueApply(funcValueDouble, x)
+}
+
+func funcValueClosure(x, k int) int {
+	return funcValueApply(func(x int) int { return x + k }, x)
+}
+
+func funcValueNested(x int) int {
+	add := func(a int) func(int) int {
+		return func(b int) int { return a + b + x }
+	}
+	return add(10)(100)
+}
+
+func funcValueMultiResult(x int) int {
+	f := func(x int) (int, int) { return x / 10, x % 10 }
+	a, b := f(x)
+	return a + b
+}
+
+func funcValueNil() bool {
+	var f func()
+	return f == nil
+}
+
+// Captured variables that are assigned are shared
+// between function literal and its parent.
+
+func funcValueCounter() int {
+	n := 0
+	inc := func() { n++ }
+	inc()
+	inc()
+	return n
+}
+
+func funcValueOuterAssign() int {
+	k := 1
+	f := func() int { return k }
+	k = 5
+	return f()
+}
+
+func funcValueParamAssign(x int) int {
+	set := func(v int) { x = v }
+	set(x * 2)
+	return x
+}
+
+// funcValueLoopVar checks that every iteration
+// has its own copy of loop variable.
+func funcValueLoopVar() int {
+	var fns []func() int
+	for i := 0; i < 3; i++ {
+		fns = append(fns, func() int { return i })
+	}
+	return fns[0]()*100 + fns[1]()*10 + fns[2]()
+}
+
+func funcValueStruct() int {
+	t := triple{a: 1, b: 2, c: 3}
+	get := func() int { return t.a*100 + t.b*10 + t.c }
+	setB := func() { t.b = 5 }
+	t = triple{a: 4, b: 0, c: 6}
+	setB()
+	return get()
+}
@@ -1,5 +1,7 @@
 package conformance
 
+import "emacs/lisp"
+
 func stringifyInt3(x int) string {
 	switch x {
 	case 0:
@@ -26,3 +28,59 @@ func stringifyInt4(x int) string {
 		return "x"
 	}
 }
+
+func switchIntTable(x int) int {
+	// Dense switches are dispatched by jump table.
+	res := 0
+	switch x {
+	case 1, 2:
+		res = 10
+	case 3:
+		res = 30
+	case 4:
+	case 5:
+		res = 50
+	default:
+		res = -1
+	}
+	return res + 1
+}
+
+func switchStringTable(s string) string {
+	switch s {
+	case "a", "b":
+		return "ab"
+	case "c":
+		return "c"
+	case "long string":
+		return "long"
+	}
+	return s + "?"
+}
+
+func switchSymbolTable(sym lisp.Symbol) int {
+	switch sym {
+	case lisp.Intern("x"):
+		return 1
+	case lisp.Intern("y"):
+		return 2
+	case lisp.Intern("z"):
+		return 3
+	}
+	return 0
+}
+
+// Repeated key: the first clause wins.
+func switchSymbolTableDup(sym lisp.Symbol) int {
+	switch sym {
+	case lisp.Intern("a"):
+		return 1
+	case lisp.Intern("b"):
+		return 2
+	case lisp.Intern("a"):
+		return 3
+	case lisp.Intern("c"):
+		return 4
+	}
+	return 0
+}
@@ -7,3 +7,25 @@ var (
 
 func sliceLen(x []int) int { return len(x) }
 func sliceCap(x []int) int { return cap(x) }
+
+func sliceSwap() int {
+	xs := []int{1, 2, 3}
+	i, j := 0, 2
+	xs[i], xs[j] = xs[j], xs[i]
+	return xs[0]*100 + xs[1]*10 + xs[2]
+}
+
+func sliceAssignIndex() int {
+	xs := []int{0, 0, 0}
+	i := 0
+	i, xs[i] = 1, 2
+	return i*100 + xs[0]*10 + xs[1]
+}
+
+func sliceAssignSlice() int {
+	a := []int{1}
+	b := []int{3}
+	x := a
+	x, x[0] = b, 9
+	return a[0]*10 + b[0]
+}
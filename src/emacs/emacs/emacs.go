@@ -0,0 +1,130 @@
+// Package emacs declares Emacs extension points:
+// major and minor modes, keymaps and hooks.
+//
+// Package level variables of MajorMode, MinorMode, Keymap
+// and Hook types are recognized by the translator and are
+// defined when package is loaded, like "define-derived-mode",
+// "define-minor-mode", "define-key" and "add-hook" do.
+// Such variables must be initialized by composite literals
+// with constant strings; function fields must refer
+// to top level functions.
+package emacs
+
+import (
+	"emacs/lisp"
+)
+
+// Keymap is a sparse keymap that is stored in a variable.
+// Variable is not changed if it is already bound,
+// but key bindings are always installed.
+type Keymap struct {
+	Name     string // Keymap variable
+	Doc      string
+	Bindings []KeyBinding
+}
+
+// KeyBinding binds key sequence to command.
+type KeyBinding struct {
+	Key     string // Key description, as accepted by "kbd"
+	Command any    // Interactive function
+}
+
+// Hook adds functions to hook variable.
+type Hook struct {
+	Name  string // Hook variable
+	Funcs []any
+}
+
+// MinorMode is a minor mode that is buffer-local unless
+// Global is set.
+//
+// Mode variable and command are named after the mode;
+// mode hook is Name+"-hook".
+// Keymap defaults to Name+"-map" if it has no name.
+type MinorMode struct {
+	Name    string
+	Doc     string
+	Lighter string
+	Global  bool
+	Keymap  Keymap
+
+	OnEnable  func()
+	OnDisable func()
+}
+
+// MajorMode is a major mode that is derived from Parent,
+// like mode that is defined by "define-derived-mode".
+//
+// Parent is a name of mode command, like "text-mode";
+// mode without parent starts from a fresh buffer state,
+// like "fundamental-mode" does.
+// Mode command is named after the mode; mode hook is
+// Name+"-hook". Keymap is always defined; it defaults
+// to Name+"-map" if it has no name.
+type MajorMode struct {
+	Name     string
+	Parent   string
+	Doc      string
+	ModeName string // Mode line name; defaults to Name
+	Keymap   Keymap
+
+	OnEnable func()
+}
+
+// EnterMajorMode implements major mode command.
+// It is called by commands that are generated by the translator.
+//
+// Like with "define-derived-mode", parent mode hooks are
+// delayed until mode hook is run and mode keymap inherits
+// parent mode keymap, unless it already has a parent.
+func EnterMajorMode(mode, parent, hook lisp.Symbol, name string, keymap, onEnable lisp.Object) {
+	if lisp.Not(parent) {
+		lisp.Call("kill-all-local-variables")
+	} else {
+		lisp.Call("make-local-variable", lisp.Intern("delay-mode-hooks"))
+		lisp.Let("delay-mode-hooks", true, func() {
+			lisp.Call("funcall", parent)
+		})
+	}
+	lisp.Call("set", lisp.Intern("major-mode"), mode)
+	lisp.Call("set", lisp.Intern("mode-name"), name)
+	if !lisp.Not(parent) && lisp.Not(lisp.Call("keymap-parent", keymap)) {
+		lisp.Call("set-keymap-parent", keymap, lisp.Call("current-local-map"))
+	}
+	lisp.Call("use-local-map", keymap)
+	if !lisp.Not(onEnable) {
+		lisp.Call("funcall", onEnable)
+	}
+	lisp.Call("run-mode-hooks", hook)
+}
+
+// ToggleMinorMode implements minor mode command.
+// It is called by commands that are generated by the translator.
+//
+// Like with "define-minor-mode", mode is toggled if arg is
+// "toggle" or if command is called interactively without
+// prefix argument. Otherwise, mode is enabled if arg is nil
+// or positive number and disabled if it is not.
+func ToggleMinorMode(mode, hook lisp.Symbol, onEnable, onDisable, args lisp.Object, interactive bool) bool {
+	arg := lisp.Call("car", args)
+	enabled := !lisp.Not(lisp.Call("symbol-value", mode))
+	switch {
+	case lisp.Eq(arg, lisp.Intern("toggle")) || (interactive && lisp.Not(arg)):
+		enabled = !enabled
+	case lisp.Not(arg):
+		enabled = true
+	default:
+		enabled = lisp.Call("prefix-numeric-value", arg).Int() > 0
+	}
+	lisp.Call("set", mode, enabled)
+
+	fn := onDisable
+	if enabled {
+		fn = onEnable
+	}
+	if !lisp.Not(fn) {
+		lisp.Call("funcall", fn)
+	}
+	lisp.Call("run-hooks", hook)
+	return enabled
+}
@@ -0,0 +1,126 @@
+// Package errors implements functions to manipulate errors.
+//
+// Wrapped errors are supported in the same way as
+// in Go "errors" package: error wraps another error
+// if it has "Unwrap() error" method.
+package errors
+
+import (
+	"emacs/lisp"
+	"emacs/reflect"
+)
+
+// New returns an error that formats as the given text.
+// Each call to New returns a distinct error value
+// even if the text is identical.
+func New(text string) error {
+	return &errorString{s: text}
+}
+
+// errorString is a trivial implementation of error.
+type errorString struct {
+	s string
+}
+
+func (e *errorString) Error() string {
+	return e.s
+}
+
+// Interfaces that are checked by Unwrap, Is and As.
+type (
+	wrapper interface {
+		Unwrap() error
+	}
+	iser interface {
+		Is(error) bool
+	}
+	aser interface {
+		As(interface{}) bool
+	}
+)
+
+// Unwrap returns the result of calling the Unwrap method on err,
+// if err's type contains an Unwrap method returning error.
+// Otherwise, Unwrap returns nil.
+func Unwrap(err error) error {
+	u, ok := err.(wrapper)
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+// Is reports whether any error in err's chain matches target.
+//
+// The chain consists of err itself followed by the sequence of errors
+// obtained by repeatedly calling Unwrap.
+//
+// An error is considered to match a target if it is equal to that target
+// or if it implements a method Is(error) bool such that Is(target) returns true.
+func Is(err, target error) bool {
+	if target == nil {
+		return err == target
+	}
+	for err != nil {
+		if err == target {
+			return true
+		}
+		if x, ok := err.(iser); ok && x.Is(target) {
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// As finds the first error in err's chain that matches target,
+// and if so, sets target to that error value and returns true.
+//
+// An error matches target if the error's concrete value is assignable
+// to the value pointed to by target, or if the error has a method
+// As(interface{}) bool such that As(target) returns true.
+//
+// As panics if target is not a non-nil pointer to either
+// a type that implements error, or to any interface type.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+	targetType := val.Type().Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType()) {
+		panic("errors: *target must be interface or implement error")
+	}
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+		if x, ok := err.(aser); ok && x.As(target) {
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// errorType returns reflect.Type of error interface.
+func errorType() reflect.Type {
+	var ptr *error
+	return reflect.TypeOf(ptr).Elem()
+}
+
+// Signal signals err as Emacs Lisp "error".
+// Error message is a result of err.Error() call.
+// Does nothing if err is nil.
+//
+// Useful to report errors to Emacs Lisp callers
+// that do not know how to handle Go error values.
+func Signal(err error) {
+	if err != nil {
+		lisp.Error("%s", err.Error())
+	}
+}
@@ -0,0 +1,36 @@
+package fmt
+
+import (
+	"emacs/errors"
+)
+
+// Errorf formats according to a format specifier and returns the string
+// as a value that satisfies error.
+//
+// If the format specifier includes a %w verb with an error operand,
+// the returned error will implement an Unwrap method returning the operand.
+// It is invalid to include more than one %w verb or to supply it with
+// an operand that does not implement the error interface.
+func Errorf(format string, a ...interface{}) error {
+	p := newPrinter()
+	p.wrapErrs = true
+	p.doPrintf(format, a)
+	if p.wrappedErr == nil {
+		return errors.New(p.buf)
+	}
+	return &wrapError{msg: p.buf, err: p.wrappedErr}
+}
+
+// wrapError is returned by Errorf that has %w verb.
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,295 @@
+package fmt
+
+import (
+	"emacs/lisp"
+	"emacs/reflect"
+	"emacs/strconv"
+)
+
+// padString appends s to buffer, padded on the left
+// (or right, for "-" flag) to the field width.
+// Width is measured in characters.
+func (p *pp) padString(s string) {
+	if !p.widPresent || p.wid <= lisp.Length(s) {
+		p.buf += s
+		return
+	}
+	padChar := ' '
+	if p.zero {
+		padChar = '0'
+	}
+	padding := repeatChar(padChar, p.wid-lisp.Length(s))
+	if p.minus {
+		p.buf += s + padding
+	} else {
+		p.buf += padding + s
+	}
+}
+
+// padNumber is like padString, but zero padding is
+// inserted between sign and digits.
+func (p *pp) padNumber(sign, digits string) {
+	width := lisp.Length(sign) + lisp.Length(digits)
+	if p.widPresent && p.zero && p.wid > width {
+		digits = repeatChar('0', p.wid-width) + digits
+	}
+	oldZero := p.zero
+	p.zero = false
+	p.padString(sign + digits)
+	p.zero = oldZero
+}
+
+func (p *pp) fmtBool(v bool, verb rune, value reflect.Value) {
+	if verb != 't' && verb != 'v' {
+		p.badVerb(verb, value.Interface())
+		return
+	}
+	if v {
+		p.padString("true")
+	} else {
+		p.padString("false")
+	}
+}
+
+func (p *pp) fmtInteger(n int, signed bool, verb rune, value reflect.Value) {
+	switch verb {
+	case 'v', 'd':
+		p.fmtIntegerBase(n, 10, signed, verb)
+	case 'b':
+		p.fmtIntegerBase(n, 2, signed, verb)
+	case 'o':
+		p.fmtIntegerBase(n, 8, signed, verb)
+	case 'x', 'X':
+		p.fmtIntegerBase(n, 16, signed, verb)
+	case 'c':
+		p.padString(charString(rune(n)))
+	case 'q':
+		p.padString(strconv.QuoteRune(rune(n)))
+	default:
+		p.badVerb(verb, value.Interface())
+	}
+}
+
+// fmtIntegerBase formats integer with respect to
+// sign, precision and "#" flags.
+func (p *pp) fmtIntegerBase(n int, base int, signed bool, verb rune) {
+	negative := signed && n < 0
+	if negative {
+		n = -n
+	}
+
+	digits := ""
+	if n != 0 || !p.precPresent || p.prec != 0 {
+		digits = formatUint(n, base, verb == 'X')
+	}
+
+	// Precision is a minimal number of digits.
+	// Zero flag is converted to precision.
+	prec := 0
+	if p.precPresent {
+		prec = p.prec
+	} else if p.zero && p.widPresent {
+		prec = p.wid
+		if negative || p.plus || p.space {
+			prec-- // Leave room for sign
+		}
+	}
+	if prec > lisp.Length(digits) {
+		digits = repeatChar('0', prec-lisp.Length(digits)) + digits
+	}
+
+	if p.sharp {
+		switch base {
+		case 2:
+			digits = "0b" + digits
+		case 8:
+			if lisp.ArefString(digits, 0) != '0' {
+				digits = "0" + digits
+			}
+		case 16:
+			if verb == 'X' {
+				digits = "0X" + digits
+			} else {
+				digits = "0x" + digits
+			}
+		}
+	}
+
+	sign := ""
+	if negative {
+		sign = "-"
+	} else if p.plus {
+		sign = "+"
+	} else if p.space {
+		sign = " "
+	}
+
+	// Zero padding is already done.
+	oldZero := p.zero
+	p.zero = false
+	p.padString(sign + digits)
+	p.zero = oldZero
+}
+
+// formatUint returns non-negative n digits in given base.
+func formatUint(n int, base int, upper bool) string {
+	switch base {
+	case 8:
+		return lisp.Call("format", "%o", n).String()
+	case 10:
+		return lisp.Call("number-to-string", n).String()
+	case 16:
+		if upper {
+			return lisp.Call("format", "%X", n).String()
+		}
+		return lisp.Call("format", "%x", n).String()
+	}
+	// Emacs "format" has no binary conversion.
+	if n == 0 {
+		return "0"
+	}
+	digits := ""
+	for n > 0 {
+		digits = charString('0'+rune(n%base)) + digits
+		n = n / base
+	}
+	return digits
+}
+
+func (p *pp) fmtFloat(x float64, verb rune, value reflect.Value) {
+	switch verb {
+	case 'v', 'g', 'G', 'e', 'E', 'f', 'F':
+	default:
+		p.badVerb(verb, value.Interface())
+		return
+	}
+
+	sign := ""
+	if x < 0 || (x == 0 && 1/x < 0) {
+		sign = "-"
+		x = -x
+	} else if p.plus {
+		sign = "+"
+	} else if p.space {
+		sign = " "
+	}
+
+	// Special values are never padded with zeros.
+	if x != x {
+		p.padSpecialFloat("NaN")
+		return
+	}
+	if x > maxFloat64 {
+		if sign == "" {
+			sign = "+" // Go always prints sign of infinity
+		}
+		p.padSpecialFloat(sign + "Inf")
+		return
+	}
+
+	digits := ""
+	switch verb {
+	case 'v', 'g', 'G':
+		if p.precPresent {
+			digits = formatFloat(x, "g", p.prec)
+		} else {
+			digits = shortestFloat(x)
+		}
+		if verb == 'G' {
+			digits = lisp.Call("upcase", digits).String()
+		}
+	case 'e', 'E':
+		digits = formatFloat(x, charString(verb), p.floatPrec())
+	case 'f', 'F':
+		digits = formatFloat(x, "f", p.floatPrec())
+	}
+	if p.sharp && verb != 'v' && lisp.Not(lisp.Call("string-match-p", "[.]", digits)) {
+		digits += "."
+	}
+	p.padNumber(sign, digits)
+}
+
+func (p *pp) padSpecialFloat(s string) {
+	oldZero := p.zero
+	p.zero = false
+	p.padString(s)
+	p.zero = oldZero
+}
+
+// floatPrec returns precision for %e and %f verbs.
+func (p *pp) floatPrec() int {
+	if p.precPresent {
+		return p.prec
+	}
+	return 6
+}
+
+// Largest finite float64 value.
+const maxFloat64 = 1.797693134862315708145274237317043567981e+308
+
+// formatFloat formats non-negative x using C-style conversion.
+func formatFloat(x float64, conv string, prec int) string {
+	spec := "%." + lisp.Call("number-to-string", prec).String() + conv
+	return lisp.Call("format", spec, x).String()
+}
+
+// shortestFloat returns the shortest representation of
+// non-negative x, like Go "%v" does.
+func shortestFloat(x float64) string {
+	s := lisp.Call("number-to-string", x).String()
+	// Emacs prints integral floats with ".0" suffix.
+	n := lisp.Length(s)
+	if n > 2 && s[n-2:] == ".0" {
+		return s[:n-2]
+	}
+	return s
+}
+
+func (p *pp) fmtString(s string, verb rune) {
+	switch verb {
+	case 'v', 's':
+		p.padString(p.truncate(s))
+	case 'q':
+		p.padString(strconv.Quote(p.truncate(s)))
+	case 'x':
+		p.padString(hexString(p.truncate(s), "%02x"))
+	case 'X':
+		p.padString(hexString(p.truncate(s), "%02X"))
+	default:
+		p.badVerb(verb, s)
+	}
+}
+
+// fmtLispObject formats value that has lisp.Object type.
+// "%s" uses "princ" representation; other verbs use "prin1".
+func (p *pp) fmtLispObject(x lisp.Object, verb rune) {
+	if verb == 's' {
+		p.padString(lisp.Call("format", "%s", x).String())
+	} else {
+		p.padString(lisp.Prin1ToString(x))
+	}
+}
+
+// truncate truncates s to the specified precision, if present.
+// Precision is measured in characters.
+func (p *pp) truncate(s string) string {
+	if p.precPresent && p.prec < lisp.Length(s) {
+		return s[:p.prec]
+	}
+	return s
+}
+
+// hexString returns UTF-8 bytes of s encoded as hex digits.
+func hexString(s string, byteFormat string) string {
+	bytes := lisp.Call("encode-coding-string", s, lisp.Intern("utf-8"))
+	res := ""
+	for i := 0; i < lisp.Length(bytes); i++ {
+		res += lisp.Call("format", byteFormat, lisp.Call("aref", bytes, i)).String()
+	}
+	return res
+}
+
+// repeatChar returns string of n characters c.
+func repeatChar(c rune, n int) string {
+	return lisp.Call("make-string", n, c).String()
+}
@@ -0,0 +1,517 @@
+// Package fmt implements a subset of Go "fmt" package.
+//
+// Supported verbs: %v %+v %d %s %q %x %X %o %b %c %e %f %g %t %T %w;
+// width, precision and flags are supported as well.
+// Values that implement error or Stringer are printed
+// by calling their Error or String method.
+package fmt
+
+import (
+	"emacs/lisp"
+	"emacs/reflect"
+)
+
+// Stringer is implemented by any value that has a String method,
+// which defines the "native" format for that value.
+type Stringer interface {
+	String() string
+}
+
+// Sprintf formats according to a format specifier and
+// returns the resulting string.
+func Sprintf(format string, a ...interface{}) string {
+	p := newPrinter()
+	p.doPrintf(format, a)
+	return p.buf
+}
+
+// Sprint formats using the default formats for its operands and
+// returns the resulting string.
+// Spaces are added between operands when neither is a string.
+func Sprint(a ...interface{}) string {
+	p := newPrinter()
+	p.doPrint(a)
+	return p.buf
+}
+
+// Sprintln formats using the default formats for its operands and
+// returns the resulting string.
+// Spaces are always added between operands and a newline is appended.
+func Sprintln(a ...interface{}) string {
+	p := newPrinter()
+	p.doPrintln(a)
+	return p.buf
+}
+
+// Writer is the interface that wraps the basic Write method.
+// It has the same method set as Go "io.Writer".
+type Writer interface {
+	Write(p []byte) (n int, err error)
+}
+
+// StreamWriter is Writer that prints to Emacs Lisp output stream,
+// usually a buffer or marker
+// (see "standard-output" documentation for other variants).
+type StreamWriter struct {
+	Stream lisp.Object
+}
+
+// Write prints p to w.Stream with "princ".
+func (w StreamWriter) Write(p []byte) (n int, err error) {
+	lisp.Call("princ", string(p), w.Stream)
+	return len(p), nil
+}
+
+// Fprintf formats according to a format specifier and writes to w.
+// It returns the number of bytes written and any write error encountered.
+func Fprintf(w Writer, format string, a ...interface{}) (n int, err error) {
+	return w.Write([]byte(Sprintf(format, a...)))
+}
+
+// pp is used to store a printer's state.
+type pp struct {
+	buf string
+
+	// Current verb flags; reset by clearFlags.
+	wid         int
+	prec        int
+	widPresent  bool
+	precPresent bool
+	minus       bool
+	plus        bool
+	sharp       bool
+	space       bool
+	zero        bool
+	plusV       bool // "%+v"
+
+	// wrapErrs is set when format string may contain a %w verb.
+	wrapErrs bool
+	// wrappedErr records the target of the %w verb.
+	wrappedErr error
+	// erroring is set when printing an error string
+	// to guard against calling handleMethods.
+	erroring bool
+}
+
+func newPrinter() *pp {
+	return &pp{}
+}
+
+func (p *pp) clearFlags() {
+	p.wid = 0
+	p.prec = 0
+	p.widPresent = false
+	p.precPresent = false
+	p.minus = false
+	p.plus = false
+	p.sharp = false
+	p.space = false
+	p.zero = false
+	p.plusV = false
+}
+
+func (p *pp) doPrint(a []interface{}) {
+	prevString := false
+	for argNum := 0; argNum < len(a); argNum++ {
+		arg := a[argNum]
+		isString := arg != nil && reflect.TypeOf(arg).Kind() == reflect.String
+		// Add a space between two non-string arguments.
+		if argNum > 0 && !isString && !prevString {
+			p.buf += " "
+		}
+		p.printArg(arg, 'v')
+		prevString = isString
+	}
+}
+
+func (p *pp) doPrintln(a []interface{}) {
+	for argNum := 0; argNum < len(a); argNum++ {
+		if argNum > 0 {
+			p.buf += " "
+		}
+		p.printArg(a[argNum], 'v')
+	}
+	p.buf += "\n"
+}
+
+// doPrintf parses format and prints arguments accordingly.
+// Format is traversed by characters, not bytes.
+func (p *pp) doPrintf(format string, a []interface{}) {
+	end := lisp.Length(format)
+	argNum := 0
+	i := 0
+	for i < end {
+		lasti := i
+		for i < end && lisp.ArefString(format, i) != '%' {
+			i++
+		}
+		if i > lasti {
+			p.buf += format[lasti:i]
+		}
+		if i >= end {
+			break // Done processing format string
+		}
+		i++ // Skip "%"
+
+		p.clearFlags()
+		for i < end && p.setFlag(lisp.ArefString(format, i)) {
+			i++
+		}
+
+		// Width.
+		if i < end && lisp.ArefString(format, i) == '*' {
+			i++
+			wid, ok, nextArg := intFromArg(a, argNum)
+			argNum = nextArg
+			if !ok {
+				p.buf += "%!(BADWIDTH)"
+			} else if wid < 0 {
+				// Negative width means "-" flag.
+				p.wid = -wid
+				p.widPresent = true
+				p.minus = true
+				p.zero = false
+			} else {
+				p.wid = wid
+				p.widPresent = true
+			}
+		} else {
+			wid, ok, next := parsenum(format, i, end)
+			p.wid = wid
+			p.widPresent = ok
+			i = next
+		}
+
+		// Precision.
+		if i < end && lisp.ArefString(format, i) == '.' {
+			i++
+			if i < end && lisp.ArefString(format, i) == '*' {
+				i++
+				prec, ok, nextArg := intFromArg(a, argNum)
+				argNum = nextArg
+				if !ok || prec < 0 {
+					p.buf += "%!(BADPREC)"
+				} else {
+					p.prec = prec
+					p.precPresent = true
+				}
+			} else {
+				prec, _, next := parsenum(format, i, end)
+				// "%.f" is the same as "%.0f".
+				p.prec = prec
+				p.precPresent = true
+				i = next
+			}
+		}
+
+		if i >= end {
+			p.buf += "%!(NOVERB)"
+			break
+		}
+		verb := lisp.ArefString(format, i)
+		i++
+
+		if verb == '%' {
+			p.buf += "%" // Percent does not absorb operands
+			continue
+		}
+		if argNum >= len(a) {
+			p.buf += "%!" + charString(verb) + "(MISSING)"
+			continue
+		}
+		if verb == 'v' && p.plus {
+			p.plus = false
+			p.plusV = true
+		}
+		p.printArg(a[argNum], verb)
+		argNum++
+	}
+
+	// Check for extra arguments.
+	if argNum < len(a) {
+		p.clearFlags()
+		p.buf += "%!(EXTRA "
+		for j := argNum; j < len(a); j++ {
+			if j > argNum {
+				p.buf += ", "
+			}
+			arg := a[j]
+			if arg == nil {
+				p.buf += "<nil>"
+			} else {
+				p.buf += reflect.TypeOf(arg).String() + "="
+				p.printArg(arg, 'v')
+			}
+		}
+		p.buf += ")"
+	}
+}
+
+// setFlag sets flag denoted by c.
+// Returns false if c is not a flag character.
+func (p *pp) setFlag(c rune) bool {
+	switch c {
+	case '#':
+		p.sharp = true
+	case '0':
+		p.zero = !p.minus // Only allow zero padding to the left
+	case '+':
+		p.plus = true
+	case '-':
+		p.minus = true
+		p.zero = false // Do not pad with zeros to the right
+	case ' ':
+		p.space = true
+	default:
+		return false
+	}
+	return true
+}
+
+// intFromArg gets the argNum'th element of a.
+// On return, isInt reports whether the argument has integer type.
+func intFromArg(a []interface{}, argNum int) (num int, isInt bool, newArgNum int) {
+	if argNum >= len(a) {
+		return 0, false, argNum
+	}
+	n, ok := a[argNum].(int)
+	return n, ok, argNum + 1
+}
+
+// parsenum converts decimal digits of s[start:end] to integer.
+// Returns index of the first non-digit character.
+func parsenum(s string, start, end int) (num int, isnum bool, newi int) {
+	n := 0
+	i := start
+	for i < end && isDigit(lisp.ArefString(s, i)) {
+		n = n*10 + int(lisp.ArefString(s, i)-'0')
+		i++
+	}
+	return n, i > start, i
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (p *pp) printArg(arg interface{}, verb rune) {
+	if arg == nil {
+		if verb == 'T' || verb == 'v' {
+			p.padString("<nil>")
+		} else {
+			p.badVerb(verb, arg)
+		}
+		return
+	}
+	if verb == 'T' {
+		p.fmtString(reflect.TypeOf(arg).String(), 's')
+		return
+	}
+	if verb == 'w' {
+		// It is invalid to use %w other than with Errorf,
+		// more than once, or with a non-error arg.
+		err, ok := arg.(error)
+		if !ok || !p.wrapErrs || p.wrappedErr != nil {
+			p.wrappedErr = nil
+			p.wrapErrs = false
+			p.badVerb(verb, arg)
+			return
+		}
+		p.wrappedErr = err
+		verb = 'v'
+	}
+	if p.handleMethods(arg, verb) {
+		return
+	}
+	p.printValue(reflect.ValueOf(arg), verb, 0)
+}
+
+// handleMethods formats arg with its Error or String method.
+// Returns false if arg has no such methods or verb is not
+// valid for strings.
+func (p *pp) handleMethods(arg interface{}, verb rune) bool {
+	if p.erroring {
+		return false
+	}
+	switch verb {
+	case 'v', 's', 'x', 'X', 'q':
+		if err, ok := arg.(error); ok {
+			p.fmtString(err.Error(), verb)
+			return true
+		}
+		if s, ok := arg.(Stringer); ok {
+			p.fmtString(s.String(), verb)
+			return true
+		}
+	}
+	return false
+}
+
+// printValue is like printArg, but starts with a reflect value.
+// Nested values are printed using their methods (if any).
+func (p *pp) printValue(value reflect.Value, verb rune, depth int) {
+	if depth > 0 && value.IsValid() && p.handleMethods(value.Interface(), verb) {
+		return
+	}
+	p.printRawValue(value, verb, depth)
+}
+
+// printRawValue prints value without calling its methods.
+func (p *pp) printRawValue(value reflect.Value, verb rune, depth int) {
+	switch value.Kind() {
+	case reflect.Invalid:
+		if depth == 0 {
+			p.buf += "<invalid reflect.Value>"
+		} else if verb == 'v' {
+			p.buf += "<nil>"
+		} else {
+			p.badVerb(verb, nil)
+		}
+	case reflect.Bool:
+		p.fmtBool(value.Bool(), verb, value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.fmtInteger(int(value.Int()), true, verb, value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.fmtInteger(int(value.Uint()), false, verb, value)
+	case reflect.Float32, reflect.Float64:
+		p.fmtFloat(value.Float(), verb, value)
+	case reflect.String:
+		p.fmtString(value.String(), verb)
+	case reflect.Map:
+		p.printMap(value, verb, depth)
+	case reflect.Struct:
+		p.printStruct(value, verb, depth)
+	case reflect.Interface:
+		if value.Type().PkgPath() == "emacs/lisp" {
+			p.fmtLispObject(value.Interface().(lisp.Object), verb)
+			return
+		}
+		elem := reflect.ValueOf(value.Interface())
+		if !elem.IsValid() {
+			p.padString("<nil>")
+		} else {
+			p.printValue(elem, verb, depth+1)
+		}
+	case reflect.Array, reflect.Slice:
+		p.printSlice(value, verb, depth)
+	case reflect.Ptr:
+		if value.IsNil() {
+			p.padString("<nil>")
+		} else {
+			p.buf += "&"
+			p.printValue(value.Elem(), verb, depth+1)
+		}
+	default:
+		p.padString("<" + value.Type().String() + " Value>")
+	}
+}
+
+func (p *pp) printStruct(value reflect.Value, verb rune, depth int) {
+	typ := value.Type()
+	p.buf += "{"
+	for i := 0; i < value.NumField(); i++ {
+		if i > 0 {
+			p.buf += " "
+		}
+		name := typ.Field(i).Name
+		if p.plusV {
+			p.buf += name + ":"
+		}
+		if isExported(name) {
+			p.printValue(value.Field(i), verb, depth+1)
+		} else {
+			// Methods of unexported fields are not accessible.
+			p.printRawValue(value.Field(i), verb, depth+1)
+		}
+	}
+	p.buf += "}"
+}
+
+func (p *pp) printSlice(value reflect.Value, verb rune, depth int) {
+	if isBytesVerb(verb) && value.Kind() == reflect.Slice &&
+		value.Type().Elem().Kind() == reflect.Uint8 {
+		p.fmtString(string(value.Interface().([]byte)), verb)
+		return
+	}
+	p.buf += "["
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			p.buf += " "
+		}
+		p.printValue(value.Index(i), verb, depth+1)
+	}
+	p.buf += "]"
+}
+
+// printMap prints map entries sorted by key.
+func (p *pp) printMap(value reflect.Value, verb rune, depth int) {
+	keys := value.MapKeys()
+	sortValues(keys)
+	p.buf += "map["
+	for i := 0; i < len(keys); i++ {
+		if i > 0 {
+			p.buf += " "
+		}
+		p.printValue(keys[i], verb, depth+1)
+		p.buf += ":"
+		p.printValue(value.MapIndex(keys[i]), verb, depth+1)
+	}
+	p.buf += "]"
+}
+
+func (p *pp) badVerb(verb rune, arg interface{}) {
+	p.erroring = true
+	p.buf += "%!" + charString(verb) + "("
+	if arg == nil {
+		p.buf += "<nil>"
+	} else {
+		p.buf += reflect.TypeOf(arg).String() + "="
+		p.printArg(arg, 'v')
+	}
+	p.buf += ")"
+	p.erroring = false
+}
+
+// sortValues sorts map keys in increasing order.
+// Insertion sort is used; maps that are printed are usually small.
+func sortValues(keys []reflect.Value) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && valueLess(keys[j], keys[j-1]); j-- {
+			tmp := keys[j]
+			keys[j] = keys[j-1]
+			keys[j-1] = tmp
+		}
+	}
+}
+
+// valueLess reports whether a < b.
+// Values of unordered kinds are never less.
+func valueLess(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return false
+}
+
+func isExported(name string) bool {
+	c := lisp.ArefString(name, 0)
+	return c >= 'A' && c <= 'Z'
+}
+
+func isBytesVerb(verb rune) bool {
+	return verb == 's' || verb == 'q' || verb == 'x' || verb == 'X'
+}
+
+// charString returns string that contains a single character c.
+func charString(c rune) string {
+	return lisp.Call("string", c).String()
+}
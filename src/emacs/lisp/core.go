@@ -16,6 +16,54 @@ func Call(fn string, args ...any) Object
 // DynCall is like Call, but permits wider range of callable arguments.
 func DynCall(callable Object, args ...any) Object
 
+// Any returns its argument as is.
+//
+// Result can be assigned to Go interface without boxing,
+// so it must be a valid Go interface value.
+// Used by runtime packages that build interface values by hand.
+func Any(x Object) any
+
+// Lambda returns Emacs Lisp function that calls Go function fn.
+// Function literals (closures) are permitted.
+//
+// Arguments are coerced to fn parameter types, like it is done
+// by Object methods; lists, vectors and alists are converted
+// to slices and maps. Results are converted back: slices are
+// returned as lists and maps as alists. Function without results
+// returns nil, multiple results are returned as a list.
+func Lambda(fn any) Object
+
+// Let calls body while special variable name is dynamically
+// bound to val. Old value is restored when body returns
+// or panics, like it is done by Emacs Lisp "let".
+//
+// Name must be a constant. When body is a function literal,
+// it is inlined and can not contain "return" statements.
+func Let(name string, val any, body func())
+
+// SaveExcursion calls body, then restores current buffer
+// and its point, like "save-excursion" does.
+//
+// All functions below restore state even if body panics.
+// Function literal bodies are inlined, like by Let.
+func SaveExcursion(body func())
+
+// SaveRestriction calls body, then restores
+// current buffer narrowing, like "save-restriction" does.
+func SaveRestriction(body func())
+
+// WithCurrentBuffer calls body while bufferOrName is current
+// buffer, like "with-current-buffer" does.
+func WithCurrentBuffer(bufferOrName any, body func())
+
+// SaveMatchData calls body, then restores match data,
+// like "save-match-data" does.
+func SaveMatchData(body func())
+
+// WithTempBuffer calls body inside new current buffer
+// that is killed afterwards, like "with-temp-buffer" does.
+func WithTempBuffer(body func())
+
 // Object is unboxed Emacs Lisp object.
 // Go-compatible value can be extracted by
 // Object methods.
@@ -37,6 +85,28 @@ type Object interface {
 	// Symbol returns underlying symbol. Panics when symbolp(val) is false.
 	Symbol() Symbol
 
+	// Buffer returns underlying buffer. Panics when bufferp(val) is false.
+	Buffer() Buffer
+
+	// Marker returns underlying marker. Panics when markerp(val) is false.
+	Marker() Marker
+
+	// Window returns underlying window. Panics when windowp(val) is false.
+	Window() Window
+
+	// Process returns underlying process. Panics when processp(val) is false.
+	Process() Process
+
+	// HashTable returns underlying hash table.
+	// Panics when hash-table-p(val) is false.
+	HashTable() HashTable
+
+	// Cons returns underlying cons cell. Panics when consp(val) is false.
+	Cons() Cons
+
+	// List returns underlying list. Panics when listp(val) is false.
+	List() List
+
 	object()
 }
 
@@ -45,5 +115,85 @@ type Symbol interface {
 	symbol()
 }
 
+// Opaque Emacs Lisp object types.
+// Values of these types are only produced by FFI functions
+// and Object methods, so they are never checked again.
+// Zero value is nil.
+
+// Buffer <- "bufferp(x)".
+type Buffer interface {
+	buffer()
+}
+
+// Marker <- "markerp(x)".
+type Marker interface {
+	marker()
+}
+
+// Window <- "windowp(x)".
+type Window interface {
+	window()
+}
+
+// Process <- "processp(x)".
+type Process interface {
+	process()
+}
+
+// HashTable <- "hash-table-p(x)".
+type HashTable interface {
+	hashTable()
+}
+
+// Cons <- "consp(x)".
+type Cons interface {
+	cons()
+}
+
 // Intern returns the canonical symbol with specified name.
 func Intern(name string) Symbol
+
+// List is Emacs Lisp list; it is not converted to Go slice.
+// Zero value is an empty list.
+//
+// Only a subset of slice operations is permitted:
+// "for range" walks list conses, len(list) is "length",
+// list[i] is "nth" and "List{x, y}" is "list" call.
+type List []Object
+
+// Sequence conversions.
+// Slices and maps are passed to Emacs Lisp as is,
+// other argument types are rejected by the translator.
+//
+// Functions that return Go values must be immediately
+// asserted to the destination type:
+//	xs := lisp.ListToSlice(list).([]int)
+// Elements are not checked during the assertion.
+
+// SliceToVector returns vector of slice elements.
+// Slice storage is returned without copying if slice
+// covers all of it.
+func SliceToVector(slice any) Object
+
+// VectorToSlice returns slice that shares storage with vec.
+func VectorToSlice(vec Object) any
+
+// SliceToList returns list of slice elements.
+func SliceToList(slice any) List
+
+// ListToSlice returns slice of list elements.
+func ListToSlice(list List) any
+
+// MapToAlist returns association list of map entries.
+func MapToAlist(m any) List
+
+// AlistToMap returns map of association list entries.
+// Like with "assoc", first entry of the key wins.
+func AlistToMap(alist List) any
+
+// MapToPlist returns property list of map entries.
+func MapToPlist(m any) List
+
+// PlistToMap returns map of property list entries.
+// Like with "plist-get", first entry of the key wins.
+func PlistToMap(plist List) any
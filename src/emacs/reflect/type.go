@@ -0,0 +1,316 @@
+// Package reflect implements a subset of Go "reflect" package.
+//
+// Type information is provided by runtime type descriptors
+// that are emitted for every type which is converted to
+// interface (and for every named type).
+package reflect
+
+import (
+	"emacs/lisp"
+)
+
+// Runtime type descriptor layout; see "vmm" package.
+const (
+	descKind = iota
+	descString
+	descName
+	descPkgPath
+	descFields
+	descMethods
+	descElem
+	descKey
+	descLen
+	descMethodTypes
+)
+
+// Kind represents the specific kind of type that a Type represents.
+type Kind int
+
+const (
+	Invalid Kind = iota
+	Bool
+	Int
+	Int8
+	Int16
+	Int32
+	Int64
+	Uint
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Uintptr
+	Float32
+	Float64
+	Complex64
+	Complex128
+	Array
+	Chan
+	Func
+	Interface
+	Map
+	Ptr
+	Slice
+	String
+	Struct
+	UnsafePointer
+)
+
+var kindNames = [...]string{
+	"invalid",
+	"bool",
+	"int",
+	"int8",
+	"int16",
+	"int32",
+	"int64",
+	"uint",
+	"uint8",
+	"uint16",
+	"uint32",
+	"uint64",
+	"uintptr",
+	"float32",
+	"float64",
+	"complex64",
+	"complex128",
+	"array",
+	"chan",
+	"func",
+	"interface",
+	"map",
+	"ptr",
+	"slice",
+	"string",
+	"struct",
+	"unsafe.Pointer",
+}
+
+// String returns the name of k.
+func (k Kind) String() string {
+	if int(k) >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "kind" + lisp.Call("number-to-string", int(k)).String()
+}
+
+// Type is the representation of a Go type.
+//
+// Type values are comparable.
+// Methods panic if they are called on types of unexpected kind.
+type Type interface {
+	// Kind returns the specific kind of this type.
+	Kind() Kind
+
+	// Name returns the type's name within its package.
+	// Returns empty string for unnamed types.
+	Name() string
+
+	// PkgPath returns a named type's package path.
+	// Returns empty string for unnamed and predeclared types.
+	PkgPath() string
+
+	// String returns a string representation of the type.
+	String() string
+
+	// Elem returns element type of Array, Chan, Map, Ptr or Slice type.
+	Elem() Type
+
+	// Key returns a map type's key type.
+	Key() Type
+
+	// Len returns an array type's length.
+	Len() int
+
+	// NumField returns a struct type's field count.
+	NumField() int
+
+	// Field returns a struct type's i'th field.
+	Field(i int) StructField
+
+	// NumMethod returns the number of exported methods in the type's method set.
+	NumMethod() int
+
+	// Method returns the i'th method in the type's method set.
+	// Methods are sorted in lexicographic order.
+	Method(i int) Method
+
+	// MethodByName returns the method with that name
+	// in the type's method set and a boolean indicating
+	// if the method was found.
+	MethodByName(name string) (Method, bool)
+
+	// Implements reports whether the type implements the interface type u.
+	Implements(u Type) bool
+
+	// AssignableTo reports whether a value of the type is assignable to type u.
+	AssignableTo(u Type) bool
+}
+
+// StructField describes a single field in a struct.
+type StructField struct {
+	Name  string
+	Type  Type
+	Index int
+}
+
+// Method represents a single method.
+type Method struct {
+	Name string
+	// Func is Emacs Lisp function that implements method.
+	// Receiver is passed as the first argument.
+	// Always nil for interface types.
+	Func  lisp.Object
+	Index int
+}
+
+// rtype is Type implementation that wraps type descriptor.
+type rtype struct {
+	desc lisp.Object
+}
+
+// TypeOf returns the dynamic type of i.
+// Returns nil if i is a nil interface value.
+func TypeOf(i interface{}) Type {
+	if i == nil {
+		return nil
+	}
+	return toType(lisp.Call("aref", lisp.Call("car", i), 0))
+}
+
+// toType returns Type for descriptor symbol.
+func toType(sym lisp.Object) Type {
+	if lisp.Not(sym) {
+		return nil
+	}
+	return cachedType(sym)
+}
+
+// rtypes maps descriptor symbols to their Type implementations.
+var rtypes = make(map[lisp.Object]*rtype)
+
+// cachedType returns the same rtype object for the same descriptor,
+// so Type values can be compared by identity.
+func cachedType(sym lisp.Object) *rtype {
+	t := rtypes[sym]
+	if t == nil {
+		t = &rtype{desc: lisp.Call("symbol-value", sym)}
+		rtypes[sym] = t
+	}
+	return t
+}
+
+func (t *rtype) attr(i int) lisp.Object {
+	return lisp.Call("aref", t.desc, i)
+}
+
+func (t *rtype) mustBe(k Kind, method string) {
+	if t.Kind() != k {
+		panic("reflect: " + method + " of non-" + k.String() + " type " + t.String())
+	}
+}
+
+func (t *rtype) Kind() Kind {
+	return Kind(t.attr(descKind).Int())
+}
+
+func (t *rtype) Name() string {
+	return t.attr(descName).String()
+}
+
+func (t *rtype) PkgPath() string {
+	return t.attr(descPkgPath).String()
+}
+
+func (t *rtype) String() string {
+	return t.attr(descString).String()
+}
+
+func (t *rtype) Elem() Type {
+	switch t.Kind() {
+	case Array, Chan, Map, Ptr, Slice:
+		return toType(t.attr(descElem))
+	}
+	panic("reflect: Elem of invalid type " + t.String())
+}
+
+func (t *rtype) Key() Type {
+	t.mustBe(Map, "Key")
+	return toType(t.attr(descKey))
+}
+
+func (t *rtype) Len() int {
+	t.mustBe(Array, "Len")
+	return t.attr(descLen).Int()
+}
+
+func (t *rtype) NumField() int {
+	t.mustBe(Struct, "NumField")
+	return lisp.Length(t.attr(descFields))
+}
+
+func (t *rtype) Field(i int) StructField {
+	t.mustBe(Struct, "Field")
+	f := lisp.Call("aref", t.attr(descFields), i)
+	return StructField{
+		Name:  lisp.Call("car", f).String(),
+		Type:  toType(lisp.Call("cdr", f)),
+		Index: i,
+	}
+}
+
+func (t *rtype) NumMethod() int {
+	return lisp.Length(t.attr(descMethods))
+}
+
+func (t *rtype) Method(i int) Method {
+	m := lisp.Call("aref", t.attr(descMethods), i)
+	return Method{
+		Name:  lisp.Call("car", m).String(),
+		Func:  lisp.Call("cdr", m),
+		Index: i,
+	}
+}
+
+func (t *rtype) MethodByName(name string) (Method, bool) {
+	n := t.NumMethod()
+	for i := 0; i < n; i++ {
+		m := t.Method(i)
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Method{}, false
+}
+
+func (t *rtype) Implements(u Type) bool {
+	if u == nil {
+		panic("reflect: nil type passed to Type.Implements")
+	}
+	if u.Kind() != Interface {
+		panic("reflect: non-interface type passed to Type.Implements")
+	}
+	uu := u.(*rtype)
+	for i := 0; i < uu.NumMethod(); i++ {
+		m, ok := t.MethodByName(uu.Method(i).Name)
+		if !ok || t.methodType(m.Index) != uu.methodType(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// methodType returns signature string of i'th method.
+func (t *rtype) methodType(i int) string {
+	return lisp.Call("aref", t.attr(descMethodTypes), i).String()
+}
+
+func (t *rtype) AssignableTo(u Type) bool {
+	if u == nil {
+		panic("reflect: nil type passed to Type.AssignableTo")
+	}
+	if u, ok := u.(*rtype); ok && u == t {
+		return true
+	}
+	return u.Kind() == Interface && t.Implements(u)
+}
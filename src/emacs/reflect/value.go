@@ -0,0 +1,334 @@
+package reflect
+
+import (
+	"emacs/lisp"
+)
+
+// Value is the reflection interface to a Go value.
+//
+// The zero Value represents no value.
+// Its IsValid method returns false, its Kind method returns Invalid.
+type Value struct {
+	typ  lisp.Object // Type descriptor symbol
+	data lisp.Object
+	addr bool        // Whether value is addressable (see CanSet)
+	box  lisp.Object // Cons that holds addressable non-struct value
+}
+
+// ValueOf returns a new Value initialized to the concrete value stored in i.
+// ValueOf(nil) returns the zero Value.
+func ValueOf(i interface{}) Value {
+	if i == nil {
+		return Value{}
+	}
+	return Value{
+		typ:  lisp.Call("aref", lisp.Call("car", i), 0),
+		data: lisp.Call("cdr", i),
+	}
+}
+
+func (v Value) descType() *rtype {
+	if !v.IsValid() {
+		panic("reflect: call of reflect.Value method on zero Value")
+	}
+	return cachedType(v.typ)
+}
+
+func (v Value) mustBe(k Kind, method string) {
+	if v.Kind() != k {
+		panic("reflect: call of reflect.Value." + method + " on " + v.Kind().String() + " Value")
+	}
+}
+
+// IsValid reports whether v represents a value.
+func (v Value) IsValid() bool {
+	return !lisp.Not(v.typ)
+}
+
+// Kind returns v's Kind.
+func (v Value) Kind() Kind {
+	if !v.IsValid() {
+		return Invalid
+	}
+	return v.descType().Kind()
+}
+
+// Type returns v's type.
+func (v Value) Type() Type {
+	return v.descType()
+}
+
+// Interface returns v's current value as an interface{}.
+func (v Value) Interface() interface{} {
+	t := v.descType()
+	if t.Kind() == Interface && t.PkgPath() != "emacs/lisp" {
+		// Value is already boxed.
+		return lisp.Any(v.data)
+	}
+	itab := lisp.Call("vector", v.typ)
+	return lisp.Any(lisp.Call("cons", itab, v.data))
+}
+
+// Bool returns v's underlying value.
+func (v Value) Bool() bool {
+	v.mustBe(Bool, "Bool")
+	return !lisp.Not(v.data)
+}
+
+// Int returns v's underlying value.
+// It panics if v's Kind is not Int, Int8, Int16, Int32, or Int64.
+func (v Value) Int() int64 {
+	switch v.Kind() {
+	case Int, Int8, Int16, Int32, Int64:
+		return int64(v.data.Int())
+	}
+	panic("reflect: call of reflect.Value.Int on " + v.Kind().String() + " Value")
+}
+
+// Uint returns v's underlying value.
+// It panics if v's Kind is not Uint, Uintptr, Uint8, Uint16, Uint32, or Uint64.
+func (v Value) Uint() uint64 {
+	switch v.Kind() {
+	case Uint, Uintptr, Uint8, Uint16, Uint32, Uint64:
+		return uint64(v.data.Int())
+	}
+	panic("reflect: call of reflect.Value.Uint on " + v.Kind().String() + " Value")
+}
+
+// Float returns v's underlying value.
+// It panics if v's Kind is not Float32 or Float64.
+func (v Value) Float() float64 {
+	switch v.Kind() {
+	case Float32, Float64:
+		return v.data.Float()
+	}
+	panic("reflect: call of reflect.Value.Float on " + v.Kind().String() + " Value")
+}
+
+// String returns v's underlying value, as a string.
+// Unlike the other getters, it does not panic if v's Kind is not String.
+// Instead, it returns a string of the form "<T Value>".
+func (v Value) String() string {
+	switch v.Kind() {
+	case Invalid:
+		return "<invalid Value>"
+	case String:
+		return v.data.String()
+	}
+	return "<" + v.descType().String() + " Value>"
+}
+
+// Len returns v's length.
+// It panics if v's Kind is not Array, Map, Slice, or String.
+func (v Value) Len() int {
+	switch v.Kind() {
+	case Array:
+		return lisp.Length(v.data)
+	case Map:
+		return lisp.Call("hash-table-count", v.data).Int()
+	case Slice:
+		return structField(v.data, 2, sliceFields).Int()
+	case String:
+		return lisp.StringBytes(v.data.String())
+	}
+	panic("reflect: call of reflect.Value.Len on " + v.Kind().String() + " Value")
+}
+
+// Index returns v's i'th element.
+// It panics if v's Kind is not Array or Slice or i is out of range.
+func (v Value) Index(i int) Value {
+	elem := v.descType().attr(descElem)
+	switch v.Kind() {
+	case Array:
+		if i < 0 || i >= v.Len() {
+			panic("reflect: array index out of range")
+		}
+		return Value{typ: elem, data: lisp.Call("aref", v.data, i)}
+	case Slice:
+		if i < 0 || i >= v.Len() {
+			panic("reflect: slice index out of range")
+		}
+		offset := structField(v.data, 1, sliceFields).Int()
+		data := structField(v.data, 0, sliceFields)
+		return Value{typ: elem, data: lisp.Call("aref", data, offset+i)}
+	}
+	panic("reflect: call of reflect.Value.Index on " + v.Kind().String() + " Value")
+}
+
+// Elem returns the value that the pointer v points to.
+// It panics if v's Kind is not Ptr.
+func (v Value) Elem() Value {
+	v.mustBe(Ptr, "Elem")
+	if lisp.Not(v.data) {
+		return Value{}
+	}
+	elem := Value{typ: v.descType().attr(descElem), addr: true}
+	if elem.Kind() == Struct {
+		// Pointer to struct shares data with struct it points to.
+		elem.data = v.data
+	} else {
+		// Other pointers are boxes that hold the value.
+		elem.data = lisp.Call("car", v.data)
+		elem.box = v.data
+	}
+	return elem
+}
+
+// CanSet reports whether the value of v can be changed.
+// Only values that are obtained by Elem of pointer are settable.
+func (v Value) CanSet() bool {
+	return v.addr
+}
+
+// Set assigns x to the value v.
+// It panics if CanSet returns false or
+// x type is not assignable to v type.
+func (v Value) Set(x Value) {
+	if !v.addr {
+		panic("reflect: reflect.Value.Set using unaddressable value")
+	}
+	if x.Kind() == Interface {
+		x = ValueOf(x.Interface())
+	}
+	if x.IsValid() && !x.Type().AssignableTo(v.Type()) {
+		panic("reflect.Set: value of type " + x.Type().String() +
+			" is not assignable to type " + v.Type().String())
+	}
+	switch v.Kind() {
+	case Interface:
+		lisp.Call("setcar", v.box, ifaceData(x, v.descType()))
+		return
+	case Struct:
+		// Handled below.
+	default:
+		lisp.Call("setcar", v.box, x.data)
+		return
+	}
+	n := v.NumField()
+	for i := 0; i < n; i++ {
+		setStructField(v.data, i, n, structField(x.data, i, n))
+	}
+}
+
+// IsNil reports whether its argument v is a nil pointer.
+// It panics if v's Kind is not Ptr.
+func (v Value) IsNil() bool {
+	v.mustBe(Ptr, "IsNil")
+	return lisp.Not(v.data)
+}
+
+// NumField returns the number of fields in the struct v.
+// It panics if v's Kind is not Struct.
+func (v Value) NumField() int {
+	return v.descType().NumField()
+}
+
+// Field returns the i'th field of the struct v.
+// It panics if v's Kind is not Struct or i is out of range.
+func (v Value) Field(i int) Value {
+	n := v.NumField()
+	if i < 0 || i >= n {
+		panic("reflect: Field index out of range")
+	}
+	f := lisp.Call("aref", v.descType().attr(descFields), i)
+	return Value{
+		typ:  lisp.Call("cdr", f),
+		data: structField(v.data, i, n),
+	}
+}
+
+// MapKeys returns a slice containing all the keys present in the map,
+// in unspecified order.
+// It panics if v's Kind is not Map.
+func (v Value) MapKeys() []Value {
+	v.mustBe(Map, "MapKeys")
+	keyTyp := v.descType().attr(descKey)
+	keys := make([]Value, 0, v.Len())
+	list := lisp.Call("goism--rt-map-keys", v.data)
+	for !lisp.Not(list) {
+		keys = append(keys, Value{typ: keyTyp, data: lisp.Call("car", list)})
+		list = lisp.Call("cdr", list)
+	}
+	return keys
+}
+
+// MapIndex returns the value associated with key in the map v.
+// It returns the zero Value if key is not found in the map.
+// It panics if v's Kind is not Map.
+func (v Value) MapIndex(key Value) Value {
+	v.mustBe(Map, "MapIndex")
+	// Fresh uninterned symbol can not be stored inside map.
+	missingKey := lisp.Call("make-symbol", "missing")
+	val := lisp.Call("gethash", key.data, v.data, missingKey)
+	if lisp.Eq(val, missingKey) {
+		return Value{}
+	}
+	return Value{typ: v.descType().attr(descElem), data: val}
+}
+
+// ifaceData returns x converted to interface type t.
+// Zero Value is converted to nil interface value.
+func ifaceData(x Value, t *rtype) lisp.Object {
+	if !x.IsValid() {
+		return nil
+	}
+	if t.PkgPath() == "emacs/lisp" {
+		return x.data // Lisp types are not boxed
+	}
+	n := t.NumMethod()
+	itab := lisp.Call("make-vector", n+1, x.typ)
+	for i := 0; i < n; i++ {
+		m, _ := x.Type().MethodByName(t.Method(i).Name)
+		lisp.Aset(itab, i+1, m.Func)
+	}
+	return lisp.Call("cons", itab, x.data)
+}
+
+// Number of "emacs/rt.Slice" fields: {data, offset, len, cap}.
+const sliceFields = 4
+
+// structField returns i'th attribute of object that is
+// a struct with n fields.
+// Struct data layout is described in "vmm" package.
+func structField(data lisp.Object, i, n int) lisp.Object {
+	if n == 1 {
+		return lisp.Call("car", data)
+	}
+	if n > 4 {
+		return lisp.Call("aref", data, i)
+	}
+	// Improper list.
+	for j := 0; j < i; j++ {
+		data = lisp.Call("cdr", data)
+	}
+	if i == n-1 {
+		return data
+	}
+	return lisp.Call("car", data)
+}
+
+// setStructField is like structField, but assigns i'th
+// attribute instead of returning it.
+func setStructField(data lisp.Object, i, n int, val lisp.Object) {
+	if n == 1 {
+		lisp.Call("setcar", data, val)
+		return
+	}
+	if n > 4 {
+		lisp.Aset(data, i, val)
+		return
+	}
+	// Improper list; last attribute is stored in the last cdr.
+	if i == n-1 {
+		i--
+		for j := 0; j < i; j++ {
+			data = lisp.Call("cdr", data)
+		}
+		lisp.Call("setcdr", data, val)
+		return
+	}
+	for j := 0; j < i; j++ {
+		data = lisp.Call("cdr", data)
+	}
+	lisp.Call("setcar", data, val)
+}
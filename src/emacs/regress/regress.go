@@ -1,3 +1,7 @@
+// Package regress contains code that used to be translated wrong.
+//
+// Version: 0.2
+// Package-Requires: ((emacs "26.1"))
 package regress
 
 // #REFS: 78.
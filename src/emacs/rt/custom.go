@@ -0,0 +1,55 @@
+package rt
+
+import "emacs/lisp"
+
+// Customize works with lists and alists, while Go
+// slices and maps have their own representation.
+// Functions below are ":set" and ":get" functions of
+// user options; they use conversions from "seq.go".
+
+// CustomGetSlice is a ":get" function of slice user options.
+func CustomGetSlice(sym lisp.Symbol) lisp.Object {
+	val := lisp.Call("default-value", sym)
+	if !isSlice(val) {
+		// Option was set to a list before it was defined.
+		return val
+	}
+	// Go can not convert Lisp object to *Slice,
+	// but Lisp call can pass it as is.
+	return lisp.Call("goism-rt.SliceToList", val)
+}
+
+// CustomSetSlice is a ":set" function of slice user options.
+func CustomSetSlice(sym lisp.Symbol, list lisp.Object) {
+	lisp.Call("set-default", sym, ListToSlice(list))
+}
+
+// CustomGetMap is a ":get" function of map user options.
+func CustomGetMap(sym lisp.Symbol) lisp.Object {
+	val := lisp.Call("default-value", sym)
+	if lisp.Not(lisp.Call("hash-table-p", val)) {
+		// Option was set to an alist before it was defined.
+		return val
+	}
+	return MapToAlist(val)
+}
+
+// CustomSetMap is a ":set" function of map user options.
+func CustomSetMap(sym lisp.Symbol, alist lisp.Object) {
+	lisp.Call("set-default", sym, AlistToMap(alist))
+}
+
+// isSlice reports whether x is a Slice object.
+// Slice is (data offset len . cap) where data is
+// either nil or vector; user lists never have
+// such layout for slice types that can be customized.
+func isSlice(x lisp.Object) bool {
+	if lisp.Not(lisp.Call("consp", x)) {
+		return false
+	}
+	data := car(x)
+	if !lisp.Not(data) && lisp.Not(lisp.Call("vectorp", data)) {
+		return false
+	}
+	return !lisp.Not(lisp.Call("integerp", lisp.Call("cadr", x)))
+}
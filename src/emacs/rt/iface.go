@@ -12,6 +12,12 @@ func itabMethod(itab lisp.Object, n int) lisp.Object {
 	return aref(itab, n+1)
 }
 
+// typeName returns type string stored inside type descriptor.
+func typeName(typ lisp.Object) string {
+	// Index 1 is a type string (see "vmm" package).
+	return aref(lisp.Call("symbol-value", typ), 1).String()
+}
+
 // Iface - Go interface.
 type Iface struct {
 	itab lisp.Object
@@ -33,23 +39,178 @@ func IfaceCall0(iface *Iface, fnID int) lisp.Object {
 // IfaceCall1 like IfaceCall0, but for methods with arity=1.
 //goism:subst
 func IfaceCall1(iface *Iface, fnID int, a1 lisp.Object) lisp.Object {
-	return lisp.DynCall(aref(iface.itab, fnID), iface.data, a1)
+	return lisp.DynCall(itabMethod(iface.itab, fnID), iface.data, a1)
 }
 
 // IfaceCall2 like IfaceCall0, but for methods with arity=2.
 //goism:subst
 func IfaceCall2(iface *Iface, fnID int, a1, a2 lisp.Object) lisp.Object {
-	return lisp.DynCall(aref(iface.itab, fnID), iface.data, a1, a2)
+	return lisp.DynCall(itabMethod(iface.itab, fnID), iface.data, a1, a2)
 }
 
 // IfaceCall3 like IfaceCall0, but for methods with arity=3.
 //goism:subst
 func IfaceCall3(iface *Iface, fnID int, a1, a2, a3 lisp.Object) lisp.Object {
-	return lisp.DynCall(aref(iface.itab, fnID), iface.data, a1, a2, a3)
+	return lisp.DynCall(itabMethod(iface.itab, fnID), iface.data, a1, a2, a3)
 }
 
 // IfaceCall4 like IfaceCall0, but for methods with arity=4.
 //goism:subst
 func IfaceCall4(iface *Iface, fnID int, a1, a2, a3, a4 lisp.Object) lisp.Object {
-	return lisp.DynCall(aref(iface.itab, fnID), iface.data, a1, a2, a3, a4)
+	return lisp.DynCall(itabMethod(iface.itab, fnID), iface.data, a1, a2, a3, a4)
+}
+
+// TypeAssert = "x.(T)", where T is not an interface type.
+// Returns interface data if dynamic type is T; panics otherwise.
+func TypeAssert(x lisp.Object, typ lisp.Object) lisp.Object {
+	if lisp.IsSymbol(x) {
+		panic("interface conversion: interface is nil, not " + typeName(typ))
+	}
+	tag := itabTag(car(x))
+	if !lisp.Eq(tag, typ) {
+		panic("interface conversion: interface is " + typeName(tag) + ", not " + typeName(typ))
+	}
+	return cdr(x)
+}
+
+// TypeAssertOk = "v, ok := x.(T)", where T is not an interface type.
+// On failure returns zero value (zv) instead of panic.
+func TypeAssertOk(x lisp.Object, typ lisp.Object, zv lisp.Object) (lisp.Object, bool) {
+	if lisp.IsSymbol(x) || !lisp.Eq(itabTag(car(x)), typ) {
+		return zv, false
+	}
+	return cdr(x), true
+}
+
+// makeItab returns itab that is used to convert value with
+// dynamic type tag to interface type iface.
+// Returns nil if tag type does not implement iface;
+// name of the first missing method is stored in missingMethod.
+//
+// Both tag and iface are type descriptor symbols.
+// Built itabs are cached inside tag symbol plist.
+func makeItab(tag, iface lisp.Object) lisp.Object {
+	itab := lisp.Call("get", tag, iface)
+	if !lisp.Not(itab) {
+		return itab
+	}
+	// Index 5 is a methods vector (see "vmm" package).
+	want := aref(lisp.Call("symbol-value", iface), 5)
+	have := aref(lisp.Call("symbol-value", tag), 5)
+	itab = makeVector(lisp.Length(want)+1, tag)
+	for i := 0; i < lisp.Length(want); i++ {
+		name := car(aref(want, i)).String()
+		fn := lookupMethod(have, name)
+		if lisp.Not(fn) {
+			missingMethod = name
+			return fn
+		}
+		lisp.Aset(itab, i+1, fn)
+	}
+	lisp.Call("put", tag, iface, itab)
+	return itab
+}
+
+// Set by makeItab on failure.
+var missingMethod string
+
+// lookupMethod returns function that implements named method.
+// Returns nil if there is no such method.
+func lookupMethod(methods lisp.Object, name string) lisp.Object {
+	for i := 0; i < lisp.Length(methods); i++ {
+		m := aref(methods, i)
+		if car(m).String() == name {
+			return cdr(m)
+		}
+	}
+	return nil
+}
+
+// TypeAssertIface = "x.(T)", where T is an interface type.
+// Returns interface value with itab that matches T; panics
+// if dynamic type does not implement T.
+func TypeAssertIface(x lisp.Object, iface lisp.Object) lisp.Object {
+	if lisp.IsSymbol(x) {
+		panic("interface conversion: interface is nil, not " + typeName(iface))
+	}
+	tag := itabTag(car(x))
+	itab := makeItab(tag, iface)
+	if lisp.Not(itab) {
+		panic("interface conversion: " + typeName(tag) + " is not " +
+			typeName(iface) + ": missing method " + missingMethod)
+	}
+	return lisp.Call("cons", itab, cdr(x))
+}
+
+// TypeAssertIfaceOk = "v, ok := x.(T)", where T is an interface type.
+// On failure returns nil interface value (zv) instead of panic.
+func TypeAssertIfaceOk(x lisp.Object, iface lisp.Object, zv lisp.Object) (lisp.Object, bool) {
+	if lisp.IsSymbol(x) {
+		return zv, false
+	}
+	itab := makeItab(itabTag(car(x)), iface)
+	if lisp.Not(itab) {
+		return zv, false
+	}
+	return lisp.Call("cons", itab, cdr(x)), true
+}
+
+// IfaceConvert converts interface value to another non-empty
+// interface type.
+// Conversion is checked during compilation, so it never fails.
+func IfaceConvert(x lisp.Object, iface lisp.Object) lisp.Object {
+	if lisp.IsSymbol(x) {
+		return x // Nil interface value
+	}
+	return lisp.Call("cons", makeItab(itabTag(car(x)), iface), cdr(x))
+}
+
+// IfaceEq = "x == y", where x and y are Go interface values.
+// Dynamic types must be identical; values of reference kinds
+// are compared by identity, other values are compared structurally.
+func IfaceEq(x, y lisp.Object) bool {
+	if lisp.IsSymbol(x) || lisp.IsSymbol(y) {
+		return lisp.Eq(x, y) // At least one of them is nil
+	}
+	tag := itabTag(car(x))
+	if !lisp.Eq(tag, itabTag(car(y))) {
+		return false
+	}
+	// Index 0 is a kind (see "vmm" package).
+	switch aref(lisp.Call("symbol-value", tag), 0).Int() {
+	case kindChan, kindFunc, kindMap, kindPtr, kindSlice:
+		return lisp.Eq(cdr(x), cdr(y))
+	default:
+		return !lisp.Not(lisp.Call("equal", cdr(x), cdr(y)))
+	}
+}
+
+// Kinds of types that are compared by identity.
+// Values must match "reflect.Kind" constants.
+const (
+	kindChan  = 18
+	kindFunc  = 19
+	kindMap   = 21
+	kindPtr   = 22
+	kindSlice = 23
+)
+
+// PanicIface = "panic(x)", where x is a Go interface value.
+// If dynamic type implements error (or Stringer), error message
+// is a result of its Error (String) method call, so
+// Emacs reports it in a readable form.
+//goism:noinline
+func PanicIface(x lisp.Object) {
+	if lisp.IsSymbol(x) {
+		panic("panic called with nil argument")
+	}
+	// Index 5 is a methods vector (see "vmm" package).
+	methods := aref(lisp.Call("symbol-value", itabTag(car(x))), 5)
+	if fn := lookupMethod(methods, "Error"); !lisp.Not(fn) {
+		Panic(lisp.DynCall(fn, cdr(x)))
+	}
+	if fn := lookupMethod(methods, "String"); !lisp.Not(fn) {
+		Panic(lisp.DynCall(fn, cdr(x)))
+	}
+	Panic(cdr(x))
 }
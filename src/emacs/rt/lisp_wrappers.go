@@ -31,3 +31,11 @@ func substring(o lisp.Object, from, to int) lisp.Object {
 func aref(arr lisp.Object, index int) lisp.Object {
 	return lisp.Call("aref", arr, index)
 }
+
+func car(o lisp.Object) lisp.Object {
+	return lisp.Call("car", o)
+}
+
+func cdr(o lisp.Object) lisp.Object {
+	return lisp.Call("cdr", o)
+}
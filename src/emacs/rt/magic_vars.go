@@ -20,4 +20,7 @@ const MultiRetLimit = 8
 
 var (
 	NilMap = make(map[lisp.Object]lisp.Object, 1)
+	// NilSlice is a shared empty slice that represents nil slice.
+	// Only append can extend it, so it is never modified.
+	NilSlice = &Slice{}
 )
\ No newline at end of file
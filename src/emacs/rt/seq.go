@@ -0,0 +1,90 @@
+package rt
+
+import "emacs/lisp"
+
+// Conversions between Go slices and maps and Emacs Lisp
+// sequences. Each conversion is a single pass over its input.
+// They implement "lisp.SliceToVector" and similar functions.
+
+// SliceToVector returns vector of slice elements.
+// Slice data vector is returned as is if slice covers all of it.
+func SliceToVector(slice *Slice) lisp.Object {
+	if slice.len == 0 {
+		return lisp.Call("make-vector", 0, lisp.Intern("nil"))
+	}
+	if slice.offset == 0 && slice.len == slice.cap {
+		return slice.data
+	}
+	return substring(slice.data, slice.offset, slice.offset+slice.len)
+}
+
+// SliceToList returns list of slice elements.
+func SliceToList(slice *Slice) lisp.Object {
+	list := lisp.Call("list")
+	for i := slice.len - 1; i >= 0; i-- {
+		list = lisp.Call("cons", SliceGet(slice, i), list)
+	}
+	return list
+}
+
+// ListToSlice returns slice that holds list elements.
+func ListToSlice(list lisp.Object) *Slice {
+	return ArrayToSlice(lisp.Call("vconcat", list))
+}
+
+// MapToAlist returns association list of map entries.
+func MapToAlist(m lisp.Object) lisp.Object {
+	alist := lisp.Call("list")
+	keys := lisp.Call("goism--rt-map-keys", m)
+	for !lisp.Not(keys) {
+		key := car(keys)
+		entry := lisp.Call("cons", key, lisp.Call("gethash", key, m))
+		alist = lisp.Call("cons", entry, alist)
+		keys = cdr(keys)
+	}
+	return alist
+}
+
+// AlistToMap returns map that holds association list entries.
+// Like with "assoc", first entry of the key wins.
+func AlistToMap(alist lisp.Object) lisp.Object {
+	m := MakeMap()
+	missing := lisp.Call("make-symbol", "missing")
+	for !lisp.Not(alist) {
+		entry := car(alist)
+		key := car(entry)
+		if lisp.Eq(lisp.Call("gethash", key, m, missing), missing) {
+			lisp.Call("puthash", key, cdr(entry), m)
+		}
+		alist = cdr(alist)
+	}
+	return m
+}
+
+// MapToPlist returns property list of map entries.
+func MapToPlist(m lisp.Object) lisp.Object {
+	plist := lisp.Call("list")
+	keys := lisp.Call("goism--rt-map-keys", m)
+	for !lisp.Not(keys) {
+		key := car(keys)
+		plist = lisp.Call("cons", lisp.Call("gethash", key, m), plist)
+		plist = lisp.Call("cons", key, plist)
+		keys = cdr(keys)
+	}
+	return plist
+}
+
+// PlistToMap returns map that holds property list entries.
+// Like with "plist-get", first entry of the key wins.
+func PlistToMap(plist lisp.Object) lisp.Object {
+	m := MakeMap()
+	missing := lisp.Call("make-symbol", "missing")
+	for !lisp.Not(plist) {
+		key := car(plist)
+		if lisp.Eq(lisp.Call("gethash", key, m, missing), missing) {
+			lisp.Call("puthash", key, lisp.Call("cadr", plist), m)
+		}
+		plist = lisp.Call("cddr", plist)
+	}
+	return m
+}
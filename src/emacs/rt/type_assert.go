@@ -39,6 +39,55 @@ func CoerceSymbol(x lisp.Object) lisp.Object {
 	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Symbol")
 }
 
+func CoerceBuffer(x lisp.Object) lisp.Object {
+	if lisp.IsBuffer(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Buffer")
+}
+
+func CoerceMarker(x lisp.Object) lisp.Object {
+	if lisp.IsMarker(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Marker")
+}
+
+func CoerceWindow(x lisp.Object) lisp.Object {
+	if lisp.IsWindow(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Window")
+}
+
+func CoerceProcess(x lisp.Object) lisp.Object {
+	if lisp.IsProcess(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Process")
+}
+
+func CoerceHashTable(x lisp.Object) lisp.Object {
+	if lisp.IsHashTable(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.HashTable")
+}
+
+func CoerceCons(x lisp.Object) lisp.Object {
+	if lisp.IsCons(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.Cons")
+}
+
+func CoerceList(x lisp.Object) lisp.Object {
+	if lisp.IsList(x) {
+		return x
+	}
+	panic("interface conversion: lisp.Object is " + objectTypeName(x) + ", not lisp.List")
+}
+
 func objectTypeName(x lisp.Object) string {
 	if lisp.IsString(x) {
 		return "string"
@@ -50,6 +99,18 @@ func objectTypeName(x lisp.Object) string {
 		return "float64"
 	} else if lisp.IsBool(x) {
 		return "bool"
+	} else if lisp.IsCons(x) {
+		return "lisp.Cons"
+	} else if lisp.IsHashTable(x) {
+		return "lisp.HashTable"
+	} else if lisp.IsBuffer(x) {
+		return "lisp.Buffer"
+	} else if lisp.IsMarker(x) {
+		return "lisp.Marker"
+	} else if lisp.IsWindow(x) {
+		return "lisp.Window"
+	} else if lisp.IsProcess(x) {
+		return "lisp.Process"
 	}
 	panic("unexpected type used in lisp.Object type assertion")
 }
@@ -0,0 +1,52 @@
+// Copyright 2010 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+package sort
+
+// Search uses binary search to find and return the smallest index i
+// in [0, n) at which f(i) is true, assuming that on the range [0, n),
+// f(i) == true implies f(i+1) == true.
+// If there is no such index, Search returns n.
+func Search(n int, f func(int) bool) int {
+	i, j := 0, n
+	for i < j {
+		h := (i + j) / 2
+		if !f(h) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+// SearchInts searches for x in a sorted slice of ints and returns the index
+// as specified by Search. The slice must be sorted in ascending order.
+func SearchInts(a []int, x int) int {
+	i, j := 0, len(a)
+	for i < j {
+		h := (i + j) / 2
+		if a[h] < x {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+// SearchStrings searches for x in a sorted slice of strings and returns the index
+// as specified by Search. The slice must be sorted in ascending order.
+func SearchStrings(a []string, x string) int {
+	i, j := 0, len(a)
+	for i < j {
+		h := (i + j) / 2
+		if a[h] < x {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+package sort
+
+import (
+	"emacs/lisp"
+	"emacs/reflect"
+)
+
+// Slice sorts the slice x given the provided less function.
+// It panics if x is not a slice.
+//
+// Emacs Lisp "sort" is stable, so Slice is the same as SliceStable.
+func Slice(x interface{}, less func(i, j int) bool) {
+	data, offset, n := sliceVector(x, "Slice")
+	if n < 2 {
+		return
+	}
+	// Indexes are sorted instead of elements, because less
+	// refers to the elements by their position.
+	perm := lisp.Call("sort", lisp.Call("number-sequence", 0, n-1), less)
+	old := lisp.Call("substring", data, offset, offset+n)
+	for i := 0; i < n; i++ {
+		lisp.Call("aset", data, offset+i, lisp.Call("aref", old, lisp.Call("car", perm)))
+		perm = lisp.Call("cdr", perm)
+	}
+}
+
+// SliceStable sorts the slice x using the provided less
+// function, keeping equal elements in their original order.
+// It panics if x is not a slice.
+func SliceStable(x interface{}, less func(i, j int) bool) {
+	Slice(x, less)
+}
+
+// SliceIsSorted reports whether the slice x is sorted according to the provided less function.
+// It panics if x is not a slice.
+func SliceIsSorted(x interface{}, less func(i, j int) bool) bool {
+	_, _, n := sliceVector(x, "SliceIsSorted")
+	for i := n - 1; i > 0; i-- {
+		if less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ints sorts a slice of ints in increasing order.
+func Ints(x []int) { sortVector(x, lisp.Intern("<")) }
+
+// Float64s sorts a slice of float64s in increasing order.
+// Not-a-number values are not ordered.
+func Float64s(x []float64) { sortVector(x, lisp.Intern("<")) }
+
+// Strings sorts a slice of strings in increasing order.
+func Strings(x []string) { sortVector(x, lisp.Intern("string<")) }
+
+// IntsAreSorted reports whether the slice x is sorted in increasing order.
+func IntsAreSorted(x []int) bool { return IsSorted(IntSlice(x)) }
+
+// Float64sAreSorted reports whether the slice x is sorted in increasing order.
+func Float64sAreSorted(x []float64) bool { return IsSorted(Float64Slice(x)) }
+
+// StringsAreSorted reports whether the slice x is sorted in increasing order.
+func StringsAreSorted(x []string) bool { return IsSorted(StringSlice(x)) }
+
+// IntSlice attaches the methods of Interface to []int, sorting in increasing order.
+type IntSlice []int
+
+func (x IntSlice) Len() int           { return len(x) }
+func (x IntSlice) Less(i, j int) bool { return x[i] < x[j] }
+func (x IntSlice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+// Sort is a convenience method: x.Sort() calls Sort(x).
+func (x IntSlice) Sort() { Sort(x) }
+
+// Float64Slice implements Interface for a []float64, sorting in increasing order.
+type Float64Slice []float64
+
+func (x Float64Slice) Len() int           { return len(x) }
+func (x Float64Slice) Less(i, j int) bool { return x[i] < x[j] }
+func (x Float64Slice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+// Sort is a convenience method: x.Sort() calls Sort(x).
+func (x Float64Slice) Sort() { Sort(x) }
+
+// StringSlice attaches the methods of Interface to []string, sorting in increasing order.
+type StringSlice []string
+
+func (x StringSlice) Len() int           { return len(x) }
+func (x StringSlice) Less(i, j int) bool { return x[i] < x[j] }
+func (x StringSlice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+// Sort is a convenience method: x.Sort() calls Sort(x).
+func (x StringSlice) Sort() { Sort(x) }
+
+// sortVector sorts elements of slice x with Emacs Lisp "sort"
+// using pred as a comparison function.
+func sortVector(x interface{}, pred lisp.Symbol) {
+	data, offset, n := sliceVector(x, "sortVector")
+	if n < 2 {
+		return
+	}
+	sorted := lisp.Call("sort", lisp.Call("substring", data, offset, offset+n), pred)
+	for i := 0; i < n; i++ {
+		lisp.Call("aset", data, offset+i, lisp.Call("aref", sorted, i))
+	}
+}
+
+// sliceVector returns backing vector, offset and length of
+// the slice x. See "emacs/rt.Slice" for the slice layout.
+func sliceVector(x interface{}, fn string) (lisp.Object, int, int) {
+	if reflect.ValueOf(x).Kind() != reflect.Slice {
+		panic("sort." + fn + ": argument is not a slice")
+	}
+	// Slice is an improper list: (data offset len . cap).
+	slice := lisp.Call("cdr", x)
+	return lisp.Call("car", slice),
+		lisp.Call("nth", 1, slice).Int(),
+		lisp.Call("nth", 2, slice).Int()
+}
@@ -0,0 +1,278 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+// Package sort implements a subset of Go "sort" package.
+//
+// Slices with plain comparison functions are sorted by
+// Emacs Lisp "sort" on their backing vectors.
+// Values that implement Interface are sorted by Go code,
+// because every comparison goes through the interface method call.
+package sort
+
+// An implementation of Interface can be sorted by the routines in this package.
+// The methods refer to elements of the underlying collection by integer index.
+type Interface interface {
+	// Len is the number of elements in the collection.
+	Len() int
+	// Less reports whether the element with index i
+	// must sort before the element with index j.
+	Less(i, j int) bool
+	// Swap swaps the elements with indexes i and j.
+	Swap(i, j int)
+}
+
+// Sort sorts data in ascending order as determined by the Less method.
+// The sort is not guaranteed to be stable.
+func Sort(data Interface) {
+	n := data.Len()
+	quickSort(data, 0, n, maxDepth(n))
+}
+
+// Stable sorts data in ascending order as determined by the Less method,
+// while keeping the original order of equal elements.
+func Stable(data Interface) {
+	stable(data, data.Len())
+}
+
+// IsSorted reports whether data is sorted.
+func IsSorted(data Interface) bool {
+	n := data.Len()
+	for i := n - 1; i > 0; i-- {
+		if data.Less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+type reverse struct {
+	data Interface
+}
+
+func (r reverse) Len() int           { return r.data.Len() }
+func (r reverse) Less(i, j int) bool { return r.data.Less(j, i) }
+func (r reverse) Swap(i, j int)      { r.data.Swap(i, j) }
+
+// Reverse returns the reverse order for data.
+func Reverse(data Interface) Interface {
+	return reverse{data: data}
+}
+
+// maxDepth returns a threshold at which quickSort should switch
+// to heapsort. It returns 2*ceil(lg(n+1)).
+func maxDepth(n int) int {
+	depth := 0
+	for i := n; i > 0; i /= 2 {
+		depth++
+	}
+	return depth * 2
+}
+
+func insertionSort(data Interface, a, b int) {
+	for i := a + 1; i < b; i++ {
+		for j := i; j > a && data.Less(j, j-1); j-- {
+			data.Swap(j, j-1)
+		}
+	}
+}
+
+// siftDown implements the heap property on data[lo:hi].
+// first is an offset into the array where the root of the heap lies.
+func siftDown(data Interface, lo, hi, first int) {
+	root := lo
+	for {
+		child := 2*root + 1
+		if child >= hi {
+			return
+		}
+		if child+1 < hi && data.Less(first+child, first+child+1) {
+			child++
+		}
+		if !data.Less(first+root, first+child) {
+			return
+		}
+		data.Swap(first+root, first+child)
+		root = child
+	}
+}
+
+func heapSort(data Interface, a, b int) {
+	first := a
+	hi := b - a
+	for i := (hi - 1) / 2; i >= 0; i-- {
+		siftDown(data, i, hi, first)
+	}
+	for i := hi - 1; i >= 0; i-- {
+		data.Swap(first, first+i)
+		siftDown(data, 0, i, first)
+	}
+}
+
+// medianOfThree moves the median of the three values
+// data[m0], data[m1], data[m2] into data[m1].
+func medianOfThree(data Interface, m1, m0, m2 int) {
+	if data.Less(m1, m0) {
+		data.Swap(m1, m0)
+	}
+	if data.Less(m2, m1) {
+		data.Swap(m2, m1)
+		if data.Less(m1, m0) {
+			data.Swap(m1, m0)
+		}
+	}
+}
+
+// doPivot partitions data[lo:hi] around median-of-three pivot.
+// Elements of data[lo:mid] are less than pivot,
+// pivot itself is placed at data[mid].
+func doPivot(data Interface, lo, hi int) int {
+	medianOfThree(data, lo, (lo+hi)/2, hi-1)
+	mid := lo + 1
+	for j := lo + 1; j < hi; j++ {
+		if data.Less(j, lo) {
+			data.Swap(mid, j)
+			mid++
+		}
+	}
+	mid--
+	data.Swap(lo, mid)
+	return mid
+}
+
+func quickSort(data Interface, a, b, depth int) {
+	for b-a > 12 {
+		if depth == 0 {
+			heapSort(data, a, b)
+			return
+		}
+		depth--
+		mid := doPivot(data, a, b)
+		// Recurse into the smaller part to bound stack depth.
+		if mid-a < b-mid {
+			quickSort(data, a, mid, depth)
+			a = mid + 1
+		} else {
+			quickSort(data, mid+1, b, depth)
+			b = mid
+		}
+	}
+	if b-a > 1 {
+		insertionSort(data, a, b)
+	}
+}
+
+func stable(data Interface, n int) {
+	blockSize := 20
+	a, b := 0, blockSize
+	for b <= n {
+		insertionSort(data, a, b)
+		a = b
+		b += blockSize
+	}
+	insertionSort(data, a, n)
+
+	for blockSize < n {
+		a, b = 0, 2*blockSize
+		for b <= n {
+			symMerge(data, a, a+blockSize, b)
+			a = b
+			b += 2 * blockSize
+		}
+		if m := a + blockSize; m < n {
+			symMerge(data, a, m, n)
+		}
+		blockSize *= 2
+	}
+}
+
+// symMerge merges the two sorted subsequences data[a:m] and data[m:b]
+// using the SymMerge algorithm from Pok-Son Kim and Arne Kutzner.
+func symMerge(data Interface, a, m, b int) {
+	if m-a == 1 {
+		// Insert data[a] into data[m:b].
+		i, j := m, b
+		for i < j {
+			h := (i + j) / 2
+			if data.Less(h, a) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := a; k < i-1; k++ {
+			data.Swap(k, k+1)
+		}
+		return
+	}
+	if b-m == 1 {
+		// Insert data[m] into data[a:m].
+		i, j := a, m
+		for i < j {
+			h := (i + j) / 2
+			if !data.Less(m, h) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := m; k > i; k-- {
+			data.Swap(k, k-1)
+		}
+		return
+	}
+
+	mid := (a + b) / 2
+	n := mid + m
+	var start, r int
+	if m > mid {
+		start = n - b
+		r = mid
+	} else {
+		start = a
+		r = m
+	}
+	p := n - 1
+	for start < r {
+		c := (start + r) / 2
+		if !data.Less(p-c, c) {
+			start = c + 1
+		} else {
+			r = c
+		}
+	}
+
+	end := n - start
+	if start < m && m < end {
+		rotate(data, start, m, end)
+	}
+	if a < start && start < mid {
+		symMerge(data, a, start, mid)
+	}
+	if mid < end && end < b {
+		symMerge(data, mid, end, b)
+	}
+}
+
+// swapRange swaps data[a:a+n] and data[b:b+n].
+func swapRange(data Interface, a, b, n int) {
+	for i := 0; i < n; i++ {
+		data.Swap(a+i, b+i)
+	}
+}
+
+// rotate rotates two consecutive blocks u = data[a:m] and v = data[m:b].
+func rotate(data Interface, a, m, b int) {
+	i := m - a
+	j := b - m
+	for i != j {
+		if i > j {
+			swapRange(data, m-i, m, j)
+			i -= j
+		} else {
+			swapRange(data, m-i, m+j-i, i)
+			j -= i
+		}
+	}
+	swapRange(data, m-i, m, i)
+}
@@ -0,0 +1,215 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+package strconv
+
+import (
+	"emacs/errors"
+	"emacs/lisp"
+)
+
+// ErrRange indicates that a value is out of range for the target type.
+var ErrRange = errors.New("value out of range")
+
+// ErrSyntax indicates that a value does not have the right syntax for the target type.
+var ErrSyntax = errors.New("invalid syntax")
+
+// A NumError records a failed conversion.
+type NumError struct {
+	Func string // the failing function (ParseBool, ParseInt, ParseFloat)
+	Num  string // the input
+	Err  error  // the reason the conversion failed (ErrRange, ErrSyntax, etc.)
+}
+
+func (e *NumError) Error() string {
+	return "strconv." + e.Func + ": " + "parsing " + Quote(e.Num) + ": " + e.Err.Error()
+}
+
+func (e *NumError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(fn, str string) *NumError {
+	return &NumError{Func: fn, Num: str, Err: ErrSyntax}
+}
+
+func rangeError(fn, str string) *NumError {
+	return &NumError{Func: fn, Num: str, Err: ErrRange}
+}
+
+func baseError(fn, str string, base int) *NumError {
+	return &NumError{Func: fn, Num: str, Err: errors.New("invalid base " + Itoa(base))}
+}
+
+func bitSizeError(fn, str string, bitSize int) *NumError {
+	return &NumError{Func: fn, Num: str, Err: errors.New("invalid bit size " + Itoa(bitSize))}
+}
+
+// Atoi is equivalent to ParseInt(s, 10, 0), converted to type int.
+func Atoi(s string) (int, error) {
+	n, err := ParseInt(s, 10, 0)
+	if err != nil {
+		nerr := err.(*NumError)
+		nerr.Func = "Atoi"
+	}
+	return int(n), err
+}
+
+// ParseInt interprets a string s in the given base (0, 2 to 36) and
+// bit size (0 to 64) and returns the corresponding value i.
+//
+// If the base argument is 0, the true base is implied by the string's
+// prefix following the sign (if present): 2 for "0b", 8 for "0" or "0o",
+// 16 for "0x", and 10 otherwise.
+//
+// The bitSize argument specifies the integer type
+// that the result must fit into. Bit sizes 0, 8, 16, 32, and 64
+// correspond to int, int8, int16, int32, and int64.
+// If bitSize is below 0 or above 64, an error is returned.
+func ParseInt(s string, base int, bitSize int) (int64, error) {
+	if s == "" {
+		return 0, syntaxError("ParseInt", s)
+	}
+	if bitSize == 0 {
+		bitSize = 64
+	} else if bitSize < 0 || bitSize > 64 {
+		return 0, bitSizeError("ParseInt", s, bitSize)
+	}
+	hi := 1<<uint(bitSize-1) - 1
+	switch s[0] {
+	case '+':
+		n, err := parseDigits("ParseInt", s, s[1:], base, -hi-1, hi, false)
+		return int64(n), err
+	case '-':
+		n, err := parseDigits("ParseInt", s, s[1:], base, -hi-1, hi, true)
+		return int64(n), err
+	default:
+		n, err := parseDigits("ParseInt", s, s, base, -hi-1, hi, false)
+		return int64(n), err
+	}
+}
+
+// ParseUint is like ParseInt but for unsigned numbers.
+// A sign prefix is not permitted.
+//
+// Emacs Lisp integers are signed, so for bitSize=64
+// the maximal accepted value is the same as for ParseInt.
+func ParseUint(s string, base int, bitSize int) (uint64, error) {
+	if s == "" {
+		return 0, syntaxError("ParseUint", s)
+	}
+	if bitSize == 0 || bitSize == 64 {
+		bitSize = 63
+	} else if bitSize < 0 || bitSize > 64 {
+		return 0, bitSizeError("ParseUint", s, bitSize)
+	}
+	hi := 1<<uint(bitSize) - 1
+	n, err := parseDigits("ParseUint", s, s, base, 0, hi, false)
+	return uint64(n), err
+}
+
+// parseDigits parses unsigned number digits with optional base prefix.
+// Value is accumulated in direction of its sign, so
+// the result is always in [lo, hi] range.
+// On overflow, the closest range boundary is returned.
+func parseDigits(fn, s0, s string, base, lo, hi int, neg bool) (int, error) {
+	if s == "" {
+		return 0, syntaxError(fn, s0)
+	}
+	switch {
+	case 2 <= base && base <= 36:
+		// Valid base; nothing to do.
+	case base == 0:
+		// Look for binary, octal or hex prefix.
+		base = 10
+		if s[0] == '0' {
+			if len(s) >= 3 && lower(rune(s[1])) == 'b' {
+				base = 2
+				s = s[2:]
+			} else if len(s) >= 3 && lower(rune(s[1])) == 'o' {
+				base = 8
+				s = s[2:]
+			} else if len(s) >= 3 && lower(rune(s[1])) == 'x' {
+				base = 16
+				s = s[2:]
+			} else {
+				base = 8
+			}
+		}
+	default:
+		return 0, baseError(fn, s0, base)
+	}
+
+	n := 0
+	for i := 0; i < len(s); i++ {
+		d := digitVal(rune(s[i]))
+		if d >= base {
+			return 0, syntaxError(fn, s0)
+		}
+		if neg {
+			if n < (lo+d)/base {
+				return lo, rangeError(fn, s0)
+			}
+			n = n*base - d
+		} else {
+			if n > (hi-d)/base {
+				return hi, rangeError(fn, s0)
+			}
+			n = n*base + d
+		}
+	}
+	return n, nil
+}
+
+// digitVal returns numeric value of digit c;
+// for non-digit characters 36 is returned.
+func digitVal(c rune) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= lower(c) && lower(c) <= 'z':
+		return int(lower(c)-'a') + 10
+	}
+	return 36
+}
+
+// lower returns lower case of ASCII letter c.
+func lower(c rune) rune {
+	return c | ('x' - 'X')
+}
+
+// ParseBool returns the boolean value represented by the string.
+// It accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.
+// Any other value returns an error.
+func ParseBool(str string) (bool, error) {
+	switch str {
+	case "1", "t", "T", "true", "TRUE", "True":
+		return true, nil
+	case "0", "f", "F", "false", "FALSE", "False":
+		return false, nil
+	}
+	return false, syntaxError("ParseBool", str)
+}
+
+// ParseFloat converts the string s to a floating-point number.
+// Decimal numbers (with optional exponent), "Inf" and "NaN"
+// are accepted. Hexadecimal floats are not supported.
+// The bitSize argument is ignored: all floats are float64.
+func ParseFloat(s string, bitSize int) (float64, error) {
+	switch lisp.Call("downcase", s).String() {
+	case "inf", "+inf", "infinity", "+infinity":
+		return lisp.Call("/", 1.0, 0.0).Float(), nil
+	case "-inf", "-infinity":
+		return lisp.Call("/", -1.0, 0.0).Float(), nil
+	case "nan", "+nan", "-nan":
+		return lisp.Call("abs", lisp.Call("/", 0.0, 0.0)).Float(), nil
+	}
+	if lisp.Not(lisp.Call("string-match-p", floatRx, s)) {
+		return 0, syntaxError("ParseFloat", s)
+	}
+	return lisp.Call("float", lisp.Call("string-to-number", s)).Float(), nil
+}
+
+// Regexp that matches valid decimal float literal.
+const floatRx = "\\`[-+]?\\([0-9]+\\.?[0-9]*\\|\\.[0-9]+\\)\\([eE][-+]?[0-9]+\\)?\\'"
@@ -0,0 +1,168 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+// Package strconv implements a subset of Go "strconv" package.
+//
+// Conversions are mapped onto Emacs Lisp "number-to-string",
+// "string-to-number" and "format" where possible.
+package strconv
+
+import (
+	"emacs/lisp"
+)
+
+// IntSize is the size in bits of an int or uint value.
+const IntSize = 64
+
+// Itoa is equivalent to FormatInt(int64(i), 10).
+func Itoa(i int) string {
+	return lisp.Call("number-to-string", i).String()
+}
+
+// FormatInt returns the string representation of i in the given base,
+// for 2 <= base <= 36. The result uses the lower-case letters 'a' to 'z'
+// for digit values >= 10.
+func FormatInt(i int64, base int) string {
+	if base < 2 || base > 36 {
+		panic("strconv: illegal AppendInt/FormatInt base")
+	}
+	if i < 0 {
+		return "-" + formatDigits(-int(i), base)
+	}
+	return formatDigits(int(i), base)
+}
+
+// FormatUint returns the string representation of i in the given base,
+// for 2 <= base <= 36. The result uses the lower-case letters 'a' to 'z'
+// for digit values >= 10.
+func FormatUint(i uint64, base int) string {
+	if base < 2 || base > 36 {
+		panic("strconv: illegal AppendInt/FormatInt base")
+	}
+	return formatDigits(int(i), base)
+}
+
+// formatDigits returns digits of non-negative n in given base.
+func formatDigits(n int, base int) string {
+	switch base {
+	case 8:
+		return lisp.Call("format", "%o", n).String()
+	case 10:
+		return lisp.Call("number-to-string", n).String()
+	case 16:
+		return lisp.Call("format", "%x", n).String()
+	}
+	if n == 0 {
+		return "0"
+	}
+	digits := ""
+	for n > 0 {
+		digits = lisp.Call("string", lisp.ArefString(digitChars, n%base)).String() + digits
+		n = n / base
+	}
+	return digits
+}
+
+const digitChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// FormatBool returns "true" or "false" according to the value of b.
+func FormatBool(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// FormatFloat converts the floating-point number f to a string,
+// according to the format fmt and precision prec.
+//
+// The format fmt is one of
+// 'e' (-d.dddde±dd, a decimal exponent),
+// 'E' (-d.ddddE±dd, a decimal exponent),
+// 'f' (-ddd.dddd, no exponent),
+// 'g' ('e' for large exponents, 'f' otherwise), or
+// 'G' ('E' for large exponents, 'f' otherwise).
+//
+// The special precision -1 uses the smallest number of digits
+// necessary to represent the value uniquely.
+// The bitSize argument is ignored: all floats are float64.
+func FormatFloat(f float64, fmt byte, prec, bitSize int) string {
+	if f != f {
+		return "NaN"
+	}
+	if f > maxFloat64 {
+		return "+Inf"
+	}
+	if f < -maxFloat64 {
+		return "-Inf"
+	}
+	if prec < 0 {
+		if fmt == 'g' || fmt == 'G' {
+			return shortestFloat(f)
+		}
+		// Emacs can not produce the shortest representation
+		// for other formats, so maximal precision is used.
+		prec = 17
+	}
+	spec := "%." + Itoa(prec) + lisp.Call("string", fmt).String()
+	return lisp.Call("format", spec, f).String()
+}
+
+// Largest finite float64 value.
+const maxFloat64 = 1.797693134862315708145274237317043567981e+308
+
+// shortestFloat returns the shortest representation of f.
+func shortestFloat(f float64) string {
+	s := lisp.Call("number-to-string", f).String()
+	// Emacs prints integral floats with ".0" suffix.
+	n := lisp.Length(s)
+	if n > 2 && s[n-2:] == ".0" {
+		return s[:n-2]
+	}
+	return s
+}
+
+// Quote returns a double-quoted Go string literal representing s.
+// The returned string uses Go escape sequences (\t, \n, \xFF)
+// for control characters.
+func Quote(s string) string {
+	res := "\""
+	for i := 0; i < lisp.Length(s); i++ {
+		res += quoteChar(lisp.ArefString(s, i), '"')
+	}
+	return res + "\""
+}
+
+// QuoteRune returns a single-quoted Go character literal representing the rune.
+func QuoteRune(r rune) string {
+	return "'" + quoteChar(r, '\'') + "'"
+}
+
+// quoteChar returns Go literal representation of character c
+// that is a part of the literal enclosed in quote characters.
+func quoteChar(c rune, quote rune) string {
+	if c == quote || c == '\\' {
+		return "\\" + lisp.Call("string", c).String()
+	}
+	switch c {
+	case '\a':
+		return `\a`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	case '\v':
+		return `\v`
+	}
+	if c < ' ' || c == 0x7f {
+		return `\x` + lisp.Call("format", "%02x", c).String()
+	}
+	return lisp.Call("string", c).String()
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+package strings
+
+// A Builder is used to efficiently build a string using Write methods.
+// Written strings are accumulated in a slice and concatenated
+// only when String is called.
+// The zero value is ready to use.
+type Builder struct {
+	parts []string
+	n     int // Length in bytes
+}
+
+// String returns the accumulated string.
+func (b *Builder) String() string {
+	if len(b.parts) > 1 {
+		b.parts[0] = Join(b.parts, "")
+		b.parts = b.parts[:1]
+	}
+	if len(b.parts) == 0 {
+		return ""
+	}
+	return b.parts[0]
+}
+
+// Len returns the number of accumulated bytes; b.Len() == len(b.String()).
+func (b *Builder) Len() int {
+	return b.n
+}
+
+// Reset resets the Builder to be empty.
+func (b *Builder) Reset() {
+	b.parts = nil
+	b.n = 0
+}
+
+// Grow is provided for compatibility; it does nothing.
+func (b *Builder) Grow(n int) {
+	if n < 0 {
+		panic("strings.Builder.Grow: negative count")
+	}
+}
+
+// WriteString appends the contents of s to b's buffer.
+// It returns the length of s and a nil error.
+func (b *Builder) WriteString(s string) (int, error) {
+	b.parts = append(b.parts, s)
+	b.n += len(s)
+	return len(s), nil
+}
+
+// WriteByte appends the byte c to b's buffer.
+// The returned error is always nil.
+func (b *Builder) WriteByte(c byte) error {
+	b.WriteString(charString(rune(c)))
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of Unicode code point r to b's buffer.
+// It returns the length of r and a nil error.
+func (b *Builder) WriteRune(r rune) (int, error) {
+	return b.WriteString(charString(r))
+}
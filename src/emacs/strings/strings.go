@@ -0,0 +1,360 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+// Package strings implements a subset of Go "strings" package.
+//
+// Functions are mapped onto Emacs Lisp string primitives
+// where possible. Indexes are byte offsets, like in Go.
+package strings
+
+import (
+	"emacs/lisp"
+	"emacs/unicode/utf8"
+)
+
+// Compare returns an integer comparing two strings lexicographically.
+// The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
+func Compare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a < b {
+		return -1
+	}
+	return +1
+}
+
+// Contains reports whether substr is within s.
+func Contains(s, substr string) bool {
+	return Index(s, substr) >= 0
+}
+
+// ContainsAny reports whether any Unicode code points in chars are within s.
+func ContainsAny(s, chars string) bool {
+	return IndexAny(s, chars) >= 0
+}
+
+// ContainsRune reports whether the Unicode code point r is within s.
+func ContainsRune(s string, r rune) bool {
+	return IndexRune(s, r) >= 0
+}
+
+// HasPrefix tests whether the string s begins with prefix.
+func HasPrefix(s, prefix string) bool {
+	return !lisp.Not(lisp.Call("string-prefix-p", prefix, s))
+}
+
+// HasSuffix tests whether the string s ends with suffix.
+func HasSuffix(s, suffix string) bool {
+	return !lisp.Not(lisp.Call("string-suffix-p", suffix, s))
+}
+
+// Index returns the index of the first instance of substr in s,
+// or -1 if substr is not present in s.
+func Index(s, substr string) int {
+	return byteIndex(s, lisp.Call("string-search", substr, s))
+}
+
+// IndexByte returns the index of the first instance of c in s,
+// or -1 if c is not present in s.
+func IndexByte(s string, c byte) int {
+	if c < utf8.RuneSelf {
+		return IndexRune(s, rune(c))
+	}
+	// Non-ASCII byte is a part of UTF-8 sequence, it is
+	// searched inside encoded string; unibyte string
+	// positions are byte indexes.
+	bytes := lisp.Call("encode-coding-string", s, lisp.Intern("utf-8"))
+	pos := lisp.Call("string-search", lisp.Call("unibyte-string", c), bytes)
+	if lisp.Not(pos) {
+		return -1
+	}
+	return pos.Int()
+}
+
+// IndexRune returns the index of the first instance of
+// the Unicode code point r, or -1 if rune is not present in s.
+func IndexRune(s string, r rune) int {
+	return byteIndex(s, lisp.Call("string-search", charString(r), s))
+}
+
+// IndexAny returns the index of the first instance of any
+// Unicode code point from chars in s, or -1 if no Unicode
+// code point from chars is present in s.
+func IndexAny(s, chars string) int {
+	for i := 0; i < lisp.Length(s); i++ {
+		ch := charString(lisp.ArefString(s, i))
+		if !lisp.Not(lisp.Call("string-search", ch, chars)) {
+			return byteOffset(s, i)
+		}
+	}
+	return -1
+}
+
+// LastIndex returns the index of the last instance of substr in s,
+// or -1 if substr is not present in s.
+func LastIndex(s, substr string) int {
+	if substr == "" {
+		return len(s)
+	}
+	last := lisp.Call("string-search", substr, s)
+	if lisp.Not(last) {
+		return -1
+	}
+	for last.Int()+1 < lisp.Length(s) {
+		pos := lisp.Call("string-search", substr, s, last.Int()+1)
+		if lisp.Not(pos) {
+			return byteIndex(s, last)
+		}
+		last = pos
+	}
+	return byteIndex(s, last)
+}
+
+// Count counts the number of non-overlapping instances of substr in s.
+// If substr is an empty string, Count returns 1 + the number of
+// Unicode code points in s.
+func Count(s, substr string) int {
+	if substr == "" {
+		return lisp.Length(s) + 1
+	}
+	n := 0
+	pos := lisp.Call("string-search", substr, s)
+	for !lisp.Not(pos) {
+		n++
+		pos = lisp.Call("string-search", substr, s, pos.Int()+lisp.Length(substr))
+	}
+	return n
+}
+
+// Split slices s into all substrings separated by sep and returns
+// a slice of the substrings between those separators.
+//
+// If sep is empty, Split splits after each UTF-8 sequence.
+func Split(s, sep string) []string {
+	return SplitN(s, sep, -1)
+}
+
+// SplitN slices s into substrings separated by sep and returns
+// a slice of the substrings between those separators.
+//
+// The count determines the number of substrings to return:
+//
+//	n > 0: at most n substrings; the last substring will be the unsplit remainder.
+//	n == 0: the result is nil (zero substrings)
+//	n < 0: all substrings
+func SplitN(s, sep string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	if sep == "" {
+		return explode(s, n)
+	}
+	if n < 0 {
+		return listToSlice(lisp.Call("split-string", s, lisp.Call("regexp-quote", sep)))
+	}
+	res := make([]string, 0, n)
+	for len(res) < n-1 {
+		pos := lisp.Call("string-search", sep, s)
+		if lisp.Not(pos) {
+			return append(res, s)
+		}
+		res = append(res, s[:pos.Int()])
+		s = s[pos.Int()+lisp.Length(sep):]
+	}
+	return append(res, s)
+}
+
+// Fields splits the string s around each instance of one or more
+// consecutive white space characters, returning a slice of substrings
+// of s or an empty slice if s contains only white space.
+func Fields(s string) []string {
+	return listToSlice(lisp.Call("split-string", s, spaceRx+"+", lisp.Intern("t")))
+}
+
+// Join concatenates the elements of its first argument to create
+// a single string. The separator string sep is placed
+// between elements in the resulting string.
+func Join(elems []string, sep string) string {
+	// Elements are collected into a list, so the
+	// result is built by a single "mapconcat" call.
+	list := lisp.Call("list")
+	for i := len(elems) - 1; i >= 0; i-- {
+		list = lisp.Call("cons", elems[i], list)
+	}
+	return lisp.MapConcat(lisp.Intern("identity"), list, sep)
+}
+
+// Repeat returns a new string consisting of count copies of the string s.
+//
+// It panics if count is negative.
+func Repeat(s string, count int) string {
+	if count < 0 {
+		panic("strings: negative Repeat count")
+	}
+	parts := lisp.Call("make-list", count, s)
+	return lisp.Call("apply", lisp.Intern("concat"), parts).String()
+}
+
+// Replace returns a copy of the string s with the first n
+// non-overlapping instances of old replaced by new.
+// If n < 0, there is no limit on the number of replacements.
+func Replace(s, old, new string, n int) string {
+	if old == new || n == 0 {
+		return s
+	}
+	if old == "" {
+		// Insert new before every character and at the end.
+		res := ""
+		for i := 0; i < lisp.Length(s) && (n < 0 || i < n); i++ {
+			res += new + charString(lisp.ArefString(s, i))
+		}
+		if n < 0 || lisp.Length(s) < n {
+			return res + new
+		}
+		return res + s[n:]
+	}
+	if n < 0 {
+		return lisp.Call("string-replace", old, new, s).String()
+	}
+	res := ""
+	for i := 0; i < n; i++ {
+		pos := lisp.Call("string-search", old, s)
+		if lisp.Not(pos) {
+			return res + s
+		}
+		res += s[:pos.Int()] + new
+		s = s[pos.Int()+lisp.Length(old):]
+	}
+	return res + s
+}
+
+// ReplaceAll returns a copy of the string s with all
+// non-overlapping instances of old replaced by new.
+func ReplaceAll(s, old, new string) string {
+	return Replace(s, old, new, -1)
+}
+
+// ToLower returns s with all Unicode letters mapped to their lower case.
+func ToLower(s string) string {
+	return lisp.Call("downcase", s).String()
+}
+
+// ToUpper returns s with all Unicode letters mapped to their upper case.
+func ToUpper(s string) string {
+	return lisp.Call("upcase", s).String()
+}
+
+// EqualFold reports whether s and t, interpreted as UTF-8 strings,
+// are equal under Unicode case-folding.
+func EqualFold(s, t string) bool {
+	return ToLower(s) == ToLower(t)
+}
+
+// TrimSpace returns a slice of the string s, with all leading
+// and trailing white space removed.
+func TrimSpace(s string) string {
+	return lisp.Call("string-trim", s, spaceRx+"+", spaceRx+"+").String()
+}
+
+// Trim returns a slice of the string s with all leading and
+// trailing Unicode code points contained in cutset removed.
+func Trim(s, cutset string) string {
+	return TrimRight(TrimLeft(s, cutset), cutset)
+}
+
+// TrimLeft returns a slice of the string s with all leading
+// Unicode code points contained in cutset removed.
+func TrimLeft(s, cutset string) string {
+	if s == "" || cutset == "" {
+		return s
+	}
+	return lisp.Call("string-trim-left", s, charsetRx(cutset)).String()
+}
+
+// TrimRight returns a slice of the string s, with all trailing
+// Unicode code points contained in cutset removed.
+func TrimRight(s, cutset string) string {
+	if s == "" || cutset == "" {
+		return s
+	}
+	return lisp.Call("string-trim-right", s, charsetRx(cutset)).String()
+}
+
+// TrimPrefix returns s without the provided leading prefix string.
+// If s doesn't start with prefix, s is returned unchanged.
+func TrimPrefix(s, prefix string) string {
+	if HasPrefix(s, prefix) {
+		return s[lisp.Length(prefix):]
+	}
+	return s
+}
+
+// TrimSuffix returns s without the provided trailing suffix string.
+// If s doesn't end with suffix, s is returned unchanged.
+func TrimSuffix(s, suffix string) string {
+	if HasSuffix(s, suffix) {
+		return s[:lisp.Length(s)-lisp.Length(suffix)]
+	}
+	return s
+}
+
+// Regexp that matches single ASCII white space character.
+const spaceRx = "[ \t\n\v\f\r]"
+
+// charsetRx returns regexp that matches one or more
+// characters from chars.
+func charsetRx(chars string) string {
+	return lisp.Call("regexp-opt-charset", lisp.Call("string-to-list", chars)).String() + "+"
+}
+
+// byteIndex converts character index pos that is returned
+// by search function to byte index.
+// Returns -1 if pos is nil (nothing found).
+func byteIndex(s string, pos lisp.Object) int {
+	if lisp.Not(pos) {
+		return -1
+	}
+	return byteOffset(s, pos.Int())
+}
+
+// byteOffset converts character index i to byte index.
+func byteOffset(s string, i int) int {
+	if !lisp.IsMultibyteString(s) {
+		return i
+	}
+	return lisp.StringBytes(s[:i])
+}
+
+// explode splits s into UTF-8 sequences (characters),
+// one per string, up to a maximum of n (n < 0 means no limit).
+func explode(s string, n int) []string {
+	l := utf8.RuneCountInString(s)
+	if n < 0 || n > l {
+		n = l
+	}
+	res := make([]string, n)
+	for i := 0; i < n-1; i++ {
+		res[i] = charString(lisp.ArefString(s, i))
+	}
+	if n > 0 {
+		res[n-1] = s[n-1:]
+	}
+	return res
+}
+
+// listToSlice converts list of strings to Go slice.
+func listToSlice(list lisp.Object) []string {
+	res := make([]string, 0, lisp.Length(list))
+	for !lisp.Not(list) {
+		res = append(res, lisp.Call("car", list).String())
+		list = lisp.Call("cdr", list)
+	}
+	return res
+}
+
+// charString returns string that contains a single character c.
+func charString(c rune) string {
+	return lisp.Call("string", c).String()
+}
@@ -0,0 +1,140 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE-go.txt file.
+
+// Package utf8 implements a subset of Go "unicode/utf8" package.
+//
+// Emacs Lisp strings are sequences of characters,
+// so most functions operate on characters directly
+// instead of decoding UTF-8 bytes.
+package utf8
+
+import (
+	"emacs/lisp"
+)
+
+// Numbers fundamental to the encoding.
+const (
+	RuneError = '\uFFFD'     // the "error" Rune or "Unicode replacement character"
+	RuneSelf  = 0x80         // characters below RuneSelf are represented as themselves in a single byte.
+	MaxRune   = '\U0010FFFF' // Maximum valid Unicode code point.
+	UTFMax    = 4            // maximum number of bytes of a UTF-8 encoded Unicode character.
+)
+
+// Surrogate range; not valid code points.
+const (
+	surrogateMin = 0xD800
+	surrogateMax = 0xDFFF
+)
+
+// RuneLen returns the number of bytes required to encode the rune.
+// It returns -1 if the rune is not a valid value to encode in UTF-8.
+func RuneLen(r rune) int {
+	switch {
+	case r < 0:
+		return -1
+	case r < RuneSelf:
+		return 1
+	case r < 0x800:
+		return 2
+	case surrogateMin <= r && r <= surrogateMax:
+		return -1
+	case r < 0x10000:
+		return 3
+	case r <= MaxRune:
+		return 4
+	}
+	return -1
+}
+
+// ValidRune reports whether r can be legally encoded as UTF-8.
+// Code points that are out of range or a surrogate half are illegal.
+func ValidRune(r rune) bool {
+	switch {
+	case 0 <= r && r < surrogateMin:
+		return true
+	case surrogateMax < r && r <= MaxRune:
+		return true
+	}
+	return false
+}
+
+// RuneCountInString returns the number of runes in s.
+func RuneCountInString(s string) int {
+	return lisp.Length(s)
+}
+
+// ValidString reports whether s consists entirely of valid UTF-8-encoded runes.
+// Emacs raw bytes are reported as invalid runes.
+func ValidString(s string) bool {
+	multibyte := lisp.IsMultibyteString(s)
+	for i := 0; i < lisp.Length(s); i++ {
+		ch := lisp.ArefString(s, i)
+		if multibyte {
+			if _, size := decodeChar(ch); size == 1 && ch >= RuneSelf {
+				return false
+			}
+		} else if ch >= RuneSelf {
+			return false // Unibyte strings contain raw bytes
+		}
+	}
+	return true
+}
+
+// DecodeRuneInString unpacks the first UTF-8 encoding in s and returns
+// the rune and its width in bytes.
+// If s is empty it returns (RuneError, 0).
+// If s starts with invalid encoding, it returns (RuneError, 1).
+func DecodeRuneInString(s string) (rune, int) {
+	if s == "" {
+		return RuneError, 0
+	}
+	return decodeChar(lisp.ArefString(s, 0))
+}
+
+// DecodeLastRuneInString is like DecodeRuneInString, but
+// unpacks the last UTF-8 encoding in s.
+func DecodeLastRuneInString(s string) (rune, int) {
+	if s == "" {
+		return RuneError, 0
+	}
+	return decodeChar(lisp.ArefString(s, lisp.Length(s)-1))
+}
+
+// decodeChar returns rune and its encoded width for Emacs character.
+func decodeChar(ch rune) (rune, int) {
+	if ValidRune(ch) {
+		return ch, RuneLen(ch)
+	}
+	return RuneError, 1 // Raw byte or invalid code point
+}
+
+// EncodeRune writes into p (which must be large enough)
+// the UTF-8 encoding of the rune.
+// If the rune is out of range, it writes the encoding of RuneError.
+// It returns the number of bytes written.
+func EncodeRune(p []byte, r rune) int {
+	if !ValidRune(r) {
+		r = RuneError
+	}
+	switch RuneLen(r) {
+	case 1:
+		p[0] = byte(r)
+		return 1
+	case 2:
+		p[0] = byte(0xC0 | r>>6)
+		p[1] = byte(0x80 | r&0x3F)
+		return 2
+	case 3:
+		p[0] = byte(0xE0 | r>>12)
+		p[1] = byte(0x80 | (r>>6)&0x3F)
+		p[2] = byte(0x80 | r&0x3F)
+		return 3
+	default:
+		p[0] = byte(0xF0 | r>>18)
+		p[1] = byte(0x80 | (r>>12)&0x3F)
+		p[2] = byte(0x80 | (r>>6)&0x3F)
+		p[3] = byte(0x80 | r&0x3F)
+		return 4
+	}
+}
@@ -0,0 +1,264 @@
+// Package ffi generates "emacs/lisp" package FFI declarations.
+//
+// FFI spec is a sequence of Lisp forms; each form declares
+// single Emacs Lisp function:
+//
+//	(sym GoName (:type arg ...) :ret "Documentation.")
+//
+// Parameter names that start with "&" are variadic.
+// Function types are written as vectors: [(:type arg ...) :ret].
+// Documentation string is optional.
+package ffi
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+	"vm"
+)
+
+// Decl is a single FFI function declaration.
+type Decl struct {
+	LispName string
+	GoName   string
+	Params   []Param
+	Result   Type
+	Doc      string
+}
+
+// Param is a named function parameter.
+type Param struct {
+	Name     string
+	Typ      Type
+	Variadic bool
+}
+
+// Type is either a type keyword, like ":int",
+// or a function type.
+type Type struct {
+	Keyword string
+	Func    *FuncType // Non-nil for function types
+}
+
+// FuncType is a signature of function type.
+type FuncType struct {
+	Params []Param
+	Result Type
+}
+
+// typeNames maps type keywords that are permitted
+// inside FFI signatures to Go type names.
+var typeNames = map[string]string{
+	":any":    "any",    // Can not be used for output type
+	":object": "Object", // lisp.Object interface type
+	":void":   "",       // Discards function result right after the call
+	":symbol": "Symbol",
+	":bool":   "bool",
+	":int":    "int",
+	":char":   "rune",
+	":string": "string",
+	":float":  "float64",
+
+	// Opaque object types; values are not checked when
+	// they are passed to or returned from FFI functions.
+	":buffer":     "Buffer",
+	":marker":     "Marker",
+	":window":     "Window",
+	":process":    "Process",
+	":hash-table": "HashTable",
+	":cons":       "Cons",
+	":list":       "List",
+}
+
+var identRx = regexp.MustCompile(`^[_\pL][_0-9\pL]*$`)
+
+// Parse reads FFI spec.
+func Parse(src string) (decls []*Decl, err error) {
+	m := vm.New()
+	spec, err := vm.Read(m, "("+src+"\n)")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			specErr, ok := r.(specError)
+			if !ok {
+				panic(r)
+			}
+			err = specErr
+		}
+	}()
+
+	names := make(map[string]bool)
+	for _, form := range listElems(spec) {
+		decl := parseDecl(form)
+		if names[decl.GoName] {
+			blame("duplicated Go name `%s'", decl.GoName)
+		}
+		names[decl.GoName] = true
+		decls = append(decls, decl)
+	}
+	return decls, nil
+}
+
+// Generate returns Go source of "emacs/lisp" FFI file.
+// Each function gets "//goism:" directive that binds
+// it to Emacs Lisp function.
+//
+// Output is not passed through gofmt: it would
+// separate "//goism:" directives from comment text.
+func Generate(decls []*Decl) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("package lisp\n\n")
+	buf.WriteString("// This package is special.\n")
+	buf.WriteString("// Code generated by goism_gen_ffi from FFI spec. DO NOT EDIT.\n")
+	for _, decl := range decls {
+		buf.WriteByte('\n')
+		buf.WriteString(docComment(decl))
+		fmt.Fprintf(&buf, "//\n//goism:\"%s\"->\"%s\"\n", decl.GoName, decl.LispName)
+		fmt.Fprintf(&buf, "func %s%s\n", decl.GoName, signature(decl.Params, decl.Result))
+	}
+	return buf.Bytes()
+}
+
+// docComment formats declaration documentation as Go comment.
+// Signature part of Emacs documentation is dropped.
+func docComment(decl *Decl) string {
+	doc := decl.Doc
+	if i := strings.Index(doc, "\n\n(fn"); i != -1 {
+		doc = doc[:i]
+	}
+	if doc == "" {
+		doc = "extern Emacs Lisp function."
+	}
+	lines := strings.Split(strings.TrimRight(doc, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+line, " ")
+	}
+	lines[0] = "// " + decl.GoName + " = " + strings.TrimPrefix(lines[0], "// ")
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func signature(params []Param, result Type) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		if p.Variadic {
+			parts[i] = p.Name + " ..." + typeName(p.Typ)
+		} else {
+			parts[i] = p.Name + " " + typeName(p.Typ)
+		}
+	}
+	sig := "(" + strings.Join(parts, ", ") + ")"
+	if res := typeName(result); res != "" {
+		sig += " " + res
+	}
+	return sig
+}
+
+func typeName(typ Type) string {
+	if typ.Func != nil {
+		return "func" + signature(typ.Func.Params, typ.Func.Result)
+	}
+	return typeNames[typ.Keyword]
+}
+
+type specError string
+
+func (e specError) Error() string { return string(e) }
+
+func blame(format string, args ...interface{}) {
+	panic(specError(fmt.Sprintf(format, args...)))
+}
+
+func parseDecl(form vm.Object) *Decl {
+	elems := listElems(form)
+	if len(elems) != 4 && len(elems) != 5 {
+		blame("%s: expected (sym GoName (params...) result [doc])", vm.Prin1ToString(form))
+	}
+	decl := &Decl{
+		LispName: symbolName(elems[0]),
+		GoName:   ident(symbolName(elems[1])),
+	}
+	decl.Params, decl.Result = parseSignature(decl.LispName, elems[2], elems[3])
+	if len(elems) == 5 {
+		doc, ok := elems[4].(string)
+		if !ok {
+			blame("%s: documentation must be a string", decl.LispName)
+		}
+		decl.Doc = doc
+	}
+	return decl
+}
+
+func parseSignature(name string, params, result vm.Object) ([]Param, Type) {
+	elems := listElems(params)
+	if len(elems)%2 != 0 {
+		blame("%s: params must be (:type name) pairs", name)
+	}
+	res := make([]Param, 0, len(elems)/2)
+	for i := 0; i < len(elems); i += 2 {
+		p := Param{
+			Name: symbolName(elems[i+1]),
+			Typ:  parseType(name, elems[i]),
+		}
+		if strings.HasPrefix(p.Name, "&") {
+			if i != len(elems)-2 {
+				blame("%s: only last param can be variadic", name)
+			}
+			p.Name, p.Variadic = p.Name[1:], true
+		}
+		p.Name = ident(p.Name)
+		res = append(res, p)
+	}
+	typ := parseType(name, result)
+	if typ.Keyword == ":any" {
+		blame("%s: can not use `:any' type as function output type", name)
+	}
+	return res, typ
+}
+
+func parseType(name string, x vm.Object) Type {
+	if vec, ok := x.(*vm.Vector); ok {
+		if len(vec.Elems) != 2 {
+			blame("%s: function type must be [(params...) result]", name)
+		}
+		params, result := parseSignature(name, vec.Elems[0], vec.Elems[1])
+		return Type{Func: &FuncType{Params: params, Result: result}}
+	}
+	kw := symbolName(x)
+	if _, ok := typeNames[kw]; !ok {
+		blame("%s: unsupported type `%s'", name, kw)
+	}
+	return Type{Keyword: kw}
+}
+
+// ident validates and then returns back passed identifier name.
+func ident(name string) string {
+	if !identRx.MatchString(name) || token.Lookup(name).IsKeyword() {
+		blame("invalid Go identifier `%s'", name)
+	}
+	return name
+}
+
+func symbolName(x vm.Object) string {
+	sym, ok := x.(*vm.Symbol)
+	if !ok || sym == vm.Nil {
+		blame("%s: symbol expected", vm.Prin1ToString(x))
+	}
+	return sym.Name
+}
+
+func listElems(x vm.Object) []vm.Object {
+	var elems []vm.Object
+	for !vm.IsNil(x) {
+		cons, ok := x.(*vm.Cons)
+		if !ok {
+			blame("%s: list expected", vm.Prin1ToString(x))
+		}
+		elems = append(elems, cons.Car)
+		x = cons.Cdr
+	}
+	return elems
+}
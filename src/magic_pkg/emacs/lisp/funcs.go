@@ -29,6 +29,7 @@ var (
 	FnMapconcat         = &Func{Sym: "mapconcat"}
 	FnIsMultibyteString = &Func{Sym: "multibyte-string-p"}
 	FnPrin1ToString     = &Func{Sym: "prin1-to-string"}
+	FnApplyPartially    = &Func{Sym: "apply-partially"}
 
 	FnCopySequence   = &Func{Sym: "copy-sequence"}
 	FnIntern         = &Func{Sym: "intern"}
@@ -52,11 +53,14 @@ var (
 	FnIsFloat  = &Func{Sym: "floatp"}
 	FnIsSymbol = &Func{Sym: "symbolp"}
 	FnIsBool   = &Func{Sym: "booleanp"}
+	FnIsCons   = &Func{Sym: "consp"}
 	FnList     = &Func{Sym: "list"}
 
 	FnCons   = &Func{Sym: "cons"}
 	FnCar    = &Func{Sym: "car"}
 	FnCdr    = &Func{Sym: "cdr"}
+	FnSetcar = &Func{Sym: "setcar"}
+	FnNth    = &Func{Sym: "nth"}
 	FnAref   = &Func{Sym: "aref"}
 	FnAset   = &Func{Sym: "aset"}
 	FnMemq   = &Func{Sym: "memq"}
@@ -76,6 +80,7 @@ var (
 	FnSub    = &Func{Sym: "-"}
 	FnMul    = &Func{Sym: "*"}
 	FnQuo    = &Func{Sym: "/"}
+	FnRem    = &Func{Sym: "%"}
 	FnStrEq  = &Func{Sym: "string="}
 	FnStrLt  = &Func{Sym: "string<"}
 	FnStrGt  = &Func{Sym: "string>"}
@@ -116,6 +121,7 @@ func initFuncs() error {
 			FnMapconcat,
 			FnIsMultibyteString,
 			FnPrin1ToString,
+			FnApplyPartially,
 			FnCopySequence,
 			FnIntern,
 			FnGethash,
@@ -136,10 +142,13 @@ func initFuncs() error {
 			FnIsFloat,
 			FnIsSymbol,
 			FnIsBool,
+			FnIsCons,
 			FnList,
 			FnCons,
 			FnCar,
 			FnCdr,
+			FnSetcar,
+			FnNth,
 			FnAref,
 			FnAset,
 			FnMemq,
@@ -155,6 +164,7 @@ func initFuncs() error {
 			FnSub,
 			FnMul,
 			FnQuo,
+			FnRem,
 			FnStrEq,
 			FnStrLt,
 			FnStrGt,
@@ -1,3 +1,27 @@
 package lisp
 
+import (
+	"bytes"
+	"strings"
+)
+
 type Symbol string
+
+// Literal returns symbol printed representation that
+// can be read back by the Emacs Lisp reader.
+func (sym Symbol) Literal() string {
+	if strings.IndexAny(string(sym), symbolSpecialChars) == -1 {
+		return string(sym) // Fast path: nothing to escape
+	}
+	var buf bytes.Buffer
+	for _, c := range string(sym) {
+		if strings.ContainsRune(symbolSpecialChars, c) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+// Characters that must be escaped inside symbol name.
+const symbolSpecialChars = " \t\n()[]\"';#`,?\\"
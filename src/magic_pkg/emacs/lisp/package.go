@@ -9,6 +9,15 @@ var Package *types.Package
 var (
 	TypObject *types.Named
 	TypSymbol *types.Named
+	TypAny    *types.Named
+
+	TypBuffer    *types.Named
+	TypMarker    *types.Named
+	TypWindow    *types.Named
+	TypProcess   *types.Named
+	TypHashTable *types.Named
+	TypCons      *types.Named
+	TypList      *types.Named
 )
 
 func InitPackage(pkg *types.Package) error {
@@ -21,6 +30,15 @@ func InitPackage(pkg *types.Package) error {
 
 	TypObject = getNamed("Object")
 	TypSymbol = getNamed("Symbol")
+	TypAny = getNamed("any")
+
+	TypBuffer = getNamed("Buffer")
+	TypMarker = getNamed("Marker")
+	TypWindow = getNamed("Window")
+	TypProcess = getNamed("Process")
+	TypHashTable = getNamed("HashTable")
+	TypCons = getNamed("Cons")
+	TypList = getNamed("List")
 
 	return initFuncs()
 }
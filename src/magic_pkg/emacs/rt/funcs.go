@@ -10,7 +10,16 @@ import (
 var FnIfaceCall [5]*sexp.Func
 
 var (
-	FnMakeIface *sexp.Func
+	FnMakeIface    *sexp.Func
+	FnTypeAssert   *sexp.Func
+	FnTypeAssertOk *sexp.Func
+
+	FnTypeAssertIface   *sexp.Func
+	FnTypeAssertIfaceOk *sexp.Func
+	FnIfaceConvert      *sexp.Func
+	FnIfaceEq           *sexp.Func
+
+	FnPanicIface *sexp.Func
 
 	FnPanic   *sexp.Func
 	FnPrint   *sexp.Func
@@ -46,6 +55,26 @@ var (
 	FnCoerceFloat  *sexp.Func
 	FnCoerceString *sexp.Func
 	FnCoerceSymbol *sexp.Func
+
+	FnCoerceBuffer    *sexp.Func
+	FnCoerceMarker    *sexp.Func
+	FnCoerceWindow    *sexp.Func
+	FnCoerceProcess   *sexp.Func
+	FnCoerceHashTable *sexp.Func
+	FnCoerceCons      *sexp.Func
+	FnCoerceList      *sexp.Func
+
+	FnSliceToVector  *sexp.Func
+	FnSliceToList    *sexp.Func
+	FnListToSlice    *sexp.Func
+	FnMapToAlist     *sexp.Func
+	FnAlistToMap     *sexp.Func
+	FnMapToPlist     *sexp.Func
+	FnPlistToMap     *sexp.Func
+	FnCustomGetSlice *sexp.Func
+	FnCustomSetSlice *sexp.Func
+	FnCustomGetMap   *sexp.Func
+	FnCustomSetMap   *sexp.Func
 )
 
 func InitFuncs(ftab *symbols.FuncTable) {
@@ -61,6 +90,13 @@ func InitFuncs(ftab *symbols.FuncTable) {
 	}
 
 	FnMakeIface = mustFindFunc("MakeIface")
+	FnTypeAssert = mustFindFunc("TypeAssert")
+	FnTypeAssertOk = mustFindFunc("TypeAssertOk")
+	FnTypeAssertIface = mustFindFunc("TypeAssertIface")
+	FnTypeAssertIfaceOk = mustFindFunc("TypeAssertIfaceOk")
+	FnIfaceConvert = mustFindFunc("IfaceConvert")
+	FnIfaceEq = mustFindFunc("IfaceEq")
+	FnPanicIface = mustFindFunc("PanicIface")
 
 	FnPanic = mustFindFunc("Panic")
 	FnPrint = mustFindFunc("Print")
@@ -96,4 +132,23 @@ func InitFuncs(ftab *symbols.FuncTable) {
 	FnCoerceFloat = mustFindFunc("CoerceFloat")
 	FnCoerceString = mustFindFunc("CoerceString")
 	FnCoerceSymbol = mustFindFunc("CoerceSymbol")
+	FnCoerceBuffer = mustFindFunc("CoerceBuffer")
+	FnCoerceMarker = mustFindFunc("CoerceMarker")
+	FnCoerceWindow = mustFindFunc("CoerceWindow")
+	FnCoerceProcess = mustFindFunc("CoerceProcess")
+	FnCoerceHashTable = mustFindFunc("CoerceHashTable")
+	FnCoerceCons = mustFindFunc("CoerceCons")
+	FnCoerceList = mustFindFunc("CoerceList")
+
+	FnSliceToVector = mustFindFunc("SliceToVector")
+	FnSliceToList = mustFindFunc("SliceToList")
+	FnListToSlice = mustFindFunc("ListToSlice")
+	FnMapToAlist = mustFindFunc("MapToAlist")
+	FnAlistToMap = mustFindFunc("AlistToMap")
+	FnMapToPlist = mustFindFunc("MapToPlist")
+	FnPlistToMap = mustFindFunc("PlistToMap")
+	FnCustomGetSlice = mustFindFunc("CustomGetSlice")
+	FnCustomSetSlice = mustFindFunc("CustomSetSlice")
+	FnCustomGetMap = mustFindFunc("CustomGetMap")
+	FnCustomSetMap = mustFindFunc("CustomSetMap")
 }
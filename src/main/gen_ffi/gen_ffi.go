@@ -0,0 +1,31 @@
+package main
+
+import (
+	"ffi"
+	"fmt"
+	"io/ioutil"
+	"main/util"
+)
+
+func init() {
+	program := &util.ProgramInfo
+	program.Description =
+		"Generate \"emacs/lisp\" FFI declarations from spec file."
+	program.Name = "goism_gen_ffi"
+}
+
+func main() {
+	util.ParseArgv(util.ArgvSchema{
+		"spec": {
+			Help: "Path to FFI spec file (like lisp/ffi/default-ffi.el)",
+			Req:  true,
+		},
+	})
+
+	src, err := ioutil.ReadFile(util.Argv("spec"))
+	util.CheckError(err)
+	decls, err := ffi.Parse(string(src))
+	util.CheckError(err)
+
+	fmt.Print(string(ffi.Generate(decls)))
+}
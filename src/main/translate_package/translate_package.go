@@ -1,14 +1,19 @@
 package main
 
 import (
+	"backends/el"
 	"backends/lapc"
 	"backends/lapc/compiler"
 	"backends/lapc/export"
+	"cfg"
 	"exn"
 	"fmt"
 	"main/util"
+	"opt"
+	"os"
 	"regexp"
 	"sexp"
+	"strconv"
 	"strings"
 	"tu"
 	"tu/load"
@@ -17,7 +22,9 @@ import (
 func init() {
 	program := &util.ProgramInfo
 	program.Description =
-		"Translate single Go package into IR format."
+		"Translate single Go package into IR format (default), " +
+			"byte-compiled Emacs Lisp (elc), Emacs Lisp source (el), " +
+			"readable bytecode (asm) or tail call report (tailcalls)."
 	program.Name = "goism_translate_package"
 }
 
@@ -28,7 +35,7 @@ func main() {
 			Req:  true,
 		},
 		"output": {
-			Help: "Produced output: {pkg|asm}",
+			Help: "Produced output: {pkg|asm|elc|el|tailcalls}",
 			Init: "pkg",
 			Enum: true,
 		},
@@ -39,18 +46,37 @@ func main() {
 		"filter": {
 			Help: "Regexp to filter 'output=asm' symbols",
 		},
+		"emacs": {
+			Help: "Target Emacs major version",
+			Init: strconv.Itoa(cfg.TargetEmacsVersion),
+		},
+		"version": {
+			Help: "Package version, unless specified by package docs",
+			Init: cfg.PackageVersion,
+		},
 	})
 
 	defer func() { util.CheckError(exn.Catch(recover())) }()
 
+	emacsVersion, err := strconv.Atoi(util.Argv("emacs"))
+	util.CheckError(err)
+	cfg.TargetEmacsVersion = emacsVersion
+	cfg.PackageVersion = util.Argv("version")
+
 	util.CheckError(load.Runtime())
 	pkg := loadPackage(util.Argv("pkgPath"), util.Argv("opt") != "false")
 
 	switch util.Argv("output") {
 	case "pkg":
-		producePackage(pkg)
+		producePackage(pkg, export.NewBuilder(pkg))
+	case "elc":
+		producePackage(pkg, export.NewElcBuilder(pkg))
+	case "el":
+		produceEl(pkg)
 	case "asm":
 		produceAsm(pkg)
+	case "tailcalls":
+		produceTailCalls(pkg)
 	}
 }
 
@@ -95,10 +121,21 @@ func produceAsm(pkg *tu.Package) {
 	}
 }
 
-func producePackage(pkg *tu.Package) {
-	cl := compiler.New()
+// produceTailCalls prints tail calls that were not eliminated.
+func produceTailCalls(pkg *tu.Package) {
+	opt.WriteTailCalls(os.Stdout, pkg.FileSet, pkg.Funcs)
+}
 
-	output := export.NewBuilder(pkg)
+// packageBuilder is implemented by export package builders.
+type packageBuilder interface {
+	AddVars(names []string)
+	AddFunc(fn *sexp.Func, obj *lapc.Object)
+	AddExpr(obj *lapc.Object)
+	Build() []byte
+}
+
+func producePackage(pkg *tu.Package, output packageBuilder) {
+	cl := compiler.New()
 
 	if len(pkg.Vars) != 0 {
 		output.AddVars(pkg.Vars)
@@ -117,6 +154,28 @@ func producePackage(pkg *tu.Package) {
 	fmt.Print(string(output.Build()))
 }
 
+// produceEl prints Emacs Lisp source of the package.
+// Unlike other outputs, it does not involve lapc compiler.
+func produceEl(pkg *tu.Package) {
+	output := el.NewBuilder(pkg)
+
+	if len(pkg.Vars) != 0 {
+		output.AddVars(pkg.Vars)
+	}
+
+	for _, fn := range pkg.Funcs {
+		if !fn.IsSubst() {
+			output.AddFunc(fn)
+		}
+	}
+
+	if len(pkg.Init.Body) != 0 {
+		output.AddInit(pkg.Init)
+	}
+
+	fmt.Print(string(output.Build()))
+}
+
 func compileFunc(cl *compiler.Compiler, fn *sexp.Func) *lapc.Object {
 	lapc.Simplify(fn.Body)
 	return cl.CompileFunc(fn)
@@ -0,0 +1,261 @@
+package opt
+
+import (
+	"sexp"
+)
+
+// EliminateDeadStores removes local variable assignments
+// whose values are never read and bindings of unused variables.
+// Side effects of removed initializers are preserved.
+func EliminateDeadStores(fn *sexp.Func) bool {
+	dse := deadStoreEliminator{params: fn.Params}
+	fn.Body = dse.walkScope(fn.Body, true)
+	return dse.triggered
+}
+
+type deadStoreEliminator struct {
+	params    []string
+	triggered bool
+}
+
+// walkScope processes statement list that forms a lexical scope.
+// Nested FormList members are flattened into it.
+// Function parameters belong to the top level scope.
+func (dse *deadStoreEliminator) walkScope(forms []sexp.Form, top bool) sexp.Block {
+	forms = flattenFormLists(forms)
+	for i, form := range forms {
+		forms[i] = dse.walkStmt(form)
+	}
+
+	for i, form := range forms {
+		switch form := form.(type) {
+		case *sexp.Bind:
+			forms[i] = dse.deadBind(form, forms[i+1:])
+		case *sexp.Rebind:
+			local := top && isParam(dse.params, form.Name)
+			for _, x := range forms[:i] {
+				if bind, ok := x.(*sexp.Bind); ok && bind.Name == form.Name {
+					local = true
+				}
+			}
+			forms[i] = dse.deadRebind(form, forms[i+1:], local)
+		}
+	}
+
+	return removeEmptyForms(forms)
+}
+
+func (dse *deadStoreEliminator) walkStmt(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case sexp.Block:
+		return dse.walkScope(form, false)
+	case *sexp.If:
+		form.Then = dse.walkScope(form.Then, false)
+		form.Else = dse.walkStmt(form.Else)
+	case *sexp.Switch:
+		dse.walkSwitchBody(&form.SwitchBody)
+	case *sexp.SwitchTrue:
+		dse.walkSwitchBody(&form.SwitchBody)
+	case *sexp.Repeat:
+		form.Body = dse.walkScope(form.Body, false)
+	case *sexp.DoTimes:
+		form.Body = dse.walkScope(form.Body, false)
+	case *sexp.Loop:
+		form.Body = dse.walkScope(form.Body, false)
+	case *sexp.While:
+		form.Body = dse.walkScope(form.Body, false)
+	case *sexp.SpecBind:
+		form.Body = dse.walkScope(form.Body, false)
+	case *sexp.Scope:
+		form.Body = dse.walkScope(form.Body, false)
+	}
+	return form
+}
+
+func (dse *deadStoreEliminator) walkSwitchBody(b *sexp.SwitchBody) {
+	for i := range b.Clauses {
+		b.Clauses[i].Body = dse.walkScope(b.Clauses[i].Body, false)
+	}
+	b.DefaultBody = dse.walkScope(b.DefaultBody, false)
+}
+
+// deadBind handles binding that is followed by rest of its scope.
+func (dse *deadStoreEliminator) deadBind(form *sexp.Bind, rest []sexp.Form) sexp.Form {
+	usage := inspectLocal(form.Name, rest...)
+	if usage.shadowed {
+		return form
+	}
+	if usage.reads == 0 {
+		// Variable is never read: all its stores are dead.
+		for i := range rest {
+			rest[i] = discardRebinds(form.Name, rest[i])
+		}
+		dse.triggered = true
+		return discardValue(form.Init)
+	}
+	if !isPure(form.Init) {
+		return form
+	}
+	// Initial value is overwritten before it is read:
+	// binding is moved to the overwriting assignment.
+	if kill := findKill(form.Name, rest); kill >= 0 {
+		rebind := rest[kill].(*sexp.Rebind)
+		rest[kill] = &sexp.Bind{
+			StmtPos: rebind.StmtPos,
+			Name:    rebind.Name,
+			Init:    rebind.Expr,
+		}
+		dse.triggered = true
+		return sexp.EmptyForm
+	}
+	return form
+}
+
+// deadRebind handles assignment that is followed by rest of the list.
+// If local is true, variable is not visible after the list end.
+func (dse *deadStoreEliminator) deadRebind(form *sexp.Rebind, rest []sexp.Form, local bool) sexp.Form {
+	if findKill(form.Name, rest) >= 0 {
+		dse.triggered = true
+		return discardValue(form.Expr)
+	}
+	if local && !hasJumps(rest...) && !inspectLocal(form.Name, rest...).mentioned() {
+		dse.triggered = true
+		return discardValue(form.Expr)
+	}
+	return form
+}
+
+// findKill returns index of assignment inside forms that
+// overwrites variable before any other use of it.
+// Returns -1 if there is no such assignment.
+func findKill(name string, forms []sexp.Form) int {
+	for i, form := range forms {
+		if rebind, ok := form.(*sexp.Rebind); ok && rebind.Name == name {
+			if inspectLocal(name, rebind.Expr).mentioned() {
+				return -1
+			}
+			return i
+		}
+		if hasJumps(form) || inspectLocal(name, form).mentioned() {
+			return -1
+		}
+	}
+	return -1
+}
+
+// localUsage describes how variable is used.
+type localUsage struct {
+	reads    int
+	rebinds  int
+	shadowed bool
+}
+
+func (u localUsage) mentioned() bool {
+	return u.reads != 0 || u.rebinds != 0 || u.shadowed
+}
+
+func inspectLocal(name string, forms ...sexp.Form) localUsage {
+	var u localUsage
+	for _, form := range forms {
+		sexp.Walk(form, func(form sexp.Form) bool {
+			switch form := form.(type) {
+			case sexp.Local:
+				if form.Name == name {
+					u.reads++
+				}
+			case *sexp.Bind:
+				u.shadowed = u.shadowed || form.Name == name
+			case *sexp.Rebind:
+				if form.Name == name {
+					u.rebinds++
+				}
+			case *sexp.DoTimes:
+				u.shadowed = u.shadowed || form.Iter.Name == name
+			}
+			return true
+		})
+	}
+	return u
+}
+
+// hasJumps reports whether form contains goto or label.
+func hasJumps(forms ...sexp.Form) bool {
+	found := false
+	for _, form := range forms {
+		sexp.Walk(form, func(form sexp.Form) bool {
+			switch form.(type) {
+			case *sexp.Goto, *sexp.Label:
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// discardRebinds replaces all assignments to name inside form.
+func discardRebinds(name string, form sexp.Form) sexp.Form {
+	return sexp.Rewrite(form, func(form sexp.Form) sexp.Form {
+		if form, ok := form.(*sexp.Rebind); ok && form.Name == name {
+			return discardValue(form.Expr)
+		}
+		return nil
+	})
+}
+
+// discardValue returns statement that evaluates form
+// only for its side effects.
+func discardValue(form sexp.Form) sexp.Form {
+	if isPure(form) {
+		return sexp.EmptyForm
+	}
+	return &sexp.ExprStmt{Expr: form}
+}
+
+// isPure reports whether form evaluation has no side effects
+// and never signals an error.
+func isPure(form sexp.Form) bool {
+	switch form := form.(type) {
+	case sexp.Int, sexp.Float, sexp.Str, sexp.Bool, sexp.Symbol, sexp.Local, sexp.Var:
+		return true
+	case *sexp.TypeCast:
+		return isPure(form.Form)
+	}
+	return sexp.IsEmptyForm(form)
+}
+
+func isParam(params []string, name string) bool {
+	for _, param := range params {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenFormLists(forms []sexp.Form) []sexp.Form {
+	for _, form := range forms {
+		if _, ok := form.(sexp.FormList); ok {
+			flat := make([]sexp.Form, 0, len(forms)+4)
+			for _, form := range forms {
+				if list, ok := form.(sexp.FormList); ok {
+					flat = append(flat, flattenFormLists(list)...)
+				} else {
+					flat = append(flat, form)
+				}
+			}
+			return flat
+		}
+	}
+	return forms
+}
+
+func removeEmptyForms(forms []sexp.Form) sexp.Block {
+	res := forms[:0]
+	for _, form := range forms {
+		if !sexp.IsEmptyForm(form) {
+			res = append(res, form)
+		}
+	}
+	return sexp.Block(res)
+}
@@ -2,6 +2,7 @@ package opt
 
 import (
 	"magic_pkg/emacs/lisp"
+	"math"
 	"sexp"
 )
 
@@ -39,6 +40,69 @@ func (p *constexprPass) foldLispCall(form *sexp.LispCall) sexp.Form {
 		if x, ok := form.Args[0].(sexp.Int); ok {
 			return sexp.Int(x - 1)
 		}
+	case lisp.FnNot:
+		if x, ok := form.Args[0].(sexp.Bool); ok {
+			return sexp.Bool(!x)
+		}
+
+	case lisp.FnAdd, lisp.FnSub, lisp.FnMul:
+		if x, y, ok := intArgs(form); ok {
+			if res, ok := foldArith(form.Fn, x, y); ok {
+				return sexp.Int(res)
+			}
+		}
+
+	case lisp.FnNumEq:
+		if x, y, ok := intArgs(form); ok {
+			return sexp.Bool(x == y)
+		}
+	case lisp.FnNumLt:
+		if x, y, ok := intArgs(form); ok {
+			return sexp.Bool(x < y)
+		}
+	case lisp.FnNumGt:
+		if x, y, ok := intArgs(form); ok {
+			return sexp.Bool(x > y)
+		}
+	case lisp.FnNumLte:
+		if x, y, ok := intArgs(form); ok {
+			return sexp.Bool(x <= y)
+		}
+	case lisp.FnNumGte:
+		if x, y, ok := intArgs(form); ok {
+			return sexp.Bool(x >= y)
+		}
 	}
 	return nil
 }
+
+// intArgs returns arguments of binary call if both of them are integers.
+func intArgs(form *sexp.LispCall) (int64, int64, bool) {
+	if len(form.Args) != 2 {
+		return 0, 0, false
+	}
+	x, ok1 := form.Args[0].(sexp.Int)
+	y, ok2 := form.Args[1].(sexp.Int)
+	return int64(x), int64(y), ok1 && ok2
+}
+
+// foldArith evaluates integer arithmetic.
+// Emacs integers do not overflow, so results that
+// are not representable as int64 are not folded.
+func foldArith(fn *lisp.Func, x, y int64) (int64, bool) {
+	switch fn {
+	case lisp.FnAdd:
+		res := x + y
+		return res, (x >= 0) != (y >= 0) || (res >= 0) == (x >= 0)
+	case lisp.FnSub:
+		res := x - y
+		return res, (x >= 0) == (y >= 0) || (res >= 0) == (x >= 0)
+	case lisp.FnMul:
+		if x == 0 || y == 0 {
+			return 0, true
+		}
+		res := x * y
+		return res, res/y == x && !(x == -1 && y == math.MinInt64) && !(y == -1 && x == math.MinInt64)
+	}
+	return 0, false
+}
@@ -23,5 +23,10 @@ func runOptPass(funcs []*sexp.Func) bool {
 func optimizeFunc(fn *sexp.Func) bool {
 	return InlineCalls(fn) ||
 		FoldConstexpr(fn) ||
-		ReduceStrength(fn)
+		ReduceStrength(fn) ||
+		ScalarizeStructs(fn) ||
+		PropagateConsts(fn) ||
+		EliminateDeadStores(fn) ||
+		OptimizeLoops(fn) ||
+		EliminateTailCalls(fn)
 }
@@ -0,0 +1,237 @@
+package opt
+
+import (
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"sexp"
+	"strconv"
+	"vmm"
+)
+
+// Indexes of rt.Slice fields.
+const (
+	sliceDataField   = 0
+	sliceOffsetField = 1
+)
+
+// OptimizeLoops moves loop-invariant computations out of
+// While and DoTimes loops.
+//
+// Element access of invariant slice is replaced with
+// direct "aref"/"aset" over slice backing vector;
+// data and offset fields are loaded once, before the loop.
+// Slice data and offset are never changed after slice creation,
+// so they can be hoisted even if loop body calls functions.
+//
+// Calls of pure Lisp functions with invariant arguments are
+// hoisted too.
+func OptimizeLoops(fn *sexp.Func) bool {
+	lo := loopOptimizer{names: make(map[string]bool)}
+	for _, param := range fn.Params {
+		lo.names[param] = true
+	}
+	for name := range loopBoundNames(fn.Body) {
+		lo.names[name] = true
+	}
+	fn.Body = sexp.Rewrite(fn.Body, lo.walkForm).(sexp.Block)
+	return lo.triggered
+}
+
+type loopOptimizer struct {
+	names     map[string]bool // All local names that are used by function
+	triggered bool
+}
+
+func (lo *loopOptimizer) walkForm(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case *sexp.While:
+		h := lo.newHoister(form)
+		form.Cond = h.rewrite(form.Cond)
+		form.Body = h.rewrite(form.Body).(sexp.Block)
+		form.Post = h.rewrite(form.Post)
+		// Nested loops are optimized after the outer loop,
+		// so invariant code is moved as far as possible.
+		form.Body = sexp.Rewrite(form.Body, lo.walkForm).(sexp.Block)
+		return h.wrap(form)
+
+	case *sexp.DoTimes:
+		h := lo.newHoister(form)
+		form.Body = h.rewrite(form.Body).(sexp.Block)
+		form.Body = sexp.Rewrite(form.Body, lo.walkForm).(sexp.Block)
+		return h.wrap(form)
+	}
+	return nil
+}
+
+// newName returns unique local name that is based on name.
+func (lo *loopOptimizer) newName(name string) string {
+	res := name
+	for i := 2; lo.names[res]; i++ {
+		res = name + strconv.Itoa(i)
+	}
+	lo.names[res] = true
+	return res
+}
+
+func (lo *loopOptimizer) newHoister(loop sexp.Form) *hoister {
+	return &hoister{
+		lo:      lo,
+		variant: loopBoundNames(loop),
+		slices:  make(map[string]hoistedSlice),
+	}
+}
+
+// hoister collects invariant forms of a single loop.
+type hoister struct {
+	lo      *loopOptimizer
+	variant map[string]bool // Locals that are changed inside loop
+	binds   sexp.Block      // Hoisted computations
+	slices  map[string]hoistedSlice
+}
+
+// hoistedSlice holds locals that are bound to slice fields.
+type hoistedSlice struct {
+	data   sexp.Local
+	offset sexp.Local
+}
+
+func (h *hoister) rewrite(form sexp.Form) sexp.Form {
+	return sexp.Rewrite(form, h.walkForm)
+}
+
+func (h *hoister) walkForm(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case *sexp.Call:
+		switch form.Fn {
+		case rt.FnSliceGet:
+			if s, ok := h.slice(form.Args[0]); ok {
+				index := sexp.NewAdd(s.offset, h.rewrite(form.Args[1]))
+				return sexp.NewLispCall(lisp.FnAref, s.data, index)
+			}
+		case rt.FnSliceSet:
+			if s, ok := h.slice(form.Args[0]); ok {
+				index := sexp.NewAdd(s.offset, h.rewrite(form.Args[1]))
+				val := h.rewrite(form.Args[2])
+				return sexp.NewLispCall(lisp.FnAset, s.data, index, val)
+			}
+		}
+
+	case *sexp.StructIndex:
+		// Inlined slice operations access fields directly.
+		// Length can be changed by append, so it is not hoisted.
+		if form.Typ != rt.TypSlice {
+			break
+		}
+		switch form.Index {
+		case sliceDataField:
+			if s, ok := h.slice(form.Struct); ok {
+				return s.data
+			}
+		case sliceOffsetField:
+			if s, ok := h.slice(form.Struct); ok {
+				return s.offset
+			}
+		}
+
+	case *sexp.LispCall:
+		if h.isInvariant(form) && hasLocals(form) {
+			return h.hoist("_inv", form)
+		}
+	}
+	return nil
+}
+
+// slice returns hoisted fields of form if it is an invariant slice.
+func (h *hoister) slice(form sexp.Form) (hoistedSlice, bool) {
+	local, ok := form.(sexp.Local)
+	if !ok || h.variant[local.Name] {
+		return hoistedSlice{}, false
+	}
+	if s, ok := h.slices[local.Name]; ok {
+		return s, true
+	}
+	s := hoistedSlice{
+		data: h.hoist(local.Name+".data", &sexp.StructIndex{
+			Struct: local,
+			Index:  sliceDataField,
+			Typ:    rt.TypSlice,
+		}),
+		offset: h.hoist(local.Name+".offset", &sexp.StructIndex{
+			Struct: local,
+			Index:  sliceOffsetField,
+			Typ:    rt.TypSlice,
+		}),
+	}
+	h.slices[local.Name] = s
+	return s, true
+}
+
+// hoist binds form before the loop and returns its reference.
+func (h *hoister) hoist(name string, form sexp.Form) sexp.Local {
+	h.lo.triggered = true
+	name = h.lo.newName(name)
+	h.binds = append(h.binds, &sexp.Bind{Name: name, Init: form})
+	return sexp.Local{Name: name, Typ: form.Type()}
+}
+
+// wrap puts hoisted bindings before the loop.
+func (h *hoister) wrap(loop sexp.Form) sexp.Form {
+	if len(h.binds) == 0 {
+		return loop
+	}
+	return append(h.binds, loop)
+}
+
+// isInvariant reports whether form evaluates to the same
+// value during every loop iteration and can be evaluated
+// before the loop without observable effects.
+func (h *hoister) isInvariant(form sexp.Form) bool {
+	switch form := form.(type) {
+	case sexp.Int, sexp.Float, sexp.Str, sexp.Bool, sexp.Symbol:
+		return true
+	case sexp.Local:
+		return !h.variant[form.Name]
+	case *sexp.TypeCast:
+		return h.isInvariant(form.Form)
+	case *sexp.LispCall:
+		if !vmm.FuncIsPure(form.Fn.Sym) {
+			return false
+		}
+		for _, arg := range form.Args {
+			if !h.isInvariant(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// hasLocals reports whether form references local variables.
+// Forms without locals are left to constant folding.
+func hasLocals(form sexp.Form) bool {
+	found := false
+	sexp.Walk(form, func(form sexp.Form) bool {
+		_, found = form.(sexp.Local)
+		return !found
+	})
+	return found
+}
+
+// loopBoundNames returns names of all locals that are
+// bound or assigned inside form.
+func loopBoundNames(form sexp.Form) map[string]bool {
+	names := make(map[string]bool)
+	sexp.Walk(form, func(form sexp.Form) bool {
+		switch form := form.(type) {
+		case *sexp.Bind:
+			names[form.Name] = true
+		case *sexp.Rebind:
+			names[form.Name] = true
+		case *sexp.DoTimes:
+			names[form.Iter.Name] = true
+		}
+		return true
+	})
+	return names
+}
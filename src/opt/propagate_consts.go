@@ -0,0 +1,332 @@
+package opt
+
+import (
+	"go/types"
+	"sexp"
+)
+
+// PropagateConsts replaces local variable references with
+// constant values that variables are known to hold.
+//
+// Analysis is flow-sensitive: values are merged after
+// branches and forgotten inside loops and after labels
+// for variables that are assigned there.
+func PropagateConsts(fn *sexp.Func) bool {
+	cp := constPropagator{assigned: assignedLocals(fn.Body)}
+	for _, param := range fn.Params {
+		cp.bind(param, nil)
+	}
+	cp.walkList(fn.Body)
+	return cp.triggered
+}
+
+type constPropagator struct {
+	env       []constBinding
+	assigned  map[string]bool // All names that are rebound inside function
+	triggered bool
+}
+
+// constBinding is a lexical variable; val is nil
+// if variable value is not known.
+type constBinding struct {
+	name string
+	val  sexp.Form
+}
+
+func (cp *constPropagator) lookup(name string) *constBinding {
+	for i := len(cp.env) - 1; i >= 0; i-- {
+		if cp.env[i].name == name {
+			return &cp.env[i]
+		}
+	}
+	return nil
+}
+
+func (cp *constPropagator) bind(name string, val sexp.Form) {
+	cp.env = append(cp.env, constBinding{name: name, val: val})
+}
+
+func (cp *constPropagator) set(name string, val sexp.Form) {
+	if b := cp.lookup(name); b != nil {
+		b.val = val
+	}
+}
+
+// kill forgets values of all variables with specified names.
+func (cp *constPropagator) kill(names map[string]bool) {
+	for i := range cp.env {
+		if names[cp.env[i].name] {
+			cp.env[i].val = nil
+		}
+	}
+}
+
+func (cp *constPropagator) snapshot() []constBinding {
+	return append([]constBinding(nil), cp.env...)
+}
+
+func (cp *constPropagator) restore(env []constBinding) {
+	cp.env = append(cp.env[:0], env...)
+}
+
+// merge keeps only those values that are equal in env.
+// Both environments must have same bindings.
+func (cp *constPropagator) merge(env []constBinding) {
+	for i := range cp.env {
+		if !sameConst(cp.env[i].val, env[i].val) {
+			cp.env[i].val = nil
+		}
+	}
+}
+
+func (cp *constPropagator) walkList(forms []sexp.Form) {
+	for i, form := range forms {
+		forms[i] = cp.walkStmt(form)
+	}
+}
+
+// walkScope walks forms that are executed in a new lexical scope.
+func (cp *constPropagator) walkScope(forms []sexp.Form) {
+	depth := len(cp.env)
+	cp.walkList(forms)
+	cp.env = cp.env[:depth]
+}
+
+func (cp *constPropagator) walkStmt(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case sexp.Block:
+		cp.walkScope(form)
+	case sexp.FormList:
+		cp.walkList(form)
+
+	case *sexp.Bind:
+		form.Init = cp.expr(form.Init)
+		cp.bind(form.Name, constOf(form.Init))
+	case *sexp.Rebind:
+		form.Expr = cp.expr(form.Expr)
+		cp.set(form.Name, constOf(form.Expr))
+
+	case *sexp.Return:
+		cp.exprList(form.Results)
+	case *sexp.ExprStmt:
+		form.Expr = cp.expr(form.Expr)
+	case *sexp.VarUpdate:
+		form.Expr = cp.expr(form.Expr)
+	case *sexp.ArrayUpdate:
+		form.Array = cp.expr(form.Array)
+		form.Index = cp.expr(form.Index)
+		form.Expr = cp.expr(form.Expr)
+	case *sexp.SliceUpdate:
+		form.Slice = cp.expr(form.Slice)
+		form.Index = cp.expr(form.Index)
+		form.Expr = cp.expr(form.Expr)
+	case *sexp.StructUpdate:
+		form.Struct = cp.expr(form.Struct)
+		form.Expr = cp.expr(form.Expr)
+
+	case *sexp.If:
+		form.Cond = cp.expr(form.Cond)
+		entry := cp.snapshot()
+		cp.walkScope(form.Then)
+		then := cp.snapshot()
+		thenExits := isTerminal(form.Then)
+		cp.restore(entry)
+		form.Else = cp.walkStmt(form.Else)
+		switch {
+		case thenExits:
+			// Only else branch reaches the end.
+		case isTerminal(form.Else):
+			cp.restore(then)
+		default:
+			cp.merge(then)
+		}
+
+	case *sexp.Switch:
+		form.Expr = cp.expr(form.Expr)
+		cp.walkSwitchBody(&form.SwitchBody)
+	case *sexp.SwitchTrue:
+		cp.walkSwitchBody(&form.SwitchBody)
+
+	case *sexp.Repeat:
+		cp.walkLoop(form, func() {
+			cp.walkScope(form.Body)
+		})
+	case *sexp.DoTimes:
+		cp.walkLoop(form, func() {
+			form.N = cp.expr(form.N)
+			cp.bind(form.Iter.Name, nil)
+			form.Step = cp.expr(form.Step)
+			cp.walkScope(form.Body)
+		})
+	case *sexp.Loop:
+		depth := len(cp.env)
+		form.Init = cp.walkStmt(form.Init)
+		cp.walkLoop(form, func() {
+			cp.walkScope(form.Body)
+			form.Post = cp.walkStmt(form.Post)
+		})
+		cp.env = cp.env[:depth]
+	case *sexp.While:
+		depth := len(cp.env)
+		form.Init = cp.walkStmt(form.Init)
+		cp.walkLoop(form, func() {
+			form.Cond = cp.expr(form.Cond)
+			cp.walkScope(form.Body)
+			form.Post = cp.walkStmt(form.Post)
+		})
+		cp.env = cp.env[:depth]
+
+	case *sexp.Label:
+		// Label can be reached by goto from anywhere.
+		cp.kill(cp.assigned)
+	case *sexp.Goto:
+		// Nothing to do.
+
+	case *sexp.Let:
+		depth := len(cp.env)
+		for _, bind := range form.Bindings {
+			cp.walkStmt(bind)
+		}
+		if form.Stmt != nil {
+			form.Stmt = cp.walkStmt(form.Stmt)
+		}
+		cp.env = cp.env[:depth]
+
+	default:
+		// Unknown statement; forget everything it may change.
+		cp.kill(assignedLocals(form))
+	}
+
+	return form
+}
+
+func (cp *constPropagator) walkSwitchBody(b *sexp.SwitchBody) {
+	for _, cc := range b.Clauses {
+		cp.kill(assignedLocals(cc.Expr))
+	}
+	entry := cp.snapshot()
+	var exits [][]constBinding
+	walkBody := func(body sexp.Block) {
+		cp.restore(entry)
+		cp.walkScope(body)
+		if !isTerminal(body) {
+			exits = append(exits, cp.snapshot())
+		}
+	}
+	for i := range b.Clauses {
+		cc := &b.Clauses[i]
+		cp.restore(entry)
+		cc.Expr = cp.expr(cc.Expr)
+		walkBody(cc.Body)
+	}
+	// Empty default body also covers "no match" case.
+	walkBody(b.DefaultBody)
+
+	if len(exits) == 0 {
+		cp.restore(entry)
+		return
+	}
+	cp.restore(exits[0])
+	for _, env := range exits[1:] {
+		cp.merge(env)
+	}
+}
+
+// walkLoop calls walk with all variables that are
+// assigned inside loop marked as unknown.
+// After the loop, only values that hold on every
+// iteration are preserved.
+func (cp *constPropagator) walkLoop(loop sexp.Form, walk func()) {
+	cp.kill(assignedLocals(loop))
+	entry := cp.snapshot()
+	walk()
+	cp.restore(entry)
+}
+
+func (cp *constPropagator) exprList(forms []sexp.Form) {
+	for i := range forms {
+		forms[i] = cp.expr(forms[i])
+	}
+}
+
+// expr substitutes variables that hold constants inside form.
+func (cp *constPropagator) expr(form sexp.Form) sexp.Form {
+	// Nested statements may assign variables.
+	cp.kill(assignedLocals(form))
+
+	return sexp.Rewrite(form, func(form sexp.Form) sexp.Form {
+		switch form := form.(type) {
+		case sexp.Local:
+			b := cp.lookup(form.Name)
+			if b == nil || b.val == nil {
+				return nil
+			}
+			cp.triggered = true
+			val := b.val.Copy()
+			if !types.Identical(val.Type(), form.Typ) {
+				return &sexp.TypeCast{Form: val, Typ: form.Typ}
+			}
+			return val
+
+		case *sexp.Let, *sexp.LambdaCall:
+			// Can shadow variables; not traversed.
+			return form
+		}
+		return nil
+	})
+}
+
+// constOf returns form if it is a constant that can be propagated.
+func constOf(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case sexp.Int, sexp.Float, sexp.Str, sexp.Bool, sexp.Symbol:
+		return form
+	case *sexp.TypeCast:
+		if constOf(form.Form) != nil {
+			return form
+		}
+	}
+	return nil
+}
+
+func sameConst(a, b sexp.Form) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a, ok := a.(*sexp.TypeCast); ok {
+		b, ok := b.(*sexp.TypeCast)
+		return ok && types.Identical(a.Typ, b.Typ) && sameConst(a.Form, b.Form)
+	}
+	return a == b
+}
+
+// isTerminal reports whether control never reaches
+// the end of form (it ends with return or goto).
+// "break" is not terminal: it can leave the switch statement
+// that contains form.
+func isTerminal(form sexp.Form) bool {
+	switch form := form.(type) {
+	case sexp.Block:
+		return len(form) != 0 && isTerminal(form[len(form)-1])
+	case sexp.FormList:
+		return len(form) != 0 && isTerminal(form[len(form)-1])
+	case *sexp.Return:
+		return true
+	case *sexp.Goto:
+		return form.LabelName != "break"
+	}
+	return false
+}
+
+// assignedLocals returns names of all variables
+// that are rebound inside form.
+func assignedLocals(form sexp.Form) map[string]bool {
+	names := make(map[string]bool)
+	sexp.Walk(form, func(form sexp.Form) bool {
+		if form, ok := form.(*sexp.Rebind); ok {
+			names[form.Name] = true
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,240 @@
+package opt
+
+import (
+	"go/types"
+	"sexp"
+	"strconv"
+)
+
+// ScalarizeStructs replaces local struct objects that never
+// escape the function with a set of locals, one per struct field.
+//
+// Struct escapes if it is stored, returned, converted to an
+// interface or pointer, captured by closure or passed to a call.
+// Only field reads, field updates and reassignments with
+// struct literals are permitted.
+func ScalarizeStructs(fn *sexp.Func) bool {
+	candidates := structCandidates(fn)
+	if len(candidates) == 0 {
+		return false
+	}
+	checkEscapes(fn.Body, candidates)
+	if len(candidates) == 0 {
+		return false
+	}
+	ss := structScalarizer{candidates: candidates}
+	fn.Body = ss.rewrite(fn.Body).(sexp.Block)
+	return true
+}
+
+// structCandidates collects locals that are bound exactly once
+// to a struct literal. Function parameters, Let and LambdaCall
+// bindings are excluded: they can not be split into several
+// bindings.
+func structCandidates(fn *sexp.Func) map[string]*types.Struct {
+	candidates := make(map[string]*types.Struct)
+	binds := make(map[string]int)
+	for _, param := range fn.Params {
+		binds[param] += 2
+	}
+	sexp.Walk(fn.Body, func(form sexp.Form) bool {
+		switch form := form.(type) {
+		case *sexp.Bind:
+			binds[form.Name]++
+			if lit := structLitOf(form.Init); lit != nil {
+				candidates[form.Name] = lit.Typ.Underlying().(*types.Struct)
+			}
+		case *sexp.DoTimes:
+			binds[form.Iter.Name] += 2
+		case *sexp.Let:
+			for _, bind := range form.Bindings {
+				binds[bind.Name]++
+			}
+		case *sexp.LambdaCall:
+			for _, bind := range form.Args {
+				binds[bind.Name]++
+			}
+		}
+		return true
+	})
+	for name := range candidates {
+		if binds[name] != 1 {
+			delete(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// checkEscapes removes escaping variables from candidates.
+func checkEscapes(form sexp.Form, candidates map[string]*types.Struct) {
+	var visit func(sexp.Form) sexp.Form
+	visitList := func(forms []sexp.Form) {
+		for _, form := range forms {
+			sexp.Rewrite(form, visit)
+		}
+	}
+	visit = func(form sexp.Form) sexp.Form {
+		switch form := form.(type) {
+		case sexp.Local:
+			delete(candidates, form.Name)
+
+		case *sexp.StructIndex:
+			if isCandidate(candidates, form.Struct) {
+				return form
+			}
+		case *sexp.StructUpdate:
+			if isCandidate(candidates, form.Struct) {
+				sexp.Rewrite(form.Expr, visit)
+				return form
+			}
+		case *sexp.Bind:
+			if lit := structLitOf(form.Init); lit != nil && candidates[form.Name] != nil {
+				visitList(lit.Vals)
+				return form
+			}
+		case *sexp.Rebind:
+			if candidates[form.Name] == nil {
+				break
+			}
+			if lit := structLitOf(form.Expr); lit != nil {
+				visitList(lit.Vals)
+				return form
+			}
+			delete(candidates, form.Name)
+		}
+		return nil
+	}
+	sexp.Rewrite(form, visit)
+}
+
+type structScalarizer struct {
+	candidates map[string]*types.Struct
+}
+
+func (ss *structScalarizer) rewrite(form sexp.Form) sexp.Form {
+	return sexp.Rewrite(form, ss.walkForm)
+}
+
+func (ss *structScalarizer) rewriteList(forms []sexp.Form) {
+	for i := range forms {
+		forms[i] = ss.rewrite(forms[i])
+	}
+}
+
+func (ss *structScalarizer) walkForm(form sexp.Form) sexp.Form {
+	switch form := form.(type) {
+	case *sexp.StructIndex:
+		if local, ok := ss.candidate(form.Struct); ok {
+			return sexp.Local{
+				Name: fieldLocalName(local.Name, form.Typ, form.Index),
+				Typ:  form.Type(),
+			}
+		}
+	case *sexp.StructUpdate:
+		if local, ok := ss.candidate(form.Struct); ok {
+			return &sexp.Rebind{
+				Name: fieldLocalName(local.Name, form.Typ, form.Index),
+				Expr: ss.rewrite(form.Expr),
+			}
+		}
+	case *sexp.Bind:
+		if typ := ss.candidates[form.Name]; typ != nil {
+			vals := structLitOf(form.Init).Vals
+			ss.rewriteList(vals)
+			binds := make(sexp.FormList, len(vals))
+			for i, val := range vals {
+				binds[i] = &sexp.Bind{
+					Name: fieldLocalName(form.Name, typ, i),
+					Init: val,
+				}
+			}
+			return binds
+		}
+	case *sexp.Rebind:
+		if typ := ss.candidates[form.Name]; typ != nil {
+			vals := structLitOf(form.Expr).Vals
+			selfRef := inspectStruct(form.Name, vals)
+			ss.rewriteList(vals)
+			return ss.rebindFields(form.Name, typ, vals, selfRef)
+		}
+	}
+	return nil
+}
+
+// rebindFields returns statement that assigns vals to the
+// field locals of struct.
+//
+// If vals refer to the struct itself, all of them are
+// evaluated before the first field is changed.
+func (ss *structScalarizer) rebindFields(name string, typ *types.Struct, vals []sexp.Form, selfRef bool) sexp.Form {
+	if !selfRef {
+		res := make(sexp.FormList, len(vals))
+		for i, val := range vals {
+			res[i] = &sexp.Rebind{Name: fieldLocalName(name, typ, i), Expr: val}
+		}
+		return res
+	}
+
+	res := make(sexp.Block, 0, len(vals)*2)
+	var rebinds []sexp.Form
+	for i, val := range vals {
+		field := fieldLocalName(name, typ, i)
+		if i == len(vals)-1 {
+			res = append(res, &sexp.Rebind{Name: field, Expr: val})
+			break
+		}
+		tmp := "_" + field
+		res = append(res, &sexp.Bind{Name: tmp, Init: val})
+		rebinds = append(rebinds, &sexp.Rebind{
+			Name: field,
+			Expr: sexp.Local{Name: tmp, Typ: val.Type()},
+		})
+	}
+	return append(res, rebinds...)
+}
+
+func (ss *structScalarizer) candidate(form sexp.Form) (sexp.Local, bool) {
+	local, ok := form.(sexp.Local)
+	return local, ok && ss.candidates[local.Name] != nil
+}
+
+func isCandidate(candidates map[string]*types.Struct, form sexp.Form) bool {
+	local, ok := form.(sexp.Local)
+	return ok && candidates[local.Name] != nil
+}
+
+// structLitOf returns struct literal that form evaluates to.
+// Address taking is represented as TypeCast of the literal.
+func structLitOf(form sexp.Form) *sexp.StructLit {
+	switch form := form.(type) {
+	case *sexp.StructLit:
+		return form
+	case *sexp.TypeCast:
+		if lit, ok := form.Form.(*sexp.StructLit); ok {
+			return lit
+		}
+	}
+	return nil
+}
+
+// inspectStruct reports whether any of forms refers to
+// the struct local with specified name.
+func inspectStruct(name string, forms []sexp.Form) bool {
+	for _, form := range forms {
+		if inspectLocal(name, form).reads != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldLocalName returns name of local that holds struct field.
+// Dot can not appear inside Go identifier, so these names
+// never clash with user variables.
+func fieldLocalName(name string, typ *types.Struct, index int) string {
+	field := typ.Field(index).Name()
+	if field == "_" {
+		field = strconv.Itoa(index)
+	}
+	return name + "." + field
+}
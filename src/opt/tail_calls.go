@@ -0,0 +1,264 @@
+package opt
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"magic_pkg/emacs/rt"
+	"path/filepath"
+	"sexp"
+	"strconv"
+	"strings"
+	"tu/symbols"
+)
+
+// tailCallLabel is a label that is placed at the function
+// body start; it is not a valid Go identifier.
+const tailCallLabel = "tail-call"
+
+// TailCall describes a call in tail position that
+// was not converted into a jump.
+type TailCall struct {
+	Callee string // Empty for indirect calls
+	Reason string
+	Pos    token.Pos
+}
+
+// EliminateTailCalls replaces self-recursive tail calls
+// with parameters rebinding and a jump to the function start.
+//
+// Eliminated calls do not consume "max-lisp-eval-depth".
+func EliminateTailCalls(fn *sexp.Func) bool {
+	tce := tailCallEliminator{fn: fn, rewrite: true}
+	tce.walkList(fn.Body)
+	if tce.triggered && !hasTailCallLabel(fn) {
+		fn.Body = append(sexp.Block{&sexp.Label{Name: tailCallLabel}}, fn.Body...)
+	}
+	return tce.triggered
+}
+
+// TailCalls returns tail calls of fn that are not eliminated.
+// Calls to Lisp functions are not reported.
+// Calls that are generated by the translator, like runtime
+// function calls or statements without source position,
+// are not reported either.
+func TailCalls(fn *sexp.Func) []TailCall {
+	tce := tailCallEliminator{fn: fn}
+	tce.walkList(fn.Body)
+	var res []TailCall
+	rtPrefix := symbols.Mangle(rt.Package.Name(), "")
+	for _, call := range tce.missed {
+		if call.Pos.IsValid() && !strings.HasPrefix(call.Callee, rtPrefix) {
+			res = append(res, call)
+		}
+	}
+	return res
+}
+
+// WriteTailCalls writes TailCalls report for every function.
+// Each line has "file.go:line: fn: callee: reason" format;
+// function names are printed as Go qualified identifiers.
+func WriteTailCalls(w io.Writer, fset *token.FileSet, funcs []*sexp.Func) {
+	for _, fn := range funcs {
+		for _, call := range TailCalls(fn) {
+			callee := "<indirect>"
+			if call.Callee != "" {
+				callee = symbols.Demangle(call.Callee)
+			}
+			pos := fset.Position(call.Pos)
+			fmt.Fprintf(w, "%s:%d: %s: %s: %s\n",
+				filepath.Base(pos.Filename), pos.Line,
+				symbols.Demangle(fn.Name), callee, call.Reason)
+		}
+	}
+}
+
+type tailCallEliminator struct {
+	fn        *sexp.Func
+	rewrite   bool
+	triggered bool
+	missed    []TailCall
+
+	// Parameters that are shadowed by local bindings.
+	// Parameter can not be rebound while it is shadowed.
+	shadowed map[string]int
+}
+
+func hasTailCallLabel(fn *sexp.Func) bool {
+	if len(fn.Body) == 0 {
+		return false
+	}
+	label, ok := fn.Body[0].(*sexp.Label)
+	return ok && label.Name == tailCallLabel
+}
+
+// walkList visits statement list; forms that introduce
+// new scope are visited recursively. Returns names of
+// shadowed parameters, caller must unshadow them
+// when the scope ends.
+func (tce *tailCallEliminator) walkList(forms []sexp.Form) []string {
+	var shadowed []string
+	for i, form := range forms {
+		switch form := form.(type) {
+		case *sexp.Return:
+			if len(form.Results) == 1 {
+				if res := tce.tailCall(form.Results[0], form.Pos); res != nil {
+					sexp.SetPos(res, form.Pos)
+					forms[i] = res
+				}
+			}
+		case *sexp.ExprStmt:
+			// Void function call that is followed by "return".
+			if i+1 < len(forms) && isVoidReturn(forms[i+1]) {
+				if res := tce.tailCall(form.Expr, form.Pos); res != nil {
+					sexp.SetPos(res, form.Pos)
+					forms[i] = res
+				}
+			}
+		case *sexp.Bind:
+			if tce.shadow(form.Name) {
+				shadowed = append(shadowed, form.Name)
+			}
+		case sexp.FormList:
+			shadowed = append(shadowed, tce.walkList(form)...)
+		default:
+			tce.walkStmt(form)
+		}
+	}
+	return shadowed
+}
+
+func (tce *tailCallEliminator) walkScope(init sexp.Form, forms []sexp.Form) {
+	var shadowed []string
+	if bind, ok := init.(*sexp.Bind); ok && tce.shadow(bind.Name) {
+		shadowed = append(shadowed, bind.Name)
+	}
+	shadowed = append(shadowed, tce.walkList(forms)...)
+	for _, name := range shadowed {
+		tce.shadowed[name]--
+	}
+}
+
+func (tce *tailCallEliminator) walkStmt(form sexp.Form) {
+	switch form := form.(type) {
+	case sexp.Block:
+		tce.walkScope(nil, form)
+	case *sexp.If:
+		tce.walkScope(nil, form.Then)
+		tce.walkScope(nil, []sexp.Form{form.Else})
+	case *sexp.Switch:
+		tce.walkSwitchBody(&form.SwitchBody)
+	case *sexp.SwitchTrue:
+		tce.walkSwitchBody(&form.SwitchBody)
+	case *sexp.Repeat:
+		tce.walkScope(nil, form.Body)
+	case *sexp.DoTimes:
+		tce.walkScope(&sexp.Bind{Name: form.Iter.Name}, form.Body)
+	case *sexp.Loop:
+		tce.walkScope(form.Init, form.Body)
+	case *sexp.While:
+		tce.walkScope(form.Init, form.Body)
+	case *sexp.Let:
+		if form.Stmt != nil {
+			binds := make([]sexp.Form, len(form.Bindings), len(form.Bindings)+1)
+			for i, bind := range form.Bindings {
+				binds[i] = bind
+			}
+			tce.walkScope(nil, append(binds, form.Stmt))
+		}
+	}
+}
+
+func (tce *tailCallEliminator) walkSwitchBody(b *sexp.SwitchBody) {
+	for _, cc := range b.Clauses {
+		tce.walkScope(nil, cc.Body)
+	}
+	tce.walkScope(nil, b.DefaultBody)
+}
+
+// shadow marks name as shadowed if it is a parameter name.
+func (tce *tailCallEliminator) shadow(name string) bool {
+	for _, param := range tce.fn.Params {
+		if param == name {
+			if tce.shadowed == nil {
+				tce.shadowed = make(map[string]int)
+			}
+			tce.shadowed[name]++
+			return true
+		}
+	}
+	return false
+}
+
+// tailCall returns a replacement for form that is in tail position.
+// Returns nil if form is not eliminated.
+func (tce *tailCallEliminator) tailCall(form sexp.Form, pos token.Pos) sexp.Form {
+	switch form := form.(type) {
+	case *sexp.Call:
+		if form.Fn != tce.fn {
+			tce.miss(form.Fn.Name, "not a self call", pos)
+			return nil
+		}
+		if len(form.Args) != len(tce.fn.Params) {
+			tce.miss(form.Fn.Name, "argument count mismatch", pos)
+			return nil
+		}
+		for _, param := range tce.fn.Params {
+			if tce.shadowed[param] != 0 {
+				tce.miss(form.Fn.Name, "parameter `"+param+"' is shadowed", pos)
+				return nil
+			}
+		}
+		if !tce.rewrite {
+			tce.miss(form.Fn.Name, "optimizations are disabled", pos)
+			return nil
+		}
+		tce.triggered = true
+		return tce.rebindParams(form.Args)
+
+	case *sexp.DynCall:
+		tce.miss("", "indirect call", pos)
+	}
+	return nil
+}
+
+func (tce *tailCallEliminator) miss(callee, reason string, pos token.Pos) {
+	tce.missed = append(tce.missed, TailCall{Callee: callee, Reason: reason, Pos: pos})
+}
+
+// rebindParams returns a block that assigns args to
+// the function parameters and jumps to the function start.
+//
+// All args are evaluated before the first parameter is changed;
+// temporaries are not needed for the last changed parameter.
+func (tce *tailCallEliminator) rebindParams(args []sexp.Form) sexp.Block {
+	var changed []int
+	for i, param := range tce.fn.Params {
+		if local, ok := args[i].(sexp.Local); !ok || local.Name != param {
+			changed = append(changed, i)
+		}
+	}
+
+	res := make(sexp.Block, 0, len(changed)*2)
+	var rebinds []sexp.Form
+	for n, i := range changed {
+		param := tce.fn.Params[i]
+		if n == len(changed)-1 {
+			res = append(res, &sexp.Rebind{Name: param, Expr: args[i]})
+			break
+		}
+		tmp := "_tail" + strconv.Itoa(i)
+		res = append(res, &sexp.Bind{Name: tmp, Init: args[i]})
+		rebinds = append(rebinds, &sexp.Rebind{
+			Name: param,
+			Expr: sexp.Local{Name: tmp, Typ: args[i].Type()},
+		})
+	}
+	res = append(res, rebinds...)
+	return append(res, &sexp.Goto{LabelName: tailCallLabel})
+}
+
+func isVoidReturn(form sexp.Form) bool {
+	ret, ok := form.(*sexp.Return)
+	return ok && len(ret.Results) == 0
+}
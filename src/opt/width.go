@@ -83,6 +83,10 @@ func width(form sexp.Form) int {
 		return 2
 	case *sexp.Label:
 		return 0
+	case *sexp.SpecBind:
+		return width(form.Init) + width(form.Body) + 2
+	case *sexp.Scope:
+		return width(form.Buffer) + width(form.Body) + 4
 	case *sexp.Repeat:
 		return -1 // #REFS: 90
 	case *sexp.DoTimes:
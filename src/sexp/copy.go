@@ -91,6 +91,20 @@ func (form *ExprStmt) Copy() Form {
 }
 func (form *Goto) Copy() Form  { return &Goto{LabelName: form.LabelName} }
 func (form *Label) Copy() Form { return &Label{Name: form.Name} }
+func (form *SpecBind) Copy() Form {
+	return &SpecBind{
+		Sym:  form.Sym,
+		Init: form.Init.Copy(),
+		Body: form.Body.Copy().(Block),
+	}
+}
+func (form *Scope) Copy() Form {
+	return &Scope{
+		Kind:   form.Kind,
+		Buffer: form.Buffer.Copy(),
+		Body:   form.Body.Copy().(Block),
+	}
+}
 
 func (form *Repeat) Copy() Form {
 	return &Repeat{
@@ -157,7 +171,11 @@ func (form *SliceSlice) Copy() Form {
 }
 
 func (form *TypeAssert) Copy() Form {
-	return &TypeAssert{Expr: form.Expr.Copy(), Typ: form.Typ}
+	return &TypeAssert{
+		Expr:    form.Expr.Copy(),
+		Typ:     form.Typ,
+		CommaOk: form.CommaOk,
+	}
 }
 
 func (call *Call) Copy() Form {
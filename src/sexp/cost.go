@@ -134,6 +134,12 @@ func (form *ExprStmt) Cost() int {
 }
 func (form *Goto) Cost() int  { return 1 }
 func (form *Label) Cost() int { return 0 }
+func (form *SpecBind) Cost() int {
+	return form.Init.Cost() + form.Body.Cost() + 2
+}
+func (form *Scope) Cost() int {
+	return form.Buffer.Cost() + form.Body.Cost() + 4
+}
 
 func (form *Repeat) Cost() int {
 	return form.Body.Cost() * int(form.N)
@@ -83,6 +83,7 @@ type (
 type (
 	// ArrayUpdate is array index expression with assignment.
 	ArrayUpdate struct {
+		StmtPos
 		Array Form
 		Index Form
 		Expr  Form
@@ -90,6 +91,7 @@ type (
 
 	// SliceUpdate is slice index expression with assignment.
 	SliceUpdate struct {
+		StmtPos
 		Slice Form
 		Index Form
 		Expr  Form
@@ -97,6 +99,7 @@ type (
 
 	// StructUpdate = "Struct.[Index] = Expr".
 	StructUpdate struct {
+		StmtPos
 		Struct Form
 		Index  int
 		Expr   Form
@@ -106,18 +109,21 @@ type (
 	// Bind associates name with expression (initializer).
 	// Introduces local variable.
 	Bind struct {
+		StmtPos
 		Name string
 		Init Form
 	}
 
 	// Rebind changes local symbol value.
 	Rebind struct {
+		StmtPos
 		Name string
 		Expr Form
 	}
 
 	// VarUpdate changes global variable value.
 	VarUpdate struct {
+		StmtPos
 		Name string
 		Expr Form
 	}
@@ -133,6 +139,7 @@ type (
 	// depending on the result, one of the branches gets
 	// executed. Else branch is optional.
 	If struct {
+		StmtPos
 		Cond Form
 		Then Block
 		Else Form // Can be EmptyForm
@@ -140,25 +147,67 @@ type (
 
 	// Switch is "expression switch statement" defined by Go spec.
 	Switch struct {
+		StmtPos
 		Expr Form
 		SwitchBody
 	}
 
 	// SwitchTrue is like Switch, but Expr is fixed to "true".
-	SwitchTrue struct{ SwitchBody }
+	SwitchTrue struct {
+		StmtPos
+		SwitchBody
+	}
 
 	// Return statement exits the function and returns
 	// one or more values to the caller.
-	Return struct{ Results []Form }
+	Return struct {
+		StmtPos
+		Results []Form
+	}
 
 	// ExprStmt is a Call which discards returned results.
-	ExprStmt struct{ Expr Form }
+	ExprStmt struct {
+		StmtPos
+		Expr Form
+	}
 
 	// Goto = "goto LabelName".
 	Goto struct{ LabelName string }
 
 	// Label = "Name:".
 	Label struct{ Name string }
+
+	// SpecBind executes Body while special variable Sym
+	// is dynamically bound to Init value.
+	// Binding is undone even if Body exits non-locally.
+	SpecBind struct {
+		StmtPos
+		Sym  string
+		Init Form
+		Body Block
+	}
+
+	// Scope executes Body inside Emacs Lisp form that
+	// restores editor state when Body exits (even non-locally).
+	// Buffer is used only by ScopeCurrentBuffer.
+	Scope struct {
+		StmtPos
+		Kind   ScopeKind
+		Buffer Form // Can be EmptyForm
+		Body   Block
+	}
+)
+
+// ScopeKind selects state that is restored by Scope.
+type ScopeKind int
+
+// Scope kinds; comments name matching Emacs Lisp forms.
+const (
+	ScopeExcursion     ScopeKind = iota // "save-excursion"
+	ScopeRestriction                    // "save-restriction"
+	ScopeCurrentBuffer                  // "with-current-buffer"
+	ScopeMatchData                      // "save-match-data"
+	ScopeTempBuffer                     // "with-temp-buffer"
 )
 
 // Loop forms.
@@ -176,6 +225,7 @@ type (
 	// - N is not necessary a constant.
 	// - Has inductive variable inside loop body (Iter).
 	DoTimes struct {
+		StmtPos
 		N    Form
 		Iter Local
 		Step Form
@@ -184,6 +234,7 @@ type (
 
 	// Loop = "while true".
 	Loop struct {
+		StmtPos
 		Init Form // Can be EmptyForm
 		Post Form // Can be EmptyForm
 		Body Block
@@ -191,6 +242,7 @@ type (
 
 	// While is a generic (low level) looping construct.
 	While struct {
+		StmtPos
 		Init Form // Can be EmptyForm
 		Cond Form
 		Post Form // Can be EmptyForm
@@ -237,9 +289,12 @@ type (
 )
 
 // TypeAssert coerces expression to specified type; panics on failure.
+// If CommaOk is set, zero value is returned on failure instead of panic
+// and assertion status is returned as a second result.
 type TypeAssert struct {
-	Expr Form
-	Typ  types.Type
+	Expr    Form
+	Typ     types.Type
+	CommaOk bool
 }
 
 // Call expression is normal (direct) function invocation.
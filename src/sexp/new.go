@@ -48,6 +48,7 @@ func NewAdd(x, y Form) *LispCall    { return NewLispCall(lisp.FnAdd, x, y) }
 func NewSub(x, y Form) *LispCall    { return NewLispCall(lisp.FnSub, x, y) }
 func NewMul(x, y Form) *LispCall    { return NewLispCall(lisp.FnMul, x, y) }
 func NewQuo(x, y Form) *LispCall    { return NewLispCall(lisp.FnQuo, x, y) }
+func NewRem(x, y Form) *LispCall    { return NewLispCall(lisp.FnRem, x, y) }
 func NewNumEq(x, y Form) *LispCall  { return NewLispCall(lisp.FnNumEq, x, y) }
 func NewNumNeq(x, y Form) *LispCall { return NewNot(NewNumEq(x, y)) }
 func NewNumLt(x, y Form) *LispCall  { return NewLispCall(lisp.FnNumLt, x, y) }
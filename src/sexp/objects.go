@@ -1,6 +1,11 @@
 package sexp
 
-import "go/types"
+import (
+	"exn"
+	"go/types"
+	"strconv"
+	"strings"
+)
 
 type funcInfo uint32
 
@@ -8,6 +13,8 @@ const (
 	funcInlineable funcInfo = 1 << iota
 	funcSubst
 	funcNoinline
+	funcInteractive
+	funcAutoload
 )
 
 // IsInlineable tells if function can be inlined
@@ -20,6 +27,21 @@ func (fn *Func) IsSubst() bool { return (fn.info & funcSubst) != 0 }
 // IsNoinline returns true for functions that should not be inlined. Ever.
 func (fn *Func) IsNoinline() bool { return (fn.info & funcNoinline) != 0 }
 
+// IsInteractive returns true for functions that are Emacs commands.
+func (fn *Func) IsInteractive() bool { return (fn.info & funcInteractive) != 0 }
+
+// IsAutoload returns true for functions that get autoload cookie.
+func (fn *Func) IsAutoload() bool { return (fn.info & funcAutoload) != 0 }
+
+// SetAutoload sets function autoload flag to true or false.
+func (fn *Func) SetAutoload(autoload bool) {
+	if autoload {
+		fn.info |= funcAutoload
+	} else {
+		fn.info &^= funcAutoload
+	}
+}
+
 // SetInlineable sets function inlineable flag to true or false.
 func (fn *Func) SetInlineable(inlineable bool) {
 	if inlineable {
@@ -31,13 +53,29 @@ func (fn *Func) SetInlineable(inlineable bool) {
 
 // LoadDirective parses function comment directive and
 // updates function info correspondingly.
+// Directive can have an argument that is separated by space.
 func (fn *Func) LoadDirective(directive string) {
-	switch directive[len("//goism:"):] {
+	name, arg := directive[len("//goism:"):], ""
+	if i := strings.IndexByte(name, ' '); i != -1 {
+		name, arg = name[:i], strings.TrimSpace(name[i+1:])
+	}
+	switch name {
 	case "subst":
 		fn.info |= funcSubst
 		fn.info |= funcInlineable // Implicitly implied
 	case "noinline":
 		fn.info |= funcNoinline
+	case "interactive":
+		// Spec is a Go string literal: //goism:interactive "sName: ".
+		// If omitted, it is derived from parameter types.
+		fn.info |= funcInteractive
+		if arg != "" {
+			spec, err := strconv.Unquote(arg)
+			if err != nil {
+				panic(exn.User("malformed interactive spec: %s", arg))
+			}
+			fn.InteractiveSpec = spec
+		}
 	}
 }
 
@@ -57,4 +95,9 @@ type Func struct {
 	info funcInfo
 
 	DocString string
+
+	// InteractiveSpec is an argument of "interactive" form.
+	// Only meaningful for interactive functions;
+	// empty spec means that command has no arguments.
+	InteractiveSpec string
 }
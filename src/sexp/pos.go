@@ -0,0 +1,46 @@
+package sexp
+
+import (
+	"go/token"
+)
+
+// StmtPos is embedded into statement forms to record
+// source position of Go statement they were created from.
+//
+// Copy does not preserve positions: copied (inlined) code
+// is attributed to the statement that contains it.
+type StmtPos struct {
+	Pos token.Pos // Zero for synthesized forms
+}
+
+// Position returns recorded statement position.
+func (p *StmtPos) Position() token.Pos { return p.Pos }
+
+// SetPosition changes recorded statement position.
+func (p *StmtPos) SetPosition(pos token.Pos) { p.Pos = pos }
+
+// Positioned is implemented by forms that embed StmtPos.
+type Positioned interface {
+	Position() token.Pos
+	SetPosition(token.Pos)
+}
+
+// SetPos assigns position to form that has none.
+// FormList and Block members are updated too, but
+// nested statements bodies are not visited.
+func SetPos(form Form, pos token.Pos) {
+	switch form := form.(type) {
+	case Positioned:
+		if !form.Position().IsValid() {
+			form.SetPosition(pos)
+		}
+	case FormList:
+		for _, x := range form {
+			SetPos(x, pos)
+		}
+	case Block:
+		for _, x := range form {
+			SetPos(x, pos)
+		}
+	}
+}
@@ -3,7 +3,16 @@ package sexp
 type rewriteFunc func(Form) Form
 
 // Rewrite provides convenient way to update AST.
+// Replacement statements inherit position of replaced statement.
 func Rewrite(form Form, fn rewriteFunc) Form {
+	res := rewriteForm(form, fn)
+	if p, ok := form.(Positioned); ok && p.Position().IsValid() {
+		SetPos(res, p.Position())
+	}
+	return res
+}
+
+func rewriteForm(form Form, fn rewriteFunc) Form {
 	switch form := form.(type) {
 	case Block:
 		return rewriteList(form, form, fn)
@@ -88,6 +97,19 @@ func Rewrite(form Form, fn rewriteFunc) Form {
 	case *Return:
 		return rewriteList(form, form.Results, fn)
 
+	case *SpecBind:
+		if form := fn(form); form != nil {
+			return form
+		}
+		form.Init = Rewrite(form.Init, fn)
+		form.Body = Rewrite(form.Body, fn).(Block)
+	case *Scope:
+		if form := fn(form); form != nil {
+			return form
+		}
+		form.Buffer = Rewrite(form.Buffer, fn)
+		form.Body = Rewrite(form.Body, fn).(Block)
+
 	case *Repeat:
 		if form := fn(form); form != nil {
 			return form
@@ -98,6 +120,7 @@ func Rewrite(form Form, fn rewriteFunc) Form {
 		if form := fn(form); form != nil {
 			return form
 		}
+		form.N = Rewrite(form.N, fn)
 		form.Step = Rewrite(form.Step, fn)
 		form.Body = Rewrite(form.Body, fn).(Block)
 
@@ -105,12 +128,15 @@ func Rewrite(form Form, fn rewriteFunc) Form {
 		if form := fn(form); form != nil {
 			return form
 		}
+		form.Init = Rewrite(form.Init, fn)
+		form.Post = Rewrite(form.Post, fn)
 		form.Body = Rewrite(form.Body, fn).(Block)
 
 	case *While:
 		if form := fn(form); form != nil {
 			return form
 		}
+		form.Init = Rewrite(form.Init, fn)
 		form.Cond = Rewrite(form.Cond, fn)
 		form.Post = Rewrite(form.Post, fn)
 		form.Body = Rewrite(form.Body, fn).(Block)
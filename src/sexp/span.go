@@ -10,19 +10,24 @@ const (
 )
 
 type Span struct {
-	Low  Form // [!] Can be nil
-	High Form // [!] Can be nil
+	Low  Form // [!] Can be nil or Nil
+	High Form // [!] Can be nil or Nil
 }
 
 func (span *Span) Kind() SpanKind {
-	if span.Low == nil {
-		if span.High == nil {
+	if isOmitted(span.Low) {
+		if isOmitted(span.High) {
 			return SpanWhole
 		}
 		return SpanHighOnly
 	}
-	if span.High == nil {
+	if isOmitted(span.High) {
 		return SpanLowOnly
 	}
 	return SpanBoth
 }
+
+// isOmitted reports whether span bound is not specified.
+func isOmitted(bound Form) bool {
+	return bound == nil || bound == Nil
+}
@@ -34,6 +34,8 @@ func (form *Return) Type() types.Type       { return xtypes.TypVoid }
 func (form *ExprStmt) Type() types.Type     { return xtypes.TypVoid }
 func (form *Goto) Type() types.Type         { return xtypes.TypVoid }
 func (form *Label) Type() types.Type        { return xtypes.TypVoid }
+func (form *SpecBind) Type() types.Type     { return xtypes.TypVoid }
+func (form *Scope) Type() types.Type        { return xtypes.TypVoid }
 
 func (form *Repeat) Type() types.Type  { return xtypes.TypVoid }
 func (form *DoTimes) Type() types.Type { return xtypes.TypVoid }
@@ -64,7 +66,7 @@ func (call *Call) Type() types.Type {
 }
 func (call *LispCall) Type() types.Type {
 	switch call.Fn {
-	case lisp.FnSub, lisp.FnAdd, lisp.FnMul, lisp.FnQuo, lisp.FnMin:
+	case lisp.FnSub, lisp.FnAdd, lisp.FnMul, lisp.FnQuo, lisp.FnRem, lisp.FnMin:
 		return call.Args[0].Type()
 
 	case lisp.FnConcat:
@@ -7,6 +7,7 @@ import (
 	"go/types"
 	"magic_pkg/emacs/rt"
 	"sexp"
+	"strconv"
 	"xtypes"
 )
 
@@ -97,17 +98,96 @@ func (conv *converter) multiValueAssign(lhs []ast.Expr, rhs ast.Expr) sexp.FormL
 }
 
 func (conv *converter) singleValueAssign(lhs, rhs []ast.Expr) sexp.FormList {
-	forms := make([]sexp.Form, 0, 1)
+	if len(lhs) == 1 {
+		conv.ctxType = conv.typeOf(lhs[0])
+		return sexp.FormList{conv.assign(lhs[0], conv.Expr(rhs[0]))}
+	}
 
+	// All operands must be evaluated before any assignment,
+	// otherwise "x[i], x[j] = x[j], x[i]" is not a swap and
+	// "i, x[i] = 1, 2" stores into updated index.
+	// Non-constant values are saved into temporaries.
+	forms := make([]sexp.Form, 0, len(lhs)*2)
+	bindTmp := func(name string, val sexp.Form) sexp.Form {
+		forms = append(forms, &sexp.Bind{Name: name, Init: val})
+		return sexp.Local{Name: name, Typ: val.Type()}
+	}
+	// Destination operands that are variables need temporaries
+	// only if this statement can change them.
+	assigned := make(map[types.Object]bool, len(lhs))
+	for _, lhs := range lhs {
+		if id, ok := lhs.(*ast.Ident); ok {
+			assigned[conv.info.ObjectOf(id)] = true
+		}
+	}
+	operands := make([][]sexp.Form, len(lhs))
+	for i := range lhs {
+		nodes := conv.lhsOperands(lhs[i])
+		operands[i] = make([]sexp.Form, len(nodes))
+		for j, node := range nodes {
+			operands[i][j] = conv.Expr(node)
+			if id, ok := node.(*ast.Ident); ok {
+				if !assigned[conv.info.ObjectOf(id)] && conv.boxedVar(id) == nil {
+					continue
+				}
+			} else if conv.isConstExpr(node) {
+				continue
+			}
+			name := "_lhs" + strconv.Itoa(i) + "_" + strconv.Itoa(j)
+			operands[i][j] = bindTmp(name, operands[i][j])
+		}
+	}
+	vals := make([]sexp.Form, len(lhs))
 	for i := range lhs {
 		conv.ctxType = conv.typeOf(lhs[i])
-		forms = append(forms, conv.assign(lhs[i], conv.Expr(rhs[i])))
+		vals[i] = conv.Expr(rhs[i])
+		if !conv.isConstExpr(rhs[i]) {
+			vals[i] = bindTmp("_rhs"+strconv.Itoa(i), vals[i])
+		}
+	}
+	for i := range lhs {
+		forms = append(forms, conv.assignTo(lhs[i], operands[i], vals[i]))
 	}
 
 	return sexp.FormList(forms)
 }
 
+// isConstExpr reports whether node is a constant or untyped nil.
+func (conv *converter) isConstExpr(node ast.Expr) bool {
+	tv := conv.info.Types[node]
+	return tv.Value != nil || tv.IsNil()
+}
+
+// lhsOperands returns index and pointer operands of assignment
+// destination. They are evaluated before the value is stored.
+func (conv *converter) lhsOperands(lhs ast.Expr) []ast.Expr {
+	switch lhs := lhs.(type) {
+	case *ast.IndexExpr:
+		return []ast.Expr{lhs.X, lhs.Index}
+	case *ast.SelectorExpr:
+		if conv.info.Selections[lhs] == nil {
+			return nil // Package-qualified variable
+		}
+		return []ast.Expr{lhs.X}
+	case *ast.StarExpr:
+		return []ast.Expr{lhs.X}
+	default:
+		return nil
+	}
+}
+
 func (conv *converter) assign(lhs ast.Expr, expr sexp.Form) sexp.Form {
+	nodes := conv.lhsOperands(lhs)
+	operands := make([]sexp.Form, len(nodes))
+	for i, node := range nodes {
+		operands[i] = conv.Expr(node)
+	}
+	return conv.assignTo(lhs, operands, expr)
+}
+
+// assignTo stores expr into lhs.
+// Operands are converted results of conv.lhsOperands(lhs).
+func (conv *converter) assignTo(lhs ast.Expr, operands []sexp.Form, expr sexp.Form) sexp.Form {
 	expr = conv.copyValue(expr, conv.typeOf(lhs))
 	switch lhs := lhs.(type) {
 	case *ast.Ident:
@@ -121,27 +201,32 @@ func (conv *converter) assign(lhs ast.Expr, expr sexp.Form) sexp.Form {
 					Expr: expr,
 				}
 			}
+			if v := conv.boxedVar(lhs); v != nil {
+				return setDeref(boxRef(v), expr)
+			}
 			return &sexp.Rebind{Name: lhs.Name, Expr: expr}
 		}
-		return &sexp.Bind{Name: lhs.Name, Init: expr}
+		return conv.bindVar(lhs, expr)
 
 	case *ast.IndexExpr:
-		switch typ := conv.typeOf(lhs.X).(type) {
+		conv.checkNotList("index assignment", lhs.X)
+		x, index := operands[0], operands[1]
+		switch typ := conv.typeOf(lhs.X).Underlying().(type) {
 		case *types.Map:
 			return &sexp.ExprStmt{
-				Expr: conv.call(rt.FnMapInsert, lhs.Index, expr, lhs.X),
+				Expr: conv.call(rt.FnMapInsert, index, expr, x),
 			}
 
 		case *types.Array:
 			return &sexp.ArrayUpdate{
-				Array: conv.Expr(lhs.X),
-				Index: conv.Expr(lhs.Index),
+				Array: x,
+				Index: index,
 				Expr:  uintElem(expr, typ.Elem()),
 			}
 
 		case *types.Slice:
 			return &sexp.ExprStmt{
-				Expr: conv.call(rt.FnSliceSet, lhs.X, lhs.Index, expr),
+				Expr: conv.call(rt.FnSliceSet, x, index, expr),
 			}
 
 		default:
@@ -152,7 +237,7 @@ func (conv *converter) assign(lhs ast.Expr, expr sexp.Form) sexp.Form {
 		typ := conv.typeOf(lhs.X)
 		if typ, ok := typ.Underlying().(*types.Struct); ok {
 			return &sexp.StructUpdate{
-				Struct: conv.Expr(lhs.X),
+				Struct: operands[0],
 				Index:  xtypes.LookupField(lhs.Sel.Name, typ),
 				Expr:   expr,
 				Typ:    typ,
@@ -161,7 +246,7 @@ func (conv *converter) assign(lhs ast.Expr, expr sexp.Form) sexp.Form {
 		if typ, ok := typ.(*types.Pointer); ok {
 			if derefTyp, ok := typ.Elem().Underlying().(*types.Struct); ok {
 				return &sexp.StructUpdate{
-					Struct: conv.Expr(lhs.X),
+					Struct: operands[0],
 					Index:  xtypes.LookupField(lhs.Sel.Name, derefTyp),
 					Expr:   expr,
 					Typ:    derefTyp,
@@ -174,7 +259,12 @@ func (conv *converter) assign(lhs ast.Expr, expr sexp.Form) sexp.Form {
 			Expr: expr,
 		}
 
-	// #TODO: indirect assign
+	case *ast.StarExpr:
+		if typ := conv.typeOf(lhs.X).(*types.Pointer); !xtypes.IsStruct(typ.Elem()) {
+			return setDeref(operands[0], expr)
+		}
+		panic(exn.Conv(conv.fileSet, "can't assign to", lhs))
+
 	default:
 		panic(exn.Conv(conv.fileSet, "can't assign to", lhs))
 	}
@@ -186,6 +276,9 @@ func (conv *converter) ignoredExpr(expr sexp.Form) sexp.Form {
 		// Function call can not be ignored because
 		// it may have side effects.
 		return &sexp.ExprStmt{Expr: expr}
+	case *sexp.TypeAssert:
+		// Can panic; in "comma, ok" form also sets second result.
+		return &sexp.ExprStmt{Expr: expr}
 
 	default:
 		// Ignored completely.
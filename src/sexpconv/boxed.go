@@ -0,0 +1,165 @@
+package sexpconv
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"sort"
+	"xtypes"
+)
+
+// Local variables of non-struct types are boxed if their
+// address is taken. Boxed variable is bound to a cons cell
+// that holds its value, so "&x" evaluates to that cell.
+// Pointer to non-struct value is always such cell;
+// struct pointers share data with struct they point to.
+//
+// Variables that are captured by function literals are
+// also boxed if they are assigned after declaration;
+// function literal gets the box, so both sides see
+// the same variable.
+
+// collectBoxedVars marks local variables of node that
+// must be boxed.
+func (conv *converter) collectBoxedVars(node ast.Node) {
+	assigned := make(map[*types.Var]bool)
+	markAssigned := func(node ast.Expr) {
+		if id, ok := node.(*ast.Ident); ok && conv.info.Defs[id] == nil {
+			if v := conv.localVar(id); v != nil {
+				assigned[v] = true
+			}
+		}
+	}
+	var lits []*ast.FuncLit
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.UnaryExpr:
+			if node.Op != token.AND {
+				break
+			}
+			if v := conv.localVar(node.X); v != nil && !xtypes.IsStruct(v.Type()) {
+				conv.boxed[v] = true
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				markAssigned(lhs)
+			}
+		case *ast.IncDecStmt:
+			markAssigned(node.X)
+		case *ast.RangeStmt:
+			if node.Tok == token.ASSIGN {
+				markAssigned(node.Key)
+				markAssigned(node.Value)
+			}
+		case *ast.FuncLit:
+			lits = append(lits, node)
+		}
+		return true
+	})
+
+	for _, lit := range lits {
+		for _, v := range conv.capturedVars(lit) {
+			if assigned[v] {
+				conv.boxed[v] = true
+			}
+		}
+	}
+}
+
+// localVar returns local variable that is referenced by node.
+// Returns nil if node is not a local variable identifier.
+func (conv *converter) localVar(node ast.Expr) *types.Var {
+	if node == nil {
+		return nil
+	}
+	id, ok := node.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := conv.info.ObjectOf(id).(*types.Var)
+	if !ok || v.IsField() || xtypes.IsGlobal(v) {
+		return nil
+	}
+	return v
+}
+
+// boxedVar returns local variable referenced by node
+// if it is boxed; returns nil otherwise.
+func (conv *converter) boxedVar(node ast.Expr) *types.Var {
+	if v := conv.localVar(node); v != nil && conv.boxed[v] {
+		return v
+	}
+	return nil
+}
+
+// boxRef returns form that evaluates to v box.
+func boxRef(v *types.Var) sexp.Local {
+	return sexp.Local{Name: v.Name(), Typ: types.NewPointer(v.Type())}
+}
+
+// deref returns value that is stored inside box.
+func deref(box sexp.Form, typ types.Type) sexp.Form {
+	return &sexp.TypeCast{Form: sexp.NewLispCall(lisp.FnCar, box), Typ: typ}
+}
+
+// setDeref returns statement that stores val inside box.
+func setDeref(box sexp.Form, val sexp.Form) sexp.Form {
+	return &sexp.ExprStmt{Expr: sexp.NewLispCall(lisp.FnSetcar, box, val)}
+}
+
+// bindVar returns binding of local variable id.
+// Boxed variable is bound to a fresh box.
+func (conv *converter) bindVar(id *ast.Ident, init sexp.Form) *sexp.Bind {
+	if v := conv.boxedVar(id); v != nil {
+		init = sexp.NewLispCall(lisp.FnList, init)
+	}
+	return &sexp.Bind{Name: id.Name, Init: init}
+}
+
+// reboxLoopVars returns statements that move boxed variables
+// declared by loop init statement into fresh boxes.
+// They are executed before loop post statement, so every
+// iteration has its own copy of the variable, as in Go.
+func (conv *converter) reboxLoopVars(init ast.Stmt) sexp.Block {
+	assign, ok := init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE {
+		return nil
+	}
+	var forms sexp.Block
+	for _, lhs := range assign.Lhs {
+		id := lhs.(*ast.Ident)
+		if v := conv.boxedVar(id); v != nil && conv.info.Defs[id] != nil {
+			box := boxRef(v)
+			forms = append(forms, &sexp.Rebind{
+				Name: v.Name(),
+				Expr: sexp.NewLispCall(lisp.FnList, deref(box, v.Type())),
+			})
+		}
+	}
+	return forms
+}
+
+// boxParams returns statements that box parameters
+// declared inside [start, end) source range.
+func (conv *converter) boxParams(start, end token.Pos) sexp.Block {
+	var params []*types.Var
+	for v := range conv.boxed {
+		if v.Pos() >= start && v.Pos() < end {
+			params = append(params, v)
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Pos() < params[j].Pos()
+	})
+	forms := make(sexp.Block, len(params))
+	for i, v := range params {
+		forms[i] = &sexp.Rebind{
+			Name: v.Name(),
+			Expr: sexp.NewLispCall(lisp.FnList, sexp.Local{Name: v.Name(), Typ: v.Type()}),
+		}
+	}
+	return forms
+}
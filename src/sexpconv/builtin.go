@@ -8,10 +8,14 @@ import (
 	"magic_pkg/emacs/lisp"
 	"magic_pkg/emacs/rt"
 	"sexp"
+	"xtypes"
 )
 
 func (conv *converter) lenBuiltin(arg ast.Expr) sexp.Form {
-	switch typ := conv.typeOf(arg).(type) {
+	if isLispList(conv.typeOf(arg)) {
+		return conv.lispCall(lisp.FnLen, arg)
+	}
+	switch typ := conv.typeOf(arg).Underlying().(type) {
 	case *types.Map:
 		return conv.lispCall(lisp.FnHashTableCount, arg)
 
@@ -31,7 +35,8 @@ func (conv *converter) lenBuiltin(arg ast.Expr) sexp.Form {
 }
 
 func (conv *converter) capBuiltin(arg ast.Expr) sexp.Form {
-	switch typ := conv.typeOf(arg).(type) {
+	conv.checkNotList("cap", arg)
+	switch typ := conv.typeOf(arg).Underlying().(type) {
 	case *types.Array:
 		return sexp.Int(typ.Len())
 
@@ -44,7 +49,7 @@ func (conv *converter) capBuiltin(arg ast.Expr) sexp.Form {
 }
 
 func (conv *converter) makeBuiltin(args []ast.Expr) sexp.Form {
-	switch typ := conv.typeOf(args[0]).(type) {
+	switch typ := conv.typeOf(args[0]).Underlying().(type) {
 	case *types.Map:
 		if len(args) == 2 {
 			return conv.call(rt.FnMakeMapCap, args[1])
@@ -69,7 +74,23 @@ func (conv *converter) appendBuiltin(args []ast.Expr) sexp.Form {
 	}
 
 	slice := args[0]
-	dstTyp := conv.typeOf(slice).(*types.Slice).Elem()
+	conv.checkNotList("append", slice)
+	dstTyp := conv.typeOf(slice).Underlying().(*types.Slice).Elem()
 	x := conv.copyValue(conv.Expr(args[1]), dstTyp)
 	return conv.call(rt.FnSlicePush, slice, x)
 }
+
+// panicBuiltin converts "panic(x)".
+// Interface values and values that implement error are
+// passed to the run time boxed, so it can produce
+// a readable error message by calling their methods.
+func (conv *converter) panicBuiltin(arg ast.Expr) sexp.Form {
+	typ := conv.typeOf(arg)
+	if types.IsInterface(typ) && !isLispType(typ) {
+		return conv.call(rt.FnPanicIface, arg)
+	}
+	if !types.IsInterface(typ) && types.Implements(typ, xtypes.TypError.Underlying().(*types.Interface)) {
+		return conv.call(rt.FnPanicIface, conv.copyValue(conv.Expr(arg), xtypes.TypError))
+	}
+	return conv.call(rt.FnPanic, arg)
+}
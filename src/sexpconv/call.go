@@ -54,6 +54,10 @@ func (conv *converter) typeCast(node ast.Expr, typ types.Type) *sexp.TypeCast {
 }
 
 func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
+	if conv.isFuncValue(node.Fun) {
+		return conv.funcValueCall(node)
+	}
+
 	// #REFS: 2.
 	switch args := node.Args; fn := node.Fun.(type) {
 	case *ast.SelectorExpr: // x.sel()
@@ -65,23 +69,37 @@ func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
 			}
 			if !types.IsInterface(recv) {
 				// Direct method call.
-				return conv.apply(
+				recvArg := conv.Expr(fn.X)
+				call := conv.apply(
 					conv.ftab.LookupMethod(recv.Obj(), fn.Sel.Name),
-					conv.exprList(append([]ast.Expr{fn.X}, args...)),
+					append([]sexp.Form{recvArg}, conv.callArgs(node)...),
 				)
+				if isPtrRecv(sel.Obj()) {
+					// Addressable struct value is passed as is,
+					// so pointer method can modify it.
+					call.Args[0] = recvArg
+				}
+				return call
 			}
 			// Interface (polymorphic) method call.
-			if len(args) >= len(rt.FnIfaceCall) {
+			argList := conv.callArgs(node)
+			if len(argList) >= len(rt.FnIfaceCall) {
 				panic(exn.NoImpl("interface method call with more than %d arguments", len(rt.FnIfaceCall)-1))
 			}
 			iface := recv.Underlying().(*types.Interface)
-			return conv.apply(
-				rt.FnIfaceCall[len(args)],
+			call := conv.apply(
+				rt.FnIfaceCall[len(argList)],
 				append([]sexp.Form{
 					conv.Expr(fn.X),
 					sexp.Int(xtypes.LookupIfaceMethod(fn.Sel.Name, iface)),
-				}, conv.exprList(args)...),
+				}, argList...),
 			)
+			// Result has static type of the method result,
+			// so it is not boxed again.
+			if results := conv.typeOf(fn).(*types.Signature).Results(); results.Len() == 1 {
+				return &sexp.TypeCast{Form: call, Typ: results.At(0).Type()}
+			}
+			return call
 		}
 
 		pkg := fn.X.(*ast.Ident)
@@ -89,7 +107,7 @@ func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
 			return conv.intrinFuncCall(fn.Sel.Name, args)
 		}
 
-		return conv.callOrCoerce(conv.info.ObjectOf(fn.Sel).Pkg(), fn.Sel, args)
+		return conv.callOrCoerce(conv.info.ObjectOf(fn.Sel).Pkg(), fn.Sel, node)
 
 	case *ast.Ident: // f()
 		switch fn.Name {
@@ -138,9 +156,11 @@ func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
 			return conv.appendBuiltin(args)
 		case "copy":
 			dst, src := args[0], args[1]
+			conv.checkNotList("copy", dst)
+			conv.checkNotList("copy", src)
 			return conv.call(rt.FnSliceCopy, dst, src)
 		case "panic":
-			return conv.call(rt.FnPanic, args[0])
+			return conv.panicBuiltin(args[0])
 		case "print", "println":
 			// #REFS: 35.
 			argList := &sexp.LispCall{
@@ -156,7 +176,12 @@ func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
 			return conv.lispCall(lisp.FnRemhash, m, key)
 
 		default:
-			return conv.callOrCoerce(conv.ftab.MasterPkg(), fn, args)
+			// Function may belong to a package other than master.
+			p := conv.info.ObjectOf(fn).Pkg()
+			if p == nil {
+				p = conv.ftab.MasterPkg()
+			}
+			return conv.callOrCoerce(p, fn, node)
 		}
 
 	case *ast.ArrayType:
@@ -171,22 +196,120 @@ func (conv *converter) CallExpr(node *ast.CallExpr) sexp.Form {
 	}
 }
 
-func (conv *converter) callOrCoerce(p *types.Package, id *ast.Ident, args []ast.Expr) sexp.Form {
+func (conv *converter) callOrCoerce(p *types.Package, id *ast.Ident, node *ast.CallExpr) sexp.Form {
 	fn := conv.ftab.LookupFunc(p, id.Name)
 	if fn != nil {
 		// Call.
-		return conv.apply(fn, conv.exprList(args))
+		return conv.apply(fn, conv.callArgs(node))
 	}
 	// Coerce.
-	arg := conv.Expr(args[0])
+	arg := conv.Expr(node.Args[0])
 	dstTyp := arg.Type()
 	typ := conv.typeOf(id)
 	if _, ok := typ.Underlying().(*types.Basic); ok {
-		return arg // #REFS: 25
+		return &sexp.TypeCast{Form: arg, Typ: typ} // #REFS: 25
 	}
-	if types.Identical(typ.Underlying(), dstTyp) {
-		return arg // #REFS: 25
+	if types.Identical(typ.Underlying(), dstTyp.Underlying()) {
+		return &sexp.TypeCast{Form: arg, Typ: typ} // #REFS: 25
 	}
 	// #REFS: 44.
 	panic(exn.NoImpl("struct conversions"))
 }
+
+// callArgs converts Go function call arguments.
+// Interface arguments are boxed according to the signature.
+// Variadic arguments are packed into a slice,
+// unless they are already passed as a slice ("f(xs...)").
+func (conv *converter) callArgs(node *ast.CallExpr) []sexp.Form {
+	sig, ok := conv.typeOf(node.Fun).(*types.Signature)
+	if !ok || conv.isTupleArg(node) {
+		return conv.exprList(node.Args)
+	}
+	n := sig.Params().Len()
+	if sig.Variadic() && !node.Ellipsis.IsValid() {
+		n--
+	}
+
+	args := make([]sexp.Form, len(node.Args))
+	for i, arg := range node.Args {
+		var typ types.Type
+		if i < n {
+			typ = sig.Params().At(i).Type()
+		} else {
+			typ = sig.Params().At(n).Type().(*types.Slice).Elem()
+		}
+		conv.ctxType = typ // Needed for untyped nil arguments
+		args[i] = conv.Expr(arg)
+		if types.IsInterface(typ) {
+			args[i] = conv.copyValue(args[i], typ)
+		}
+	}
+	if n == sig.Params().Len() {
+		return args
+	}
+
+	typ := sig.Params().At(n).Type().(*types.Slice)
+	vals := append([]sexp.Form(nil), args[n:]...)
+	return append(args[:n], &sexp.SliceLit{Vals: vals, Typ: typ})
+}
+
+// isTupleArg reports whether call is "f(g())" where g
+// returns multiple values.
+func (conv *converter) isTupleArg(node *ast.CallExpr) bool {
+	if len(node.Args) != 1 {
+		return false
+	}
+	_, ok := conv.typeOf(node.Args[0]).(*types.Tuple)
+	return ok
+}
+
+// isFuncValue reports whether callee is a function value
+// rather than a function, method, builtin or type name.
+func (conv *converter) isFuncValue(fn ast.Expr) bool {
+	tv := conv.info.Types[fn]
+	if !tv.IsValue() {
+		return false
+	}
+	if _, ok := tv.Type.Underlying().(*types.Signature); !ok {
+		return false
+	}
+	switch fn := fn.(type) {
+	case *ast.ParenExpr:
+		return conv.isFuncValue(fn.X)
+	case *ast.Ident:
+		_, ok := conv.info.Uses[fn].(*types.Var)
+		return ok
+	case *ast.SelectorExpr:
+		if sel := conv.info.Selections[fn]; sel != nil {
+			return sel.Kind() == types.FieldVal
+		}
+		_, ok := conv.info.Uses[fn.Sel].(*types.Var)
+		return ok
+	default:
+		return true
+	}
+}
+
+// funcValueCall calls function value with "funcall".
+// Results are returned in the same way as for direct calls.
+func (conv *converter) funcValueCall(node *ast.CallExpr) sexp.Form {
+	sig := conv.typeOf(node.Fun).Underlying().(*types.Signature)
+	var typ types.Type = xtypes.EmptyTuple
+	if results := sig.Results(); results.Len() == 1 {
+		typ = results.At(0).Type()
+	} else if results.Len() > 1 {
+		typ = results
+	}
+	return &sexp.DynCall{
+		Callable: conv.Expr(node.Fun),
+		Args:     conv.callArgs(node),
+		Typ:      typ,
+	}
+}
+
+// isPtrRecv reports whether method has a pointer receiver.
+func isPtrRecv(method types.Object) bool {
+	recv := method.Type().(*types.Signature).Recv()
+	_, ok := recv.Type().(*types.Pointer)
+	return ok
+}
@@ -1,6 +1,7 @@
 package sexpconv
 
 import (
+	"exn"
 	"go/types"
 	"magic_pkg/emacs/lisp"
 	"magic_pkg/emacs/rt"
@@ -21,8 +22,15 @@ func (conv *converter) copyStruct(typ *types.Named, form sexp.Form) *sexp.Struct
 	return &sexp.StructLit{Vals: vals, Typ: typ}
 }
 
+// Function call result is a fresh value, so it is not copied;
+// copying would also evaluate the call more than once.
+func isCall(form sexp.Form) bool {
+	_, ok := form.(*sexp.Call)
+	return ok
+}
+
 func (conv *converter) copyNamed(typ *types.Named, form sexp.Form) sexp.Form {
-	if xtypes.IsStruct(typ) && !isStructLit(form) {
+	if xtypes.IsStruct(typ) && !isStructLit(form) && !isCall(form) {
 		return conv.copyStruct(typ, form)
 	}
 	// #FIXME: copy interface types?
@@ -48,16 +56,61 @@ func (conv *converter) copyValue(form sexp.Form, dstTyp types.Type) sexp.Form {
 	}
 
 	if dstTyp != nil && types.IsInterface(dstTyp) {
-		dstTyp := dstTyp.(*types.Named)
-		if dstTyp.Obj().Pkg() == lisp.Package || types.Identical(typ, dstTyp) {
-			return res
+		return conv.boxValue(res, typ, dstTyp)
+	}
+	return res
+}
+
+// boxValue converts value of typ to dstTyp interface value.
+func (conv *converter) boxValue(form sexp.Form, typ, dstTyp types.Type) sexp.Form {
+	switch {
+	case isLispType(dstTyp):
+		return form // Emacs Lisp values are never boxed
+	case identical(typ, dstTyp), identical(typ, lisp.TypAny):
+		return form
+	case isUntypedNil(typ):
+		return form // Nil value is already context-typed
+	case types.IsInterface(typ) && !isLispType(typ):
+		// Interface-to-interface conversion.
+		// Dynamic type is preserved.
+		if dstTyp.Underlying().(*types.Interface).Empty() {
+			return form
 		}
-		itab := conv.itabEnv.Intern(xtypes.AsNamedType(typ), dstTyp)
-		return sexp.NewCall(
+		checkIfaceMethods(dstTyp)
+		return &sexp.TypeCast{
+			Form: sexp.NewCall(
+				rt.FnIfaceConvert,
+				form,
+				sexp.Symbol{Val: conv.itabEnv.InternType(dstTyp)},
+			),
+			Typ: dstTyp,
+		}
+	}
+
+	itab := conv.itabEnv.Intern(typ, dstTyp)
+	// Result is typed to avoid boxing of already boxed value.
+	return &sexp.TypeCast{
+		Form: sexp.NewCall(
 			rt.FnMakeIface,
 			sexp.Var{Name: itab, Typ: lisp.TypObject},
-			res,
-		)
+			form,
+		),
+		Typ: dstTyp,
+	}
+}
+
+// checkIfaceMethods panics if interface type can not be
+// a target of run-time conversion.
+// Itabs are built by method names, but type descriptors
+// contain only exported methods.
+func checkIfaceMethods(typ types.Type) {
+	iface := typ.Underlying().(*types.Interface)
+	if isLispType(typ) {
+		panic(exn.NoImpl("run-time conversion to `%s'", typ))
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if !iface.Method(i).Exported() {
+			panic(exn.NoImpl("run-time conversion to `%s' (unexported methods)", typ))
+		}
 	}
-	return res
 }
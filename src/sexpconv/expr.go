@@ -9,6 +9,7 @@ import (
 	"magic_pkg/emacs/lisp"
 	"magic_pkg/emacs/rt"
 	"sexp"
+	"tu/symbols"
 	"xtypes"
 )
 
@@ -45,6 +46,8 @@ func (conv *converter) Expr(node ast.Expr) sexp.Form {
 		return conv.SliceExpr(node)
 	case *ast.StarExpr:
 		return conv.StarExpr(node)
+	case *ast.FuncLit:
+		return conv.FuncLit(node)
 
 	default:
 		panic(errUnexpectedExpr(conv, node))
@@ -59,29 +62,29 @@ func (conv *converter) Ident(node *ast.Ident) sexp.Form {
 	obj := conv.info.Uses[node]
 	typ := obj.Type()
 
-	if typ, ok := typ.(*types.Basic); ok {
-		// Coerce untyped nil to correct value depending on
-		// the context type.
-		if typ.Kind() == types.UntypedNil {
-			switch conv.ctxType.(type) {
-			case *types.Map:
-				return nilMap
-			case *types.Slice:
-				return nilSlice
-			case *types.Signature:
-				return nilFunc
-			case *types.Interface:
-				return nilInterface
-			}
+	// Coerce untyped nil to correct value depending on
+	// the context type.
+	if isUntypedNil(typ) && conv.ctxType != nil {
+		if form := nilValue(conv.ctxType); form != nil {
+			return form
 		}
 	}
 
+	if fn, ok := obj.(*types.Func); ok {
+		return &sexp.TypeCast{
+			Form: sexp.Symbol{Val: symbols.MangleFunc(fn)},
+			Typ:  typ,
+		}
+	}
 	if xtypes.IsGlobal(obj) {
 		return sexp.Var{
-			Name: conv.env.InternVar(nil, node.Name),
+			Name: conv.env.InternVar(obj.Pkg(), node.Name),
 			Typ:  typ,
 		}
 	}
+	if v := conv.boxedVar(node); v != nil {
+		return deref(boxRef(v), typ)
+	}
 	return sexp.Local{
 		Name: node.Name,
 		Typ:  typ,
@@ -93,6 +96,12 @@ func (conv *converter) BinaryExpr(node *ast.BinaryExpr) sexp.Form {
 		return cv
 	}
 
+	if node.Op == token.EQL || node.Op == token.NEQ {
+		if form := conv.refEquality(node); form != nil {
+			return form
+		}
+	}
+
 	typ := conv.basicTypeOf(node.X)
 	x, y := conv.Expr(node.X), conv.Expr(node.Y)
 
@@ -106,6 +115,8 @@ func (conv *converter) BinaryExpr(node *ast.BinaryExpr) sexp.Form {
 			return sexp.NewMul(x, y)
 		case token.QUO:
 			return sexp.NewQuo(x, y)
+		case token.REM:
+			return sexp.NewRem(x, y)
 		case token.EQL:
 			return sexp.NewNumEq(x, y)
 		case token.NEQ:
@@ -138,6 +149,8 @@ func (conv *converter) BinaryExpr(node *ast.BinaryExpr) sexp.Form {
 			return sexp.NewConcat(x, y)
 		case token.EQL:
 			return sexp.NewStrEq(x, y)
+		case token.NEQ:
+			return sexp.NewNot(sexp.NewStrEq(x, y))
 		case token.LSS:
 			return sexp.NewStrLt(x, y)
 		case token.GTR:
@@ -164,6 +177,40 @@ func (conv *converter) BinaryExpr(node *ast.BinaryExpr) sexp.Form {
 	panic(errUnexpectedExpr(conv, node))
 }
 
+// refEquality returns "==" or "!=" for reference-like types.
+// Returns nil if operands have other types.
+func (conv *converter) refEquality(node *ast.BinaryExpr) sexp.Form {
+	typ := conv.typeOf(node.X)
+	if isUntypedNil(typ) || types.IsInterface(conv.typeOf(node.Y)) {
+		typ = conv.typeOf(node.Y)
+	}
+
+	switch typ.Underlying().(type) {
+	case *types.Interface, *types.Pointer, *types.Map, *types.Slice,
+		*types.Signature, *types.Chan:
+		// Comparable by reference (or boxed value).
+	default:
+		return nil
+	}
+
+	conv.ctxType = typ
+	x, y := conv.Expr(node.X), conv.Expr(node.Y)
+	var cmp sexp.Form
+	isNilCmp := isUntypedNil(conv.typeOf(node.X)) || isUntypedNil(conv.typeOf(node.Y))
+	if types.IsInterface(typ) && !isLispType(typ) && !isNilCmp {
+		// Dynamic types and values must be equal.
+		x, y = conv.copyValue(x, typ), conv.copyValue(y, typ)
+		cmp = conv.call(rt.FnIfaceEq, x, y)
+	} else {
+		cmp = sexp.NewLispCall(lisp.FnEq, x, y)
+	}
+
+	if node.Op == token.NEQ {
+		return sexp.NewNot(cmp)
+	}
+	return cmp
+}
+
 func (conv *converter) structIndex(typ *types.Struct, node *ast.SelectorExpr) *sexp.StructIndex {
 	return &sexp.StructIndex{
 		Struct: conv.Expr(node.X),
@@ -195,6 +242,20 @@ func (conv *converter) SelectorExpr(node *ast.SelectorExpr) sexp.Form {
 	}
 	switch obj := conv.info.Uses[id].(type) {
 	case *types.PkgName:
+		if obj.Imported() != lisp.Package {
+			switch member := conv.info.Uses[node.Sel].(type) {
+			case *types.Func:
+				return &sexp.TypeCast{
+					Form: sexp.Symbol{Val: symbols.MangleFunc(member)},
+					Typ:  member.Type(),
+				}
+			case *types.Var:
+				return sexp.Var{
+					Name: conv.env.InternVar(obj.Imported(), node.Sel.Name),
+					Typ:  member.Type(),
+				}
+			}
+		}
 		return sexp.Symbol{
 			Val: conv.env.InternVar(obj.Imported(), node.Sel.Name),
 		}
@@ -208,6 +269,12 @@ func (conv *converter) UnaryExpr(node *ast.UnaryExpr) sexp.Form {
 		return cv
 	}
 
+	if node.Op == token.AND {
+		// Boxed struct still shares data with its pointers.
+		if v := conv.boxedVar(node.X); v != nil && !xtypes.IsStruct(v.Type()) {
+			return boxRef(v)
+		}
+	}
 	x := conv.Expr(node.X)
 
 	switch node.Op {
@@ -236,20 +303,35 @@ func (conv *converter) takeAddr(form sexp.Form) sexp.Form {
 }
 
 func (conv *converter) TypeAssertExpr(node *ast.TypeAssertExpr) sexp.Form {
+	if form := conv.seqFromLisp(node); form != nil {
+		return form
+	}
 	expr := conv.Expr(node.X)
 	assertTyp := conv.typeOf(node.Type)
-	return &sexp.TypeAssert{Expr: expr, Typ: assertTyp}
+	// In "v, ok := x.(T)" context expression has (T, bool) type.
+	_, commaOk := conv.typeOf(node).(*types.Tuple)
+	if types.IsInterface(assertTyp) && !isLispType(assertTyp) {
+		checkIfaceMethods(assertTyp)
+	}
+	conv.itabEnv.InternType(assertTyp)
+	return &sexp.TypeAssert{Expr: expr, Typ: assertTyp, CommaOk: commaOk}
 }
 
 func (conv *converter) IndexExpr(node *ast.IndexExpr) sexp.Form {
-	switch typ := conv.typeOf(node.X).(type) {
+	if isLispList(conv.typeOf(node.X)) {
+		return conv.lispCall(lisp.FnNth, node.Index, node.X)
+	}
+	switch typ := conv.typeOf(node.X).Underlying().(type) {
 	case *types.Map:
-		return conv.lispCall(
-			lisp.FnGethash,
-			node.Index,
-			node.X,
-			ZeroValue(typ.Elem()),
-		)
+		return &sexp.TypeCast{
+			Form: conv.lispCall(
+				lisp.FnGethash,
+				node.Index,
+				node.X,
+				ZeroValue(typ.Elem()),
+			),
+			Typ: typ.Elem(),
+		}
 
 	case *types.Array:
 		return &sexp.ArrayIndex{
@@ -258,7 +340,10 @@ func (conv *converter) IndexExpr(node *ast.IndexExpr) sexp.Form {
 		}
 
 	case *types.Slice:
-		return conv.call(rt.FnSliceGet, node.X, node.Index)
+		return &sexp.TypeCast{
+			Form: conv.call(rt.FnSliceGet, node.X, node.Index),
+			Typ:  typ.Elem(),
+		}
 
 	case *types.Basic:
 		assert.True(typ.Kind() == types.String)
@@ -270,6 +355,7 @@ func (conv *converter) IndexExpr(node *ast.IndexExpr) sexp.Form {
 }
 
 func (conv *converter) SliceExpr(node *ast.SliceExpr) sexp.Form {
+	conv.checkNotList("slice expression", node.X)
 	low := conv.ExprOrNil(node.Low)
 	high := conv.ExprOrNil(node.High)
 	x := conv.Expr(node.X)
@@ -284,6 +370,9 @@ func (conv *converter) SliceExpr(node *ast.SliceExpr) sexp.Form {
 }
 
 func (conv *converter) CompositeLit(node *ast.CompositeLit) sexp.Form {
+	if isLispList(conv.typeOf(node)) {
+		return conv.listLit(node)
+	}
 	switch typ := conv.typeOf(node).(type) {
 	case *types.Array:
 		return conv.arrayLit(node, typ)
@@ -298,11 +387,9 @@ func (conv *converter) CompositeLit(node *ast.CompositeLit) sexp.Form {
 }
 
 func (conv *converter) sliceLit(node *ast.CompositeLit, typ *types.Slice) sexp.Form {
-	if len(node.Elts) == 0 {
-		return ZeroValue(typ)
-	}
-
-	return &sexp.SliceLit{Vals: conv.exprList(node.Elts), Typ: typ}
+	elts := conv.exprList(node.Elts)
+	conv.copyValueList(elts, typ.Elem())
+	return &sexp.SliceLit{Vals: elts, Typ: typ}
 }
 
 func (conv *converter) arrayLit(node *ast.CompositeLit, typ *types.Array) sexp.Form {
@@ -357,6 +444,9 @@ func (conv *converter) structLit(node *ast.CompositeLit, typ *types.Named) sexp.
 
 func (conv *converter) StarExpr(node *ast.StarExpr) sexp.Form {
 	typ := conv.typeOf(node.X).(*types.Pointer)
+	if !xtypes.IsStruct(typ.Elem()) {
+		return deref(conv.Expr(node.X), typ.Elem())
+	}
 	if derefTyp, ok := typ.Elem().(*types.Named); ok {
 		return conv.copyNamed(derefTyp, conv.Expr(node.X))
 	}
@@ -5,13 +5,21 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"magic_pkg/emacs/rt"
 	"sexp"
+	"strconv"
+	"xtypes"
 )
 
 func (conv *converter) RangeStmt(node *ast.RangeStmt) sexp.Form {
-	switch typ := conv.typeOf(node.X).(type) {
+	if isLispList(conv.typeOf(node.X)) {
+		return conv.foreachList(node)
+	}
+	switch typ := conv.typeOf(node.X).Underlying().(type) {
 	case *types.Array:
 		return conv.foreachArray(node, typ)
+	case *types.Slice:
+		return conv.foreachSlice(node, typ)
 
 	default:
 		panic(exn.NoImpl("for/range for %T", typ))
@@ -29,16 +37,16 @@ func (conv *converter) foreachArray(node *ast.RangeStmt, typ *types.Array) sexp.
 	if node.Value == nil && node.Key == nil {
 		return &sexp.Repeat{N: typ.Len(), Body: body}
 	}
-	key := node.Key.(*ast.Ident)
-	keyTyp := conv.basicTypeOf(key)
 
 	// for <KEY> := range <X>.
 	if node.Value == nil {
+		conv.nrange++
+		iter := sexp.Local{Name: "_i" + strconv.Itoa(conv.nrange), Typ: xtypes.TypInt}
 		return &sexp.DoTimes{
 			N:    sexp.Int(typ.Len()),
-			Iter: sexp.Local{Name: key.Name, Typ: keyTyp},
+			Iter: iter,
 			Step: sexp.Int(1),
-			Body: body,
+			Body: conv.bindRangeKey(node, iter, body),
 		}
 	}
 
@@ -46,6 +54,46 @@ func (conv *converter) foreachArray(node *ast.RangeStmt, typ *types.Array) sexp.
 	panic(exn.NoImpl("for loop variant"))
 }
 
+// foreachSlice converts range loop over slice into DoTimes.
+// Slice and its length are evaluated once, before the loop;
+// hidden counter runs over [0, len), so index is always
+// in bounds and elements can be accessed directly
+// (see opt.OptimizeLoops).
+func (conv *converter) foreachSlice(node *ast.RangeStmt, typ *types.Slice) sexp.Form {
+	if node.Tok == token.ASSIGN {
+		panic(exn.NoImpl("'=' assign in for initializer"))
+	}
+
+	conv.nrange++
+	suffix := strconv.Itoa(conv.nrange)
+	slice := sexp.Local{Name: "_slice" + suffix, Typ: typ}
+	iter := sexp.Local{Name: "_i" + suffix, Typ: xtypes.TypInt}
+
+	body := conv.BlockStmt(node.Body)
+	// For <KEY>, <VAL> := range <X>.
+	if val, ok := node.Value.(*ast.Ident); ok && val.Name != "_" {
+		elem := &sexp.TypeCast{
+			Form: conv.call(rt.FnSliceGet, slice, iter),
+			Typ:  typ.Elem(),
+		}
+		bind := conv.bindVar(val, conv.copyValue(elem, nil))
+		body = append(sexp.Block{bind}, body...)
+	}
+	body = conv.bindRangeKey(node, iter, body)
+
+	n := sexp.Local{Name: "_len" + suffix, Typ: xtypes.TypInt}
+	return sexp.Block{
+		&sexp.Bind{Name: slice.Name, Init: conv.Expr(node.X)},
+		&sexp.Bind{Name: n.Name, Init: conv.call(rt.FnSliceLen, slice)},
+		&sexp.DoTimes{
+			N:    n,
+			Iter: iter,
+			Step: sexp.Int(1),
+			Body: body,
+		},
+	}
+}
+
 func (conv *converter) ForStmt(node *ast.ForStmt) sexp.Form {
 	var (
 		post sexp.Form
@@ -57,6 +105,9 @@ func (conv *converter) ForStmt(node *ast.ForStmt) sexp.Form {
 	} else {
 		post = conv.Stmt(node.Post)
 	}
+	if rebox := conv.reboxLoopVars(node.Init); len(rebox) != 0 {
+		post = sexp.FormList(append(rebox, post))
+	}
 	if node.Init == nil {
 		init = sexp.EmptyForm
 	} else {
@@ -79,3 +130,15 @@ func (conv *converter) ForStmt(node *ast.ForStmt) sexp.Form {
 		Body: body,
 	}
 }
+
+// bindRangeKey prepends range loop key binding to body.
+// Loops count on hidden iter local; key is a fresh copy of it
+// on each iteration, so body can assign key without changing
+// the iteration.
+func (conv *converter) bindRangeKey(node *ast.RangeStmt, iter sexp.Local, body sexp.Block) sexp.Block {
+	key, ok := node.Key.(*ast.Ident)
+	if !ok || key.Name == "_" {
+		return body
+	}
+	return append(sexp.Block{conv.bindVar(key, iter)}, body...)
+}
@@ -0,0 +1,92 @@
+package sexpconv
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"xtypes"
+)
+
+// FuncLit lifts function literal to the package level.
+// Captured variables become leading parameters of lifted function;
+// literal value is that function partially applied to them.
+//
+// Variables are captured by value, unless they are boxed;
+// boxed variables are captured by reference (see collectBoxedVars).
+func (conv *converter) FuncLit(node *ast.FuncLit) sexp.Form {
+	sig := conv.typeOf(node).(*types.Signature)
+	captured := conv.capturedVars(node)
+
+	conv.nlambda++
+	fn := &sexp.Func{
+		Name:    fmt.Sprintf("%s.func%d", conv.funcName, conv.nlambda),
+		Params:  make([]string, 0, len(captured)+sig.Params().Len()),
+		Results: sig.Results(),
+	}
+	if fn.Results == nil {
+		fn.Results = xtypes.EmptyTuple
+	}
+	for _, v := range captured {
+		fn.Params = append(fn.Params, v.Name())
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		fn.Params = append(fn.Params, sig.Params().At(i).Name())
+	}
+
+	retType := conv.retType
+	conv.retType = fn.Results
+	fn.Body = append(conv.boxParams(node.Pos(), node.Body.Pos()), conv.BlockStmt(node.Body)...)
+	if fn.Results == xtypes.EmptyTuple {
+		fn.Body = append(fn.Body, &sexp.Return{})
+	}
+	conv.retType = retType
+	*conv.lambdas = append(*conv.lambdas, fn)
+
+	if len(captured) == 0 {
+		return &sexp.TypeCast{Form: sexp.Symbol{Val: fn.Name}, Typ: sig}
+	}
+	args := make([]sexp.Form, 0, len(captured)+1)
+	args = append(args, sexp.Symbol{Val: fn.Name})
+	for _, v := range captured {
+		if conv.boxed[v] {
+			args = append(args, boxRef(v)) // Captured by reference
+		} else {
+			args = append(args, sexp.Local{Name: v.Name(), Typ: v.Type()})
+		}
+	}
+	return &sexp.TypeCast{
+		Form: &sexp.LispCall{Fn: lisp.FnApplyPartially, Args: args},
+		Typ:  sig,
+	}
+}
+
+// capturedVars returns local variables that are used inside
+// function literal, but declared outside of it.
+func (conv *converter) capturedVars(node *ast.FuncLit) []*types.Var {
+	var captured []*types.Var
+	seen := make(map[*types.Var]bool)
+	isCaptured := func(id *ast.Ident) *types.Var {
+		v, ok := conv.info.Uses[id].(*types.Var)
+		if !ok || v.IsField() || xtypes.IsGlobal(v) {
+			return nil
+		}
+		if v.Pos() >= node.Pos() && v.Pos() < node.End() {
+			return nil
+		}
+		return v
+	}
+
+	ast.Inspect(node.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if v := isCaptured(id); v != nil && !seen[v] {
+				seen[v] = true
+				captured = append(captured, v)
+			}
+		}
+		return true
+	})
+
+	return captured
+}
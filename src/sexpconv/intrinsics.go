@@ -2,31 +2,54 @@ package sexpconv
 
 import (
 	"assert"
+	"exn"
 	"go/ast"
 	"go/constant"
+	"go/types"
 	"magic_pkg/emacs/lisp"
 	"magic_pkg/emacs/rt"
 	"sexp"
+	"xtypes"
 )
 
 func (conv *converter) lispObjectMethod(fn string, recv ast.Expr, args []ast.Expr) sexp.Form {
+	// Coercion functions return lisp.Object; result type
+	// is restored to make it usable in typed contexts.
 	switch fn {
 	case "Bool":
-		return conv.call(rt.FnCoerceBool, recv)
+		return coerced(conv.call(rt.FnCoerceBool, recv), xtypes.TypBool)
 	case "Int":
-		return conv.call(rt.FnCoerceInt, recv)
+		return coerced(conv.call(rt.FnCoerceInt, recv), xtypes.TypInt)
 	case "Float":
-		return conv.call(rt.FnCoerceFloat, recv)
+		return coerced(conv.call(rt.FnCoerceFloat, recv), xtypes.TypFloat64)
 	case "String":
-		return conv.call(rt.FnCoerceString, recv)
+		return coerced(conv.call(rt.FnCoerceString, recv), xtypes.TypString)
 	case "Symbol":
-		return conv.call(rt.FnCoerceSymbol, recv)
+		return coerced(conv.call(rt.FnCoerceSymbol, recv), lisp.TypSymbol)
+	case "Buffer":
+		return coerced(conv.call(rt.FnCoerceBuffer, recv), lisp.TypBuffer)
+	case "Marker":
+		return coerced(conv.call(rt.FnCoerceMarker, recv), lisp.TypMarker)
+	case "Window":
+		return coerced(conv.call(rt.FnCoerceWindow, recv), lisp.TypWindow)
+	case "Process":
+		return coerced(conv.call(rt.FnCoerceProcess, recv), lisp.TypProcess)
+	case "HashTable":
+		return coerced(conv.call(rt.FnCoerceHashTable, recv), lisp.TypHashTable)
+	case "Cons":
+		return coerced(conv.call(rt.FnCoerceCons, recv), lisp.TypCons)
+	case "List":
+		return coerced(conv.call(rt.FnCoerceList, recv), lisp.TypList)
 	}
 
 	assert.Unreachable()
 	return nil
 }
 
+func coerced(form sexp.Form, typ types.Type) sexp.Form {
+	return &sexp.TypeCast{Form: form, Typ: typ}
+}
+
 func (conv *converter) intrinFuncCall(sym string, args []ast.Expr) sexp.Form {
 	switch sym {
 	case "Int", "Float", "Str", "Symbol", "Bool":
@@ -38,7 +61,7 @@ func (conv *converter) intrinFuncCall(sym string, args []ast.Expr) sexp.Form {
 	case "DynCall":
 		return &sexp.DynCall{
 			Callable: conv.Expr(args[0]),
-			Args:     conv.exprList(args[1:]),
+			Args:     conv.lispArgs(args[1:]),
 			Typ:      lisp.TypObject,
 		}
 
@@ -48,21 +71,33 @@ func (conv *converter) intrinFuncCall(sym string, args []ast.Expr) sexp.Form {
 			name := constant.StringVal(cv)
 			return conv.lispApply(
 				lisp.InternFunc(name),
-				conv.exprList(args[1:]),
+				conv.lispArgs(args[1:]),
 			)
 		}
 		return &sexp.DynCall{
 			Callable: conv.Expr(args[0]),
-			Args:     conv.exprList(args[1:]),
+			Args:     conv.lispArgs(args[1:]),
 			Typ:      lisp.TypObject,
 		}
 
 	case "Intern":
 		return conv.intrinIntern(args[0])
 
+	case "Any":
+		return &sexp.TypeCast{Form: conv.Expr(args[0]), Typ: lisp.TypAny}
+
+	case "Lambda":
+		return conv.lispLambda(args[0])
+
+	case "SliceToVector", "SliceToList", "MapToAlist", "MapToPlist":
+		return conv.seqToLisp(sym, args[0])
+	case "VectorToSlice", "ListToSlice", "AlistToMap", "PlistToMap":
+		// Valid calls are handled by seqFromLisp.
+		panic(exn.User("lisp.%s result must be immediately type asserted", sym))
+
 	default:
 		fn := lisp.FFI[sym]
-		args := conv.exprList(args)
+		args := conv.lispArgs(args)
 		return &sexp.LispCall{Fn: fn, Args: args}
 	}
 }
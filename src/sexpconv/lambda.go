@@ -0,0 +1,181 @@
+package sexpconv
+
+import (
+	"exn"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"sexp"
+)
+
+// lispLambda converts "lisp.Lambda(fn)" call.
+//
+// Adapter function is lifted to the package level, like
+// function literals are. It takes fn as a leading parameter,
+// so the result is adapter partially applied to fn.
+func (conv *converter) lispLambda(arg ast.Expr) sexp.Form {
+	sig, ok := conv.typeOf(arg).Underlying().(*types.Signature)
+	if !ok {
+		panic(exn.User("lisp.Lambda argument must be a function, got `%s'", conv.typeOf(arg)))
+	}
+
+	conv.nlambda++
+	fn := &sexp.Func{
+		Name:     fmt.Sprintf("%s.lambda%d", conv.funcName, conv.nlambda),
+		Params:   []string{"fn"},
+		Variadic: sig.Variadic(),
+		Results:  types.NewTuple(types.NewVar(token.NoPos, nil, "", lisp.TypObject)),
+	}
+	args := make([]sexp.Form, sig.Params().Len())
+	for i := range args {
+		name := fmt.Sprintf("arg%d", i)
+		fn.Params = append(fn.Params, name)
+		args[i] = conv.lambdaArg(sexp.Local{Name: name, Typ: lisp.TypObject}, sig.Params().At(i).Type())
+	}
+	fn.Body = conv.lambdaBody(sexp.Local{Name: "fn", Typ: sig}, args, sig.Results())
+	*conv.lambdas = append(*conv.lambdas, fn)
+
+	return &sexp.TypeCast{
+		Form: sexp.NewLispCall(lisp.FnApplyPartially, sexp.Symbol{Val: fn.Name}, conv.Expr(arg)),
+		Typ:  lisp.TypObject,
+	}
+}
+
+// lispArgs converts arguments of Emacs Lisp function call.
+// Go function values are passed like "lisp.Lambda(fn)" is,
+// so their arguments are coerced to parameter types.
+func (conv *converter) lispArgs(args []ast.Expr) []sexp.Form {
+	forms := make([]sexp.Form, len(args))
+	for i, arg := range args {
+		if _, ok := conv.typeOf(arg).Underlying().(*types.Signature); ok {
+			forms[i] = conv.lispLambda(arg)
+		} else {
+			forms[i] = conv.Expr(arg)
+		}
+	}
+	return forms
+}
+
+// lambdaBody returns adapter body that calls Go function
+// and converts its results. Function without results returns nil;
+// multiple results are collected into a list.
+func (conv *converter) lambdaBody(fn sexp.Local, args []sexp.Form, results *types.Tuple) sexp.Block {
+	var typ types.Type = results
+	if results.Len() == 1 {
+		typ = results.At(0).Type()
+	}
+	call := &sexp.DynCall{Callable: fn, Args: args, Typ: typ}
+
+	switch results.Len() {
+	case 0:
+		return sexp.Block{
+			&sexp.ExprStmt{Expr: call},
+			&sexp.Return{Results: []sexp.Form{sexp.Nil}},
+		}
+	case 1:
+		return sexp.Block{
+			&sexp.Return{Results: []sexp.Form{conv.lambdaResult(call, typ)}},
+		}
+	}
+
+	// Results are saved before conversion; conversion
+	// functions may overwrite RetN variables.
+	body := make(sexp.Block, 0, results.Len()+1)
+	vals := make([]sexp.Form, results.Len())
+	for i := range vals {
+		name := fmt.Sprintf("res%d", i)
+		var res sexp.Form = call
+		if i != 0 {
+			res = sexp.Var{Name: rt.RetVars[i], Typ: results.At(i).Type()}
+		}
+		body = append(body, &sexp.Bind{Name: name, Init: res})
+		vals[i] = conv.lambdaResult(sexp.Local{Name: name, Typ: results.At(i).Type()}, results.At(i).Type())
+	}
+	list := sexp.NewLispCall(lisp.FnList, vals...)
+	return append(body, &sexp.Return{Results: []sexp.Form{list}})
+}
+
+// lambdaArg coerces Emacs Lisp argument to Go parameter type.
+// Checks are the same as in lisp.Object methods.
+func (conv *converter) lambdaArg(arg sexp.Form, typ types.Type) sexp.Form {
+	if fn := lispTypeCoercion(typ); fn != nil {
+		return coerced(conv.call(fn, arg), typ)
+	}
+	if isLispType(typ) {
+		return coerced(arg, typ) // lisp.Object and lisp.any
+	}
+
+	switch utyp := typ.Underlying().(type) {
+	case *types.Basic:
+		switch info := utyp.Info(); {
+		case info&types.IsBoolean != 0:
+			return coerced(conv.call(rt.FnCoerceBool, arg), typ)
+		case info&types.IsInteger != 0:
+			return coerced(conv.call(rt.FnCoerceInt, arg), typ)
+		case info&types.IsFloat != 0:
+			return coerced(conv.call(rt.FnCoerceFloat, arg), typ)
+		case info&types.IsString != 0:
+			return coerced(conv.call(rt.FnCoerceString, arg), typ)
+		}
+	case *types.Slice:
+		// Both lists and vectors are accepted.
+		return coerced(conv.call(rt.FnListToSlice, arg), typ)
+	case *types.Map:
+		return coerced(conv.call(rt.FnAlistToMap, arg), typ)
+	case *types.Signature:
+		return coerced(arg, typ) // Any Emacs Lisp callable
+	case *types.Interface:
+		if utyp.Empty() {
+			return conv.boxValue(arg, lisp.TypObject, typ)
+		}
+	}
+	panic(exn.NoImpl("lisp.Lambda parameter of type `%s'", typ))
+}
+
+// lambdaResult converts Go result to Emacs Lisp value.
+func (conv *converter) lambdaResult(res sexp.Form, typ types.Type) sexp.Form {
+	if isLispType(typ) {
+		return res
+	}
+
+	switch utyp := typ.Underlying().(type) {
+	case *types.Basic:
+		if utyp.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) != 0 {
+			return res
+		}
+	case *types.Slice:
+		return coerced(conv.call(rt.FnSliceToList, res), lisp.TypList)
+	case *types.Map:
+		return coerced(conv.call(rt.FnMapToAlist, res), lisp.TypList)
+	case *types.Signature:
+		return res
+	}
+	panic(exn.NoImpl("lisp.Lambda result of type `%s'", typ))
+}
+
+// lispTypeCoercion returns coercion function for opaque
+// "emacs/lisp" types. Returns nil for other types.
+func lispTypeCoercion(typ types.Type) *sexp.Func {
+	switch {
+	case types.Identical(typ, lisp.TypSymbol):
+		return rt.FnCoerceSymbol
+	case types.Identical(typ, lisp.TypBuffer):
+		return rt.FnCoerceBuffer
+	case types.Identical(typ, lisp.TypMarker):
+		return rt.FnCoerceMarker
+	case types.Identical(typ, lisp.TypWindow):
+		return rt.FnCoerceWindow
+	case types.Identical(typ, lisp.TypProcess):
+		return rt.FnCoerceProcess
+	case types.Identical(typ, lisp.TypHashTable):
+		return rt.FnCoerceHashTable
+	case types.Identical(typ, lisp.TypCons):
+		return rt.FnCoerceCons
+	case types.Identical(typ, lisp.TypList):
+		return rt.FnCoerceList
+	}
+	return nil
+}
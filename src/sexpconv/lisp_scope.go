@@ -0,0 +1,92 @@
+package sexpconv
+
+import (
+	"exn"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+)
+
+// lispScopeStmt converts "emacs/lisp" calls that execute
+// function argument inside dynamic scope, like "lisp.Let"
+// or "lisp.SaveExcursion".
+// Returns nil if node is not such call.
+func (conv *converter) lispScopeStmt(node ast.Expr) sexp.Form {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "lisp" {
+		return nil
+	}
+	args := call.Args
+
+	switch sym := sel.Sel.Name; sym {
+	case "Let":
+		cv := conv.valueOf(args[0])
+		if cv == nil {
+			panic(exn.User("lisp.Let variable name must be a constant"))
+		}
+		conv.ctxType = lisp.TypAny // Needed for untyped nil value
+		return &sexp.SpecBind{
+			Sym:  constant.StringVal(cv),
+			Init: conv.Expr(args[1]),
+			Body: conv.scopeBody(sym, args[2]),
+		}
+
+	case "WithCurrentBuffer":
+		conv.ctxType = lisp.TypAny
+		return &sexp.Scope{
+			Kind:   sexp.ScopeCurrentBuffer,
+			Buffer: conv.Expr(args[0]),
+			Body:   conv.scopeBody(sym, args[1]),
+		}
+	case "SaveExcursion", "SaveRestriction", "SaveMatchData", "WithTempBuffer":
+		return &sexp.Scope{
+			Kind:   scopeKinds[sym],
+			Buffer: sexp.EmptyForm,
+			Body:   conv.scopeBody(sym, args[0]),
+		}
+	}
+
+	return nil
+}
+
+var scopeKinds = map[string]sexp.ScopeKind{
+	"SaveExcursion":   sexp.ScopeExcursion,
+	"SaveRestriction": sexp.ScopeRestriction,
+	"SaveMatchData":   sexp.ScopeMatchData,
+	"WithTempBuffer":  sexp.ScopeTempBuffer,
+}
+
+// scopeBody returns statements that call scoped function fn.
+//
+// Function literal is inlined, so it can assign to variables
+// of enclosing function. Dynamic scope must be left
+// normally, hence "return" inside literal is rejected.
+func (conv *converter) scopeBody(sym string, fn ast.Expr) sexp.Block {
+	lit, ok := fn.(*ast.FuncLit)
+	if !ok {
+		sig := conv.typeOf(fn).Underlying().(*types.Signature)
+		return sexp.Block{&sexp.ExprStmt{
+			Expr: &sexp.DynCall{Callable: conv.Expr(fn), Typ: sig.Results()},
+		}}
+	}
+
+	ast.Inspect(lit.Body, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.FuncLit:
+			return false // Nested function has its own returns
+		case *ast.ReturnStmt:
+			panic(exn.User("return inside lisp.%s function literal", sym))
+		}
+		return true
+	})
+	return conv.BlockStmt(lit.Body)
+}
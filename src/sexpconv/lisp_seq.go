@@ -0,0 +1,148 @@
+package sexpconv
+
+import (
+	"exn"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"sexp"
+	"strconv"
+	"xtypes"
+)
+
+// Conversions between Go and Emacs Lisp sequences;
+// see "lisp.List" and "lisp.SliceToVector" documentation.
+
+// seqFromLispFuncs maps lisp package functions that produce
+// Go values to the runtime implementation and to the
+// kind of destination type.
+var seqFromLispFuncs = map[string]struct {
+	fn    **sexp.Func
+	isMap bool
+}{
+	"VectorToSlice": {fn: &rt.FnArrayToSlice},
+	"ListToSlice":   {fn: &rt.FnListToSlice},
+	"AlistToMap":    {fn: &rt.FnAlistToMap, isMap: true},
+	"PlistToMap":    {fn: &rt.FnPlistToMap, isMap: true},
+}
+
+// isLispList reports whether typ is "lisp.List".
+func isLispList(typ types.Type) bool {
+	return lisp.TypList != nil && types.Identical(typ, lisp.TypList)
+}
+
+// checkNotList panics if slice operation is applied to "lisp.List".
+func (conv *converter) checkNotList(op string, node ast.Expr) {
+	if isLispList(conv.typeOf(node)) {
+		panic(exn.NoImpl("%s for lisp.List", op))
+	}
+}
+
+// seqToLisp converts "lisp.SliceToVector" and similar calls.
+func (conv *converter) seqToLisp(sym string, arg ast.Expr) sexp.Form {
+	var fn *sexp.Func
+	var typ types.Type = lisp.TypList
+	switch sym {
+	case "SliceToVector":
+		fn, typ = rt.FnSliceToVector, lisp.TypObject
+	case "SliceToList":
+		fn = rt.FnSliceToList
+	case "MapToAlist":
+		fn = rt.FnMapToAlist
+	case "MapToPlist":
+		fn = rt.FnMapToPlist
+	}
+
+	argTyp := conv.typeOf(arg).Underlying()
+	if sym == "SliceToVector" || sym == "SliceToList" {
+		if _, ok := argTyp.(*types.Slice); !ok || isLispList(conv.typeOf(arg)) {
+			panic(exn.User("lisp.%s argument must be a slice, got `%s'", sym, conv.typeOf(arg)))
+		}
+	} else if _, ok := argTyp.(*types.Map); !ok {
+		panic(exn.User("lisp.%s argument must be a map, got `%s'", sym, conv.typeOf(arg)))
+	}
+	return coerced(conv.call(fn, arg), typ)
+}
+
+// seqFromLisp converts "lisp.ListToSlice(x).(T)" and similar
+// assertions. Returns nil if node is not such assertion.
+func (conv *converter) seqFromLisp(node *ast.TypeAssertExpr) sexp.Form {
+	call, ok := node.X.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "lisp" {
+		return nil
+	}
+	info, ok := seqFromLispFuncs[sel.Sel.Name]
+	if !ok {
+		return nil
+	}
+
+	if _, commaOk := conv.typeOf(node).(*types.Tuple); commaOk {
+		panic(exn.NoImpl("comma-ok assertion of lisp.%s result", sel.Sel.Name))
+	}
+	typ := conv.typeOf(node.Type)
+	if info.isMap {
+		if _, ok := typ.Underlying().(*types.Map); !ok {
+			panic(exn.User("lisp.%s result must be asserted to a map type", sel.Sel.Name))
+		}
+	} else {
+		if _, ok := typ.Underlying().(*types.Slice); !ok || isLispList(typ) {
+			panic(exn.User("lisp.%s result must be asserted to a slice type", sel.Sel.Name))
+		}
+	}
+	return coerced(conv.call(*info.fn, call.Args[0]), typ)
+}
+
+// listLit converts "lisp.List{...}" literal into "list" call.
+func (conv *converter) listLit(node *ast.CompositeLit) sexp.Form {
+	for _, elt := range node.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			panic(exn.NoImpl("keyed lisp.List literal"))
+		}
+	}
+	return coerced(sexp.NewLispCall(lisp.FnList, conv.exprList(node.Elts)...), lisp.TypList)
+}
+
+// foreachList converts range loop over "lisp.List" into While
+// that walks list conses. Like "dolist", it stops at the first
+// non-cons tail.
+func (conv *converter) foreachList(node *ast.RangeStmt) sexp.Form {
+	if node.Tok == token.ASSIGN {
+		panic(exn.NoImpl("'=' assign in for initializer"))
+	}
+
+	conv.nrange++
+	suffix := strconv.Itoa(conv.nrange)
+	list := sexp.Local{Name: "_list" + suffix, Typ: lisp.TypList}
+	init := sexp.FormList{&sexp.Bind{Name: list.Name, Init: conv.Expr(node.X)}}
+	post := sexp.FormList{
+		&sexp.Rebind{Name: list.Name, Expr: coerced(sexp.NewLispCall(lisp.FnCdr, list), lisp.TypList)},
+	}
+	iter := sexp.Local{Name: "_i" + suffix, Typ: xtypes.TypInt}
+	if key, ok := node.Key.(*ast.Ident); ok && key.Name != "_" {
+		init = append(init, &sexp.Bind{Name: iter.Name, Init: sexp.Int(0)})
+		post = append(post, &sexp.Rebind{Name: iter.Name, Expr: sexp.NewAdd1(iter)})
+	}
+
+	body := conv.BlockStmt(node.Body)
+	if val, ok := node.Value.(*ast.Ident); ok && val.Name != "_" {
+		bind := conv.bindVar(val, sexp.NewLispCall(lisp.FnCar, list))
+		body = append(sexp.Block{bind}, body...)
+	}
+	body = conv.bindRangeKey(node, iter, body)
+
+	return sexp.Block{&sexp.While{
+		Init: init,
+		Cond: sexp.NewLispCall(lisp.FnIsCons, list),
+		Post: post,
+		Body: body,
+	}}
+}
@@ -15,6 +15,8 @@ type Converter struct {
 	env     *symbols.Env
 	ftab    *symbols.FuncTable
 	itabEnv *symbols.ItabEnv
+
+	lambdas []*sexp.Func
 }
 
 func (conv *Converter) FuncTable() *symbols.FuncTable {
@@ -25,6 +27,14 @@ func (conv *Converter) Env() *symbols.Env {
 	return conv.env
 }
 
+// Lambdas returns functions that were lifted from function literals
+// since the last call.
+func (conv *Converter) Lambdas() []*sexp.Func {
+	lambdas := conv.lambdas
+	conv.lambdas = nil
+	return lambdas
+}
+
 type converter struct {
 	info    *types.Info
 	fileSet *token.FileSet
@@ -37,6 +47,18 @@ type converter struct {
 	ctxType types.Type
 	// Type that should be used for ctxType inside "return" statements.
 	retType *types.Tuple
+
+	// Name of function that is being converted;
+	// used to name lifted function literals.
+	funcName string
+	nlambda  int
+	lambdas  *[]*sexp.Func
+
+	// Counter that is used to name range loops temporaries.
+	nrange int
+
+	// Local variables that are bound to boxes (see collectBoxedVars).
+	boxed map[*types.Var]bool
 }
 
 func NewConverter(ftab *symbols.FuncTable, env *symbols.Env, itabEnv *symbols.ItabEnv) *Converter {
@@ -50,18 +72,25 @@ func (conv *Converter) newConverter(p *xast.Package) converter {
 		env:     conv.env,
 		ftab:    conv.ftab,
 		itabEnv: conv.itabEnv,
+		lambdas: &conv.lambdas,
+		boxed:   make(map[*types.Var]bool),
 	}
 }
 
 func (conv *Converter) VarInit(assign *xast.Assign) sexp.Form {
 	c := conv.newConverter(assign.Pkg)
+	// Function literals are named after initialized variable.
+	c.funcName = symbols.Mangle(assign.Pkg.FullName, assign.Lhs[0].Name)
+	c.collectBoxedVars(assign.Rhs)
 	return c.VarInit(assign.Lhs, assign.Rhs)
 }
 
 func (conv *Converter) FuncBody(fn *xast.Func) sexp.Block {
 	c := conv.newConverter(fn.Pkg)
 	c.retType = fn.Ret
-	body := c.BlockStmt(fn.Body)
+	c.funcName = fn.Name
+	c.collectBoxedVars(fn.Body)
+	body := append(c.boxParams(token.NoPos, fn.Body.Pos()), c.BlockStmt(fn.Body)...)
 
 	// Adding return statement.
 	// It is needed in void functions without explicit "return".
@@ -7,7 +7,15 @@ import (
 	"xast"
 )
 
+// Stmt converts Go statement and records its position
+// inside resulting form.
 func (conv *converter) Stmt(node ast.Stmt) sexp.Form {
+	form := conv.stmt(node)
+	sexp.SetPos(form, node.Pos())
+	return form
+}
+
+func (conv *converter) stmt(node ast.Stmt) sexp.Form {
 	switch node := node.(type) {
 	case *ast.IfStmt:
 		return conv.IfStmt(node)
@@ -110,7 +118,7 @@ func (conv *converter) valueSpec(forms []sexp.Form, spec *ast.ValueSpec) []sexp.
 		zv := ZeroValue(conv.typeOf(spec.Type))
 		for _, ident := range spec.Names {
 			if ident.Name != "_" {
-				forms = append(forms, &sexp.Bind{Name: ident.Name, Init: zv})
+				forms = append(forms, conv.bindVar(ident, zv))
 			}
 		}
 	} else {
@@ -121,19 +129,15 @@ func (conv *converter) valueSpec(forms []sexp.Form, spec *ast.ValueSpec) []sexp.
 }
 
 func (conv *converter) IncDecStmt(node *ast.IncDecStmt) sexp.Form {
-	target := node.X.(*ast.Ident) // #FIXME: should be any "addressable".
 	if node.Tok == token.INC {
-		return &sexp.Rebind{
-			Name: target.Name,
-			Expr: sexp.NewAdd1(conv.Expr(target)),
-		}
-	}
-	return &sexp.Rebind{
-		Name: target.Name,
-		Expr: sexp.NewSub1(conv.Expr(target)),
+		return conv.assign(node.X, sexp.NewAdd1(conv.Expr(node.X)))
 	}
+	return conv.assign(node.X, sexp.NewSub1(conv.Expr(node.X)))
 }
 
 func (conv *converter) ExprStmt(node *ast.ExprStmt) sexp.Form {
+	if form := conv.lispScopeStmt(node.X); form != nil {
+		return form
+	}
 	return &sexp.ExprStmt{Expr: conv.Expr(node.X)}
 }
@@ -2,6 +2,8 @@ package sexpconv
 
 import (
 	"go/ast"
+	"go/types"
+	"magic_pkg/emacs/lisp"
 	"sexp"
 )
 
@@ -41,3 +43,43 @@ func toFormList(form sexp.Form) sexp.FormList {
 	}
 	return sexp.FormList([]sexp.Form{form})
 }
+
+// isLispType reports whether typ is declared inside "emacs/lisp".
+func isLispType(typ types.Type) bool {
+	if typ, ok := typ.(*types.Named); ok {
+		return typ.Obj().Pkg() == lisp.Package
+	}
+	return false
+}
+
+func isUntypedNil(typ types.Type) bool {
+	if typ, ok := typ.(*types.Basic); ok {
+		return typ.Kind() == types.UntypedNil
+	}
+	return false
+}
+
+// identical is like types.Identical, but also matches package-level
+// named types by their package path and name.
+// Package that is both imported and loaded from source has
+// two distinct objects for every such type.
+func identical(a, b types.Type) bool {
+	if types.Identical(a, b) {
+		return true
+	}
+	x, ok := a.(*types.Named)
+	if !ok {
+		return false
+	}
+	y, ok := b.(*types.Named)
+	if !ok {
+		return false
+	}
+	return isPkgLevel(x.Obj()) && isPkgLevel(y.Obj()) &&
+		x.Obj().Name() == y.Obj().Name() &&
+		x.Obj().Pkg().Path() == y.Obj().Pkg().Path()
+}
+
+func isPkgLevel(obj types.Object) bool {
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
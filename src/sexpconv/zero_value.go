@@ -29,7 +29,7 @@ func basicTypeZeroValue(typ *types.Basic) sexp.Form {
 }
 
 func ZeroValue(typ types.Type) sexp.Form {
-	if lisp.Package != nil && types.Identical(typ, lisp.TypSymbol) {
+	if lisp.Package != nil && isLispType(typ) {
 		return sexp.Nil
 	}
 
@@ -47,6 +47,18 @@ func ZeroValue(typ types.Type) sexp.Form {
 	case *types.Map:
 		return nilMap
 
+	case *types.Slice:
+		return nilSlice
+
+	case *types.Signature:
+		return nilFunc
+
+	case *types.Pointer:
+		return sexp.Nil
+
+	case *types.Interface:
+		return nilInterface
+
 	case *types.Named:
 		utyp := typ.Underlying()
 		if structTyp, ok := utyp.(*types.Struct); ok {
@@ -56,15 +68,40 @@ func ZeroValue(typ types.Type) sexp.Form {
 			}
 			return &sexp.StructLit{Vals: vals, Typ: typ}
 		}
-		if _, ok := utyp.(*types.Interface); ok {
-			return nilInterface
-		}
-		return basicTypeZeroValue(utyp.(*types.Basic))
+		return ZeroValue(utyp)
 	}
 
 	panic(exn.NoImpl("can not provide zero value for %#v", typ))
 }
 
+// nilValue returns "nil" that is typed with specified type.
+// Returns nil if typ has no nil value.
+func nilValue(typ types.Type) sexp.Form {
+	if isLispList(typ) {
+		return &sexp.TypeCast{Form: sexp.Nil, Typ: typ}
+	}
+	var form sexp.Form
+	switch typ.Underlying().(type) {
+	case *types.Map:
+		form = nilMap
+	case *types.Slice:
+		form = nilSlice
+	case *types.Signature:
+		form = nilFunc
+	case *types.Pointer:
+		form = sexp.Nil
+	case *types.Interface:
+		if isLispType(typ) {
+			form = sexp.Nil
+		} else {
+			form = nilInterface
+		}
+	default:
+		return nil
+	}
+	return &sexp.TypeCast{Form: form, Typ: typ}
+}
+
 // Nil values
 var (
 	// #REFS: #74.
@@ -73,7 +110,11 @@ var (
 		Typ:  types.NewMap(lisp.TypObject, lisp.TypObject),
 	}
 
+	nilSlice = sexp.Var{
+		Name: "goism-rt.NilSlice",
+		Typ:  types.NewSlice(lisp.TypObject),
+	}
+
 	nilFunc      = sexp.Symbol{Val: "goism-rt.NilFunction"}
 	nilInterface = sexp.Symbol{Val: "goism-rt.NilInterface"}
-	nilSlice     = sexp.Symbol{Val: "goism-rt.NilSlice"}
 )
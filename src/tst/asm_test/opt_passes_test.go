@@ -0,0 +1,336 @@
+package asm_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"magic_pkg/emacs/lisp"
+	"opt"
+	"sexp"
+	"testing"
+)
+
+// passTest describes function body before and after
+// the optimization pass. Bodies are compared by their
+// compiled code.
+type passTest struct {
+	name   string
+	before sexp.Block
+	after  sexp.Block
+}
+
+func testPass(t *testing.T, pass func(*sexp.Func) bool, tests []passTest) {
+	cl := compiler.New()
+	compile := func(body sexp.Block) string {
+		fn := &sexp.Func{Params: []string{"n"}, Body: body}
+		lapc.Simplify(fn.Body)
+		return string(cl.CompileFunc(fn).Code)
+	}
+	for _, test := range tests {
+		fn := &sexp.Func{Params: []string{"n"}, Body: test.before}
+		triggered := pass(fn)
+		res := compile(fn.Body)
+		expected := compile(test.after)
+		if res != expected {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", test.name, res, expected)
+		}
+		if !triggered {
+			t.Errorf("%s: pass is not triggered", test.name)
+		}
+	}
+}
+
+func TestPropagateConsts(t *testing.T) {
+	n := local("n")
+	x := local("x")
+	y := local("y")
+	isZero := sexp.NewNumEq(n, sexp.Int(0))
+	rebind := func(name string, val sexp.Form) *sexp.Rebind {
+		return &sexp.Rebind{Name: name, Expr: val}
+	}
+	bind := func(name string, val sexp.Form) *sexp.Bind {
+		return &sexp.Bind{Name: name, Init: val}
+	}
+	// clauses returns switch clauses that assign x values.
+	clauses := func(vals ...int64) []sexp.CaseClause {
+		res := make([]sexp.CaseClause, len(vals))
+		for i, val := range vals {
+			res[i] = sexp.CaseClause{
+				Expr: sexp.Int(int64(i)),
+				Body: sexp.Block{rebind("x", sexp.Int(val))},
+			}
+		}
+		return res
+	}
+
+	tests := []passTest{
+		{
+			name: "if merge same values",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2))},
+					Else: sexp.Block{rebind("x", sexp.Int(2))},
+				},
+				ret(sexp.NewAdd(x, n)),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2))},
+					Else: sexp.Block{rebind("x", sexp.Int(2))},
+				},
+				ret(sexp.NewAdd(sexp.Int(2), n)),
+			},
+		},
+		{
+			name: "if merge different values",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2))},
+					Else: sexp.EmptyForm,
+				},
+				ret(sexp.NewAdd(x, y)),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2))},
+					Else: sexp.EmptyForm,
+				},
+				ret(sexp.NewAdd(x, sexp.Int(5))),
+			},
+		},
+		{
+			name: "if terminal branch",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2)), ret(x)},
+					Else: sexp.EmptyForm,
+				},
+				ret(x),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.If{
+					Cond: isZero,
+					Then: sexp.Block{rebind("x", sexp.Int(2)), ret(sexp.Int(2))},
+					Else: sexp.EmptyForm,
+				},
+				ret(sexp.Int(1)),
+			},
+		},
+		{
+			name: "while",
+			before: sexp.Block{
+				bind("x", sexp.Int(0)),
+				bind("y", sexp.Int(3)),
+				&sexp.While{
+					Init: sexp.EmptyForm,
+					Cond: sexp.NewNumLt(x, n),
+					Post: sexp.EmptyForm,
+					Body: sexp.Block{rebind("x", sexp.NewAdd(x, y))},
+				},
+				ret(sexp.NewAdd(x, y)),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(0)),
+				bind("y", sexp.Int(3)),
+				&sexp.While{
+					Init: sexp.EmptyForm,
+					Cond: sexp.NewNumLt(x, n),
+					Post: sexp.EmptyForm,
+					Body: sexp.Block{rebind("x", sexp.NewAdd(x, sexp.Int(3)))},
+				},
+				ret(sexp.NewAdd(x, sexp.Int(3))),
+			},
+		},
+		{
+			name: "switch merge same values",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses:     clauses(7, 7),
+						DefaultBody: sexp.Block{rebind("x", sexp.Int(7))},
+					},
+				},
+				ret(sexp.NewAdd(x, n)),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses:     clauses(7, 7),
+						DefaultBody: sexp.Block{rebind("x", sexp.Int(7))},
+					},
+				},
+				ret(sexp.NewAdd(sexp.Int(7), n)),
+			},
+		},
+		{
+			// Missing default clause keeps the entry value.
+			name: "switch merge without default",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses:     clauses(7, 7),
+						DefaultBody: sexp.EmptyBlock,
+					},
+				},
+				ret(sexp.NewAdd(x, y)),
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses:     clauses(7, 7),
+						DefaultBody: sexp.EmptyBlock,
+					},
+				},
+				ret(sexp.NewAdd(x, sexp.Int(5))),
+			},
+		},
+		{
+			// Label can be reached by goto that follows
+			// the assignment of x; y is never assigned.
+			name: "label",
+			before: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.Label{Name: "again"},
+				&sexp.If{
+					Cond: sexp.NewNumEq(x, n),
+					Then: sexp.Block{ret(y)},
+					Else: sexp.EmptyForm,
+				},
+				rebind("x", sexp.NewAdd1(x)),
+				&sexp.Goto{LabelName: "again"},
+			},
+			after: sexp.Block{
+				bind("x", sexp.Int(1)),
+				bind("y", sexp.Int(5)),
+				&sexp.Label{Name: "again"},
+				&sexp.If{
+					Cond: sexp.NewNumEq(x, n),
+					Then: sexp.Block{ret(sexp.Int(5))},
+					Else: sexp.EmptyForm,
+				},
+				rebind("x", sexp.NewAdd1(x)),
+				&sexp.Goto{LabelName: "again"},
+			},
+		},
+	}
+	testPass(t, opt.PropagateConsts, tests)
+}
+
+func TestEliminateDeadStores(t *testing.T) {
+	n := local("n")
+	x := local("x")
+	call := func() sexp.Form {
+		return sexp.NewLispCall(lisp.FnCar, n)
+	}
+
+	tests := []passTest{
+		{
+			name: "unused bind",
+			before: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.Int(1)},
+				ret(n),
+			},
+			after: sexp.Block{
+				ret(n),
+			},
+		},
+		{
+			name: "unused bind with side effects",
+			before: sexp.Block{
+				&sexp.Bind{Name: "x", Init: call()},
+				&sexp.Rebind{Name: "x", Expr: call()},
+				ret(n),
+			},
+			after: sexp.Block{
+				&sexp.ExprStmt{Expr: call()},
+				&sexp.ExprStmt{Expr: call()},
+				ret(n),
+			},
+		},
+		{
+			name: "overwritten bind",
+			before: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.Int(1)},
+				&sexp.Rebind{Name: "x", Expr: sexp.NewAdd1(n)},
+				ret(x),
+			},
+			after: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.NewAdd1(n)},
+				ret(x),
+			},
+		},
+		{
+			name: "overwritten rebind",
+			before: sexp.Block{
+				&sexp.Bind{Name: "x", Init: call()},
+				&sexp.Rebind{Name: "x", Expr: call()},
+				&sexp.Rebind{Name: "x", Expr: sexp.Int(2)},
+				ret(x),
+			},
+			after: sexp.Block{
+				&sexp.Bind{Name: "x", Init: call()},
+				&sexp.ExprStmt{Expr: call()},
+				&sexp.Rebind{Name: "x", Expr: sexp.Int(2)},
+				ret(x),
+			},
+		},
+		{
+			name: "unused param rebind",
+			before: sexp.Block{
+				&sexp.ExprStmt{Expr: call()},
+				&sexp.Rebind{Name: "n", Expr: sexp.Int(5)},
+				ret(sexp.Int(1)),
+			},
+			after: sexp.Block{
+				&sexp.ExprStmt{Expr: call()},
+				ret(sexp.Int(1)),
+			},
+		},
+		{
+			name: "nested scope",
+			before: sexp.Block{
+				&sexp.If{
+					Cond: sexp.NewNumEq(n, sexp.Int(0)),
+					Then: sexp.Block{
+						&sexp.Bind{Name: "x", Init: sexp.Int(1)},
+						ret(sexp.Int(2)),
+					},
+					Else: sexp.EmptyForm,
+				},
+				ret(n),
+			},
+			after: sexp.Block{
+				&sexp.If{
+					Cond: sexp.NewNumEq(n, sexp.Int(0)),
+					Then: sexp.Block{ret(sexp.Int(2))},
+					Else: sexp.EmptyForm,
+				},
+				ret(n),
+			},
+		},
+	}
+	testPass(t, opt.EliminateDeadStores, tests)
+}
@@ -0,0 +1,110 @@
+package asm_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"go/types"
+	"sexp"
+	"strings"
+	"testing"
+)
+
+func local(name string) sexp.Local {
+	return sexp.Local{Name: name, Typ: types.Typ[types.Int]}
+}
+
+func ret(x sexp.Form) *sexp.Return {
+	return &sexp.Return{Results: []sexp.Form{x}}
+}
+
+func TestOptimize(t *testing.T) {
+	n := local("n")
+	x := local("x")
+	tests := []struct {
+		name     string
+		fn       *sexp.Func
+		stack    int
+		expected string
+	}{
+		{
+			name: "constant branch",
+			fn: &sexp.Func{
+				Params: []string{"n"},
+				Body: sexp.Block{
+					&sexp.If{
+						Cond: sexp.Bool(true),
+						Then: sexp.Block{ret(sexp.Int(1))},
+						Else: sexp.Block{ret(n)},
+					},
+				},
+			},
+			stack: 2,
+			expected: `
+constant 1
+return`,
+		},
+		{
+			name: "not branch",
+			fn: &sexp.Func{
+				Params: []string{"n"},
+				Body: sexp.Block{
+					&sexp.If{
+						Cond: sexp.NewNot(sexp.NewNumEq(n, sexp.Int(0))),
+						Then: sexp.Block{ret(sexp.Int(1))},
+						Else: sexp.EmptyForm,
+					},
+					ret(n),
+				},
+			},
+			stack: 3,
+			expected: `
+stack-ref 0
+constant 0
+num=
+goto-if-not-nil endif-0
+constant 1
+return
+label endif-0
+stack-ref 0
+return`,
+		},
+		{
+			name: "loop break",
+			fn: &sexp.Func{
+				Body: sexp.Block{
+					&sexp.Bind{Name: "x", Init: sexp.Int(0)},
+					&sexp.Loop{
+						Init: sexp.EmptyForm,
+						Post: sexp.EmptyForm,
+						Body: sexp.Block{
+							&sexp.Rebind{Name: "x", Expr: sexp.NewAdd1(x)},
+							sexp.BreakGoto,
+						},
+					},
+					ret(x),
+				},
+			},
+			stack: 2,
+			expected: `
+constant 0
+stack-ref 0
+add1
+stack-set 1
+stack-ref 0
+return`,
+		},
+	}
+
+	cl := compiler.New()
+	for _, test := range tests {
+		lapc.Simplify(test.fn.Body)
+		obj := cl.CompileFunc(test.fn)
+		res := strings.TrimSpace(string(obj.Code))
+		if res != strings.TrimSpace(test.expected) {
+			t.Errorf("%s:\ngot:\n%s\nwant:%s", test.name, res, test.expected)
+		}
+		if obj.StackUsage != test.stack {
+			t.Errorf("%s: stack usage is %d (want %d)", test.name, obj.StackUsage, test.stack)
+		}
+	}
+}
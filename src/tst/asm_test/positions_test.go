@@ -0,0 +1,152 @@
+package asm_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"backends/lapc/export"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sexp"
+	"strings"
+	"testing"
+	"tst/goism"
+	"tu"
+	"vm"
+)
+
+// compilePositionsFunc returns compiled function which
+// statements are located at lines 2, 3 and 5 of "a.go".
+func compilePositionsFunc() (*token.FileSet, *sexp.Func, *lapc.Object) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("/src/emacs/pkg/a.go", -1, 100)
+	file.SetLines([]int{0, 10, 20, 30, 40})
+	line := func(n int) sexp.StmtPos {
+		return sexp.StmtPos{Pos: file.LineStart(n)}
+	}
+
+	n := local("n")
+	fn := &sexp.Func{
+		Name:   "goism-pkg.f",
+		Params: []string{"n"},
+		Body: sexp.Block{
+			&sexp.If{
+				StmtPos: line(2),
+				Cond:    sexp.NewNumEq(n, sexp.Int(0)),
+				Then: sexp.Block{
+					&sexp.Return{StmtPos: line(3), Results: []sexp.Form{n}},
+				},
+				Else: sexp.EmptyForm,
+			},
+			&sexp.Return{StmtPos: line(5), Results: []sexp.Form{sexp.Int(1)}},
+		},
+	}
+	lapc.Simplify(fn.Body)
+	return fset, fn, compiler.New().CompileFunc(fn)
+}
+
+func TestPositions(t *testing.T) {
+	fset, _, obj := compilePositionsFunc()
+
+	expected := []export.LinePos{
+		{PC: 0, File: "a.go", Line: 2},
+		{PC: 6, File: "a.go", Line: 3},
+		{PC: 8, File: "a.go", Line: 5},
+	}
+	res := export.LineTable(fset, obj.Positions)
+	if len(res) != len(expected) {
+		t.Fatalf("line table: got %v (want %v)", res, expected)
+	}
+	for i := range res {
+		if res[i] != expected[i] {
+			t.Errorf("line table[%d]: got %v (want %v)", i, res[i], expected[i])
+		}
+	}
+}
+
+func TestIRPositions(t *testing.T) {
+	fset, fn, obj := compilePositionsFunc()
+
+	code := string(obj.Code)
+	var positions []string
+	for _, line := range strings.Split(code, "\n") {
+		if strings.HasPrefix(line, "position") {
+			positions = append(positions, line)
+		}
+	}
+	if res := strings.Join(positions, ";"); res != "position 0;position 1;position 2" {
+		t.Errorf("IR positions: got %s (want 3 positions)", res)
+	}
+	if len(obj.CodePositions) != 3 {
+		t.Errorf("code positions: got %v (want 3 entries)", obj.CodePositions)
+	}
+
+	b := export.NewBuilder(&tu.Package{FileSet: fset})
+	b.AddFunc(fn, obj)
+	expected := `nil ["a.go" 2 "a.go" 3 "a.go" 5 ] ` + code
+	if res := string(b.Build()); !strings.Contains(res, expected) {
+		t.Errorf("IR package: %q not found in %s", expected, res)
+	}
+}
+
+// TestSourcePosition loads line table written by ElcBuilder
+// into VM and queries it with "goism-source-position".
+func TestSourcePosition(t *testing.T) {
+	fset, fn, obj := compilePositionsFunc()
+	b := export.NewElcBuilder(&tu.Package{FileSet: fset, Feature: "goism-pkg"})
+	b.AddFunc(fn, obj)
+
+	m := vm.New()
+	put := `(put 'goism-pkg.f 'goism-positions [0 "a.go" 2 6 "a.go" 3 8 "a.go" 5])`
+	if !strings.Contains(string(b.Build()), put+"\n") {
+		t.Fatalf("elc: %s not found", put)
+	}
+	if _, err := m.EvalString(put); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := ioutil.ReadFile(filepath.Join(goism.Home, "lisp/interactive/debug.el"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defun, err := vm.Read(m, string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Skip defun, name, params, doc string and interactive form;
+	// body is evaluated with params bound by let.
+	body := defun
+	for i := 0; i < 5; i++ {
+		body = body.(*vm.Cons).Cdr
+	}
+	sourcePosition := func(fn string, offset int64) vm.Object {
+		params := vm.List(
+			vm.List(m.Intern("fn"), vm.List(m.Intern("quote"), m.Intern(fn))),
+			vm.List(m.Intern("offset"), offset),
+		)
+		form := &vm.Cons{Car: m.Intern("let"), Cdr: &vm.Cons{Car: params, Cdr: body}}
+		res, err := m.Eval(form)
+		if err != nil {
+			t.Fatalf("%s %d: %v", fn, offset, err)
+		}
+		return res
+	}
+
+	tests := []struct {
+		fn       string
+		offset   int64
+		expected vm.Object
+	}{
+		{"goism-pkg.f", 0, "a.go:2"},
+		{"goism-pkg.f", 5, "a.go:2"},
+		{"goism-pkg.f", 6, "a.go:3"},
+		{"goism-pkg.f", 100, "a.go:5"},
+		{"goism-pkg.g", 0, vm.Nil},
+	}
+	for _, test := range tests {
+		if res := sourcePosition(test.fn, test.offset); res != test.expected {
+			t.Errorf("(goism-source-position '%s %d): got %v (want %v)",
+				test.fn, test.offset, res, test.expected)
+		}
+	}
+}
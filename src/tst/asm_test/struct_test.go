@@ -0,0 +1,46 @@
+package asm_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"go/token"
+	"go/types"
+	"sexp"
+	"strings"
+	"testing"
+)
+
+// TestStructUpdate checks that every field of cons-represented
+// struct is updated in its own cell: (a b . c).
+func TestStructUpdate(t *testing.T) {
+	fields := make([]*types.Var, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		fields[i] = types.NewField(token.NoPos, nil, name, types.Typ[types.Int], false)
+	}
+	typ := types.NewStruct(fields, nil)
+	s := sexp.Local{Name: "s", Typ: typ}
+
+	tests := []struct {
+		index    int
+		expected string
+	}{
+		{0, "stack-ref 0\nconstant 0\nsetcar"},
+		{1, "stack-ref 0\ncdr\nconstant 0\nsetcar"},
+		{2, "stack-ref 0\ncdr\nconstant 0\nsetcdr"},
+	}
+	cl := compiler.New()
+	for _, test := range tests {
+		fn := &sexp.Func{
+			Params: []string{"s"},
+			Body: sexp.Block{
+				&sexp.StructUpdate{Struct: s, Index: test.index, Expr: sexp.Int(10), Typ: typ},
+				ret(s),
+			},
+		}
+		lapc.Simplify(fn.Body)
+		res := string(cl.CompileFunc(fn).Code)
+		if !strings.Contains(res, test.expected+"\n") {
+			t.Errorf("field %d:\ngot:\n%s\nwant:\n%s", test.index, res, test.expected)
+		}
+	}
+}
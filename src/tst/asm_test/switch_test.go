@@ -0,0 +1,106 @@
+package asm_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"cfg"
+	"sexp"
+	"strings"
+	"testing"
+)
+
+func TestSwitchTable(t *testing.T) {
+	n := local("n")
+	newSwitch := func() *sexp.Func {
+		shared := sexp.Block{ret(sexp.Int(10))}
+		return &sexp.Func{
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses: []sexp.CaseClause{
+							{Expr: sexp.Int(1), Body: shared},
+							{Expr: sexp.Int(2), Body: shared},
+							{Expr: sexp.Int(3), Body: sexp.Block{ret(sexp.Int(30))}},
+						},
+						DefaultBody: sexp.Block{ret(n)},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		emacsVersion int
+		stack        int
+		constants    string
+		expected     string
+	}{
+		{
+			emacsVersion: 28,
+			stack:        3,
+			constants:    `[#s(hash-table size 3 test eq rehash-size 1.5 rehash-threshold 0.8125 purecopy t data (1 case-1 2 case-1 3 case-2)) 10 30 ]`,
+			expected: `
+stack-ref 0
+constant 0
+switch
+stack-ref 0
+return
+label case-1
+constant 1
+return
+label case-2
+constant 2
+return`,
+		},
+		{
+			emacsVersion: 25,
+			stack:        4,
+			constants:    `[1 10 2 3 30 ]`,
+			expected: `
+stack-ref 0
+stack-ref 0
+constant 0
+num=
+goto-if-nil else-1
+constant 1
+return
+label else-1
+stack-ref 0
+constant 2
+num=
+goto-if-nil else-3
+constant 1
+return
+label else-3
+stack-ref 0
+constant 3
+num=
+goto-if-nil else-5
+constant 4
+return
+label else-5
+stack-ref 1
+return`,
+		},
+	}
+
+	defer func(version int) { cfg.TargetEmacsVersion = version }(cfg.TargetEmacsVersion)
+	cl := compiler.New()
+	for _, test := range tests {
+		cfg.TargetEmacsVersion = test.emacsVersion
+		fn := newSwitch()
+		lapc.Simplify(fn.Body)
+		obj := cl.CompileFunc(fn)
+		res := strings.TrimSpace(string(obj.Code))
+		if res != strings.TrimSpace(test.expected) {
+			t.Errorf("emacs %d:\ngot:\n%s\nwant:%s", test.emacsVersion, res, test.expected)
+		}
+		if cvec := string(obj.ConstVec.Bytes()); cvec != test.constants {
+			t.Errorf("emacs %d: constants are %s (want %s)", test.emacsVersion, cvec, test.constants)
+		}
+		if obj.StackUsage != test.stack {
+			t.Errorf("emacs %d: stack usage is %d (want %d)", test.emacsVersion, obj.StackUsage, test.stack)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package bytecode_test
+
+import (
+	"backends/lapc/bytecode"
+	"backends/lapc/ir"
+	"bytes"
+	"testing"
+)
+
+func encode(build func(p *ir.InstrPusher, u *ir.Unit)) []byte {
+	u := ir.NewUnit()
+	u.Init()
+	build(u.InstrPusher(), u)
+	return bytecode.NewEncoder().Encode(u.Result())
+}
+
+func checkBytecode(t *testing.T, name string, res, expected []byte) {
+	if !bytes.Equal(res, expected) {
+		t.Errorf("%s: got %v (want %v)", name, res, expected)
+	}
+}
+
+func TestEncodeOperands(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(p *ir.InstrPusher, u *ir.Unit)
+		expected []byte
+	}{
+		{"constant 0", func(p *ir.InstrPusher, u *ir.Unit) { p.ConstRef(0) }, []byte{192}},
+		{"constant 63", func(p *ir.InstrPusher, u *ir.Unit) { p.ConstRef(63) }, []byte{255}},
+		{"constant 300", func(p *ir.InstrPusher, u *ir.Unit) { p.ConstRef(300) }, []byte{129, 44, 1}},
+		{"stack-ref 0", func(p *ir.InstrPusher, u *ir.Unit) { p.StackRef(0) }, []byte{137}},
+		{"stack-ref 5", func(p *ir.InstrPusher, u *ir.Unit) { p.StackRef(5) }, []byte{5}},
+		{"stack-ref 6", func(p *ir.InstrPusher, u *ir.Unit) { p.StackRef(6) }, []byte{6, 6}},
+		{"stack-ref 256", func(p *ir.InstrPusher, u *ir.Unit) { p.StackRef(256) }, []byte{7, 0, 1}},
+		{"stack-set 3", func(p *ir.InstrPusher, u *ir.Unit) { p.StackSet(3) }, []byte{178, 3}},
+		{"stack-set 256", func(p *ir.InstrPusher, u *ir.Unit) { p.StackSet(256) }, []byte{179, 0, 1}},
+		{"var-ref 2", func(p *ir.InstrPusher, u *ir.Unit) { p.VarRef(2) }, []byte{10}},
+		{"var-set 7", func(p *ir.InstrPusher, u *ir.Unit) { p.VarSet(7) }, []byte{22, 7}},
+		{"var-bind 1", func(p *ir.InstrPusher, u *ir.Unit) { p.VarBind(1) }, []byte{25}},
+		{"unbind 1", func(p *ir.InstrPusher, u *ir.Unit) { p.Unbind(1) }, []byte{41}},
+		{"save-excursion", func(p *ir.InstrPusher, u *ir.Unit) { p.SaveExcursion() }, []byte{138}},
+		{"unwind-protect", func(p *ir.InstrPusher, u *ir.Unit) { p.UnwindProtect() }, []byte{142}},
+		{"call 3", func(p *ir.InstrPusher, u *ir.Unit) { p.Call(3, "f") }, []byte{35}},
+		{"discard 1", func(p *ir.InstrPusher, u *ir.Unit) { p.Discard(1) }, []byte{136}},
+		{"discard 0", func(p *ir.InstrPusher, u *ir.Unit) { p.Discard(0) }, []byte{}},
+		{"discard 200", func(p *ir.InstrPusher, u *ir.Unit) { p.Discard(200) }, []byte{182, 127, 182, 73}},
+		{"list 1", func(p *ir.InstrPusher, u *ir.Unit) { p.List(1) }, []byte{67}},
+		{"list 4", func(p *ir.InstrPusher, u *ir.Unit) { p.List(4) }, []byte{70}},
+		{"list 5", func(p *ir.InstrPusher, u *ir.Unit) { p.List(5) }, []byte{175, 5}},
+		{"concat 1", func(p *ir.InstrPusher, u *ir.Unit) { p.Concat(1) }, []byte{176, 1}},
+		{"concat 2", func(p *ir.InstrPusher, u *ir.Unit) { p.Concat(2) }, []byte{80}},
+		{"concat 4", func(p *ir.InstrPusher, u *ir.Unit) { p.Concat(4) }, []byte{82}},
+		{"add return", func(p *ir.InstrPusher, u *ir.Unit) { p.Add(); p.Return() }, []byte{92, 135}},
+	}
+
+	for _, test := range tests {
+		checkBytecode(t, test.name, encode(test.build), test.expected)
+	}
+}
+
+func TestEncodeJumps(t *testing.T) {
+	// Loop with backward and forward jumps:
+	//   0: label loop
+	//   0: dup
+	//   1: goto-if-nil end
+	//   4: sub1
+	//   5: goto loop
+	//   8: label end
+	//   8: return
+	res := encode(func(p *ir.InstrPusher, u *ir.Unit) {
+		loop := u.NewLabel("loop")
+		end := u.NewLabel("end")
+		p.Label(loop)
+		p.StackRef(0)
+		p.JmpNil(end)
+		p.Sub1()
+		p.Jmp(loop)
+		p.Label(end)
+		p.Return()
+	})
+	checkBytecode(t, "loop", res, []byte{137, 131, 8, 0, 83, 130, 0, 0, 135})
+}
+
+func TestEncoderReuse(t *testing.T) {
+	enc := bytecode.NewEncoder()
+	u := ir.NewUnit()
+
+	u.Init()
+	label := u.NewLabel("a")
+	u.InstrPusher().Jmp(label)
+	u.InstrPusher().ConstRef(0)
+	u.InstrPusher().Label(label)
+	u.InstrPusher().Return()
+	checkBytecode(t, "first", enc.Encode(u.Result()), []byte{130, 4, 0, 192, 135})
+
+	u.Init()
+	label = u.NewLabel("b")
+	u.InstrPusher().Label(label)
+	u.InstrPusher().Jmp(label)
+	checkBytecode(t, "second", enc.Encode(u.Result()), []byte{130, 0, 0})
+}
+
+func TestEncodePositions(t *testing.T) {
+	u := ir.NewUnit()
+	u.Init()
+	p := u.InstrPusher()
+	u.SetPos(10)
+	p.StackRef(0)
+	p.ConstRef(300) // Same position, no new entry
+	u.SetPos(20)
+	p.Add()
+	u.SetPos(0) // Unknown position is covered by previous entry
+	p.StackRef(6)
+	u.SetPos(30)
+	p.Return()
+
+	enc := bytecode.NewEncoder()
+	enc.Encode(u.Result())
+	expected := []bytecode.PosEntry{
+		{PC: 0, Pos: 10},
+		{PC: 4, Pos: 20},
+		{PC: 7, Pos: 30},
+	}
+	res := enc.Positions()
+	if len(res) != len(expected) {
+		t.Fatalf("positions: got %v (want %v)", res, expected)
+	}
+	for i := range res {
+		if res[i] != expected[i] {
+			t.Errorf("positions[%d]: got %v (want %v)", i, res[i], expected[i])
+		}
+	}
+}
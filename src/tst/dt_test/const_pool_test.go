@@ -65,3 +65,27 @@ func TestConstPoolBytes(t *testing.T) {
 		t.Errorf("%s != %s", string(result), string(expected))
 	}
 }
+
+func TestConstPoolSymbolEscaping(t *testing.T) {
+	cvec := dt.ConstPool{}
+	cvec.InsertSym("goism--%type/*conformance.point")
+	cvec.InsertSym("goism--%type/[]int")
+
+	result := cvec.Bytes()
+	expected := []byte(`[goism--%type/*conformance.point goism--%type/\[\]int ]`)
+	if !bytes.Equal(result, expected) {
+		t.Errorf("%s != %s", string(result), string(expected))
+	}
+}
+
+func TestConstPoolAtomEscaping(t *testing.T) {
+	cvec := dt.ConstPool{}
+	cvec.InsertString(`a"b\c`)
+	cvec.InsertFloat(2)
+
+	result := cvec.Bytes()
+	expected := []byte(`["a\"b\\c" 2.0 ]`)
+	if !bytes.Equal(result, expected) {
+		t.Errorf("%s != %s", string(result), string(expected))
+	}
+}
@@ -0,0 +1,105 @@
+package el_test
+
+import (
+	"backends/el"
+	"go/types"
+	"sexp"
+	"testing"
+	"tu"
+)
+
+func local(name string) sexp.Local {
+	return sexp.Local{Name: name, Typ: types.Typ[types.Int]}
+}
+
+func TestBuildFuncs(t *testing.T) {
+	n := local("n")
+	x := local("x")
+	funcs := []*sexp.Func{
+		{
+			Name:   "test.f",
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.If{
+					Cond: sexp.NewNumEq(n, sexp.Int(0)),
+					Then: sexp.Block{&sexp.Return{Results: []sexp.Form{sexp.Int(1)}}},
+					Else: sexp.EmptyForm,
+				},
+				&sexp.Return{Results: []sexp.Form{n}},
+			},
+		},
+		{
+			Name:      "test.g",
+			DocString: "g counts to 1.\n",
+			Body: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.Int(0)},
+				&sexp.Loop{
+					Init: sexp.EmptyForm,
+					Post: sexp.EmptyForm,
+					Body: sexp.Block{
+						&sexp.Rebind{Name: "x", Expr: sexp.NewAdd1(x)},
+						sexp.BreakGoto,
+					},
+				},
+				&sexp.Return{Results: []sexp.Form{x}},
+			},
+		},
+	}
+
+	cmd := &sexp.Func{
+		Name:      "test.cmd",
+		Params:    []string{"n"},
+		DocString: "cmd is a command.\n",
+		Body:      sexp.Block{&sexp.Return{Results: []sexp.Form{n}}},
+	}
+	cmd.LoadDirective(`//goism:interactive "p"`)
+	cmd.SetAutoload(true)
+	funcs = append(funcs, cmd)
+
+	b := el.NewBuilder(&tu.Package{
+		Name:     "test",
+		Feature:  "goism-test",
+		Summary:  "Test package",
+		Headers:  []tu.Header{{Key: "Version", Val: "1.0"}},
+		Requires: []string{"goism-rt"},
+	})
+	b.AddVars([]string{"test.v"})
+	for _, fn := range funcs {
+		b.AddFunc(fn)
+	}
+	res := string(b.Build())
+
+	expected := `;;; goism-test.el --- Test package  -*- lexical-binding: t -*-
+;; THIS CODE IS GENERATED, AVOID MANUAL EDITING!
+
+;; Version: 1.0
+
+;;; Code:
+
+(require 'cl-lib)
+(require 'goism-rt)
+
+(defvar test.v nil)
+
+(defun test.f (n) (cl-block nil (when (= n 0) (cl-return 1)) n))
+
+(defun test.g ()
+  "g counts to 1."
+  (let ((x 0))
+    (cl-block break (while t (setq x (1+ x)) (cl-return-from break)))
+    x))
+
+;;;###autoload
+(defun test.cmd (n)
+  "cmd is a command."
+  (interactive "p")
+  n)
+
+(provide 'goism-test)
+
+;;; goism-test.el ends here
+`
+	if res != expected {
+		t.Errorf("output mismatch:\n%s\n(want)\n%s", res, expected)
+	}
+}
@@ -0,0 +1,175 @@
+package el_test
+
+import (
+	"backends/el"
+	"go/types"
+	"sexp"
+	"strings"
+	"testing"
+	"tu"
+)
+
+// buildDefuns returns funcs definitions produced by el.Builder;
+// package header and footer are stripped.
+func buildDefuns(funcs []*sexp.Func) string {
+	b := el.NewBuilder(&tu.Package{Name: "test", Feature: "goism-test"})
+	for _, fn := range funcs {
+		b.AddFunc(fn)
+	}
+	res := string(b.Build())
+	res = res[strings.Index(res, "(defun"):]
+	return strings.TrimSpace(res[:strings.Index(res, "(provide")])
+}
+
+// TestBuildStmts checks statements that are mapped to
+// Emacs Lisp control forms: switch, loops, let and goto.
+func TestBuildStmts(t *testing.T) {
+	n := local("n")
+	x := local("x")
+	i := local("i")
+	s := sexp.Local{Name: "s", Typ: types.Typ[types.String]}
+	ret := func(x sexp.Form) *sexp.Return {
+		return &sexp.Return{Results: []sexp.Form{x}}
+	}
+	shared := sexp.Block{ret(sexp.Int(10))}
+
+	funcs := []*sexp.Func{
+		{
+			Name:   "test.switchInt",
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.Switch{
+					Expr: n,
+					SwitchBody: sexp.SwitchBody{
+						Clauses: []sexp.CaseClause{
+							{Expr: sexp.Int(1), Body: shared},
+							{Expr: sexp.Int(2), Body: shared},
+							{Expr: sexp.Int(3), Body: sexp.Block{ret(sexp.Int(30))}},
+						},
+						DefaultBody: sexp.Block{ret(n)},
+					},
+				},
+			},
+		},
+		{
+			Name:   "test.switchStr",
+			Params: []string{"s"},
+			Body: sexp.Block{
+				&sexp.Switch{
+					Expr: s,
+					SwitchBody: sexp.SwitchBody{
+						Clauses: []sexp.CaseClause{
+							{Expr: sexp.Str("a"), Body: sexp.Block{ret(sexp.Int(1))}},
+						},
+						DefaultBody: sexp.EmptyBlock,
+					},
+				},
+				ret(sexp.Int(0)),
+			},
+		},
+		{
+			Name:   "test.loops",
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.Int(0)},
+				&sexp.While{
+					Init: &sexp.Bind{Name: "i", Init: sexp.Int(0)},
+					Cond: sexp.NewNumLt(i, n),
+					Post: &sexp.Rebind{Name: "i", Expr: sexp.NewAdd1(i)},
+					Body: sexp.Block{
+						&sexp.If{
+							Cond: sexp.NewNumEq(i, sexp.Int(2)),
+							Then: sexp.Block{sexp.ContinueGoto},
+							Else: sexp.EmptyForm,
+						},
+						&sexp.If{
+							Cond: sexp.NewNumEq(i, sexp.Int(4)),
+							Then: sexp.Block{sexp.BreakGoto},
+							Else: sexp.EmptyForm,
+						},
+						&sexp.Rebind{Name: "x", Expr: sexp.NewAdd(x, i)},
+					},
+				},
+				&sexp.DoTimes{
+					N:    n,
+					Iter: i,
+					Step: sexp.Int(1),
+					Body: sexp.Block{&sexp.Rebind{Name: "x", Expr: sexp.NewAdd(x, i)}},
+				},
+				&sexp.Repeat{
+					N:    2,
+					Body: sexp.Block{&sexp.Rebind{Name: "x", Expr: sexp.NewAdd1(x)}},
+				},
+				ret(x),
+			},
+		},
+		{
+			Name:   "test.let",
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.Let{
+					Bindings: []*sexp.Bind{
+						{Name: "x", Init: sexp.NewMul(n, sexp.Int(2))},
+					},
+					Stmt: ret(sexp.NewAdd1(x)),
+				},
+			},
+		},
+		{
+			Name:   "test.gotos",
+			Params: []string{"n"},
+			Body: sexp.Block{
+				&sexp.Bind{Name: "x", Init: sexp.Int(0)},
+				&sexp.Label{Name: "loop"},
+				&sexp.If{
+					Cond: sexp.NewNumGte(x, n),
+					Then: sexp.Block{&sexp.Goto{LabelName: "done"}},
+					Else: sexp.EmptyForm,
+				},
+				&sexp.Rebind{Name: "x", Expr: sexp.NewAdd1(x)},
+				&sexp.Goto{LabelName: "loop"},
+				&sexp.Label{Name: "done"},
+				ret(x),
+			},
+		},
+	}
+
+	res := buildDefuns(funcs)
+	expected := `(defun test.switchInt (n) (cl-case n ((1 2) 10) (3 30) (t n)))
+
+(defun test.switchStr (s)
+  (cl-block nil (let ((_it s)) (cond ((string= _it "a") (cl-return 1)))) 0))
+
+(defun test.loops (n)
+  (let ((x 0))
+    (let ((i 0))
+      (cl-block break
+        (while (< i n)
+          (cl-block continue
+            (when (= i 2) (cl-return-from continue))
+            (when (= i 4) (cl-return-from break))
+            (setq x (+ x i)))
+          (setq i (1+ i)))))
+    (dotimes (i n) (setq x (+ x i)))
+    (dotimes (_ 2) (setq x (1+ x)))
+    x))
+
+(defun test.let (n) (let ((x (* n 2))) (1+ x)))
+
+(defun test.gotos (n)
+  (cl-block nil
+    (let (x (goto-label :entry-point))
+      (while goto-label
+        (setq goto-label
+              (cl-block goto-1
+                (cl-case goto-label
+                  (:entry-point (setq x 0) :loop)
+                  (:loop
+                   (when (>= x n) (cl-return-from goto-1 :done))
+                   (setq x (1+ x))
+                   (cl-return-from goto-1 :loop))
+                  (:done (cl-return x)))))))))`
+	if res != expected {
+		t.Errorf("output mismatch:\n%s\n(want)\n%s", res, expected)
+	}
+}
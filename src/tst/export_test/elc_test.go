@@ -0,0 +1,84 @@
+package export_test
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"backends/lapc/export"
+	"testing"
+	"tst/goism"
+	"tu/load"
+)
+
+// buildElc translates package into ".elc" file contents,
+// like "-output=elc" translate_package mode does.
+func buildElc(pkgPath string) ([]byte, error) {
+	if err := load.Runtime(); err != nil {
+		return nil, err
+	}
+	pkg, err := load.Package(pkgPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	b := export.NewElcBuilder(pkg)
+	cl := compiler.New()
+	if len(pkg.Vars) != 0 {
+		b.AddVars(pkg.Vars)
+	}
+	for _, fn := range pkg.Funcs {
+		if !fn.IsSubst() {
+			lapc.Simplify(fn.Body)
+			b.AddFunc(fn, cl.CompileFunc(fn))
+		}
+	}
+	if len(pkg.Init.Body) != 0 {
+		lapc.Simplify(pkg.Init.Body)
+		b.AddExpr(cl.CompileFunc(pkg.Init))
+	}
+	return b.Build(), nil
+}
+
+// TestElcLoad loads ".elc" file into VM and runs
+// functions that were read back from it.
+func TestElcLoad(t *testing.T) {
+	m, err := goism.NewVM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	elc, err := buildElc("emacs/conformance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(string(elc)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`(featurep 'goism-conformance)`, "t"},
+		// Initialized by top level "byte-code" form.
+		{`goism-conformance.var1`, "1"},
+		// Jump tables are read as hash tables.
+		{`(goism-conformance.switchIntTable 1)`, "11"},
+		{`(goism-conformance.fmtSprintfFloat 3.14159)`, `"3.14159|3.14|   3.142"`},
+		{`(goism-conformance.stringsRepeat "na" 3)`, `"nanana"`},
+		{`(goism-conformance.strconvQuote "a\"b\n")`, `"\"a\\\"b\\n\""`},
+		{`(goism-conformance.utf8RuneLen ?♞)`, "3"},
+		{`(goism-conformance.funcValueCounter)`, "2"},
+		{`(goism-conformance.sortStable)`, `"a,d,bb,cc,ee"`},
+		{`(goism-conformance.tailSum 100000 0)`, "5000050000"},
+		// Line tables are stored as symbol properties.
+		{`(vectorp (get 'goism-conformance.tailSum 'goism-positions))`, "t"},
+	}
+	for _, test := range tests {
+		res, err := m.EvalString(test.expr)
+		if err != nil {
+			res = "error: " + err.Error()
+		}
+		if res != test.expected {
+			t.Errorf("%s: got %s (want %s)", test.expr, res, test.expected)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package export_test
+
+import (
+	"backends/lapc/export"
+	"sexp"
+	"testing"
+)
+
+func TestArgsDescriptor(t *testing.T) {
+	tests := []struct {
+		params   []string
+		variadic bool
+		expected int
+	}{
+		{nil, false, 0},
+		{[]string{"a", "b"}, false, 2 + 2<<8},
+		// "rest" arg is counted neither as required,
+		// nor as positional arg.
+		{[]string{"rest"}, true, 128},
+		{[]string{"a", "rest"}, true, 1 + 128 + 1<<8},
+		{[]string{"a", "b", "rest"}, true, 2 + 128 + 2<<8},
+	}
+	for _, test := range tests {
+		fn := &sexp.Func{Params: test.params, Variadic: test.variadic}
+		if res := export.ArgsDescriptor(fn); res != test.expected {
+			t.Errorf("%v (variadic=%v): got %d (want %d)",
+				test.params, test.variadic, res, test.expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package ffi_test
+
+import (
+	"ffi"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"tst/goism"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := `
+;; Comment.
+(identity Identity (:any x) :object
+ "Return the argument unchanged.
+
+(fn ARG)")
+(max MaxInt (:int x :int &xs) :int)
+(set-process-filter SetProcessFilter
+ (:object process [(:object proc :string output) :void] filter)
+ :void)
+`
+	decls, err := ffi.Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := string(ffi.Generate(decls))
+	expected := `package lisp
+
+// This package is special.
+// Code generated by goism_gen_ffi from FFI spec. DO NOT EDIT.
+
+// Identity = Return the argument unchanged.
+//
+//goism:"Identity"->"identity"
+func Identity(x any) Object
+
+// MaxInt = extern Emacs Lisp function.
+//
+//goism:"MaxInt"->"max"
+func MaxInt(x int, xs ...int) int
+
+// SetProcessFilter = extern Emacs Lisp function.
+//
+//goism:"SetProcessFilter"->"set-process-filter"
+func SetProcessFilter(process Object, filter func(proc Object, output string))
+`
+	if res != expected {
+		t.Errorf("output mismatch:\n%s\n(want)\n%s", res, expected)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{`(identity Identity (:any x) :any)`, "can not use `:any' type"},
+		{`(identity Identity (:any x))`, "expected (sym GoName"},
+		{`(identity Identity (:vector x) :object)`, "unsupported type `:vector'"},
+		{`(identity Identity (:any &x :any y) :object)`, "only last param"},
+		{`(identity Identity (:any x y) :object)`, "(:type name) pairs"},
+		{`(identity func (:any x) :object)`, "invalid Go identifier `func'"},
+		{`(identity Identity (:any x) :object 1)`, "documentation must be a string"},
+		{"(a A () :void)\n(b A () :void)", "duplicated Go name `A'"},
+	}
+	for _, test := range tests {
+		_, err := ffi.Parse(test.spec)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v (want %q)", test.spec, err, test.err)
+		}
+	}
+}
+
+// TestDefaultSpec checks that "emacs/lisp" FFI file
+// is in sync with default FFI spec.
+func TestDefaultSpec(t *testing.T) {
+	spec, err := ioutil.ReadFile(goism.Home + "/lisp/ffi/default-ffi.el")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := ioutil.ReadFile(goism.Home + "/src/emacs/lisp/ffi.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls, err := ffi.Parse(string(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ffi.Generate(decls)) != string(code) {
+		t.Error("emacs/lisp/ffi.go is outdated; run `make ffi'")
+	}
+}
@@ -17,3 +17,19 @@ func RunTestCalls(pkg string, t *testing.T, table CallTests) {
 		tst.CheckError(t, call, res, outputExpected)
 	}
 }
+
+// OrderedCallTests is a {"call", "result"} pairs table.
+// Unlike CallTests, calls are run in order, so they can
+// depend on state left by preceding calls.
+// Used with RunOrderedTestCalls.
+type OrderedCallTests [][2]string
+
+// RunOrderedTestCalls runs every function from given OrderedCallTests
+// table in order. Every result is checked with tst.CheckError.
+func RunOrderedTestCalls(pkg string, t *testing.T, table OrderedCallTests) {
+	for _, test := range table {
+		call, outputExpected := test[0], test[1]
+		res := EvalCall(pkg, call)
+		tst.CheckError(t, call, res, outputExpected)
+	}
+}
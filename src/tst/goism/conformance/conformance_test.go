@@ -53,6 +53,8 @@ func Test2GlobalVars(t *testing.T) {
 		{"var4", "4"},
 		{"var5", "5"},
 		{"var6", "6"},
+		{"var7", "0"},
+		{"var8", `""`},
 	}
 
 	for _, row := range table {
@@ -146,6 +148,24 @@ func Test7Switch(t *testing.T) {
 		"stringifyInt4 1": `"1"`,
 		"stringifyInt4 2": `"2"`,
 		"stringifyInt4 3": `"x"`,
+
+		"switchIntTable 0":                "0",
+		"switchIntTable 1":                "11",
+		"switchIntTable 2":                "11",
+		"switchIntTable 3":                "31",
+		"switchIntTable 4":                "1",
+		"switchIntTable 5":                "51",
+		"switchIntTable 6":                "0",
+		`switchStringTable "a"`:           `"ab"`,
+		`switchStringTable "b"`:           `"ab"`,
+		`switchStringTable "c"`:           `"c"`,
+		`switchStringTable "long string"`: `"long"`,
+		`switchStringTable "d"`:           `"d?"`,
+		"switchSymbolTable 'x":            "1",
+		"switchSymbolTable 'z":            "3",
+		"switchSymbolTable 'w":            "0",
+		"switchSymbolTableDup 'a":         "1",
+		"switchSymbolTableDup 'c":         "4",
 	})
 }
 
@@ -155,6 +175,9 @@ func Test8Slices(t *testing.T) {
 		"sliceLen $sliceOf4_5": "4",
 		"sliceCap $sliceOf3":   "3",
 		"sliceCap $sliceOf4_5": "5",
+		"sliceSwap":            "321",
+		"sliceAssignIndex":     "120",
+		"sliceAssignSlice":     "93",
 	})
 }
 
@@ -183,7 +206,15 @@ func Test9For(t *testing.T) {
 
 func Test10Range(t *testing.T) {
 	testCalls(t, goism.CallTests{
-		"sumArray1": "6",
+		"sumArray1":        "6",
+		"sumSlice1":        "6",
+		"sumSlice2":        "6",
+		"rangeSubslice":    "11",
+		"rangeSliceCount":  "3",
+		"rangeSliceAppend": "6",
+		"rangeNamedSlice":  "11",
+		"rangeSliceModKey": "36",
+		"rangeArrayModKey": "6",
 	})
 }
 
@@ -228,6 +259,381 @@ func Test12Strings(t *testing.T) {
 	})
 }
 
+func Test13Reflect(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"reflectKindOfInt 10":              "2",
+		"reflectKindOfStruct":              "25",
+		"reflectTypeString":                `"conformance.reflectPoint"`,
+		"reflectTypeName":                  `"reflectPoint"`,
+		"reflectPtrElemName":               `"reflectPoint"`,
+		"reflectNilTypeOf":                 "t",
+		"reflectNumField":                  "3",
+		`reflectFieldName 0`:               `"X"`,
+		`reflectFieldName 2`:               `"Label"`,
+		`reflectFieldType 1`:               `"int"`,
+		`reflectFieldType 2`:               `"string"`,
+		"reflectFieldValue 1 2":            "3",
+		"reflectFieldString":               `"xyz"`,
+		`reflectMethodByName "Sum"`:        "t",
+		`reflectMethodByName "Foo"`:        "nil",
+		"reflectInterface 10":              "10",
+		"reflectTypeAssertOk 10":           "nil",
+		"reflectSliceIndex 1":              "23",
+		"reflectPromotedCall 1 2":          "3",
+		"reflectPromotedIface 3 4":         "7",
+		"reflectPromotedNested":            "23",
+		"reflectPromotedEmbeddedIface 1 2": "6",
+		"reflectPromotedMethods":           `"Inc Sum "`,
+		"reflectImplements":                `"ttff"`,
+	})
+}
+
+func Test14Fmt(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"fmtSprintfInt 42":                `"42|   42|42   |00042|2a|2A|+42"`,
+		"fmtSprintfInt -7":                `"-7|   -7|-7   |-0007|-7|-7|-7"`,
+		`fmtSprintfString "goism"`:        `"goism|\"goism\"| goism|goism |go|676f69736d"`,
+		"fmtSprintfFloat 3.14159":         `"3.14159|3.14|   3.142"`,
+		"fmtSprintfFloat 2.0":             `"2|2.00|   2.000"`,
+		"fmtSprintfStruct 1 2":            `"{1 2}|{X:1 Y:2}|&{1 2}"`,
+		"fmtSprintfSlice":                 `"[1 2 3]|[a b]|[]"`,
+		"fmtSprintfMap":                   `"map[a:1 b:2 c:3]"`,
+		"fmtSprintfType":                  `"int|string|conformance.fmtPoint|[]int"`,
+		`fmtSprintfStringer "John" "Doe"`: `"John Doe|John Doe|\"John Doe\""`,
+		`fmtSprintfError "oops"`:          `"wrapped: oops"`,
+		"fmtErrorf 404":                   `"code 404"`,
+		"fmtSprintfBad":                   `"1 %!z(int=2)|%!d(MISSING)"`,
+		"fmtSprintfExtra":                 `"1%!(EXTRA string=x)"`,
+		"fmtSprintfNil":                   `"<nil>"`,
+		"fmtSprint":                       `"a1 2b3.5"`,
+		"fmtSprintln":                     "\"a 1 true\n\"",
+		"fmtFprintf 5":                    `"n=5"`,
+		"fmtFprintfWriter":                `"x=1;n=4"`,
+	})
+}
+
+func Test15Errors(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		`errorsNewMessage "boom"`:  `"boom"`,
+		"errorsNewDistinct":        "nil",
+		"errorsSameValue":          "t",
+		"errorsNilCompare":         "t",
+		`errorsWrapMessage "key"`:  `"lookup key: not found"`,
+		"errorsIsWrapped":          "t",
+		"errorsIsOther":            "nil",
+		"errorsIsNotWrapped":       "nil",
+		"errorsUnwrapNil":          "t",
+		"errorsUnwrap":             "t",
+		"errorsAs 404":             "404",
+		"errorsAsMiss":             "nil",
+		"errorsAsPtr 7":            "7",
+		"errorsAsIface":            `(nil t t "busy")`,
+		"errorsAsError":            `"wrapped: not found"`,
+		"errorsStructEqual 1":      "t",
+		"errorsBadWrap":            `"%!w(*errors.errorString=&{not found})"`,
+		"errorsPrint":              `"not found|not found|\"not found\""`,
+		`errorsLispMessage "boom"`: `"boom"`,
+	})
+}
+
+func Test16StringsPkg(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		`stringsIndex "chicken" "ken"`:                    "4",
+		`stringsIndex "chicken" "dmr"`:                    "-1",
+		`stringsIndex "héllo" "llo"`:                      "3",
+		`stringsIndexByte "abc" ?c`:                       "2",
+		`stringsIndexByte "a♞b" ?b`:                       "4",
+		`stringsIndexByte "a♞b" 226`:                      "1",
+		`stringsIndexByte "a♞b" 158`:                      "3",
+		`stringsIndexByte "abc" 255`:                      "-1",
+		`stringsLastIndex "go gopher" "go"`:               "3",
+		`stringsLastIndex "go" "x"`:                       "-1",
+		`stringsContains "seafood" "foo"`:                 "t",
+		`stringsContains "seafood" "bar"`:                 "nil",
+		`stringsPrefixSuffix "abcxyz"`:                    "t",
+		`stringsPrefixSuffix "abc"`:                       "nil",
+		`stringsSplitJoin "a,b,c" ","`:                    `"a|b|c;3"`,
+		`stringsSplitJoin "a b" ""`:                       `"a| |b;3"`,
+		`stringsSplitJoin "" ","`:                         `";1"`,
+		`stringsSplitN "a,b,c,d" "," 2`:                   `"a|b,c,d"`,
+		`stringsSplitN "a,b,c" "," -1`:                    `"a|b|c"`,
+		`stringsFields "  foo bar\tbaz  "`:                `"foo|bar|baz"`,
+		`stringsReplace "oink oink oink" "k" "ky" 2`:      `"oinky oinky oink"`,
+		`stringsReplace "oink oink oink" "oink" "moo" -1`: `"moo moo moo"`,
+		`stringsTrim " xhelloy "`:                         `"xhelloy|hello|helloy | xhello"`,
+		`stringsCase "Gopher"`:                            `"GOPHERgopher"`,
+		`stringsRepeat "na" 3`:                            `"nanana"`,
+		`stringsCount "cheese" "e"`:                       "3",
+		`stringsCount "five" ""`:                          "5",
+		"stringsBuilder 3":                                `"0,1,2,♞9"`,
+		`strconvAtoi "123"`:                               "123",
+		`strconvAtoi "-45"`:                               "-45",
+		`strconvAtoi "12a"`:                               "-1",
+		`strconvAtoiError "12a"`:                          `"strconv.Atoi: parsing \"12a\": invalid syntax"`,
+		`strconvAtoiError "99999999999999999999"`:         `"strconv.Atoi: parsing \"99999999999999999999\": value out of range"`,
+		`strconvParseInt "ff" 16 64`:                      "255",
+		`strconvParseInt "0x1F" 0 64`:                     "31",
+		`strconvParseInt "-101" 2 64`:                     "-5",
+		`strconvParseInt "200" 10 8`:                      "-1",
+		`strconvParseFloat "3.25"`:                        "3.25",
+		`strconvParseFloat "1e3"`:                         "1000.0",
+		`strconvParseFloat "x"`:                           "-1.0",
+		`strconvParseBool "true"`:                         "t",
+		`strconvParseBool "F"`:                            "nil",
+		`strconvParseBool "yes"`:                          "nil",
+		"strconvFormat 255":                               `"11111111|ff|73|true"`,
+		"strconvFormat -10":                               `"-1010|-a|-a|false"`,
+		"strconvFormatFloat 3.14159":                      `"3.14|3.14159"`,
+		"strconvFormatFloat 0.5":                          `"0.50|0.5"`,
+		`strconvQuote "a\"b\n"`:                           `"\"a\\\"b\\n\""`,
+		`utf8RuneCount "héllo"`:                           "5",
+		"utf8RuneLen ?a":                                  "1",
+		"utf8RuneLen ?é":                                  "2",
+		"utf8RuneLen ?♞":                                  "3",
+		`utf8Decode "é!"`:                                 "2332",
+		`utf8Decode "a"`:                                  "971",
+		`utf8Decode ""`:                                   "655330",
+	})
+}
+
+func Test17SortPkg(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"sortInts":          `"1,2,3,5,8,9"`,
+		"sortSubslice":      `"5,2,3,4,1"`,
+		"sortStrings":       `"apple,fig,pear"`,
+		"sortSlice":         `"9,8,5,3,2,1"`,
+		"sortSliceStable":   `"bdac"`,
+		"sortSort 100":      "t",
+		"sortSort 13":       "t",
+		"sortSortInterface": `"a,bb,ccc,dddd"`,
+		"sortReverse":       `"3,2,1"`,
+		"sortStable":        `"a,d,bb,cc,ee"`,
+		"sortSearch 5":      "22",
+		"sortSearch 4":      "22",
+		"sortSearch 8":      "44",
+		"listPushIterate":   `"123321;3"`,
+		"listRemoveMove":    `"ca;2"`,
+		"heapOrder":         `"1,2,3,5,8"`,
+	})
+}
+
+func Test18TailCalls(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"tailSum 100000 0":     "5000050000",
+		"tailFib 50 0 1":       "12586269025",
+		"testTailCount 100000": "100000",
+		"testTailSkipNegative": "11",
+		"tailShadowed 10":      "0",
+	})
+}
+
+func Test19ConstProp(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"constPropStraight":        "50",
+		"constPropBranches t":      "52",
+		"constPropBranches nil":    "13",
+		"constPropEarlyReturn -1":  "100",
+		"constPropEarlyReturn 5":   "12",
+		"constPropLoop 4":          "12",
+		"constPropGoto 5":          "32",
+		"constPropSwitch 1":        "10",
+		"constPropSwitch 2":        "30",
+		"constPropSwitch 3":        "1",
+		"constPropShadow 1":        "4",
+		"constPropShadow -1":       "0",
+		"testDeadStoreSideEffects": "424",
+		"testDeadStoreMultiValue":  "9",
+	})
+}
+
+func Test20ScalarStructs(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"scalarDist 1 2 4 6":     "25",
+		"scalarUpdate 4":         "604",
+		"scalarSwap 1 2":         "21",
+		"scalarCopy 5":           "6",
+		"scalarNested 4":         "9",
+		"scalarMethods 3":        "12",
+		"scalarEscapePointer 2":  "12",
+		"testScalarEscapeReturn": "45",
+		"testScalarEscapeIface":  "8",
+		"scalarEscapeClosure 5":  "11",
+		"structMiddleField":      "303",
+	})
+}
+
+func Test21LoopOpt(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"loopSliceFill 4 3":       "18",
+		"loopInvariantExpr 2 3 4": "30",
+		"loopNestedSlices":        "50",
+		"loopSliceReassigned":     "51",
+		"loopSubsliceWrite":       "78",
+	})
+}
+
+func Test22Interactive(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testCmdNoArgs":      "(interactive nil)",
+		"testCmdExplicit":    "(interactive \"sGreeting: \nnCount: \")",
+		"testCmdDerived":     "(interactive \"sName: \np\nSSym: \nxX: \")",
+		"testCmdPrefix":      `(interactive "p")`,
+		"testNotCommand":     "nil",
+		"testIsCommand":      "t",
+		"testCmdCall":        "14",
+		"testNotCommandForm": "nil",
+	})
+}
+
+func Test23Custom(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testCustomValues":     "123",
+		"testCustomTypes":      "(boolean integer string (repeat string) (alist :key-type string :value-type integer))",
+		"testCustomStandard":   `(('20) ('("a" "b")) ('(("x" . 1))))`,
+		"testCustomDoc":        `"customFlag enables something."`,
+		"testCustomSetWords":   "233",
+		"testCustomSetAliases": "1223",
+		"testCustomGetWords":   `("a" "b")`,
+	})
+}
+
+func Test24Modes(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testModeToggle":       "418",
+		"testModeState":        "(t t nil nil)",
+		"testModeKeymap":       "goism-conformance.modeCmd",
+		"testModeGlobalKeymap": "goism-conformance.modeCmd",
+		"testModeCommand":      "t",
+		"testModeDoc":          `"Conformance test mode."`,
+		"testModeLighter":      `(goism-conformance-test-mode " Test")`,
+		"testModeVars":         `"goism-conformance-test-modegoism-conformance-global-modegoism-conformance-test-mode-hook"`,
+		"testMajorMode":        `("bmBM" goism-conformance-major-mode "Major" nil)`,
+		"testMajorModeKeymap":  "(t goism-conformance.modeCmd ignore)",
+		"testMajorModeBase":    `("bB" "goism-conformance-base-mode")`,
+	})
+}
+
+func Test25LispTypes(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testLispTypesBuffer":       `"text"`,
+		"testLispTypesBufferEq":     "t",
+		"testLispTypesZero":         "t",
+		"testLispTypesCons":         "102",
+		"testLispTypesHashTable":    "3",
+		"testLispTypesCoerce":       "t",
+		"testLispTypesArg '(1 . 2)": "1",
+		"testLispTypesBadMarker":    "error: interface conversion: lisp.Object is lisp.Buffer, not lisp.Marker",
+		"testLispTypesBadWindow":    "error: interface conversion: lisp.Object is lisp.Cons, not lisp.Window",
+	})
+}
+
+func Test26SeqInterop(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testSeqSliceToVector": "([1 2 3] [2 3])",
+		"testSeqVectorToSlice": "13",
+		"testSeqSliceToList":   `("a" "b")`,
+		"testSeqListToSlice":   "123",
+		"testSeqMapToAlist":    `(("a" . 1))`,
+		`testSeqAlistToMap '(("a" . 1) ("a" . 2) ("b" . 3))`: "12",
+		"testSeqMapToPlist":                      `("a" 1)`,
+		`testSeqPlistToMap '("a" 1 "b" 2 "a" 3)`: "12",
+		"testSeqRange '(1 2 3 4)":                "8",
+		"testSeqRangeIndex '(a b c)":             "3",
+		"testSeqRangeModKey '(1 2 3)":            "306",
+		"testSeqListOps '(1 2 3)":                "(3 1)",
+		"testSeqEmptyList":                       "t",
+	})
+}
+
+func Test27Lambda(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testLambdaMapcar '(1 2 3)":   "(2 4 6)",
+		"testLambdaClosure 10":        "(11)",
+		"testLambdaFuncValue":         `"foobar"`,
+		"testLambdaMultiResult":       "(3 1)",
+		"testLambdaVoid":              "(nil 1)",
+		"testLambdaSlice":             "(3 2 1)",
+		"testLambdaVariadic":          "6",
+		"testLambdaCoerce":            "error: interface conversion: lisp.Object is int, not string",
+		"testLambdaImplicit '(1 2 3)": "(2 3 4)",
+		"testLambdaImplicitFFI":       "11",
+		"testLambdaImplicitCoerce":    "error: interface conversion: lisp.Object is int, not string",
+	})
+}
+
+func Test28DynamicBinding(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testLetVisible":   "(10 nil)",
+		"testLetNested":    "(2 1)",
+		"testLetFuncValue": `"x"`,
+		"testBufferLocal":  "(2 3 1)",
+	})
+}
+
+// Test28LetUnwind calls are order dependent,
+// so they are not a part of CallTests table.
+func Test28LetUnwind(t *testing.T) {
+	testOrderedCalls(t, goism.OrderedCallTests{
+		{"testLetUnbound", "nil"},
+		{"testLetPanic", "error: unwound"},
+		{"testLetUnbound", "nil"},
+	})
+}
+
+func Test29Scoped(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testSaveExcursion":          `(2 5 "xabc")`,
+		"testSaveExcursionFuncValue": `(5 "xabc")`,
+		"testSaveExcursionBuffer":    "t",
+		"testSaveRestriction":        `("hello" "hello world")`,
+		"testWithCurrentBuffer":      `("bc" t)`,
+		"testSaveMatchData":          "1",
+		"testWithTempBuffer":         `("tmp" nil t)`,
+	})
+}
+
+// Test29ScopedUnwind calls are order dependent:
+// each "Unwound" call checks state left by preceding panic.
+func Test29ScopedUnwind(t *testing.T) {
+	testOrderedCalls(t, goism.OrderedCallTests{
+		{"testSaveExcursionPanic", "error: unwound"},
+		{"testSaveExcursionUnwound", "t"},
+		{"testSaveRestrictionPanic", "error: unwound"},
+		{"testSaveRestrictionUnwound", `"hello world"`},
+		{"testWithCurrentBufferPanic", "error: unwound"},
+		{"testWithCurrentBufferUnwound", "t"},
+		{"testSaveMatchDataPanic", "error: unwound"},
+		{"testSaveMatchDataUnwound", "1"},
+		{"testWithTempBufferPanic", "error: unwound"},
+		{"testWithTempBufferUnwound", "t"},
+	})
+}
+
+func Test30Pointers(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"testPtrLocal":       "24",
+		"testPtrParam 1":     "2",
+		"testPtrShared":      `"ab"`,
+		"testPtrToPtr":       "2",
+		"testPtrTupleAssign": "79",
+	})
+}
+
+func Test31FuncValues(t *testing.T) {
+	testCalls(t, goism.CallTests{
+		"funcValueNamed 4":        "8",
+		"funcValueClosure 1 2":    "3",
+		"funcValueNested 1":       "111",
+		"funcValueMultiResult 47": "11",
+		"funcValueNil":            "t",
+		"funcValueCounter":        "2",
+		"funcValueOuterAssign":    "5",
+		"funcValueParamAssign 3":  "6",
+		"funcValueLoopVar":        "12",
+		"funcValueStruct":         "456",
+	})
+}
+
 func TestCombined(t *testing.T) {
 	testCalls(t, goism.CallTests{
 		"factorial 0": "1",
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -66,7 +67,9 @@ func prepareGcgoProgram(info *testInfo, testFileContents []byte) string {
 		TestFuncs:        info.Funcs,
 	})
 
-	path := fmt.Sprintf("%s/build/pairwise.go", goism.Home)
+	dir := fmt.Sprintf("%s/build", goism.Home)
+	assert.Nil(os.MkdirAll(dir, 0755))
+	path := dir + "/pairwise.go"
 	assert.Nil(ioutil.WriteFile(path, program.Bytes(), 0644))
 	return path
 }
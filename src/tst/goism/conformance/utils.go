@@ -15,6 +15,10 @@ func testCalls(t *testing.T, table goism.CallTests) {
 	goism.RunTestCalls(pkg, t, table)
 }
 
+func testOrderedCalls(t *testing.T, table goism.OrderedCallTests) {
+	goism.RunOrderedTestCalls(pkg, t, table)
+}
+
 // Enclose each passed string value into double quotes (unconditionnaly).
 func q(xs ...string) []string {
 	for i, x := range xs {
@@ -10,16 +10,17 @@ import (
 
 var varReplaceRx = regexp.MustCompile(`\$(\w+)`)
 
-// Eval is a fundamental way to communicate with Emacs daemon.
-// It sends Lisp S-expression (function argument) to Emacs
-// for evaluation. The result is returned as a string.
+// Eval is a fundamental way to communicate with test target.
+// It sends Lisp S-expression (function argument) to goism VM
+// or Emacs daemon for evaluation (see UseEmacs).
+// The result is returned as a string.
 //
 // It does not perform any expression transformation,
 // it is passed "as is".
 // In practive, it means that you must mangle goism-generated
 // symbols by hand.
 //
-// Also note that this call modifies Emacs daemon state.
+// Also note that this call modifies target state.
 // Side effects are preserved between calls.
 //
 // If error occurs, its message is returned as a result.
@@ -30,6 +31,10 @@ var varReplaceRx = regexp.MustCompile(`\$(\w+)`)
 // Eval("(+ 1 2)") => "3"
 // Eval("nil")     => "nil"
 func Eval(expr string) string {
+	if !UseEmacs {
+		return vmEval(expr)
+	}
+
 	var (
 		stdout bytes.Buffer
 		stderr bytes.Buffer
@@ -46,8 +51,11 @@ func Eval(expr string) string {
 	return strings.TrimRight(string(stdout.String()), "\t\n\r")
 }
 
-// LoadPackage loads package of specified name into Emacs daemon.
+// LoadPackage loads package of specified name into test target.
 func LoadPackage(pkg string) string {
+	if !UseEmacs {
+		return vmLoadPackage(pkg)
+	}
 	return Eval(fmt.Sprintf(`(goism-load "%s")`, pkg))
 }
 
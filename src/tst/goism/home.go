@@ -1,15 +1,24 @@
 package goism
 
 import (
+	"go/build"
 	"os"
+	"path/filepath"
 )
 
 // Home is a path to directory that
 // contains goism repository (sources, scripts, etc).
+//
+// GOISM_HOME environment variable is used if set,
+// otherwise the first GOPATH entry is assumed to be a repository root
+// (this is how Makefile sets it up).
 var Home = func() string {
-	res := os.Getenv("GOISM_HOME")
-	if res == "" {
-		panic("GOISM_HOME environment variable is not set")
+	if res := os.Getenv("GOISM_HOME"); res != "" {
+		return res
 	}
-	return res
+	paths := filepath.SplitList(build.Default.GOPATH)
+	if len(paths) == 0 || paths[0] == "" {
+		panic("neither GOISM_HOME nor GOPATH environment variable is set")
+	}
+	return paths[0]
 }()
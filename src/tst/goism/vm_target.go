@@ -0,0 +1,74 @@
+package goism
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"vm"
+	"vm/load"
+)
+
+// UseEmacs selects test target.
+// By default, tests are run by goism VM, so Emacs is not required.
+// Set GOISM_TARGET=emacs to use Emacs daemon instead;
+// it must be started by "script/tst/daemon_start".
+var UseEmacs = os.Getenv("GOISM_TARGET") == "emacs"
+
+// runtimePackages are loaded into VM before any test package.
+// Keep in sync with "script/tst/daemon_start".
+var runtimePackages = []string{
+	"rt",
+	"reflect",
+	"errors",
+	"unicode/utf8",
+	"strconv",
+	"strings",
+	"sort",
+	"container/list",
+	"container/heap",
+	"fmt",
+	"emacs",
+}
+
+var machine struct {
+	sync.Once
+	vm *vm.VM
+}
+
+// NewVM returns fresh VM with runtime packages loaded.
+func NewVM() (*vm.VM, error) {
+	m := vm.New()
+	for _, pkg := range runtimePackages {
+		if err := load.Package(m, pkg); err != nil {
+			return nil, errors.New("load " + pkg + ": " + err.Error())
+		}
+	}
+	return m, nil
+}
+
+// getMachine returns VM that is shared by all tests.
+func getMachine() *vm.VM {
+	machine.Do(func() {
+		m, err := NewVM()
+		if err != nil {
+			panic(err.Error())
+		}
+		machine.vm = m
+	})
+	return machine.vm
+}
+
+func vmEval(expr string) string {
+	res, err := getMachine().EvalString(expr)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return res
+}
+
+func vmLoadPackage(pkg string) string {
+	if err := load.Package(getMachine(), pkg); err != nil {
+		return "error: " + err.Error()
+	}
+	return "nil"
+}
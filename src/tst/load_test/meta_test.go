@@ -0,0 +1,44 @@
+package load_test
+
+import (
+	"testing"
+	"tu"
+	"tu/load"
+)
+
+func TestHeaders(t *testing.T) {
+	if err := load.Runtime(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pkgPath  string
+		expected []tu.Header
+	}{
+		// Explicit headers; runtime is added to requirements.
+		{"emacs/regress", []tu.Header{
+			{Key: "Version", Val: "0.2"},
+			{Key: "Package-Requires", Val: `((emacs "26.1") (goism-rt "0.1"))`},
+		}},
+		// Default headers with imported goism packages.
+		{"emacs/errors", []tu.Header{
+			{Key: "Version", Val: "0.1"},
+			{Key: "Package-Requires", Val: `((emacs "28.1") (goism-rt "0.1") (goism-reflect "0.1"))`},
+		}},
+	}
+	for _, test := range tests {
+		pkg, err := load.Package(test.pkgPath, false)
+		if err != nil {
+			t.Errorf("%s: %v", test.pkgPath, err)
+			continue
+		}
+		if len(pkg.Headers) != len(test.expected) {
+			t.Errorf("%s: got %v (want %v)", test.pkgPath, pkg.Headers, test.expected)
+			continue
+		}
+		for i, h := range pkg.Headers {
+			if h != test.expected[i] {
+				t.Errorf("%s: header[%d]: got %v (want %v)", test.pkgPath, i, h, test.expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package load_test
+
+import (
+	"bytes"
+	"opt"
+	"strings"
+	"testing"
+	"tu/load"
+)
+
+func TestTailCallsReport(t *testing.T) {
+	if err := load.Runtime(); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := load.Package("emacs/conformance", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	opt.WriteTailCalls(&buf, pkg.FileSet, pkg.Funcs)
+	report := buf.String()
+
+	// Only lines that belong to the tail calls test file
+	// are compared; other files change too often.
+	var lines []string
+	for _, line := range strings.Split(report, "\n") {
+		if strings.HasPrefix(line, "18_tail_calls_test.go:") {
+			lines = append(lines, line)
+		}
+	}
+	expected := []string{
+		"18_tail_calls_test.go:49: conformance.testTailSkipNegative: conformance.tailSkipNegative: not a self call",
+		"18_tail_calls_test.go:59: conformance.tailShadowed: conformance.tailShadowed: parameter `n' is shadowed",
+	}
+	if res := strings.Join(lines, "\n"); res != strings.Join(expected, "\n") {
+		t.Errorf("tail calls report:\n%s\n(want)\n%s", res, strings.Join(expected, "\n"))
+	}
+
+	// Calls that are generated by the translator are not reported.
+	if strings.Contains(report, ": rt.") {
+		t.Errorf("runtime calls are reported:\n%s", report)
+	}
+}
@@ -0,0 +1,89 @@
+package vm_test
+
+import (
+	"testing"
+	"vm"
+)
+
+func TestEvalString(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(+ 1 2.5)`, `3.5`},
+		{`'(1 . (2 . (3 . nil)))`, `(1 2 3)`},
+		{`'(a . b)`, `(a . b)`},
+		{`[1 "x" ?a]`, `[1 "x" 97]`},
+		{`(let ((x 1)) (setq x (1+ x)) (list x 'quote))`, `(2 quote)`},
+		{`(let ((i 0) res) (while (< i 3) (setq res (cons i res) i (1+ i))) res)`, `(2 1 0)`},
+		{`(list (when t 1 2) (when nil 1))`, `(2 nil)`},
+		{`(format "%d|%5.2f|%S" 10 3.14159 "a\"b")`, `"10| 3.14|\"a\\\"b\""`},
+		{`(split-string "a,b,,c" ",")`, `("a" "b" "" "c")`},
+		{`(sort (list 3 1 2) '<)`, `(1 2 3)`},
+		{`(let ((h (make-hash-table :test 'equal))) (puthash "k" 1 h) (gethash "k" h))`, `1`},
+		{`(/ 1.0 0)`, `1.0e+INF`},
+		{`(string-to-number "1e3")`, `1000.0`},
+		// ".elc" syntax: unibyte strings, hash tables and byte-code objects.
+		{`(list (length "\377a") (aref "\377" 0) (aref "\u00ff" 0))`, `(2 255 255)`},
+		{`(funcall #'car '(1 2))`, `1`},
+		{`(gethash 'b #s(hash-table test eq data (a 1 b 2)))`, `2`},
+		{`(funcall #[257 "\211\300\\\207" [10] 3 "doc"] 5)`, `15`},
+		{`(progn (defvar x 1) (defvar x 2) x)`, `1`},
+		{`(progn (provide 'foo) (list (featurep 'foo) (require 'foo) (require 'bar nil t)))`, `(t foo nil)`},
+	}
+	m := vm.New()
+	for _, test := range tests {
+		res, err := m.EvalString(test.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.src, err)
+			continue
+		}
+		if res != test.expected {
+			t.Errorf("%s: got %s (want %s)", test.src, res, test.expected)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(car 1)`, `Wrong type argument: listp, 1`},
+		{`(error "boom %d" 1)`, `boom 1`},
+		{`(undefined-fn)`, `Symbol’s function definition is void: undefined-fn`},
+		{`(aref [1] 2)`, `Args out of range: [1], 2`},
+	}
+	m := vm.New()
+	for _, test := range tests {
+		_, err := m.EvalString(test.src)
+		if err == nil {
+			t.Errorf("%s: expected error", test.src)
+			continue
+		}
+		if err.Error() != test.expected {
+			t.Errorf("%s: got %s (want %s)", test.src, err, test.expected)
+		}
+	}
+}
+
+func TestCallFunction(t *testing.T) {
+	// (lambda (x y) (+ x y)) compiled by hand.
+	fn := &vm.Function{
+		ArgDesc:  2 + 2<<8,
+		Code:     []byte{1, 1, 92, 135}, // stack-ref 1; stack-ref 1; add; return
+		MaxDepth: 4,
+	}
+	m := vm.New()
+	m.Defalias("add2", fn)
+	res, err := m.Call(fn, int64(1), int64(2))
+	if err != nil || res != vm.Object(int64(3)) {
+		t.Errorf("add2: got %v, %v (want 3)", res, err)
+	}
+	if s, _ := m.EvalString(`(add2 10 20)`); s != "30" {
+		t.Errorf("(add2 10 20): got %s (want 30)", s)
+	}
+	if _, err := m.Call(fn, int64(1)); err == nil {
+		t.Errorf("add2 with 1 arg: expected error")
+	}
+}
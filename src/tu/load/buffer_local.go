@@ -0,0 +1,54 @@
+package load
+
+import (
+	"go/ast"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"strings"
+	"xast"
+)
+
+// collectBufferLocals returns variables that are marked with
+// "//goism:local" directive. Such variables automatically
+// become buffer-local when they are set.
+//
+// Must be called before collectCustoms, so directive
+// is not included into custom variable documentation.
+func collectBufferLocals(p *xast.Package) map[*types.Var]bool {
+	locals := make(map[*types.Var]bool)
+	eachVarSpec(p, func(spec *ast.ValueSpec, doc *ast.CommentGroup) {
+		if !parseLocalDirective(doc) {
+			return
+		}
+		for _, name := range spec.Names {
+			if name.Name != "_" {
+				locals[p.Defs[name].(*types.Var)] = true
+			}
+		}
+	})
+	return locals
+}
+
+func parseLocalDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	found := false
+	for _, line := range doc.List {
+		if strings.TrimSpace(line.Text) == "//goism:local" {
+			found = true
+			line.Text = "//" // Clear comment line
+		}
+	}
+	return found
+}
+
+// bufferLocalDecl returns form that makes variable sym
+// automatically buffer-local.
+func bufferLocalDecl(sym string) sexp.Form {
+	return &sexp.ExprStmt{Expr: sexp.NewLispCall(
+		lisp.InternFunc("make-variable-buffer-local"),
+		sexp.Symbol{Val: sym},
+	)}
+}
@@ -0,0 +1,162 @@
+package load
+
+import (
+	"exn"
+	"go/ast"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"magic_pkg/emacs/rt"
+	"sexp"
+	"strings"
+	"tu/symbols"
+	"xast"
+)
+
+// customVar is a package variable that is exported as user option.
+type customVar struct {
+	group string // Customization group symbol
+	doc   string
+}
+
+// collectCustoms returns variables that are marked with
+// "//goism:custom" directive.
+// Directive argument specifies customization group;
+// if omitted, package group is used.
+func collectCustoms(p *xast.Package) map[*types.Var]*customVar {
+	customs := make(map[*types.Var]*customVar)
+	eachVarSpec(p, func(spec *ast.ValueSpec, doc *ast.CommentGroup) {
+		custom := parseCustomDirective(p, doc)
+		if custom == nil {
+			return
+		}
+		for _, name := range spec.Names {
+			if name.Name != "_" {
+				customs[p.Defs[name].(*types.Var)] = custom
+			}
+		}
+	})
+	return customs
+}
+
+func parseCustomDirective(p *xast.Package, doc *ast.CommentGroup) *customVar {
+	if doc == nil {
+		return nil
+	}
+	var custom *customVar
+	for _, line := range doc.List {
+		if !strings.HasPrefix(line.Text, "//goism:custom") {
+			continue
+		}
+		custom = &customVar{
+			group: strings.TrimSpace(line.Text[len("//goism:custom"):]),
+		}
+		if custom.group == "" {
+			custom.group = pkgGroup(p)
+		}
+		line.Text = "//" // Clear comment line
+	}
+	if custom != nil {
+		custom.doc = strings.TrimRight(doc.Text(), "\n")
+	}
+	return custom
+}
+
+// pkgGroup returns name of package customization group.
+func pkgGroup(p *xast.Package) string {
+	return strings.TrimSuffix(symbols.Mangle(p.FullName, ""), ".")
+}
+
+// pkgGroupDecl returns form that declares package customization group.
+func pkgGroupDecl(p *xast.Package) sexp.Form {
+	return &sexp.ExprStmt{Expr: sexp.NewLispCall(
+		lisp.InternFunc("custom-declare-group"),
+		sexp.Symbol{Val: pkgGroup(p)},
+		sexp.Nil,
+		sexp.Str("Options of `"+p.TypPkg.Path()+"' Go package."),
+	)}
+}
+
+// customInit returns "custom-declare-variable" call that
+// replaces initialization of custom variable.
+// Customize operates on Lisp values, so slices and
+// maps are converted to lists and alists.
+func customInit(custom *customVar, init *sexp.VarUpdate, typ types.Type) sexp.Form {
+	standard := init.Expr
+	args := []sexp.Form{
+		sexp.Symbol{Val: init.Name},
+		nil, // Standard value; set below
+		sexp.Str(custom.doc),
+		sexp.Symbol{Val: ":type"},
+		customType(init.Name, typ),
+		sexp.Symbol{Val: ":group"},
+		sexp.Symbol{Val: custom.group},
+	}
+	switch typ.Underlying().(type) {
+	case *types.Slice:
+		standard = sexp.NewCall(rt.FnSliceToList, standard)
+		args = append(args,
+			sexp.Symbol{Val: ":set"}, sexp.Symbol{Val: rt.FnCustomSetSlice.Name},
+			sexp.Symbol{Val: ":get"}, sexp.Symbol{Val: rt.FnCustomGetSlice.Name},
+		)
+	case *types.Map:
+		standard = sexp.NewCall(rt.FnMapToAlist, standard)
+		args = append(args,
+			sexp.Symbol{Val: ":set"}, sexp.Symbol{Val: rt.FnCustomSetMap.Name},
+			sexp.Symbol{Val: ":get"}, sexp.Symbol{Val: rt.FnCustomGetMap.Name},
+		)
+	}
+	// Standard value is a form that is evaluated by Customize.
+	args[1] = sexp.NewLispCall(lisp.FnList, sexp.Symbol{Val: "quote"}, standard)
+	return &sexp.ExprStmt{Expr: sexp.NewLispCall(lisp.InternFunc("custom-declare-variable"), args...)}
+}
+
+// customType returns form that evaluates to ":type" of user option.
+func customType(name string, typ types.Type) sexp.Form {
+	if res := customElemType(typ); res != nil {
+		return res
+	}
+	switch typ := typ.Underlying().(type) {
+	case *types.Slice:
+		if elem := customElemType(typ.Elem()); elem != nil {
+			return sexp.NewLispCall(lisp.FnList, sexp.Symbol{Val: "repeat"}, elem)
+		}
+	case *types.Map:
+		key, elem := customElemType(typ.Key()), customElemType(typ.Elem())
+		if key != nil && elem != nil {
+			return sexp.NewLispCall(
+				lisp.FnList,
+				sexp.Symbol{Val: "alist"},
+				sexp.Symbol{Val: ":key-type"}, key,
+				sexp.Symbol{Val: ":value-type"}, elem,
+			)
+		}
+	}
+	panic(exn.User("%s: `%s' type can not be customized", name, typ))
+}
+
+// customElemType is like customType, but only
+// handles types that have no Go-specific representation.
+// Returns nil for other types.
+func customElemType(typ types.Type) sexp.Form {
+	switch typ {
+	case lisp.TypSymbol:
+		return sexp.Symbol{Val: "symbol"}
+	case lisp.TypObject:
+		return sexp.Symbol{Val: "sexp"}
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+	switch info := basic.Info(); {
+	case info&types.IsBoolean != 0:
+		return sexp.Symbol{Val: "boolean"}
+	case info&types.IsInteger != 0:
+		return sexp.Symbol{Val: "integer"}
+	case info&types.IsFloat != 0:
+		return sexp.Symbol{Val: "number"}
+	case info&types.IsString != 0:
+		return sexp.Symbol{Val: "string"}
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package load
+
+import (
+	"exn"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"tu/symbols"
+	"xast"
+)
+
+// emacsPkgPath is an import path of package that declares
+// Emacs extension points (see "emacs/emacs" package docs).
+const emacsPkgPath = "emacs/emacs"
+
+// emacsDecls lowers package variables of "emacs/emacs" types
+// into forms that define modes, keymaps and hooks.
+// Variables are initialized as usual; their declarations
+// are read at compile time, so bad bindings are reported
+// before the package is loaded.
+type emacsDecls struct {
+	u     *unit
+	p     *xast.Package
+	inits map[*types.Var]ast.Expr
+
+	forms []sexp.Form  // Executed after package variables initialization
+	funcs []*sexp.Func // Mode commands
+}
+
+func collectEmacsDecls(u *unit, p *xast.Package) *emacsDecls {
+	ed := &emacsDecls{u: u, p: p, inits: make(map[*types.Var]ast.Expr)}
+	for _, init := range p.InitOrder {
+		if len(init.Lhs) == 1 {
+			ed.inits[init.Lhs[0]] = init.Rhs
+		}
+	}
+	for _, init := range p.InitOrder {
+		for _, v := range init.Lhs {
+			switch emacsTypeName(v.Type()) {
+			case "MajorMode":
+				ed.majorMode(ed.literal(v))
+			case "MinorMode":
+				ed.minorMode(ed.literal(v))
+			case "Keymap":
+				ed.keymap(ed.literal(v), "")
+			case "Hook":
+				ed.hook(ed.literal(v))
+			}
+		}
+	}
+	return ed
+}
+
+// emacsTypeName returns name of "emacs/emacs" package type.
+// For other types, empty string is returned.
+func emacsTypeName(typ types.Type) string {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != emacsPkgPath {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// literal returns fields of composite literal that initializes v.
+func (ed *emacsDecls) literal(v *types.Var) map[string]ast.Expr {
+	lit, ok := ed.inits[v].(*ast.CompositeLit)
+	if !ok {
+		panic(exn.User("%s: must be initialized by composite literal", v.Name()))
+	}
+	return ed.fields(v.Name(), lit)
+}
+
+func (ed *emacsDecls) fields(name string, lit *ast.CompositeLit) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			panic(exn.User("%s: composite literal must have keyed fields", name))
+		}
+		fields[kv.Key.(*ast.Ident).Name] = kv.Value
+	}
+	return fields
+}
+
+func (ed *emacsDecls) constant(expr ast.Expr) constant.Value {
+	if expr == nil {
+		return nil
+	}
+	cv := ed.p.Types[expr].Value
+	if cv == nil {
+		panic(exn.User("%s: constant expected", ed.p.FileSet.Position(expr.Pos())))
+	}
+	return cv
+}
+
+func (ed *emacsDecls) str(expr ast.Expr) string {
+	if cv := ed.constant(expr); cv != nil {
+		return constant.StringVal(cv)
+	}
+	return ""
+}
+
+func (ed *emacsDecls) bool(expr ast.Expr) bool {
+	if cv := ed.constant(expr); cv != nil {
+		return constant.BoolVal(cv)
+	}
+	return false
+}
+
+// funcRef returns function that is referenced by expr.
+// Returns nil for nil expr and nil literal.
+func (ed *emacsDecls) funcRef(expr ast.Expr) *types.Func {
+	if expr == nil || ed.p.Types[expr].IsNil() {
+		return nil
+	}
+	var ident *ast.Ident
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = expr
+	case *ast.SelectorExpr:
+		ident = expr.Sel
+	}
+	if ident != nil {
+		fn, ok := ed.p.Uses[ident].(*types.Func)
+		if ok && fn.Type().(*types.Signature).Recv() == nil {
+			return fn
+		}
+	}
+	panic(exn.User("%s: top level function expected", ed.p.FileSet.Position(expr.Pos())))
+}
+
+// funcSym returns symbol of function referenced by expr, or nil.
+func (ed *emacsDecls) funcSym(expr ast.Expr) sexp.Form {
+	if fn := ed.funcRef(expr); fn != nil {
+		return sexp.Symbol{Val: symbols.MangleFunc(fn)}
+	}
+	return sexp.Nil
+}
+
+// command is like funcSym, but also checks that
+// function is interactive.
+func (ed *emacsDecls) command(expr ast.Expr) sexp.Form {
+	fn := ed.funcRef(expr)
+	if fn == nil {
+		panic(exn.User("%s: command expected", ed.p.FileSet.Position(expr.Pos())))
+	}
+	if def := ed.u.conv.FuncTable().LookupFunc(fn.Pkg(), fn.Name()); def == nil || !def.IsInteractive() {
+		panic(exn.User("%s: `%s' is not interactive", ed.p.FileSet.Position(expr.Pos()), fn.Name()))
+	}
+	return sexp.Symbol{Val: symbols.MangleFunc(fn)}
+}
+
+func (ed *emacsDecls) call(fn string, args ...sexp.Form) {
+	ed.forms = append(ed.forms, &sexp.ExprStmt{
+		Expr: sexp.NewLispCall(lisp.InternFunc(fn), args...),
+	})
+}
+
+// defvar is like "defvar": variable is set only if it is unbound.
+func (ed *emacsDecls) defvar(name string, init sexp.Form, doc string) {
+	sym := sexp.Symbol{Val: name}
+	ed.forms = append(ed.forms, &sexp.If{
+		Cond: sexp.NewNot(sexp.NewLispCall(lisp.InternFunc("boundp"), sym)),
+		Then: sexp.Block{&sexp.ExprStmt{
+			Expr: sexp.NewLispCall(lisp.InternFunc("set-default"), sym, init),
+		}},
+		Else: sexp.EmptyForm,
+	})
+	if doc != "" {
+		ed.call("put", sym, sexp.Symbol{Val: "variable-documentation"}, sexp.Str(doc))
+	}
+}
+
+// keymap defines keymap and returns its variable name.
+func (ed *emacsDecls) keymap(fields map[string]ast.Expr, defaultName string) string {
+	name := ed.str(fields["Name"])
+	if name == "" {
+		name = defaultName
+	}
+	if name == "" {
+		panic(exn.User("keymap name is not specified"))
+	}
+	ed.defvar(name, sexp.NewLispCall(lisp.InternFunc("make-sparse-keymap")), ed.str(fields["Doc"]))
+	if bindings, ok := fields["Bindings"].(*ast.CompositeLit); ok {
+		for _, elt := range bindings.Elts {
+			binding := ed.fields(name, elt.(*ast.CompositeLit))
+			key := sexp.NewLispCall(lisp.InternFunc("kbd"), sexp.Str(ed.str(binding["Key"])))
+			keymap := sexp.Var{Name: name, Typ: lisp.TypObject}
+			ed.call("define-key", keymap, key, ed.command(binding["Command"]))
+		}
+	}
+	return name
+}
+
+func (ed *emacsDecls) hook(fields map[string]ast.Expr) {
+	name := ed.str(fields["Name"])
+	if name == "" {
+		panic(exn.User("hook name is not specified"))
+	}
+	if funcs, ok := fields["Funcs"].(*ast.CompositeLit); ok {
+		for _, elt := range funcs.Elts {
+			ed.call("add-hook", sexp.Symbol{Val: name}, ed.funcSym(elt))
+		}
+	}
+}
+
+// modeKeymap returns minor mode keymap (or nil) that
+// is either defined in place or refers to keymap variable.
+func (ed *emacsDecls) modeKeymap(mode string, expr ast.Expr) sexp.Form {
+	var name string
+	switch expr := ast.Unparen(expr).(type) {
+	case nil:
+		return sexp.Nil
+	case *ast.CompositeLit:
+		name = ed.keymap(ed.fields(mode, expr), mode+"-map")
+	case *ast.Ident:
+		v, ok := ed.p.Uses[expr].(*types.Var)
+		if !ok || v.Parent() != ed.p.TypPkg.Scope() {
+			panic(exn.User("%s: keymap must be a package variable", mode))
+		}
+		name = ed.str(ed.literal(v)["Name"])
+	default:
+		panic(exn.User("%s: keymap must be a composite literal or variable", mode))
+	}
+	return sexp.Var{Name: name, Typ: lisp.TypObject}
+}
+
+func (ed *emacsDecls) majorMode(fields map[string]ast.Expr) {
+	name := ed.str(fields["Name"])
+	if name == "" {
+		panic(exn.User("major mode name is not specified"))
+	}
+	hook := name + "-hook"
+	modeName := ed.str(fields["ModeName"])
+	if modeName == "" {
+		modeName = name
+	}
+	parent := sexp.Form(sexp.Nil)
+	if s := ed.str(fields["Parent"]); s != "" {
+		parent = sexp.Symbol{Val: s}
+	}
+
+	ed.defvar(hook, sexp.Nil, "Hook run after entering `"+name+"'.")
+	var keymap sexp.Form
+	if fields["Keymap"] == nil {
+		// Unlike minor mode, major mode always has a keymap.
+		keymap = sexp.Var{Name: ed.keymap(nil, name+"-map"), Typ: lisp.TypObject}
+	} else {
+		keymap = ed.modeKeymap(name, fields["Keymap"])
+	}
+
+	enter := sexp.NewCall(ed.emacsFunc("EnterMajorMode"),
+		sexp.Symbol{Val: name},
+		parent,
+		sexp.Symbol{Val: hook},
+		sexp.Str(modeName),
+		keymap,
+		ed.funcSym(fields["OnEnable"]),
+	)
+	fn := &sexp.Func{
+		Name:      name,
+		DocString: ed.str(fields["Doc"]),
+		Body:      sexp.Block{&sexp.ExprStmt{Expr: enter}, &sexp.Return{}},
+	}
+	fn.LoadDirective("//goism:interactive")
+	fn.SetAutoload(true)
+	ed.funcs = append(ed.funcs, fn)
+}
+
+func (ed *emacsDecls) minorMode(fields map[string]ast.Expr) {
+	name := ed.str(fields["Name"])
+	if name == "" {
+		panic(exn.User("minor mode name is not specified"))
+	}
+	hook := name + "-hook"
+	doc := ed.str(fields["Doc"])
+	lighter := sexp.Form(sexp.Nil)
+	if s := ed.str(fields["Lighter"]); s != "" {
+		lighter = sexp.Str(s)
+	}
+
+	ed.defvar(name, sexp.Nil, "Non-nil if `"+name+"' is enabled.")
+	if !ed.bool(fields["Global"]) {
+		ed.call("make-variable-buffer-local", sexp.Symbol{Val: name})
+	}
+	ed.defvar(hook, sexp.Nil, "Hook run after entering or leaving `"+name+"'.")
+	keymap := ed.modeKeymap(name, fields["Keymap"])
+	ed.call("add-minor-mode", sexp.Symbol{Val: name}, lighter, keymap)
+
+	ed.funcs = append(ed.funcs, ed.modeCommand(name, hook, doc, fields))
+}
+
+// modeCommand returns minor mode command.
+// Like commands that are defined by "define-minor-mode",
+// it takes optional argument and returns new mode state.
+func (ed *emacsDecls) modeCommand(name, hook, doc string, fields map[string]ast.Expr) *sexp.Func {
+	args := sexp.Local{Name: "args", Typ: lisp.TypObject}
+	interactive := sexp.NewLispCall(
+		lisp.InternFunc("called-interactively-p"),
+		sexp.Symbol{Val: "any"},
+	)
+	toggle := sexp.NewCall(ed.emacsFunc("ToggleMinorMode"),
+		sexp.Symbol{Val: name},
+		sexp.Symbol{Val: hook},
+		ed.funcSym(fields["OnEnable"]),
+		ed.funcSym(fields["OnDisable"]),
+		args,
+		interactive,
+	)
+	fn := &sexp.Func{
+		Name:      name,
+		Params:    []string{args.Name},
+		Variadic:  true,
+		Results:   types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.Bool])),
+		DocString: doc,
+		Body:      sexp.Block{&sexp.Return{Results: []sexp.Form{toggle}}},
+	}
+	fn.LoadDirective(`//goism:interactive "P"`)
+	fn.SetAutoload(true)
+	return fn
+}
+
+// emacsFunc returns "emacs/emacs" package function.
+func (ed *emacsDecls) emacsFunc(name string) *sexp.Func {
+	fn := ed.u.conv.FuncTable().LookupFunc(ed.emacsPkg(), name)
+	if fn == nil {
+		panic(exn.Logic("`%s' misses `%s' function", emacsPkgPath, name))
+	}
+	return fn
+}
+
+// emacsPkg returns "emacs/emacs" package that is imported by ed.p.
+func (ed *emacsDecls) emacsPkg() *types.Package {
+	for _, imp := range ed.p.TypPkg.Imports() {
+		if imp.Path() == emacsPkgPath {
+			return imp
+		}
+	}
+	panic(exn.Logic("`%s' is not imported", emacsPkgPath))
+}
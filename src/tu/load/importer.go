@@ -10,6 +10,9 @@ type emacsImporter struct {
 }
 
 func (ei *emacsImporter) Import(path string) (*types.Package, error) {
+	if emacsPath := stdPackages[path]; emacsPath != "" {
+		path = emacsPath
+	}
 	if err := checkPkgPath(path); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package load
+
+import (
+	"exn"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"strings"
+)
+
+// fillInteractiveSpec derives interactive spec from
+// parameter types unless it is given explicitly.
+//
+// Each parameter is read by its own spec line:
+//
+//	string      -> "s" (read string from minibuffer)
+//	integer     -> "p" (numeric prefix argument)
+//	lisp.Symbol -> "S" (read symbol from minibuffer)
+//	lisp.Object -> "x" (read Lisp expression)
+func fillInteractiveSpec(fn *sexp.Func, sig *types.Signature) {
+	if fn.InteractiveSpec != "" || sig.Params().Len() == 0 {
+		return
+	}
+	if sig.Variadic() {
+		panic(exn.User("%s: variadic command requires explicit interactive spec", fn.Name))
+	}
+	lines := make([]string, sig.Params().Len())
+	for i := range lines {
+		param := sig.Params().At(i)
+		code := interactiveCode(param.Type())
+		if code == "" {
+			panic(exn.User("%s: can not derive interactive spec for `%s %s' parameter",
+				fn.Name, param.Name(), param.Type()))
+		}
+		if code != "p" {
+			code += interactivePrompt(param.Name())
+		}
+		lines[i] = code
+	}
+	fn.InteractiveSpec = strings.Join(lines, "\n")
+}
+
+func interactiveCode(typ types.Type) string {
+	switch typ {
+	case lisp.TypSymbol:
+		return "S"
+	case lisp.TypObject:
+		return "x"
+	}
+	if typ, ok := typ.Underlying().(*types.Basic); ok {
+		switch info := typ.Info(); {
+		case info&types.IsString != 0:
+			return "s"
+		case info&types.IsInteger != 0:
+			return "p"
+		}
+	}
+	return ""
+}
+
+// interactivePrompt returns minibuffer prompt for parameter.
+func interactivePrompt(name string) string {
+	if name == "" || name == "_" {
+		return "Argument: "
+	}
+	return strings.ToUpper(name[:1]) + name[1:] + ": "
+}
@@ -2,6 +2,7 @@ package load
 
 import (
 	"bytes"
+	"exn"
 	"fmt"
 	"go/ast"
 	"go/build"
@@ -39,13 +40,14 @@ type funcDeclData struct {
 }
 
 type initData struct {
-	vars []string
-	init *sexp.Func
+	vars  []string
+	init  *sexp.Func
+	funcs []*sexp.Func // Functions that are generated for declarations
 }
 
-func newUnit(ftab *symbols.FuncTable, masterPkg *types.Package, pkgPath string) *unit {
+func newUnit(ftab *symbols.FuncTable, pkgPath string) *unit {
 	env := symbols.NewEnv(pkgPath)
-	itabEnv := symbols.NewItabEnv(masterPkg)
+	itabEnv := symbols.NewItabEnv()
 	return &unit{
 		env:     env,
 		ins:     ftab.Inserter(),
@@ -57,18 +59,17 @@ func newUnit(ftab *symbols.FuncTable, masterPkg *types.Package, pkgPath string)
 
 func Runtime() error {
 	pkgPath := "emacs/rt"
-	pkg, err := translatePkg(pkgPath)
+	pkg, err := translatePkg(token.NewFileSet(), pkgPath)
 	if err != nil {
 		return err
 	}
 	ftab := symbols.NewFuncTable(pkg.TypPkg)
-	u := newUnit(ftab, pkg.TypPkg, pkgPath)
+	u := newUnit(ftab, pkgPath)
 	u.pkgs = []*xast.Package{pkg}
 	collectFuncs(u)
 	rt.InitPackage(pkg.TypPkg)
 	rt.InitFuncs(ftab)
-	funcs := u.ins.GetAllFuncs()
-	convertFuncs(u, funcs, true)
+	funcs := convertFuncs(u, u.ins.GetAllFuncs(), true)
 	opt.OptimizeFuncs(funcs)
 	return nil
 }
@@ -77,47 +78,73 @@ func Package(pkgPath string, optimize bool) (*tu.Package, error) {
 	if err := checkPkgPath(pkgPath); err != nil {
 		return nil, errors.Wrapf(err, "translate `%s'", pkgPath)
 	}
-	masterPkg, err := translatePkg(pkgPath)
+	masterPkg, err := translatePkg(token.NewFileSet(), pkgPath)
 	if err != nil {
 		return nil, err
 	}
 	ftab := symbols.NewFuncTable(masterPkg.TypPkg)
-	u := newUnit(ftab, masterPkg.TypPkg, pkgPath)
+	u := newUnit(ftab, pkgPath)
 	err = collectImports(u, masterPkg)
 	if err != nil {
 		return nil, err
 	}
 
 	collectFuncs(u)
-	funcs := u.ins.GetAllFuncs()
-	convertFuncs(u, funcs, optimize)
+	funcs := convertFuncs(u, u.ins.GetAllFuncs(), optimize)
 	if optimize {
 		opt.OptimizeFuncs(funcs)
 	}
 
 	initializers := collectInitializers(u, masterPkg)
+	for _, lambda := range u.conv.Lambdas() {
+		u.ins.Lambda(masterPkg.TypPkg, lambda)
+		if optimize {
+			opt.OptimizeFuncs([]*sexp.Func{lambda})
+		}
+	}
 
+	requires := pkgRequires(masterPkg)
 	return &tu.Package{
-		Name:    masterPkg.AstPkg.Name,
-		Funcs:   u.ins.GetMasterFuncs(),
-		Init:    initializers.init,
-		Vars:    initializers.vars,
-		Comment: pkgComment(masterPkg.AstPkg.Files),
+		Name:     masterPkg.AstPkg.Name,
+		Funcs:    append(u.ins.GetMasterFuncs(), initializers.funcs...),
+		Init:     initializers.init,
+		Vars:     initializers.vars,
+		Comment:  pkgComment(masterPkg.AstPkg.Files),
+		Feature:  pkgFeature(masterPkg),
+		Summary:  pkgSummary(masterPkg.AstPkg.Name, masterPkg.AstPkg.Files),
+		Headers:  pkgHeaders(masterPkg.AstPkg.Files, requires),
+		Requires: requires,
+		FileSet:  masterPkg.FileSet,
 	}, nil
 }
 
-func convertFuncs(u *unit, funcs []*sexp.Func, optimize bool) {
+// convertFuncs converts function bodies.
+// Returned slice also includes functions lifted from function literals.
+func convertFuncs(u *unit, funcs []*sexp.Func, optimize bool) []*sexp.Func {
+	all := make([]*sexp.Func, 0, len(funcs))
 	for _, fn := range funcs {
-		data := u.decls[fn]
+		data, ok := u.decls[fn]
+		if !ok {
+			// Promoted method wrappers are created with bodies.
+			all = append(all, fn)
+			continue
+		}
 		fn.Body = u.conv.FuncBody(&xast.Func{
 			Pkg:  data.pkg,
+			Name: fn.Name,
 			Ret:  fn.Results,
 			Body: data.decl.Body,
 		})
 		if optimize && !fn.IsNoinline() && isInlineable(fn) {
 			fn.SetInlineable(true)
 		}
+		all = append(all, fn)
+		for _, lambda := range u.conv.Lambdas() {
+			u.ins.Lambda(data.pkg.TypPkg, lambda)
+			all = append(all, lambda)
+		}
 	}
+	return all
 }
 
 func collectFuncs(u *unit) {
@@ -130,6 +157,7 @@ func collectFuncs(u *unit) {
 			}
 		}
 	}
+	collectPromotedMethods(u)
 }
 
 func parseFuncDocText(fn *sexp.Func, doc *ast.CommentGroup) string {
@@ -156,9 +184,10 @@ func getRecvType(recv *types.Var) *types.TypeName {
 func collectFunc(u *unit, p *xast.Package, decl *ast.FuncDecl) {
 	sig := declSignature(p.Info, decl)
 	name := decl.Name.Name
+	// Variadic parameter is passed as a slice, so
+	// function is not variadic on Emacs Lisp level.
 	fn := &sexp.Func{
-		Variadic: sig.Variadic(),
-		Results:  resultTuple(sig),
+		Results: resultTuple(sig),
 	}
 	fn.DocString = parseFuncDocText(fn, decl.Doc)
 	if recv := sig.Recv(); recv == nil {
@@ -166,9 +195,17 @@ func collectFunc(u *unit, p *xast.Package, decl *ast.FuncDecl) {
 		fn.Params = make([]string, 0, decl.Type.Params.NumFields())
 		fn.Name = symbols.Mangle(p.FullName, name)
 		fillFuncParamsInfo(u, fn, sig)
+		if fn.IsInteractive() {
+			fillInteractiveSpec(fn, sig)
+			// Exported commands are package entry points.
+			fn.SetAutoload(ast.IsExported(name))
+		}
 		u.ins.Func(p.TypPkg, name, fn)
 	} else {
 		// Method.
+		if fn.IsInteractive() {
+			panic(exn.User("%s: method can not be interactive", name))
+		}
 		fn.Params = make([]string, 0, decl.Type.Params.NumFields()+1)
 		fn.Params = append(fn.Params, recv.Name()) // "recv" param
 		typ := getRecvType(recv)
@@ -191,7 +228,8 @@ func fillFuncParamsInfo(u *unit, fn *sexp.Func, sig *types.Signature) {
 			if fn.InterfaceInputs == nil {
 				fn.InterfaceInputs = make(map[int]types.Type, sig.Params().Len()-i)
 			}
-			fn.InterfaceInputs[i] = param.Type()
+			// Index in call argument list; receiver is an argument, too.
+			fn.InterfaceInputs[len(fn.Params)-1] = param.Type()
 		}
 	}
 }
@@ -202,29 +240,32 @@ func collectInitializers(u *unit, p *xast.Package) initData {
 	vars := make([]string, 0, 8)
 	env := conv.Env()
 
-	// Initialize master package itabs.
-	for _, itab := range u.itabEnv.GetMasterItabs() {
-		vars = append(vars, itab.Name)
-		iface := itab.Iface
-		elems := make([]sexp.Form, iface.NumMethods()+1)
-		elems[0] = sexp.Symbol{Val: p.FullName + "." + itab.ImplName}
-		for i := 0; i < iface.NumMethods(); i++ {
-			sym := symbols.MangleMethod(
-				p.FullName,
-				itab.ImplName,
-				iface.Method(i).Name(),
-			)
-			elems[i+1] = sexp.Symbol{Val: sym}
+	// User options are defined by "custom-declare-variable"
+	// instead of "defvar" and plain assignment.
+	locals := collectBufferLocals(p)
+	customs := collectCustoms(p)
+	groupDeclared := false
+	initVar := func(v *types.Var, form sexp.Form) sexp.Form {
+		custom := customs[v]
+		if custom == nil {
+			return form
 		}
-		body = append(body, &sexp.VarUpdate{
-			Name: itab.Name,
-			Expr: sexp.NewLispCall(
-				lisp.FnVector,
-				elems...,
-			),
-		})
+		if forms, ok := form.(sexp.FormList); ok && len(forms) == 1 {
+			form = forms[0]
+		}
+		init, ok := form.(*sexp.VarUpdate)
+		if !ok {
+			panic(exn.User("%s: custom variable must be initialized separately", v.Name()))
+		}
+		form = customInit(custom, init, v.Type())
+		if !groupDeclared && custom.group == pkgGroup(p) {
+			groupDeclared = true
+			form = sexp.FormList{pkgGroupDecl(p), form}
+		}
+		return form
 	}
 
+	initialized := make(map[*types.Var]bool)
 	blankIdent := &ast.Ident{Name: "_"}
 	for _, init := range p.InitOrder {
 		idents := make([]*ast.Ident, len(init.Lhs))
@@ -234,15 +275,24 @@ func collectInitializers(u *unit, p *xast.Package) initData {
 			} else {
 				idents[i] = &ast.Ident{Name: v.Name()}
 				p.Uses[idents[i]] = v
-				vars = append(vars, env.InternVar(nil, v.Name()))
+				initialized[v] = true
+				sym := env.InternVar(nil, v.Name())
+				if customs[v] == nil {
+					vars = append(vars, sym)
+				}
 			}
 		}
 
-		body = append(body, conv.VarInit(&xast.Assign{
+		form := conv.VarInit(&xast.Assign{
 			Pkg: p,
 			Lhs: idents,
 			Rhs: init.Rhs,
-		}))
+		})
+		for _, v := range init.Lhs {
+			form = initVar(v, form)
+		}
+		sexp.SetPos(form, init.Rhs.Pos())
+		body = append(body, form)
 
 		// Clear information that was added to type info above.
 		for _, ident := range idents {
@@ -252,18 +302,57 @@ func collectInitializers(u *unit, p *xast.Package) initData {
 
 	// InitOrder misses entries for variables without explicit
 	// initializers. They are collected here.
+	// Env can not be used to find them: variables that
+	// are referenced by functions are already interned.
 	topScope := p.TypPkg.Scope()
 	for _, name := range topScope.Names() {
-		if v, ok := topScope.Lookup(name).(*types.Var); ok {
-			if env.ContainsVar(v.Name()) {
+		switch obj := topScope.Lookup(name).(type) {
+		case *types.Var:
+			if initialized[obj] {
 				continue
 			}
-			sym := env.InternVar(nil, v.Name())
-			vars = append(vars, sym)
-			body = append(body, conv.VarZeroInit(sym, v.Type()))
+			sym := env.InternVar(nil, obj.Name())
+			if customs[obj] == nil {
+				vars = append(vars, sym)
+			}
+			body = append(body, initVar(obj, conv.VarZeroInit(sym, obj.Type())))
+
+		case *types.TypeName:
+			// Every named type gets its runtime type descriptor.
+			if !obj.IsAlias() {
+				u.itabEnv.InternType(obj.Type())
+			}
+		}
+	}
+
+	// Variables become buffer-local after they are
+	// initialized, so initial value is the default value.
+	for _, name := range topScope.Names() {
+		if v, ok := topScope.Lookup(name).(*types.Var); ok && locals[v] {
+			body = append(body, bufferLocalDecl(env.InternVar(nil, v.Name())))
 		}
 	}
 
+	// Minor modes, keymaps and hooks are defined after
+	// all variables are initialized.
+	decls := collectEmacsDecls(u, p)
+	body = append(body, decls.forms...)
+
+	// Type descriptors and itabs must be initialized before
+	// any other variable, because initializers may refer them.
+	// Collected after all other code is converted to
+	// catch all dynamic types that are used.
+	rtti := make([]sexp.Form, 0, 8)
+	for _, desc := range u.itabEnv.GetTypes() {
+		vars = append(vars, desc.Name)
+		rtti = append(rtti, typeDescInit(desc))
+	}
+	for _, itab := range u.itabEnv.GetItabs() {
+		vars = append(vars, itab.Name)
+		rtti = append(rtti, itabInit(itab))
+	}
+	body = append(rtti, body...)
+
 	if len(body) != 0 {
 		body = append(body, &sexp.Return{})
 	}
@@ -273,7 +362,8 @@ func collectInitializers(u *unit, p *xast.Package) initData {
 			Name: "init",
 			Body: sexp.Block(body),
 		},
-		vars: vars,
+		vars:  vars,
+		funcs: decls.funcs,
 	}
 }
 
@@ -284,7 +374,7 @@ func collectImportsIter(pkgs *[]*xast.Package, p *xast.Package) error {
 			continue // "emacs/lisp" is a special package (lisp "unsafe")
 		}
 
-		pkg, err := translatePkg(imp.Path())
+		pkg, err := translatePkg(p.FileSet, imp.Path())
 		if err != nil {
 			return err
 		}
@@ -303,9 +393,12 @@ func collectImports(u *unit, p *xast.Package) error {
 	return err
 }
 
-func translatePkg(pkgPath string) (*xast.Package, error) {
+// translatePkg parses and typechecks package.
+// Imported packages should share fset with importer,
+// so positions of all package forms are unique.
+func translatePkg(fset *token.FileSet, pkgPath string) (*xast.Package, error) {
+	importPath := pkgPath
 	pkgPath = build.Default.GOPATH + "/src/" + pkgPath
-	fset := token.NewFileSet()
 	astPkg, err := parseDir(fset, pkgPath, parser.ParseComments)
 	if err != nil {
 		return nil, err
@@ -316,7 +409,7 @@ func translatePkg(pkgPath string) (*xast.Package, error) {
 		Uses:       make(map[*ast.Ident]types.Object),
 		Selections: make(map[*ast.SelectorExpr]*types.Selection),
 	}
-	typPkg, err := typecheckPkg(fset, astPkg, ti)
+	typPkg, err := typecheckPkg(fset, importPath, astPkg, ti)
 	if err != nil {
 		return nil, err
 	}
@@ -354,13 +447,13 @@ var typecheckCfg = types.Config{
 	Importer: &emacsImporter{impl: importer.Default()},
 }
 
-func typecheckPkg(fset *token.FileSet, pkg *ast.Package, ti *types.Info) (*types.Package, error) {
+func typecheckPkg(fset *token.FileSet, path string, pkg *ast.Package, ti *types.Info) (*types.Package, error) {
 	// Convert file map to slice.
 	files := make([]*ast.File, 0, len(pkg.Files))
 	for _, file := range pkg.Files {
 		files = append(files, file)
 	}
-	return typecheckCfg.Check(pkg.Name, fset, files, ti)
+	return typecheckCfg.Check(path, fset, files, ti)
 }
 
 func pkgComment(files map[string]*ast.File) string {
@@ -372,7 +465,7 @@ func pkgComment(files map[string]*ast.File) string {
 			buf.WriteString("\t<")
 			buf.WriteString(filepath.Base(name))
 			buf.WriteString(">\n")
-			buf.WriteString(file.Doc.Text())
+			buf.WriteString(stripHeaders(file.Doc.Text()))
 		}
 	}
 
@@ -0,0 +1,180 @@
+package load
+
+import (
+	"bytes"
+	"cfg"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"magic_pkg/emacs/lisp"
+	"sort"
+	"strings"
+	"tu"
+	"unicode"
+	"unicode/utf8"
+	"xast"
+)
+
+// Package metadata that is needed to publish translated
+// package as a single-file package.el package.
+
+// headerKeys are library header keywords that are moved
+// from package documentation into the file header.
+// Documentation line should look like "Version: 1.0".
+var headerKeys = map[string]bool{
+	"Author":           true,
+	"Maintainer":       true,
+	"Version":          true,
+	"Package-Version":  true,
+	"Package-Requires": true,
+	"Keywords":         true,
+	"URL":              true,
+	"Homepage":         true,
+	"License":          true,
+}
+
+// pkgFeature returns name of feature that is provided by package.
+func pkgFeature(p *xast.Package) string {
+	return featureName(p.FullName)
+}
+
+func featureName(pkgPath string) string {
+	return "goism-" + strings.Replace(pkgPath, "/", "-", -1)
+}
+
+// pkgRequires returns features of goism packages that are
+// imported by p. Runtime package is imported implicitly.
+// Imported packages are sorted, so output is reproducible.
+func pkgRequires(p *xast.Package) []string {
+	rt := featureName("rt")
+	var imports []string
+	for _, imp := range p.TypPkg.Imports() {
+		feature := featureName(pkgFullName(imp.Path()))
+		if imp != lisp.Package && feature != rt {
+			imports = append(imports, feature)
+		}
+	}
+	sort.Strings(imports)
+	if p.FullName != "rt" {
+		return append([]string{rt}, imports...)
+	}
+	return imports
+}
+
+// pkgDocs returns package documentation texts
+// in file name order.
+func pkgDocs(files map[string]*ast.File) []string {
+	names := make([]string, 0, len(files))
+	for name, file := range files {
+		if file.Doc != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	docs := make([]string, len(names))
+	for i, name := range names {
+		docs[i] = files[name].Doc.Text()
+	}
+	return docs
+}
+
+// pkgHeaders collects header keywords from package documentation.
+// If "Version" is not specified, cfg.PackageVersion is used.
+// If "Package-Requires" is not specified, it is derived
+// from target Emacs version.
+// Required goism features are added to "Package-Requires".
+func pkgHeaders(files map[string]*ast.File, requires []string) []tu.Header {
+	var headers []tu.Header
+	hasVersion := false
+	requiresIndex := -1
+	for _, text := range pkgDocs(files) {
+		for _, line := range strings.Split(text, "\n") {
+			if key, val, ok := parseHeader(line); ok {
+				switch key {
+				case "Version", "Package-Version":
+					hasVersion = true
+				case "Package-Requires":
+					requiresIndex = len(headers)
+				}
+				headers = append(headers, tu.Header{Key: key, Val: val})
+			}
+		}
+	}
+	if !hasVersion {
+		headers = append(headers, tu.Header{
+			Key: "Version",
+			Val: cfg.PackageVersion,
+		})
+	}
+	if requiresIndex == -1 {
+		requiresIndex = len(headers)
+		headers = append(headers, tu.Header{
+			Key: "Package-Requires",
+			Val: fmt.Sprintf(`((emacs "%d.1"))`, cfg.TargetEmacsVersion),
+		})
+	}
+	h := &headers[requiresIndex]
+	h.Val = addPackageRequires(h.Val, requires)
+	return headers
+}
+
+// addPackageRequires appends features that are missing
+// from "Package-Requires" list.
+// Malformed list is returned unchanged.
+func addPackageRequires(list string, features []string) string {
+	if !strings.HasSuffix(list, ")") {
+		return list
+	}
+	var buf bytes.Buffer
+	buf.WriteString(list[:len(list)-1])
+	for _, feature := range features {
+		if strings.Contains(list, "("+feature+" ") {
+			continue
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("(")) {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, `(%s "%s")`, feature, cfg.PackageVersion)
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// parseHeader splits "Key: value" documentation line.
+// Only keys from headerKeys are recognized.
+func parseHeader(line string) (key, val string, ok bool) {
+	i := strings.Index(line, ": ")
+	if i == -1 || !headerKeys[line[:i]] {
+		return "", "", false
+	}
+	return line[:i], strings.TrimSpace(line[i+2:]), true
+}
+
+// stripHeaders removes header keyword lines from documentation text.
+func stripHeaders(text string) string {
+	lines := strings.Split(text, "\n")
+	res := lines[:0]
+	for _, line := range lines {
+		if _, _, ok := parseHeader(line); !ok {
+			res = append(res, line)
+		}
+	}
+	return strings.Join(res, "\n")
+}
+
+// pkgSummary returns the first sentence of package documentation
+// without "Package name" prefix: "Package foo does x." => "Does x".
+func pkgSummary(name string, files map[string]*ast.File) string {
+	docs := pkgDocs(files)
+	if len(docs) == 0 {
+		return "Translated Go package"
+	}
+	summary := doc.Synopsis(docs[0])
+	summary = strings.TrimPrefix(summary, "Package "+name+" ")
+	summary = strings.TrimSuffix(summary, ".")
+	if summary == "" {
+		return "Translated Go package"
+	}
+	r, size := utf8.DecodeRuneInString(summary)
+	return string(unicode.ToUpper(r)) + summary[size:]
+}
@@ -0,0 +1,145 @@
+package load
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/rt"
+	"sexp"
+	"tu/symbols"
+	"xast"
+	"xtypes"
+)
+
+// Methods that are promoted from embedded fields are
+// called through wrappers. Wrapper is a method of the
+// embedding type that selects embedded field and calls
+// the original method with it as a receiver.
+// Wrappers are bound to the same symbols as ordinary
+// methods would be, so itabs and direct calls use them
+// without any special handling.
+
+// collectPromotedMethods inserts wrappers for methods that
+// are promoted to named types of all unit packages.
+// Must be called after all declared methods are collected.
+func collectPromotedMethods(u *unit) {
+	for _, p := range u.pkgs {
+		for _, f := range p.AstPkg.Files {
+			for _, decl := range f.Decls {
+				decl, ok := decl.(*ast.GenDecl)
+				if !ok || decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					obj := p.Defs[spec.(*ast.TypeSpec).Name].(*types.TypeName)
+					collectTypePromotedMethods(u, p, obj)
+				}
+			}
+		}
+	}
+}
+
+func collectTypePromotedMethods(u *unit, p *xast.Package, obj *types.TypeName) {
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return
+	}
+	// Pointer method set includes value methods, too.
+	mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		if len(sel.Index()) == 1 {
+			continue
+		}
+		if fn := promotedMethod(u, p, obj, sel); fn != nil {
+			u.ins.Method(obj, sel.Obj().Name(), fn)
+		}
+	}
+}
+
+// promotedMethod returns wrapper for promoted method sel.
+// Returns nil if embedded field type is not supported.
+func promotedMethod(u *unit, p *xast.Package, obj *types.TypeName, sel *types.Selection) *sexp.Func {
+	method := sel.Obj().(*types.Func)
+	sig := method.Type().(*types.Signature)
+	fn := &sexp.Func{
+		Name:    symbols.MangleMethod(p.FullName, obj.Name(), method.Name()),
+		Params:  make([]string, 0, sig.Params().Len()+1),
+		Results: resultTuple(sig),
+	}
+	fn.Params = append(fn.Params, "recv")
+
+	// Single level of indirection for structs is the same
+	// as not having indirection at all.
+	var recv sexp.Form = sexp.Local{Name: "recv", Typ: obj.Type()}
+	var typ types.Type = obj.Type()
+	path := sel.Index()
+	for _, index := range path[:len(path)-1] {
+		structTyp, ok := xtypes.MaybeDeref(typ).Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		recv = &sexp.StructIndex{Struct: recv, Index: index, Typ: structTyp}
+		typ = structTyp.Field(index).Type()
+	}
+
+	args := make([]sexp.Form, sig.Params().Len())
+	for i := range args {
+		param := sig.Params().At(i)
+		name := fmt.Sprintf("arg%d", i)
+		fn.Params = append(fn.Params, name)
+		args[i] = sexp.Local{Name: name, Typ: param.Type()}
+		if types.IsInterface(param.Type()) {
+			if fn.InterfaceInputs == nil {
+				fn.InterfaceInputs = make(map[int]types.Type, len(args)-i)
+			}
+			fn.InterfaceInputs[len(fn.Params)-1] = param.Type()
+		}
+	}
+
+	var call *sexp.Call
+	switch {
+	case types.IsInterface(typ):
+		if len(args) >= len(rt.FnIfaceCall) {
+			return nil
+		}
+		iface := typ.Underlying().(*types.Interface)
+		call = &sexp.Call{
+			Fn: rt.FnIfaceCall[len(args)],
+			Args: append([]sexp.Form{
+				recv,
+				sexp.Int(xtypes.LookupIfaceMethod(method.Name(), iface)),
+			}, args...),
+		}
+	case xtypes.IsStruct(xtypes.MaybeDeref(typ)) || isPtr(typ) == isPtrRecv(method):
+		target := u.conv.FuncTable().LookupMethod(xtypes.AsNamedType(typ).Obj(), method.Name())
+		if target == nil {
+			return nil
+		}
+		call = &sexp.Call{Fn: target, Args: append([]sexp.Form{recv}, args...)}
+	default:
+		// Pointers to other types are boxes, so embedded field
+		// must have the same indirection as method receiver.
+		return nil
+	}
+
+	if sig.Results().Len() == 0 {
+		fn.Body = sexp.Block{
+			&sexp.ExprStmt{Expr: call},
+			&sexp.Return{},
+		}
+	} else {
+		// Additional results are already stored in RetN variables.
+		fn.Body = sexp.Block{&sexp.Return{Results: []sexp.Form{call}}}
+	}
+	return fn
+}
+
+func isPtr(typ types.Type) bool {
+	_, ok := typ.(*types.Pointer)
+	return ok
+}
+
+func isPtrRecv(method *types.Func) bool {
+	return isPtr(method.Type().(*types.Signature).Recv().Type())
+}
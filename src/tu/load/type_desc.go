@@ -0,0 +1,178 @@
+package load
+
+import (
+	"exn"
+	"go/token"
+	"go/types"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"tu/symbols"
+	"vmm"
+	"xtypes"
+)
+
+// itabInit returns form that initializes itab variable.
+// Itab is a vector of [type-desc-symbol method-symbols...].
+func itabInit(itab symbols.Itab) sexp.Form {
+	iface := itab.Iface
+	mset := types.NewMethodSet(itab.Impl)
+	elems := make([]sexp.Form, iface.NumMethods()+1)
+	elems[0] = sexp.Symbol{Val: symbols.MangleType(itab.Impl)}
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		sel := mset.Lookup(m.Pkg(), m.Name())
+		if sel == nil || isLispType(itab.Impl) || !hasMethodSym(itab.Impl, sel) {
+			panic(exn.NoImpl("`%s' to `%s' conversion", itab.Impl, iface))
+		}
+		elems[i+1] = sexp.Symbol{
+			Val: symbols.MangleSelection(itab.Impl, sel),
+		}
+	}
+	return &sexp.VarUpdate{
+		Name: itab.Name,
+		Expr: sexp.NewLispCall(lisp.FnVector, elems...),
+	}
+}
+
+// typeDescInit returns form that initializes type descriptor variable.
+// See "vmm" package for descriptor layout.
+func typeDescInit(desc symbols.TypeDesc) sexp.Form {
+	typ := desc.Typ
+	attrs := make([]sexp.Form, vmm.TypeDescSize)
+	for i := range attrs {
+		attrs[i] = sexp.Nil
+	}
+
+	attrs[vmm.TypeDescKind] = sexp.Int(vmm.KindOf(typ))
+	attrs[vmm.TypeDescString] = sexp.Str(types.TypeString(typ, pkgName))
+	attrs[vmm.TypeDescName] = sexp.Str("")
+	attrs[vmm.TypeDescPkgPath] = sexp.Str("")
+	attrs[vmm.TypeDescLen] = sexp.Int(0)
+	switch typ := typ.(type) {
+	case *types.Named:
+		attrs[vmm.TypeDescName] = sexp.Str(typ.Obj().Name())
+		if pkg := typ.Obj().Pkg(); pkg != nil {
+			attrs[vmm.TypeDescPkgPath] = sexp.Str(pkg.Path())
+		}
+	case *types.Basic:
+		attrs[vmm.TypeDescName] = sexp.Str(typ.Name())
+	}
+
+	switch utyp := typ.Underlying().(type) {
+	case *types.Pointer:
+		attrs[vmm.TypeDescElem] = typeDescRef(utyp.Elem())
+	case *types.Slice:
+		attrs[vmm.TypeDescElem] = typeDescRef(utyp.Elem())
+	case *types.Chan:
+		attrs[vmm.TypeDescElem] = typeDescRef(utyp.Elem())
+	case *types.Array:
+		attrs[vmm.TypeDescElem] = typeDescRef(utyp.Elem())
+		attrs[vmm.TypeDescLen] = sexp.Int(utyp.Len())
+	case *types.Map:
+		attrs[vmm.TypeDescKey] = typeDescRef(utyp.Key())
+		attrs[vmm.TypeDescElem] = typeDescRef(utyp.Elem())
+	case *types.Struct:
+		fields := make([]sexp.Form, utyp.NumFields())
+		for i := range fields {
+			field := utyp.Field(i)
+			fields[i] = sexp.NewLispCall(
+				lisp.FnCons,
+				sexp.Str(field.Name()),
+				typeDescRef(field.Type()),
+			)
+		}
+		attrs[vmm.TypeDescFields] = pairVector(fields)
+	}
+	methods, methodTypes := typeMethods(typ)
+	attrs[vmm.TypeDescMethods] = pairVector(methods)
+	attrs[vmm.TypeDescMethodTypes] = pairVector(methodTypes)
+
+	return &sexp.VarUpdate{
+		Name: desc.Name,
+		Expr: sexp.NewLispCall(lisp.FnVector, attrs...),
+	}
+}
+
+// typeMethods returns (name . function) pairs for every
+// exported method of given type and signature strings of
+// these methods.
+// Functions are nil for interface types.
+func typeMethods(typ types.Type) (methods, methodTypes []sexp.Form) {
+	if iface, ok := typ.Underlying().(*types.Interface); ok {
+		for i := 0; i < iface.NumMethods(); i++ {
+			if m := iface.Method(i); m.Exported() {
+				methods = append(methods, sexp.NewLispCall(
+					lisp.FnCons, sexp.Str(m.Name()), sexp.Nil,
+				))
+				methodTypes = append(methodTypes, methodTypeString(m))
+			}
+		}
+		return methods, methodTypes
+	}
+
+	mset := types.NewMethodSet(typ)
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		if !sel.Obj().Exported() || !hasMethodSym(typ, sel) {
+			continue
+		}
+		methods = append(methods, sexp.NewLispCall(
+			lisp.FnCons,
+			sexp.Str(sel.Obj().Name()),
+			sexp.Symbol{Val: symbols.MangleSelection(typ, sel)},
+		))
+		methodTypes = append(methodTypes, methodTypeString(sel.Obj()))
+	}
+	return methods, methodTypes
+}
+
+// methodTypeString returns method signature without receiver
+// and parameter names. Package paths are used instead of
+// package names, so equal signatures have equal strings.
+func methodTypeString(method types.Object) sexp.Form {
+	sig := method.Type().(*types.Signature)
+	sig = types.NewSignatureType(
+		nil, nil, nil,
+		unnamedTuple(sig.Params()),
+		unnamedTuple(sig.Results()),
+		sig.Variadic(),
+	)
+	return sexp.Str(types.TypeString(sig, func(pkg *types.Package) string {
+		return pkg.Path()
+	}))
+}
+
+func unnamedTuple(tuple *types.Tuple) *types.Tuple {
+	vars := make([]*types.Var, tuple.Len())
+	for i := range vars {
+		vars[i] = types.NewVar(token.NoPos, nil, "", tuple.At(i).Type())
+	}
+	return types.NewTuple(vars...)
+}
+
+// hasMethodSym reports whether method sel of typ is bound
+// to a symbol. Promoted methods have wrappers only if typ
+// is named (see "promoted.go").
+func hasMethodSym(typ types.Type, sel *types.Selection) bool {
+	return len(sel.Index()) == 1 || xtypes.AsNamedType(typ) != nil
+}
+
+func typeDescRef(typ types.Type) sexp.Form {
+	return sexp.Symbol{Val: symbols.MangleType(typ)}
+}
+
+func pairVector(pairs []sexp.Form) sexp.Form {
+	if len(pairs) == 0 {
+		return sexp.Nil
+	}
+	return sexp.NewLispCall(lisp.FnVector, pairs...)
+}
+
+func pkgName(pkg *types.Package) string {
+	return pkg.Name()
+}
+
+func isLispType(typ types.Type) bool {
+	named := xtypes.AsNamedType(typ)
+	return named != nil && named.Obj().Pkg() == lisp.Package
+}
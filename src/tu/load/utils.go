@@ -2,13 +2,37 @@ package load
 
 import (
 	"go/ast"
+	"go/token"
 	"go/types"
 	"strings"
+	"xast"
 	"xtypes"
 
 	"github.com/pkg/errors"
 )
 
+// eachVarSpec calls fn for every package level variable spec.
+// Doc is spec documentation; for single spec declaration
+// without parentheses, declaration documentation is used.
+func eachVarSpec(p *xast.Package, fn func(spec *ast.ValueSpec, doc *ast.CommentGroup)) {
+	for _, f := range p.AstPkg.Files {
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.ValueSpec)
+				doc := spec.Doc
+				if doc == nil && !decl.Lparen.IsValid() {
+					doc = decl.Doc
+				}
+				fn(spec, doc)
+			}
+		}
+	}
+}
+
 func declSignature(ti *types.Info, decl *ast.FuncDecl) *types.Signature {
 	return ti.Defs[decl.Name].Type().(*types.Signature)
 }
@@ -20,6 +44,21 @@ func resultTuple(sig *types.Signature) *types.Tuple {
 	return xtypes.EmptyTuple
 }
 
+// stdPackages maps Go standard library package paths
+// to their goism implementations.
+// Mapped packages can be imported by their standard paths.
+var stdPackages = map[string]string{
+	"container/heap": "emacs/container/heap",
+	"container/list": "emacs/container/list",
+	"errors":         "emacs/errors",
+	"fmt":            "emacs/fmt",
+	"reflect":        "emacs/reflect",
+	"sort":           "emacs/sort",
+	"strconv":        "emacs/strconv",
+	"strings":        "emacs/strings",
+	"unicode/utf8":   "emacs/unicode/utf8",
+}
+
 func checkPkgPath(pkgPath string) error {
 	// Only "emacs/" prefix check is mandatory,
 	// but in order to provide better error message
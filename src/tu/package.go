@@ -1,6 +1,7 @@
 package tu
 
 import (
+	"go/token"
 	"sexp"
 )
 
@@ -16,4 +17,28 @@ type Package struct {
 	Init *sexp.Func
 
 	Comment string
+
+	// Feature is provided by the package; generated
+	// file is expected to be named after it.
+	Feature string
+
+	// Summary is a short package description that
+	// goes into the first line of generated file.
+	Summary string
+
+	// Headers are library header keywords, like "Version".
+	Headers []Header
+
+	// Requires lists features of imported goism packages.
+	Requires []string
+
+	// FileSet resolves positions that are recorded in Funcs.
+	FileSet *token.FileSet
+}
+
+// Header is a library header keyword that is
+// used by package.el, like ";; Version: 1.0".
+type Header struct {
+	Key string
+	Val string
 }
@@ -42,7 +42,7 @@ func (env *Env) internVar(bucket map[string]string, pkgPath string, name string)
 
 func (env *Env) InternVar(pkg *types.Package, name string) string {
 	switch {
-	case pkg == nil:
+	case pkg == nil || pkgFullName(pkg.Path()) == env.masterPkgName:
 		return env.internVar(env.symbols, env.masterPkgName, name)
 
 	case pkg == lisp.Package:
@@ -15,13 +15,16 @@ type FuncTable struct {
 	externFuncs map[funcKey]*sexp.Func
 }
 
+// Packages are identified by their import paths because
+// single package can be represented by different
+// *types.Package objects (source and imported ones).
 type funcKey struct {
-	pkg  *types.Package
-	name string
+	pkgPath string
+	name    string
 }
 
 type methodKey struct {
-	pkgName  string
+	pkgPath  string
 	typeName string
 	name     string
 }
@@ -49,22 +52,26 @@ func (ftab *FuncTable) MasterPkg() *types.Package {
 
 // LookupFunc returns stored function or nil if no entry is found.
 func (ftab *FuncTable) LookupFunc(p *types.Package, name string) *sexp.Func {
-	if p == ftab.masterPkg {
+	if ftab.isMaster(p) {
 		return ftab.funcs[name]
 	}
-	return ftab.externFuncs[funcKey{pkg: p, name: name}]
+	return ftab.externFuncs[funcKey{pkgPath: p.Path(), name: name}]
 }
 
 // LookupMethod returns stored method or nil if no entry is found.
 func (ftab *FuncTable) LookupMethod(recv *types.TypeName, name string) *sexp.Func {
 	key := methodKey{
-		pkgName:  recv.Pkg().Name(),
+		pkgPath:  recv.Pkg().Path(),
 		typeName: recv.Name(),
 		name:     name,
 	}
 	return ftab.methods[key]
 }
 
+func (ftab *FuncTable) isMaster(p *types.Package) bool {
+	return p.Path() == ftab.masterPkg.Path()
+}
+
 // FuncTableInserter collects functions to fill FunctionTable.
 type FuncTableInserter struct {
 	ftab *FuncTable
@@ -75,11 +82,11 @@ type FuncTableInserter struct {
 
 // Func inserts a new function into table.
 func (ins *FuncTableInserter) Func(p *types.Package, name string, fn *sexp.Func) {
-	if p == ins.ftab.masterPkg {
+	if ins.ftab.isMaster(p) {
 		ins.ftab.funcs[name] = fn
 		ins.masterFuncs = append(ins.masterFuncs, fn)
 	} else {
-		ins.ftab.externFuncs[funcKey{pkg: p, name: name}] = fn
+		ins.ftab.externFuncs[funcKey{pkgPath: p.Path(), name: name}] = fn
 		ins.otherFuncs = append(ins.otherFuncs, fn)
 	}
 }
@@ -87,12 +94,22 @@ func (ins *FuncTableInserter) Func(p *types.Package, name string, fn *sexp.Func)
 // Method inserts a new method into table.
 func (ins *FuncTableInserter) Method(recv *types.TypeName, name string, fn *sexp.Func) {
 	key := methodKey{
-		pkgName:  recv.Pkg().Name(),
+		pkgPath:  recv.Pkg().Path(),
 		typeName: recv.Name(),
 		name:     name,
 	}
 	ins.ftab.methods[key] = fn
-	if key.pkgName == ins.ftab.masterPkg.Name() {
+	if ins.ftab.isMaster(recv.Pkg()) {
+		ins.masterFuncs = append(ins.masterFuncs, fn)
+	} else {
+		ins.otherFuncs = append(ins.otherFuncs, fn)
+	}
+}
+
+// Lambda inserts a function that was lifted from function literal.
+// Lambdas can not be looked up by name.
+func (ins *FuncTableInserter) Lambda(p *types.Package, fn *sexp.Func) {
+	if ins.ftab.isMaster(p) {
 		ins.masterFuncs = append(ins.masterFuncs, fn)
 	} else {
 		ins.otherFuncs = append(ins.otherFuncs, fn)
@@ -107,6 +124,10 @@ func (ins *FuncTableInserter) GetMasterFuncs() []*sexp.Func {
 
 // GetAllFuncs returns all functons ever inserted into function table.
 // Returned slice elements are sorted with in-source declaration order.
+// Returned slice does not share memory with the table, so
+// inserting lambdas does not change it.
 func (ins *FuncTableInserter) GetAllFuncs() []*sexp.Func {
-	return append(ins.otherFuncs, ins.masterFuncs...)
+	funcs := make([]*sexp.Func, 0, len(ins.otherFuncs)+len(ins.masterFuncs))
+	funcs = append(funcs, ins.otherFuncs...)
+	return append(funcs, ins.masterFuncs...)
 }
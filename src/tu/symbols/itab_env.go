@@ -5,60 +5,88 @@ import (
 )
 
 // ItabEnv used to store interface dynamic type info.
+//
+// Every interned itab and type descriptor must be
+// initialized by the package that is being translated.
+// Symbol names are canonical, so the same object can be
+// safely initialized by several packages.
 type ItabEnv struct {
-	masterPkg *types.Package
+	itabs    []Itab
+	typeDesc []TypeDesc
 
-	vals        map[itabKey]string
-	masterItabs []Itab
-}
-
-type typeInfo struct {
-	name string
-	pkg  *types.Package
-}
-
-type itabKey struct {
-	impl  *types.Named
-	iface *types.Named
+	syms map[string]bool
 }
 
 // Itab contains information about interface table variable.
 type Itab struct {
-	Name     string // Symbol name
-	ImplName string // Implementation type name
-	Iface    *types.Interface
+	Name  string     // Symbol name
+	Impl  types.Type // Implementation (dynamic) type
+	Iface *types.Interface
 }
 
-func NewItabEnv(masterPkg *types.Package) *ItabEnv {
+// TypeDesc contains information about runtime type descriptor variable.
+type TypeDesc struct {
+	Name string // Symbol name
+	Typ  types.Type
+}
+
+func NewItabEnv() *ItabEnv {
 	return &ItabEnv{
-		masterPkg: masterPkg,
-		vals:      make(map[itabKey]string, 32),
+		syms: make(map[string]bool, 32),
 	}
 }
 
 // Intern returns itab variable name.
-func (env *ItabEnv) Intern(implTyp, ifaceTyp *types.Named) string {
-	key := itabKey{impl: implTyp, iface: ifaceTyp}
-	if val := env.vals[key]; val != "" {
-		return val
+// Implementation type descriptor is interned as well.
+func (env *ItabEnv) Intern(implTyp, ifaceTyp types.Type) string {
+	sym := MangleItab(implTyp, ifaceTyp)
+	if env.syms[sym] {
+		return sym
 	}
-	implObj, ifaceObj := implTyp.Obj(), ifaceTyp.Obj()
-	implStr := implObj.Name()
-	ifaceStr := ifaceObj.Pkg().Name() + "." + ifaceObj.Name()
-	name := "%itab/" + implStr + "/" + ifaceStr
-	// #FIXME: should have full package path here instead of Pkg().Name().
-	sym := ManglePriv(implObj.Pkg().Name(), name)
-	env.vals[key] = sym
-	if implObj.Pkg() == env.masterPkg {
-		env.masterItabs = append(env.masterItabs, Itab{
-			Name:     sym,
-			Iface:    ifaceTyp.Underlying().(*types.Interface),
-			ImplName: implObj.Name(),
-		})
+	env.syms[sym] = true
+	env.InternType(implTyp)
+	env.itabs = append(env.itabs, Itab{
+		Name:  sym,
+		Impl:  implTyp,
+		Iface: ifaceTyp.Underlying().(*types.Interface),
+	})
+	return sym
+}
+
+// InternType returns type descriptor variable name.
+// All types that are referenced by descriptor are interned, too.
+func (env *ItabEnv) InternType(typ types.Type) string {
+	sym := MangleType(typ)
+	if env.syms[sym] {
+		return sym
+	}
+	env.syms[sym] = true
+	env.typeDesc = append(env.typeDesc, TypeDesc{Name: sym, Typ: typ})
+
+	switch typ := typ.Underlying().(type) {
+	case *types.Pointer:
+		env.InternType(typ.Elem())
+	case *types.Slice:
+		env.InternType(typ.Elem())
+	case *types.Array:
+		env.InternType(typ.Elem())
+	case *types.Chan:
+		env.InternType(typ.Elem())
+	case *types.Map:
+		env.InternType(typ.Key())
+		env.InternType(typ.Elem())
+	case *types.Struct:
+		for i := 0; i < typ.NumFields(); i++ {
+			env.InternType(typ.Field(i).Type())
+		}
 	}
 	return sym
 }
 
-func (env *ItabEnv) GetMasterItabs() []Itab {
-	return env.masterItabs
+func (env *ItabEnv) GetItabs() []Itab {
+	return env.itabs
+}
+
+func (env *ItabEnv) GetTypes() []TypeDesc {
+	return env.typeDesc
 }
@@ -2,6 +2,9 @@ package symbols
 
 import (
 	"fmt"
+	"go/types"
+	"strings"
+	"xtypes"
 )
 
 const (
@@ -27,3 +30,67 @@ func MangleMethod(pkgPath string, recv string, name string) string {
 func ManglePriv(pkgPath string, name string) string {
 	return fmt.Sprintf("%s%s.%s", symPrivatePrefix, pkgPath, name)
 }
+
+// Demangle returns a Go qualified name of mangled symbol.
+// Package path is replaced by the package name.
+// Demangle("goism-unicode/utf8.RuneLen") => "utf8.RuneLen".
+func Demangle(sym string) string {
+	switch {
+	case strings.HasPrefix(sym, symPrivatePrefix):
+		sym = sym[len(symPrivatePrefix):]
+	case strings.HasPrefix(sym, symPrefix):
+		sym = sym[len(symPrefix):]
+	default:
+		return sym
+	}
+	if i := strings.LastIndexByte(sym, '/'); i != -1 {
+		sym = sym[i+1:]
+	}
+	return sym
+}
+
+// MangleType returns a symbol name that is bound to the
+// runtime type descriptor of specified type.
+// MangleType(*pkg.T) => "goism--%type/*pkg.T".
+//
+// Package path is used instead of package name, so
+// equal types have equal symbols in all packages.
+func MangleType(typ types.Type) string {
+	return symPrivatePrefix + "%type/" + typeString(typ)
+}
+
+// MangleItab returns a symbol name that is bound to the
+// interface table for given {implementation, interface} pair.
+// MangleItab(pkg.T, fmt.Stringer) => "goism--%itab/pkg.T/fmt.Stringer".
+func MangleItab(implTyp, ifaceTyp types.Type) string {
+	return symPrivatePrefix + "%itab/" + typeString(implTyp) + "/" + typeString(ifaceTyp)
+}
+
+// MangleFunc returns a symbol name of Go function or method.
+func MangleFunc(fn *types.Func) string {
+	pkgPath := pkgFullName(fn.Pkg().Path())
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return Mangle(pkgPath, fn.Name())
+	}
+	typ := xtypes.AsNamedType(recv.Type())
+	return MangleMethod(pkgPath, typ.Obj().Name(), fn.Name())
+}
+
+// MangleSelection returns a symbol name of the method that is
+// selected from typ method set.
+// Promoted methods are bound to wrappers that are defined for typ.
+func MangleSelection(typ types.Type, sel *types.Selection) string {
+	fn := sel.Obj().(*types.Func)
+	if len(sel.Index()) == 1 {
+		return MangleFunc(fn)
+	}
+	obj := xtypes.AsNamedType(typ).Obj()
+	return MangleMethod(pkgFullName(obj.Pkg().Path()), obj.Name(), fn.Name())
+}
+
+func typeString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		return pkgFullName(pkg.Path())
+	})
+}
@@ -0,0 +1,120 @@
+package vm
+
+// Buffer-local values and dynamic bindings.
+//
+// Automatically buffer-local variable (see "make-variable-buffer-local")
+// gets buffer-local value when it is set; buffers without local
+// value see the default value, which is stored in the symbol itself.
+
+// specBinding is a dynamic binding stack entry.
+// Binding of buffer-local value is restored in the buffer
+// where it was made, no matter which buffer is current.
+//
+// Entries with unwind function are recorded by "unwind-protect"
+// and "save-" instructions; other fields are unused for them.
+type specBinding struct {
+	sym    *Symbol
+	buf    *Buffer // Nil if default value is bound
+	val    Object  // Previous value
+	bound  bool    // Previous value is bound
+	unwind func()
+}
+
+// localValue returns symbol buffer-local value.
+// Nil buffer has no local values.
+func (buf *Buffer) localValue(sym *Symbol) (Object, bool) {
+	if buf == nil {
+		return nil, false
+	}
+	val, ok := buf.locals[sym]
+	return val, ok
+}
+
+func (buf *Buffer) setLocalValue(sym *Symbol, val Object) {
+	if buf.locals == nil {
+		buf.locals = make(map[*Symbol]Object)
+	}
+	buf.locals[sym] = val
+}
+
+// varRef returns symbol value that is seen from the current buffer.
+func (vm *VM) varRef(sym *Symbol) Object {
+	if val, ok := vm.current.localValue(sym); ok {
+		return val
+	}
+	return symbolValue(sym)
+}
+
+// varSet sets symbol value that is seen from the current buffer.
+func (vm *VM) varSet(sym *Symbol, val Object) {
+	_, ok := vm.current.localValue(sym)
+	if ok || (sym.local && vm.current != nil) {
+		vm.current.setLocalValue(sym, val)
+		return
+	}
+	setValue(sym, val)
+}
+
+// varBound reports whether symbol value that is seen
+// from the current buffer is bound.
+func (vm *VM) varBound(sym *Symbol) bool {
+	_, ok := vm.current.localValue(sym)
+	return ok || sym.bound
+}
+
+// specbind binds symbol dynamically, like "let" does.
+// If symbol has no buffer-local value, default value is bound.
+func (vm *VM) specbind(sym *Symbol, val Object) {
+	b := specBinding{sym: sym, val: sym.Value, bound: sym.bound}
+	if old, ok := vm.current.localValue(sym); ok {
+		b.buf, b.val, b.bound = vm.current, old, true
+	}
+	if b.buf != nil {
+		b.buf.setLocalValue(sym, val)
+	} else {
+		setValue(sym, val)
+	}
+	vm.specpdl = append(vm.specpdl, b)
+}
+
+// recordUnwind pushes binding stack entry that
+// calls fn when it is unbound.
+func (vm *VM) recordUnwind(fn func()) {
+	vm.specpdl = append(vm.specpdl, specBinding{unwind: fn})
+}
+
+// unwindProtect records "unwind-protect" handler.
+// Handler is a function or, for old bytecode, a list of forms.
+func (vm *VM) unwindProtect(handler Object) {
+	vm.recordUnwind(func() {
+		if forms, ok := handler.(*Cons); ok {
+			vm.evalBody(listToSlice(forms))
+		} else {
+			vm.funcall(handler, nil)
+		}
+	})
+}
+
+// unbind undoes n most recent dynamic bindings.
+func (vm *VM) unbind(n int) {
+	if n > len(vm.specpdl) {
+		signal(invalidByteCodeSym, "binding stack underflow")
+	}
+	vm.unbindTo(len(vm.specpdl) - n)
+}
+
+// unbindTo undoes dynamic bindings until
+// binding stack is truncated to depth.
+func (vm *VM) unbindTo(depth int) {
+	for len(vm.specpdl) > depth {
+		b := vm.specpdl[len(vm.specpdl)-1]
+		vm.specpdl = vm.specpdl[:len(vm.specpdl)-1]
+		if b.unwind != nil {
+			b.unwind()
+		} else if b.buf != nil {
+			b.buf.setLocalValue(b.sym, b.val)
+		} else {
+			b.sym.Value, b.sym.bound = b.val, b.bound
+		}
+	}
+}
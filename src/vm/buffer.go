@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Buffer point and narrowing.
+//
+// Positions are stored as 0-based character offsets;
+// Lisp positions are 1-based, like in Emacs.
+// Text is always inserted at point.
+
+// marker is a buffer position that is adjusted by insertions.
+// Advancing marker moves when text is inserted at its position.
+type marker struct {
+	pos     int
+	advance bool
+}
+
+// size returns buffer text length in characters.
+func (buf *Buffer) size() int {
+	return utf8.RuneCountInString(buf.Text.String())
+}
+
+// accessible returns text of accessible portion.
+func (buf *Buffer) accessible() string {
+	text := buf.Text.String()
+	return text[byteOffset(text, buf.begv):byteOffset(text, buf.zv)]
+}
+
+func (buf *Buffer) insert(s string) {
+	if s == "" {
+		return
+	}
+	if text := buf.Text.String(); buf.pt != utf8.RuneCountInString(text) {
+		at := byteOffset(text, buf.pt)
+		buf.Text = strings.Builder{}
+		buf.Text.WriteString(text[:at] + s + text[at:])
+	} else {
+		buf.Text.WriteString(s)
+	}
+
+	n := utf8.RuneCountInString(s)
+	for _, m := range buf.markers {
+		if m.pos > buf.pt || (m.advance && m.pos == buf.pt) {
+			m.pos += n
+		}
+	}
+	buf.zv += n
+	buf.pt += n
+}
+
+// gotoChar moves point; position is clamped
+// to the accessible portion.
+func (buf *Buffer) gotoChar(pos int) {
+	switch {
+	case pos < buf.begv:
+		pos = buf.begv
+	case pos > buf.zv:
+		pos = buf.zv
+	}
+	buf.pt = pos
+}
+
+func (buf *Buffer) narrowed() bool {
+	return buf.begv != 0 || buf.zv != buf.size()
+}
+
+func (buf *Buffer) narrow(begv, zv int) {
+	buf.begv, buf.zv = begv, zv
+	buf.gotoChar(buf.pt)
+}
+
+func (buf *Buffer) widen() {
+	buf.begv, buf.zv = 0, buf.size()
+}
+
+func (buf *Buffer) newMarker(pos int, advance bool) *marker {
+	m := &marker{pos: pos, advance: advance}
+	buf.markers = append(buf.markers, m)
+	return m
+}
+
+func (buf *Buffer) removeMarker(m *marker) {
+	for i, x := range buf.markers {
+		if x == m {
+			buf.markers = append(buf.markers[:i], buf.markers[i+1:]...)
+			return
+		}
+	}
+}
+
+// saveExcursion records binding stack entry that
+// restores current buffer and its point.
+// Nothing is restored if buffer is killed.
+func (vm *VM) saveExcursion() {
+	buf := vm.current
+	if buf == nil {
+		vm.recordUnwind(func() { vm.current = nil })
+		return
+	}
+	pt := buf.newMarker(buf.pt, false)
+	vm.recordUnwind(func() {
+		buf.removeMarker(pt)
+		if buf.Live {
+			vm.current = buf
+			buf.gotoChar(pt.pos)
+		}
+	})
+}
+
+// saveCurrentBuffer records binding stack entry
+// that restores current buffer if it is alive.
+func (vm *VM) saveCurrentBuffer() {
+	buf := vm.current
+	vm.recordUnwind(func() {
+		if buf == nil || buf.Live {
+			vm.current = buf
+		}
+	})
+}
+
+// saveRestriction records binding stack entry that
+// restores narrowing of the current buffer.
+func (vm *VM) saveRestriction() {
+	buf := vm.current
+	switch {
+	case buf == nil:
+		vm.recordUnwind(func() {})
+	case !buf.narrowed():
+		vm.recordUnwind(buf.widen)
+	default:
+		begv := buf.newMarker(buf.begv, false)
+		zv := buf.newMarker(buf.zv, true)
+		vm.recordUnwind(func() {
+			buf.removeMarker(begv)
+			buf.removeMarker(zv)
+			buf.narrow(begv.pos, zv.pos)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package vm
+
+// builtins are defined inside every new VM.
+// Each builtins*.go file appends its own functions in init.
+var builtins []*Builtin
+
+// many is a Builtin.MaxArgs value for "&rest" functions.
+const many = -1
+
+func defBuiltins(defs ...*Builtin) {
+	builtins = append(builtins, defs...)
+}
+
+// optArg returns i-th argument or nil if it is not passed.
+func optArg(args []Object, i int) Object {
+	if i < len(args) {
+		return args[i]
+	}
+	return Nil
+}
+
+func toInt(x Object) int64 {
+	n, ok := x.(int64)
+	if !ok {
+		wrongType("integerp", x)
+	}
+	return n
+}
+
+func toSymbol(x Object) *Symbol {
+	sym, ok := x.(*Symbol)
+	if !ok {
+		wrongType("symbolp", x)
+	}
+	return sym
+}
+
+func toCons(x Object) *Cons {
+	cons, ok := x.(*Cons)
+	if !ok {
+		wrongType("consp", x)
+	}
+	return cons
+}
+
+func toHashTable(x Object) *HashTable {
+	h, ok := x.(*HashTable)
+	if !ok {
+		wrongType("hash-table-p", x)
+	}
+	return h
+}
+
+func toBuffer(x Object) *Buffer {
+	buf, ok := x.(*Buffer)
+	if !ok {
+		wrongType("bufferp", x)
+	}
+	return buf
+}
+
+// toString returns string text; unibyte strings are accepted.
+func toString(x Object) string {
+	switch x := x.(type) {
+	case string:
+		return x
+	case Unibyte:
+		return string(x)
+	}
+	wrongType("stringp", x)
+	return ""
+}
+
+// stringDesignator is like toString, but symbols are also accepted.
+func stringDesignator(x Object) string {
+	if sym, ok := x.(*Symbol); ok {
+		return sym.Name
+	}
+	return toString(x)
+}
+
+func isString(x Object) bool {
+	switch x.(type) {
+	case string, Unibyte:
+		return true
+	}
+	return false
+}
+
+// toSlice returns elements of a list, vector or string.
+func toSlice(seq Object) []Object {
+	switch seq := seq.(type) {
+	case *Vector:
+		return seq.Elems
+	case string:
+		elems := make([]Object, 0, len(seq))
+		for _, ch := range seq {
+			elems = append(elems, int64(ch))
+		}
+		return elems
+	case Unibyte:
+		elems := make([]Object, len(seq))
+		for i := 0; i < len(seq); i++ {
+			elems[i] = int64(seq[i])
+		}
+		return elems
+	}
+	var elems []Object
+	x := seq
+	for !IsNil(x) {
+		cons, ok := x.(*Cons)
+		if !ok {
+			wrongType("listp", seq)
+		}
+		elems = append(elems, cons.Car)
+		x = cons.Cdr
+	}
+	return elems
+}
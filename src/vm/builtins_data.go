@@ -0,0 +1,533 @@
+package vm
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Lists, vectors, sequences and symbols.
+func init() {
+	defBuiltins(
+		&Builtin{"cons", 2, 2, func(vm *VM, args []Object) Object {
+			return &Cons{Car: args[0], Cdr: args[1]}
+		}},
+		&Builtin{"car", 1, 1, func(vm *VM, args []Object) Object {
+			return car(args[0])
+		}},
+		&Builtin{"cdr", 1, 1, func(vm *VM, args []Object) Object {
+			return cdr(args[0])
+		}},
+		&Builtin{"cadr", 1, 1, func(vm *VM, args []Object) Object {
+			return car(cdr(args[0]))
+		}},
+		&Builtin{"cddr", 1, 1, func(vm *VM, args []Object) Object {
+			return cdr(cdr(args[0]))
+		}},
+		&Builtin{"setcar", 2, 2, func(vm *VM, args []Object) Object {
+			return setcar(args[0], args[1])
+		}},
+		&Builtin{"setcdr", 2, 2, func(vm *VM, args []Object) Object {
+			return setcdr(args[0], args[1])
+		}},
+		&Builtin{"nthcdr", 2, 2, func(vm *VM, args []Object) Object {
+			return nthcdr(toInt(args[0]), args[1])
+		}},
+		&Builtin{"nth", 2, 2, func(vm *VM, args []Object) Object {
+			return car(nthcdr(toInt(args[0]), args[1]))
+		}},
+		&Builtin{"list", 0, many, func(vm *VM, args []Object) Object {
+			return List(args...)
+		}},
+		&Builtin{"make-list", 2, 2, func(vm *VM, args []Object) Object {
+			var res Object = Nil
+			for i := toInt(args[0]); i > 0; i-- {
+				res = &Cons{Car: args[1], Cdr: res}
+			}
+			return res
+		}},
+		&Builtin{"number-sequence", 1, 3, func(vm *VM, args []Object) Object {
+			from, to := args[0], optArg(args, 1)
+			if IsNil(to) {
+				return List(from)
+			}
+			var step Object = int64(1)
+			if !IsNil(optArg(args, 2)) {
+				step = args[2]
+			}
+			var elems []Object
+			if numCompare(step, int64(0)) > 0 {
+				for x := from; numCompare(x, to) <= 0; x = arith(opAdd, x, step) {
+					elems = append(elems, x)
+				}
+			} else {
+				for x := from; numCompare(x, to) >= 0; x = arith(opAdd, x, step) {
+					elems = append(elems, x)
+				}
+			}
+			return List(elems...)
+		}},
+		&Builtin{"memq", 2, 2, func(vm *VM, args []Object) Object {
+			return memq(args[0], args[1])
+		}},
+		&Builtin{"member", 2, 2, func(vm *VM, args []Object) Object {
+			return member(args[0], args[1])
+		}},
+		&Builtin{"assq", 2, 2, func(vm *VM, args []Object) Object {
+			for lst := args[1]; !IsNil(lst); lst = cdr(lst) {
+				if entry, ok := car(lst).(*Cons); ok && eq(entry.Car, args[0]) {
+					return entry
+				}
+			}
+			return Nil
+		}},
+
+		&Builtin{"vector", 0, many, func(vm *VM, args []Object) Object {
+			return &Vector{Elems: append([]Object(nil), args...)}
+		}},
+		&Builtin{"make-vector", 2, 2, func(vm *VM, args []Object) Object {
+			n := toInt(args[0])
+			if n < 0 {
+				wrongType("wholenump", args[0])
+			}
+			elems := make([]Object, n)
+			for i := range elems {
+				elems[i] = args[1]
+			}
+			return &Vector{Elems: elems}
+		}},
+		&Builtin{"vconcat", 0, many, func(vm *VM, args []Object) Object {
+			var elems []Object
+			for _, arg := range args {
+				elems = append(elems, toSlice(arg)...)
+			}
+			if elems == nil {
+				elems = []Object{}
+			}
+			return &Vector{Elems: elems}
+		}},
+		&Builtin{"aref", 2, 2, func(vm *VM, args []Object) Object {
+			return aref(args[0], args[1])
+		}},
+		&Builtin{"aset", 3, 3, func(vm *VM, args []Object) Object {
+			return aset(args[0], args[1], args[2])
+		}},
+		&Builtin{"length", 1, 1, func(vm *VM, args []Object) Object {
+			return int64(length(args[0]))
+		}},
+		&Builtin{"copy-sequence", 1, 1, func(vm *VM, args []Object) Object {
+			switch seq := args[0].(type) {
+			case *Vector:
+				return &Vector{Elems: append([]Object{}, seq.Elems...)}
+			case string, Unibyte:
+				return seq
+			}
+			return List(toSlice(args[0])...)
+		}},
+		&Builtin{"sort", 2, 2, func(vm *VM, args []Object) Object {
+			return vm.sort(args[0], args[1])
+		}},
+
+		&Builtin{"eq", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(eq(args[0], args[1]))
+		}},
+		&Builtin{"equal", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(equal(args[0], args[1]))
+		}},
+		&Builtin{"not", 1, 1, func(vm *VM, args []Object) Object {
+			return Bool(IsNil(args[0]))
+		}},
+		&Builtin{"null", 1, 1, func(vm *VM, args []Object) Object {
+			return Bool(IsNil(args[0]))
+		}},
+		&Builtin{"identity", 1, 1, func(vm *VM, args []Object) Object {
+			return args[0]
+		}},
+		&Builtin{"consp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*Cons)
+			return Bool(ok)
+		}},
+		&Builtin{"listp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*Cons)
+			return Bool(ok || IsNil(args[0]))
+		}},
+		&Builtin{"symbolp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*Symbol)
+			return Bool(ok)
+		}},
+		&Builtin{"stringp", 1, 1, func(vm *VM, args []Object) Object {
+			return Bool(isString(args[0]))
+		}},
+		&Builtin{"integerp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(int64)
+			return Bool(ok)
+		}},
+		&Builtin{"floatp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(float64)
+			return Bool(ok)
+		}},
+		&Builtin{"booleanp", 1, 1, func(vm *VM, args []Object) Object {
+			return Bool(args[0] == Object(Nil) || args[0] == Object(T))
+		}},
+		&Builtin{"vectorp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*Vector)
+			return Bool(ok)
+		}},
+
+		&Builtin{"hash-table-p", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*HashTable)
+			return Bool(ok)
+		}},
+
+		&Builtin{"symbol-value", 1, 1, func(vm *VM, args []Object) Object {
+			return vm.varRef(toSymbol(args[0]))
+		}},
+		&Builtin{"set", 2, 2, func(vm *VM, args []Object) Object {
+			vm.varSet(toSymbol(args[0]), args[1])
+			return args[1]
+		}},
+		// Default value is stored in the symbol itself.
+		&Builtin{"default-value", 1, 1, func(vm *VM, args []Object) Object {
+			return symbolValue(toSymbol(args[0]))
+		}},
+		&Builtin{"set-default", 2, 2, func(vm *VM, args []Object) Object {
+			setValue(toSymbol(args[0]), args[1])
+			return args[1]
+		}},
+		&Builtin{"local-variable-p", 1, 2, func(vm *VM, args []Object) Object {
+			buf := vm.current
+			if !IsNil(optArg(args, 1)) {
+				buf = toBuffer(args[1])
+			}
+			_, ok := buf.localValue(toSymbol(args[0]))
+			return Bool(ok)
+		}},
+		&Builtin{"buffer-local-value", 2, 2, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			if val, ok := toBuffer(args[1]).localValue(sym); ok {
+				return val
+			}
+			return symbolValue(sym)
+		}},
+		&Builtin{"boundp", 1, 1, func(vm *VM, args []Object) Object {
+			return Bool(vm.varBound(toSymbol(args[0])))
+		}},
+		&Builtin{"symbol-name", 1, 1, func(vm *VM, args []Object) Object {
+			return toSymbol(args[0]).Name
+		}},
+		&Builtin{"intern", 1, 1, func(vm *VM, args []Object) Object {
+			return vm.Intern(toString(args[0]))
+		}},
+		&Builtin{"make-symbol", 1, 1, func(vm *VM, args []Object) Object {
+			return newSymbol(toString(args[0]))
+		}},
+		&Builtin{"get", 2, 2, func(vm *VM, args []Object) Object {
+			return Get(toSymbol(args[0]), args[1])
+		}},
+		&Builtin{"put", 3, 3, func(vm *VM, args []Object) Object {
+			Put(toSymbol(args[0]), args[1], args[2])
+			return args[2]
+		}},
+		&Builtin{"fset", 2, 2, func(vm *VM, args []Object) Object {
+			toSymbol(args[0]).Func = args[1]
+			return args[1]
+		}},
+		&Builtin{"defalias", 2, 3, func(vm *VM, args []Object) Object {
+			toSymbol(args[0]).Func = args[1]
+			return args[0]
+		}},
+		&Builtin{"make-byte-code", 4, many, func(vm *VM, args []Object) Object {
+			return makeByteCode(args)
+		}},
+	)
+}
+
+func car(x Object) Object {
+	if cons, ok := x.(*Cons); ok {
+		return cons.Car
+	}
+	if !IsNil(x) {
+		wrongType("listp", x)
+	}
+	return Nil
+}
+
+func cdr(x Object) Object {
+	if cons, ok := x.(*Cons); ok {
+		return cons.Cdr
+	}
+	if !IsNil(x) {
+		wrongType("listp", x)
+	}
+	return Nil
+}
+
+func setcar(cell, val Object) Object {
+	toCons(cell).Car = val
+	return val
+}
+
+func setcdr(cell, val Object) Object {
+	toCons(cell).Cdr = val
+	return val
+}
+
+func nthcdr(n int64, lst Object) Object {
+	for ; n > 0 && !IsNil(lst); n-- {
+		lst = cdr(lst)
+	}
+	return lst
+}
+
+func memq(elt, lst Object) Object {
+	for ; !IsNil(lst); lst = cdr(lst) {
+		if eq(car(lst), elt) {
+			return lst
+		}
+	}
+	return Nil
+}
+
+func member(elt, lst Object) Object {
+	for ; !IsNil(lst); lst = cdr(lst) {
+		if equal(car(lst), elt) {
+			return lst
+		}
+	}
+	return Nil
+}
+
+// eq reports whether x and y are the same Lisp object.
+// Strings have no identity in VM, so they are compared by contents.
+func eq(x, y Object) bool {
+	return x == y
+}
+
+func equal(x, y Object) bool {
+	switch x := x.(type) {
+	case *Cons:
+		y, ok := y.(*Cons)
+		for ok {
+			if !equal(x.Car, y.Car) {
+				return false
+			}
+			xNext, xOk := x.Cdr.(*Cons)
+			yNext, yOk := y.Cdr.(*Cons)
+			if !xOk || !yOk {
+				return equal(x.Cdr, y.Cdr)
+			}
+			x, y = xNext, yNext
+		}
+		return false
+	case *Vector:
+		y, ok := y.(*Vector)
+		if !ok || len(x.Elems) != len(y.Elems) {
+			return false
+		}
+		for i := range x.Elems {
+			if !equal(x.Elems[i], y.Elems[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		if y, ok := y.(Unibyte); ok {
+			return x == string(y) && isASCII(x)
+		}
+	case Unibyte:
+		if y, ok := y.(string); ok {
+			return y == string(x) && isASCII(y)
+		}
+	}
+	return x == y
+}
+
+// makeByteCode returns byte-code function object with given slots:
+// argdesc, bytecode, constants, max stack depth and optional
+// docstring and interactive spec.
+func makeByteCode(slots []Object) *Function {
+	if len(slots) < 4 || len(slots) > 6 {
+		signal(invalidReadSyntaxSym, "Invalid byte-code object")
+	}
+	consts, ok := slots[2].(*Vector)
+	if !ok {
+		wrongType("vectorp", slots[2])
+	}
+	fn := &Function{
+		ArgDesc:  int(toInt(slots[0])),
+		Code:     []byte(toString(slots[1])),
+		Consts:   consts.Elems,
+		MaxDepth: int(toInt(slots[3])),
+	}
+	if doc, ok := optArg(slots, 4).(string); ok {
+		fn.Doc = doc
+	}
+	if len(slots) == 6 {
+		fn.Interactive = slots[5]
+	}
+	return fn
+}
+
+// slots returns number of byte-code function object slots.
+// Only commands have interactive spec slot.
+func (fn *Function) slots() int {
+	if fn.Interactive == nil {
+		return 5
+	}
+	return 6
+}
+
+func length(x Object) int {
+	switch x := x.(type) {
+	case string:
+		if isASCII(x) {
+			return len(x)
+		}
+		return utf8.RuneCountInString(x)
+	case Unibyte:
+		return len(x)
+	case *Vector:
+		return len(x.Elems)
+	case *Function:
+		return x.slots()
+	}
+	n := 0
+	for lst := x; !IsNil(lst); n++ {
+		cons, ok := lst.(*Cons)
+		if !ok {
+			wrongType("listp", x)
+		}
+		lst = cons.Cdr
+	}
+	return n
+}
+
+// checkIndex returns idx as int, if it is inside [0, n) range.
+func checkIndex(seq, idx Object, n int) int {
+	i := toInt(idx)
+	if i < 0 || i >= int64(n) {
+		signal(argsOutOfRangeSym, seq, idx)
+	}
+	return int(i)
+}
+
+func aref(arr, idx Object) Object {
+	switch arr := arr.(type) {
+	case *Vector:
+		return arr.Elems[checkIndex(arr, idx, len(arr.Elems))]
+	case Unibyte:
+		return int64(arr[checkIndex(arr, idx, len(arr))])
+	case string:
+		if isASCII(arr) {
+			return int64(arr[checkIndex(arr, idx, len(arr))])
+		}
+		i := checkIndex(arr, idx, utf8.RuneCountInString(arr))
+		for _, ch := range arr {
+			if i == 0 {
+				return int64(ch)
+			}
+			i--
+		}
+	case *Function:
+		return [...]Object{
+			int64(arr.ArgDesc),
+			Unibyte(arr.Code),
+			&Vector{Elems: arr.Consts},
+			int64(arr.MaxDepth),
+			arr.Doc,
+			arr.Interactive,
+		}[checkIndex(arr, idx, arr.slots())]
+	}
+	wrongType("arrayp", arr)
+	return nil
+}
+
+func aset(arr, idx, val Object) Object {
+	vec, ok := arr.(*Vector)
+	if !ok {
+		if isString(arr) {
+			// Go strings are immutable; translated code never mutates them.
+			signal(unsupportedOpcodeSym, "aset on string")
+		}
+		wrongType("arrayp", arr)
+	}
+	vec.Elems[checkIndex(arr, idx, len(vec.Elems))] = val
+	return val
+}
+
+// sliceIndexes converts "substring" style indexes into [from, to) range.
+// Negative indexes count from the end; nil "to" means the end.
+func sliceIndexes(seq, fromArg, toArg Object, n int) (int, int) {
+	from, to := int64(0), int64(n)
+	if !IsNil(fromArg) {
+		from = toInt(fromArg)
+	}
+	if !IsNil(toArg) {
+		to = toInt(toArg)
+	}
+	if from < 0 {
+		from += int64(n)
+	}
+	if to < 0 {
+		to += int64(n)
+	}
+	if from < 0 || to > int64(n) || from > to {
+		signal(argsOutOfRangeSym, seq, fromArg, toArg)
+	}
+	return int(from), int(to)
+}
+
+func substring(seq, fromArg, toArg Object) Object {
+	switch seq := seq.(type) {
+	case *Vector:
+		from, to := sliceIndexes(seq, fromArg, toArg, len(seq.Elems))
+		return &Vector{Elems: append([]Object{}, seq.Elems[from:to]...)}
+	case Unibyte:
+		from, to := sliceIndexes(seq, fromArg, toArg, len(seq))
+		return seq[from:to]
+	case string:
+		if isASCII(seq) {
+			from, to := sliceIndexes(seq, fromArg, toArg, len(seq))
+			return seq[from:to]
+		}
+		runes := []rune(seq)
+		from, to := sliceIndexes(seq, fromArg, toArg, len(runes))
+		return string(runes[from:to])
+	}
+	wrongType("arrayp", seq)
+	return nil
+}
+
+// sort is a stable sort of list or vector.
+// Vectors are sorted in place; for lists, cons cells are reused.
+func (vm *VM) sort(seq, pred Object) Object {
+	elems := toSlice(seq)
+	if vec, ok := seq.(*Vector); ok {
+		elems = vec.Elems
+	} else if !IsNil(seq) {
+		if _, ok := seq.(*Cons); !ok {
+			wrongType("sequencep", seq)
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		return !IsNil(vm.funcall(pred, []Object{elems[i], elems[j]}))
+	})
+	if _, ok := seq.(*Vector); ok {
+		return seq
+	}
+	lst := seq
+	for _, elem := range elems {
+		cons := lst.(*Cons)
+		cons.Car = elem
+		lst = cons.Cdr
+	}
+	return seq
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
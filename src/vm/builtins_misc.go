@@ -0,0 +1,547 @@
+package vm
+
+import (
+	"io"
+	"strconv"
+)
+
+// Control flow, output and buffers.
+func init() {
+	defBuiltins(
+		&Builtin{"funcall", 1, many, func(vm *VM, args []Object) Object {
+			return vm.funcall(args[0], args[1:])
+		}},
+		&Builtin{"apply", 1, many, func(vm *VM, args []Object) Object {
+			last := len(args) - 1
+			all := append(append([]Object(nil), args[1:last]...), toSlice(args[last])...)
+			if last == 0 {
+				// (apply '(fn args...)) form.
+				return vm.funcall(all[0], all[1:])
+			}
+			return vm.funcall(args[0], all)
+		}},
+		&Builtin{"apply-partially", 1, many, func(vm *VM, args []Object) Object {
+			return &Partial{Fn: args[0], Args: append([]Object(nil), args[1:]...)}
+		}},
+		&Builtin{"mapcar", 2, 2, func(vm *VM, args []Object) Object {
+			elems := toSlice(args[1])
+			res := make([]Object, len(elems))
+			for i, elem := range elems {
+				res[i] = vm.funcall(args[0], []Object{elem})
+			}
+			return List(res...)
+		}},
+		&Builtin{"byte-code", 3, 3, func(vm *VM, args []Object) Object {
+			// Top level ".elc" form: function without arguments
+			// that is called immediately.
+			return vm.funcall(makeByteCode([]Object{int64(0), args[0], args[1], args[2]}), nil)
+		}},
+		&Builtin{"provide", 1, 2, func(vm *VM, args []Object) Object {
+			vm.Provide(toSymbol(args[0]).Name)
+			return args[0]
+		}},
+		&Builtin{"featurep", 1, 2, func(vm *VM, args []Object) Object {
+			return Bool(vm.featurep(toSymbol(args[0])))
+		}},
+		&Builtin{"require", 1, 3, func(vm *VM, args []Object) Object {
+			// Files can not be loaded by VM, so
+			// feature must be already provided.
+			feature := toSymbol(args[0])
+			if !vm.featurep(feature) {
+				if !IsNil(optArg(args, 2)) {
+					return Nil
+				}
+				signalError("Cannot open load file: " + feature.Name)
+			}
+			return feature
+		}},
+		&Builtin{"commandp", 1, 2, func(vm *VM, args []Object) Object {
+			return Bool(interactiveSpec(args[0]) != nil)
+		}},
+		&Builtin{"interactive-form", 1, 1, func(vm *VM, args []Object) Object {
+			spec := interactiveSpec(args[0])
+			if spec == nil {
+				return Nil
+			}
+			return &Cons{Car: vm.Intern("interactive"), Cdr: &Cons{Car: spec, Cdr: Nil}}
+		}},
+		&Builtin{"documentation", 1, 2, func(vm *VM, args []Object) Object {
+			if fn, ok := indirectFunction(args[0]).(*Function); ok && fn.Doc != "" {
+				return fn.Doc
+			}
+			return Nil
+		}},
+		&Builtin{"error", 1, many, func(vm *VM, args []Object) Object {
+			signalError(format(toString(args[0]), args[1:]))
+			return nil
+		}},
+		&Builtin{"signal", 2, 2, func(vm *VM, args []Object) Object {
+			panic(&Signal{Sym: toSymbol(args[0]), Data: args[1]})
+		}},
+		&Builtin{"throw", 2, 2, func(vm *VM, args []Object) Object {
+			panic(&throw{tag: args[0], val: args[1]})
+		}},
+		&Builtin{"error-message-string", 1, 1, func(vm *VM, args []Object) Object {
+			cons := toCons(args[0])
+			return (&Signal{Sym: toSymbol(cons.Car), Data: cons.Cdr}).Error()
+		}},
+
+		&Builtin{"princ", 1, 2, func(vm *VM, args []Object) Object {
+			vm.output(optArg(args, 1), princToString(args[0]))
+			return args[0]
+		}},
+		&Builtin{"prin1", 1, 2, func(vm *VM, args []Object) Object {
+			vm.output(optArg(args, 1), Prin1ToString(args[0]))
+			return args[0]
+		}},
+		&Builtin{"terpri", 0, 2, func(vm *VM, args []Object) Object {
+			vm.output(optArg(args, 0), "\n")
+			return T
+		}},
+		&Builtin{"generate-new-buffer", 1, 2, func(vm *VM, args []Object) Object {
+			// Unlike Emacs, names are always made unique by a suffix.
+			vm.buffers++
+			name := toString(args[0]) + "<" + strconv.Itoa(vm.buffers) + ">"
+			return &Buffer{Name: name, Live: true}
+		}},
+		&Builtin{"set-buffer", 1, 1, func(vm *VM, args []Object) Object {
+			buf := toBuffer(args[0])
+			if !buf.Live {
+				signalError("Selecting deleted buffer")
+			}
+			vm.current = buf
+			return buf
+		}},
+		&Builtin{"bufferp", 1, 1, func(vm *VM, args []Object) Object {
+			_, ok := args[0].(*Buffer)
+			return Bool(ok)
+		}},
+		// VM has no marker, window and process objects,
+		// but "emacs/rt" type checks call these predicates.
+		&Builtin{"markerp", 1, 1, func(vm *VM, args []Object) Object {
+			return Nil
+		}},
+		&Builtin{"windowp", 1, 1, func(vm *VM, args []Object) Object {
+			return Nil
+		}},
+		&Builtin{"processp", 1, 1, func(vm *VM, args []Object) Object {
+			return Nil
+		}},
+		&Builtin{"buffer-live-p", 1, 1, func(vm *VM, args []Object) Object {
+			buf, ok := args[0].(*Buffer)
+			return Bool(ok && buf.Live)
+		}},
+		&Builtin{"current-buffer", 0, 0, func(vm *VM, args []Object) Object {
+			if vm.current == nil {
+				return Nil
+			}
+			return vm.current
+		}},
+		&Builtin{"buffer-string", 0, 0, func(vm *VM, args []Object) Object {
+			if vm.current == nil {
+				return ""
+			}
+			return vm.current.accessible()
+		}},
+		&Builtin{"insert", 0, many, func(vm *VM, args []Object) Object {
+			buf := vm.currentBuffer()
+			for _, arg := range args {
+				if ch, ok := arg.(int64); ok {
+					buf.insert(string(toChar(ch)))
+				} else {
+					buf.insert(toString(arg))
+				}
+			}
+			return Nil
+		}},
+		&Builtin{"point", 0, 0, func(vm *VM, args []Object) Object {
+			return int64(vm.currentBuffer().pt + 1)
+		}},
+		&Builtin{"point-min", 0, 0, func(vm *VM, args []Object) Object {
+			return int64(vm.currentBuffer().begv + 1)
+		}},
+		&Builtin{"point-max", 0, 0, func(vm *VM, args []Object) Object {
+			return int64(vm.currentBuffer().zv + 1)
+		}},
+		&Builtin{"goto-char", 1, 1, func(vm *VM, args []Object) Object {
+			vm.currentBuffer().gotoChar(int(toInt(args[0])) - 1)
+			return args[0]
+		}},
+		&Builtin{"narrow-to-region", 2, 2, func(vm *VM, args []Object) Object {
+			buf := vm.currentBuffer()
+			start, end := int(toInt(args[0]))-1, int(toInt(args[1]))-1
+			if start > end {
+				start, end = end, start
+			}
+			if start < 0 || end > buf.size() {
+				signal(argsOutOfRangeSym, args[0], args[1])
+			}
+			buf.narrow(start, end)
+			return Nil
+		}},
+		&Builtin{"widen", 0, 0, func(vm *VM, args []Object) Object {
+			vm.currentBuffer().widen()
+			return Nil
+		}},
+		&Builtin{"kill-buffer", 0, 1, func(vm *VM, args []Object) Object {
+			buf := vm.current
+			if !IsNil(optArg(args, 0)) {
+				buf = toBuffer(args[0])
+			}
+			if buf != nil {
+				buf.Live = false
+				if buf == vm.current {
+					vm.current = nil
+				}
+			}
+			return T
+		}},
+	)
+
+	// Customization; only variable initialization and
+	// symbol properties that are used by Customize are supported.
+	defBuiltins(
+		&Builtin{"custom-declare-variable", 3, many, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			Put(sym, vm.Intern("standard-value"), &Cons{Car: args[1], Cdr: Nil})
+			Put(sym, vm.Intern("variable-documentation"), args[2])
+			props := map[string]string{
+				":type":  "custom-type",
+				":set":   "custom-set",
+				":get":   "custom-get",
+				":group": "",
+			}
+			for i := 3; i+1 < len(args); i += 2 {
+				key := toSymbol(args[i]).Name
+				prop, ok := props[key]
+				switch {
+				case !ok:
+					signalError("Unsupported custom keyword: " + key)
+				case key == ":group":
+					group := toSymbol(args[i+1])
+					members := Get(group, vm.Intern("custom-group"))
+					member := &Cons{Car: sym, Cdr: &Cons{Car: vm.Intern("custom-variable"), Cdr: Nil}}
+					Put(group, vm.Intern("custom-group"), &Cons{Car: member, Cdr: members})
+				default:
+					Put(sym, vm.Intern(prop), args[i+1])
+				}
+			}
+			vm.customInitialize(sym, args[1])
+			return sym
+		}},
+		&Builtin{"custom-declare-group", 3, many, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			Put(sym, vm.Intern("group-documentation"), args[2])
+			return sym
+		}},
+	)
+
+	// Modes, keymaps and hooks; keymaps are alists and
+	// key descriptions are used as key sequences.
+	defBuiltins(
+		&Builtin{"make-sparse-keymap", 0, 1, func(vm *VM, args []Object) Object {
+			return List(vm.Intern("keymap"))
+		}},
+		&Builtin{"define-key", 3, 4, func(vm *VM, args []Object) Object {
+			keymap := toCons(args[0])
+			binding := &Cons{Car: args[1], Cdr: args[2]}
+			setcdr(keymap, &Cons{Car: binding, Cdr: keymap.Cdr})
+			return args[2]
+		}},
+		&Builtin{"kbd", 1, 1, func(vm *VM, args []Object) Object {
+			return args[0]
+		}},
+		// Parent keymap is a tail of child keymap,
+		// so its bindings are also looked up.
+		&Builtin{"lookup-key", 2, 3, func(vm *VM, args []Object) Object {
+			keymapSym := vm.Intern("keymap")
+			for lst := cdr(args[0]); !IsNil(lst); lst = cdr(lst) {
+				binding := car(lst)
+				if binding == Object(keymapSym) {
+					continue
+				}
+				if equal(car(binding), args[1]) {
+					return cdr(binding)
+				}
+			}
+			return Nil
+		}},
+		&Builtin{"set-keymap-parent", 2, 2, func(vm *VM, args []Object) Object {
+			setcdr(keymapTail(vm, args[0]), args[1])
+			return args[1]
+		}},
+		&Builtin{"keymap-parent", 1, 1, func(vm *VM, args []Object) Object {
+			return cdr(keymapTail(vm, args[0]))
+		}},
+		&Builtin{"use-local-map", 1, 1, func(vm *VM, args []Object) Object {
+			vm.currentBuffer().keymap = args[0]
+			return Nil
+		}},
+		&Builtin{"current-local-map", 0, 0, func(vm *VM, args []Object) Object {
+			if vm.current == nil || vm.current.keymap == nil {
+				return Nil
+			}
+			return vm.current.keymap
+		}},
+		&Builtin{"add-hook", 2, 4, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			var funcs Object = Nil
+			if sym.bound {
+				funcs = sym.Value
+			}
+			if IsNil(member(args[1], funcs)) {
+				setValue(sym, &Cons{Car: args[1], Cdr: funcs})
+			}
+			return Nil
+		}},
+		&Builtin{"run-hooks", 0, many, func(vm *VM, args []Object) Object {
+			vm.runHooks(args)
+			return Nil
+		}},
+		// Hooks are delayed while "delay-mode-hooks" is non-nil;
+		// they run before hooks of the next undelayed call.
+		&Builtin{"run-mode-hooks", 0, many, func(vm *VM, args []Object) Object {
+			delayed := vm.Intern("delayed-mode-hooks")
+			if !IsNil(vm.varRef(vm.Intern("delay-mode-hooks"))) {
+				hooks := vm.varRef(delayed)
+				for _, hook := range args {
+					hooks = &Cons{Car: hook, Cdr: hooks}
+				}
+				vm.varSet(delayed, hooks)
+				return Nil
+			}
+			hooks := toSlice(vm.varRef(delayed))
+			for i, j := 0, len(hooks)-1; i < j; i, j = i+1, j-1 {
+				hooks[i], hooks[j] = hooks[j], hooks[i]
+			}
+			vm.varSet(delayed, Nil)
+			vm.runHooks(append(hooks, args...))
+			return Nil
+		}},
+		&Builtin{"add-minor-mode", 2, many, func(vm *VM, args []Object) Object {
+			modes := vm.Intern("minor-mode-alist")
+			maps := vm.Intern("minor-mode-map-alist")
+			for _, sym := range []*Symbol{modes, maps} {
+				if !sym.bound {
+					setValue(sym, Nil)
+				}
+			}
+			setValue(modes, &Cons{Car: List(args[0], args[1]), Cdr: modes.Value})
+			if keymap := optArg(args, 2); !IsNil(keymap) {
+				setValue(maps, &Cons{Car: &Cons{Car: args[0], Cdr: keymap}, Cdr: maps.Value})
+			}
+			return Nil
+		}},
+		&Builtin{"make-local-variable", 1, 1, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			if _, ok := vm.current.localValue(sym); !ok && vm.current != nil {
+				// Void local values are not supported;
+				// unbound variable gets nil.
+				var val Object = Nil
+				if sym.bound {
+					val = sym.Value
+				}
+				vm.current.setLocalValue(sym, val)
+			}
+			return sym
+		}},
+		// Local values of variables with "permanent-local"
+		// property are kept.
+		&Builtin{"kill-all-local-variables", 0, 1, func(vm *VM, args []Object) Object {
+			buf := vm.currentBuffer()
+			vm.runHooks([]Object{vm.Intern("change-major-mode-hook")})
+			permanent := vm.Intern("permanent-local")
+			for sym := range buf.locals {
+				if IsNil(Get(sym, permanent)) {
+					delete(buf.locals, sym)
+				}
+			}
+			buf.keymap = nil
+			return Nil
+		}},
+		&Builtin{"make-variable-buffer-local", 1, 1, func(vm *VM, args []Object) Object {
+			sym := toSymbol(args[0])
+			if !sym.bound {
+				setValue(sym, Nil)
+			}
+			sym.local = true
+			return sym
+		}},
+		&Builtin{"prefix-numeric-value", 1, 1, func(vm *VM, args []Object) Object {
+			switch arg := args[0].(type) {
+			case int64:
+				return arg
+			case *Cons:
+				return car(arg)
+			}
+			if args[0] == Object(vm.Intern("-")) {
+				return int64(-1)
+			}
+			return int64(1)
+		}},
+		// Commands can only be called by Lisp code.
+		&Builtin{"called-interactively-p", 0, 1, func(vm *VM, args []Object) Object {
+			return Nil
+		}},
+	)
+
+	// Runtime helpers from "lisp/rt.el".
+	defBuiltins(
+		&Builtin{"goism--rt-map-keys", 1, 1, func(vm *VM, args []Object) Object {
+			// Emacs implementation pushes keys, so they are reversed.
+			var keys Object = Nil
+			for _, key := range toHashTable(args[0]).keys {
+				keys = &Cons{Car: key, Cdr: keys}
+			}
+			return keys
+		}},
+		&Builtin{"goism-error-message", 1, 1, func(vm *VM, args []Object) Object {
+			return vm.errorMessage(args[0])
+		}},
+		&Builtin{"goism-check-error", 1, 1, func(vm *VM, args []Object) Object {
+			if msg := vm.errorMessage(args[0]); !IsNil(msg) {
+				signalError(toString(msg))
+			}
+			return Nil
+		}},
+	)
+}
+
+// interactiveSpec returns interactive spec of command fn.
+// Symbols are resolved to their function definitions.
+// For non-command functions, nil is returned.
+func interactiveSpec(fn Object) Object {
+	if fn, ok := indirectFunction(fn).(*Function); ok {
+		return fn.Interactive
+	}
+	return nil
+}
+
+// indirectFunction follows symbol function definitions
+// until non-symbol object is reached.
+func indirectFunction(fn Object) Object {
+	for {
+		sym, ok := fn.(*Symbol)
+		if !ok || sym == Nil {
+			return fn
+		}
+		fn = sym.Func
+	}
+}
+
+// customInitialize works like "custom-initialize-reset":
+// option is set to its current value (or standard value,
+// if it is unbound) by option setter.
+func (vm *VM) customInitialize(sym *Symbol, standard Object) {
+	var val Object
+	switch getter := Get(sym, vm.Intern("custom-get")); {
+	case !sym.bound:
+		val = vm.eval(standard)
+	case getter != Nil:
+		val = vm.funcall(getter, []Object{sym})
+	default:
+		val = sym.Value
+	}
+	if setter := Get(sym, vm.Intern("custom-set")); setter != Nil {
+		vm.funcall(setter, []Object{sym, val})
+	} else {
+		setValue(sym, val)
+	}
+}
+
+// errorMessage returns message of Go error interface value.
+// For nil error, nil is returned.
+func (vm *VM) errorMessage(err Object) Object {
+	if err == Object(vm.Intern("goism-rt.NilInterface")) {
+		return Nil
+	}
+	// The only method of "error" interface is "Error".
+	itab := car(err)
+	return vm.funcall(aref(itab, int64(1)), []Object{cdr(err)})
+}
+
+// currentBuffer returns current buffer;
+// it is an error if there is none.
+func (vm *VM) currentBuffer() *Buffer {
+	if vm.current == nil {
+		signalError("No current buffer")
+	}
+	return vm.current
+}
+
+// output writes text to printcharfun stream.
+func (vm *VM) output(stream Object, text string) {
+	switch stream := stream.(type) {
+	case *Buffer:
+		if !stream.Live {
+			signalError("Selecting deleted buffer")
+		}
+		stream.insert(text)
+		return
+	case *Symbol:
+		if stream == Nil || stream == T {
+			io.WriteString(vm.Stdout, text)
+			return
+		}
+	}
+	// Function stream receives one character at time.
+	for _, ch := range text {
+		vm.funcall(stream, []Object{int64(ch)})
+	}
+}
+
+// keymapTail returns the last cons of keymap own bindings;
+// its cdr is a parent keymap.
+func keymapTail(vm *VM, keymap Object) Object {
+	keymapSym := vm.Intern("keymap")
+	tail := Object(toCons(keymap))
+	for lst := cdr(tail); !IsNil(lst) && car(lst) != Object(keymapSym); lst = cdr(lst) {
+		tail = lst
+	}
+	return tail
+}
+
+// runHooks calls functions of every bound hook variable.
+func (vm *VM) runHooks(hooks []Object) {
+	for _, hook := range hooks {
+		sym := toSymbol(hook)
+		if !vm.varBound(sym) {
+			continue
+		}
+		for lst := vm.varRef(sym); !IsNil(lst); lst = cdr(lst) {
+			vm.funcall(car(lst), nil)
+		}
+	}
+}
+
+// defModeVars defines variables that are automatically
+// buffer-local in Emacs and describe the major mode.
+// featurep reports whether feature is a member of "features" list.
+func (vm *VM) featurep(feature *Symbol) bool {
+	for _, x := range toSlice(symbolValue(vm.Intern("features"))) {
+		if x == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (vm *VM) defModeVars() {
+	vars := []struct {
+		name string
+		val  Object
+	}{
+		{"major-mode", vm.Intern("fundamental-mode")},
+		{"mode-name", "Fundamental"},
+		{"delay-mode-hooks", Nil},
+		{"delayed-mode-hooks", Nil},
+	}
+	for _, v := range vars {
+		sym := vm.Intern(v.name)
+		setValue(sym, v.val)
+		sym.local = true
+	}
+	for _, name := range []string{"delay-mode-hooks", "delayed-mode-hooks"} {
+		Put(vm.Intern(name), vm.Intern("permanent-local"), T)
+	}
+}
@@ -0,0 +1,285 @@
+package vm
+
+import (
+	"math"
+)
+
+var (
+	posInf = math.Inf(1)
+	negInf = math.Inf(-1)
+	nan    = math.NaN()
+)
+
+// Emacs "most-positive-fixnum" on 64-bit platforms.
+const mostPositiveFixnum = 1<<61 - 1
+
+// Arithmetic.
+func init() {
+	defBuiltins(
+		&Builtin{"+", 0, many, func(vm *VM, args []Object) Object {
+			return foldArith(opAdd, int64(0), args)
+		}},
+		&Builtin{"*", 0, many, func(vm *VM, args []Object) Object {
+			return foldArith(opMul, int64(1), args)
+		}},
+		&Builtin{"-", 0, many, func(vm *VM, args []Object) Object {
+			switch len(args) {
+			case 0:
+				return int64(0)
+			case 1:
+				return arith(opSub, int64(0), args[0])
+			}
+			return foldArith(opSub, args[0], args[1:])
+		}},
+		&Builtin{"/", 1, many, func(vm *VM, args []Object) Object {
+			if len(args) == 1 {
+				return arith(opQuo, int64(1), args[0])
+			}
+			// If any argument is float, all operations are float.
+			acc := args[0]
+			for _, arg := range args {
+				if _, ok := arg.(float64); ok {
+					acc = toFloat(acc)
+				}
+			}
+			return foldArith(opQuo, acc, args[1:])
+		}},
+		&Builtin{"%", 2, 2, func(vm *VM, args []Object) Object {
+			x, y := toInt(args[0]), toInt(args[1])
+			if y == 0 {
+				signal(arithErrorSym)
+			}
+			return x % y
+		}},
+		&Builtin{"1+", 1, 1, func(vm *VM, args []Object) Object {
+			return arith(opAdd, args[0], int64(1))
+		}},
+		&Builtin{"1-", 1, 1, func(vm *VM, args []Object) Object {
+			return arith(opSub, args[0], int64(1))
+		}},
+		&Builtin{"abs", 1, 1, func(vm *VM, args []Object) Object {
+			if numCompare(args[0], int64(0)) < 0 {
+				return arith(opSub, int64(0), args[0])
+			}
+			return args[0]
+		}},
+		&Builtin{"float", 1, 1, func(vm *VM, args []Object) Object {
+			return toFloat(args[0])
+		}},
+		&Builtin{"truncate", 1, 1, func(vm *VM, args []Object) Object {
+			if x, ok := args[0].(float64); ok {
+				return int64(x)
+			}
+			return toInt(args[0])
+		}},
+		&Builtin{"log", 1, 2, func(vm *VM, args []Object) Object {
+			x := toFloat(args[0]).(float64)
+			if IsNil(optArg(args, 1)) {
+				return math.Log(x)
+			}
+			return math.Log(x) / math.Log(toFloat(args[1]).(float64))
+		}},
+		&Builtin{"min", 1, many, func(vm *VM, args []Object) Object {
+			res := args[0]
+			for _, arg := range args[1:] {
+				if numCompare(arg, res) < 0 {
+					res = arg
+				}
+			}
+			return res
+		}},
+		&Builtin{"max", 1, many, func(vm *VM, args []Object) Object {
+			res := args[0]
+			for _, arg := range args[1:] {
+				if numCompare(arg, res) > 0 {
+					res = arg
+				}
+			}
+			return res
+		}},
+		&Builtin{"logand", 0, many, func(vm *VM, args []Object) Object {
+			res := int64(-1)
+			for _, arg := range args {
+				res &= toInt(arg)
+			}
+			return res
+		}},
+		&Builtin{"logior", 0, many, func(vm *VM, args []Object) Object {
+			res := int64(0)
+			for _, arg := range args {
+				res |= toInt(arg)
+			}
+			return res
+		}},
+		&Builtin{"logxor", 0, many, func(vm *VM, args []Object) Object {
+			res := int64(0)
+			for _, arg := range args {
+				res ^= toInt(arg)
+			}
+			return res
+		}},
+		&Builtin{"ash", 2, 2, func(vm *VM, args []Object) Object {
+			return ash(toInt(args[0]), toInt(args[1]))
+		}},
+		&Builtin{"lsh", 2, 2, func(vm *VM, args []Object) Object {
+			// Emacs 28 "lsh" treats negative value as unsigned
+			// fixnum when it is shifted right.
+			value, count := toInt(args[0]), toInt(args[1])
+			if value < 0 && count < 0 {
+				value = (value >> 1) & mostPositiveFixnum
+				count++
+			}
+			return ash(value, count)
+		}},
+	)
+
+	cmp := func(name string, op int) *Builtin {
+		return &Builtin{name, 1, many, func(vm *VM, args []Object) Object {
+			for i := 1; i < len(args); i++ {
+				if !numCompareOp(op, args[i-1], args[i]) {
+					return Nil
+				}
+			}
+			if len(args) == 1 {
+				toNumber(args[0])
+			}
+			return T
+		}}
+	}
+	defBuiltins(
+		cmp("=", opNumEq),
+		cmp("<", opNumLt),
+		cmp(">", opNumGt),
+		cmp("<=", opNumLte),
+		cmp(">=", opNumGte),
+	)
+}
+
+func toNumber(x Object) Object {
+	switch x.(type) {
+	case int64, float64:
+		return x
+	}
+	wrongType("number-or-marker-p", x)
+	return nil
+}
+
+func toFloat(x Object) Object {
+	switch x := toNumber(x).(type) {
+	case int64:
+		return float64(x)
+	default:
+		return x
+	}
+}
+
+func foldArith(op int, acc Object, args []Object) Object {
+	toNumber(acc)
+	for _, arg := range args {
+		acc = arith(op, acc, arg)
+	}
+	return acc
+}
+
+// arith applies binary arithmetic opcode to numbers.
+// Result is float if any of operands is float.
+func arith(op int, x, y Object) Object {
+	x, y = toNumber(x), toNumber(y)
+	a, aInt := x.(int64)
+	b, bInt := y.(int64)
+	if aInt && bInt {
+		switch op {
+		case opAdd:
+			return a + b
+		case opSub:
+			return a - b
+		case opMul:
+			return a * b
+		case opQuo:
+			if b == 0 {
+				signal(arithErrorSym)
+			}
+			return a / b
+		}
+	}
+
+	f, g := toFloat(x).(float64), toFloat(y).(float64)
+	switch op {
+	case opAdd:
+		return f + g
+	case opSub:
+		return f - g
+	case opMul:
+		return f * g
+	case opQuo:
+		return f / g
+	}
+	panic("unexpected arith op")
+}
+
+// numCompare returns -1, 0 or 1.
+// NaN compares as greater than anything.
+func numCompare(x, y Object) int {
+	x, y = toNumber(x), toNumber(y)
+	a, aInt := x.(int64)
+	b, bInt := y.(int64)
+	if aInt && bInt {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	f, g := toFloat(x).(float64), toFloat(y).(float64)
+	switch {
+	case f < g:
+		return -1
+	case f == g:
+		return 0
+	}
+	return 1
+}
+
+func numCompareOp(op int, x, y Object) bool {
+	if isNaN(x) || isNaN(y) {
+		toNumber(x)
+		toNumber(y)
+		return false
+	}
+	c := numCompare(x, y)
+	switch op {
+	case opNumEq:
+		return c == 0
+	case opNumLt:
+		return c < 0
+	case opNumGt:
+		return c > 0
+	case opNumLte:
+		return c <= 0
+	case opNumGte:
+		return c >= 0
+	}
+	panic("unexpected compare op")
+}
+
+func isNaN(x Object) bool {
+	f, ok := x.(float64)
+	return ok && math.IsNaN(f)
+}
+
+func ash(value, count int64) int64 {
+	switch {
+	case count >= 64:
+		return 0
+	case count >= 0:
+		return value << uint(count)
+	case count <= -64:
+		if value < 0 {
+			return -1
+		}
+		return 0
+	}
+	return value >> uint(-count)
+}
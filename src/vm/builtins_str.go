@@ -0,0 +1,378 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Strings and characters.
+func init() {
+	defBuiltins(
+		&Builtin{"concat", 0, many, func(vm *VM, args []Object) Object {
+			return concat(args)
+		}},
+		&Builtin{"substring", 1, 3, func(vm *VM, args []Object) Object {
+			return substring(args[0], optArg(args, 1), optArg(args, 2))
+		}},
+		&Builtin{"string", 0, many, func(vm *VM, args []Object) Object {
+			return charsToString(args)
+		}},
+		&Builtin{"unibyte-string", 0, many, func(vm *VM, args []Object) Object {
+			res := make([]byte, len(args))
+			for i, arg := range args {
+				n := toInt(arg)
+				if n < 0 || n > 0xff {
+					signal(argsOutOfRangeSym, arg, int64(0), int64(0xff))
+				}
+				res[i] = byte(n)
+			}
+			return Unibyte(res)
+		}},
+		&Builtin{"make-string", 2, 3, func(vm *VM, args []Object) Object {
+			n := toInt(args[0])
+			if n < 0 {
+				wrongType("wholenump", args[0])
+			}
+			return strings.Repeat(string(toChar(args[1])), int(n))
+		}},
+		&Builtin{"string-to-list", 1, 1, func(vm *VM, args []Object) Object {
+			return List(toSlice(toStringObject(args[0]))...)
+		}},
+		&Builtin{"string-bytes", 1, 1, func(vm *VM, args []Object) Object {
+			return int64(len(toString(args[0])))
+		}},
+		&Builtin{"multibyte-string-p", 1, 1, func(vm *VM, args []Object) Object {
+			s, ok := args[0].(string)
+			return Bool(ok && !isASCII(s))
+		}},
+		&Builtin{"string=", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(stringDesignator(args[0]) == stringDesignator(args[1]))
+		}},
+		&Builtin{"string-equal", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(stringDesignator(args[0]) == stringDesignator(args[1]))
+		}},
+		&Builtin{"string<", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(stringLess(args[0], args[1]))
+		}},
+		&Builtin{"string-lessp", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(stringLess(args[0], args[1]))
+		}},
+		&Builtin{"string>", 2, 2, func(vm *VM, args []Object) Object {
+			return Bool(stringLess(args[1], args[0]))
+		}},
+		&Builtin{"upcase", 1, 1, func(vm *VM, args []Object) Object {
+			return mapCase(args[0], unicode.ToUpper, strings.ToUpper)
+		}},
+		&Builtin{"downcase", 1, 1, func(vm *VM, args []Object) Object {
+			return mapCase(args[0], unicode.ToLower, strings.ToLower)
+		}},
+
+		&Builtin{"string-search", 2, 3, func(vm *VM, args []Object) Object {
+			needle, haystack := toString(args[0]), toString(args[1])
+			// Unibyte string characters are bytes.
+			_, unibyte := args[1].(Unibyte)
+			start := 0
+			if !IsNil(optArg(args, 2)) {
+				start = checkIndex(args[1], args[2], length(args[1])+1)
+				if !unibyte {
+					start = byteOffset(haystack, start)
+				}
+			}
+			i := strings.Index(haystack[start:], needle)
+			switch {
+			case i == -1:
+				return Nil
+			case unibyte:
+				return int64(start + i)
+			}
+			return int64(charIndex(haystack, start+i))
+		}},
+		&Builtin{"string-prefix-p", 2, 3, func(vm *VM, args []Object) Object {
+			prefix, s := toString(args[0]), toString(args[1])
+			if !IsNil(optArg(args, 2)) {
+				prefix, s = strings.ToLower(prefix), strings.ToLower(s)
+			}
+			return Bool(strings.HasPrefix(s, prefix))
+		}},
+		&Builtin{"string-suffix-p", 2, 3, func(vm *VM, args []Object) Object {
+			suffix, s := toString(args[0]), toString(args[1])
+			if !IsNil(optArg(args, 2)) {
+				suffix, s = strings.ToLower(suffix), strings.ToLower(s)
+			}
+			return Bool(strings.HasSuffix(s, suffix))
+		}},
+		&Builtin{"string-replace", 3, 3, func(vm *VM, args []Object) Object {
+			from, to, s := toString(args[0]), toString(args[1]), toString(args[2])
+			if from == "" {
+				signal(wrongLengthArgumentSym, args[0])
+			}
+			return strings.Replace(s, from, to, -1)
+		}},
+
+		&Builtin{"number-to-string", 1, 1, func(vm *VM, args []Object) Object {
+			return numberToString(toNumber(args[0]))
+		}},
+		&Builtin{"string-to-number", 1, 2, func(vm *VM, args []Object) Object {
+			base := int64(10)
+			if !IsNil(optArg(args, 1)) {
+				base = toInt(args[1])
+			}
+			return stringToNumber(toString(args[0]), int(base))
+		}},
+		&Builtin{"format", 1, many, func(vm *VM, args []Object) Object {
+			return format(toString(args[0]), args[1:])
+		}},
+		&Builtin{"format-message", 1, many, func(vm *VM, args []Object) Object {
+			return format(toString(args[0]), args[1:])
+		}},
+		&Builtin{"prin1-to-string", 1, 2, func(vm *VM, args []Object) Object {
+			if !IsNil(optArg(args, 1)) {
+				return princToString(args[0])
+			}
+			return Prin1ToString(args[0])
+		}},
+		&Builtin{"mapconcat", 2, 3, func(vm *VM, args []Object) Object {
+			elems := toSlice(args[1])
+			parts := make([]Object, 0, len(elems)*2)
+			for i, elem := range elems {
+				if i != 0 && len(args) > 2 {
+					parts = append(parts, args[2])
+				}
+				parts = append(parts, vm.funcall(args[0], []Object{elem}))
+			}
+			return concat(parts)
+		}},
+		&Builtin{"encode-coding-string", 2, 4, func(vm *VM, args []Object) Object {
+			// Go strings are UTF-8 already; other coding
+			// systems are never used by translated code.
+			if name := toSymbol(args[1]).Name; name != "utf-8" && name != "utf-8-unix" {
+				signal(unsupportedOpcodeSym, args[1])
+			}
+			return Unibyte(toString(args[0]))
+		}},
+		&Builtin{"decode-coding-string", 2, 4, func(vm *VM, args []Object) Object {
+			if name := toSymbol(args[1]).Name; name != "utf-8" && name != "utf-8-unix" {
+				signal(unsupportedOpcodeSym, args[1])
+			}
+			return strings.ToValidUTF8(toString(args[0]), "�")
+		}},
+	)
+}
+
+// toStringObject checks that x is a string.
+func toStringObject(x Object) Object {
+	toString(x)
+	return x
+}
+
+// toChar returns character code as rune.
+func toChar(x Object) rune {
+	ch := toInt(x)
+	if ch < 0 || ch > 0x3FFFFF {
+		wrongType("characterp", x)
+	}
+	return rune(ch)
+}
+
+func stringLess(x, y Object) bool {
+	// UTF-8 byte order is the same as code point order.
+	return stringDesignator(x) < stringDesignator(y)
+}
+
+// concat joins strings, lists and vectors of characters.
+// Result is unibyte only if all arguments are unibyte strings.
+func concat(args []Object) Object {
+	var buf strings.Builder
+	unibyte := len(args) != 0
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			unibyte = unibyte && isASCII(arg)
+			buf.WriteString(arg)
+		case Unibyte:
+			buf.WriteString(string(arg))
+		default:
+			unibyte = false
+			for _, ch := range toSlice(arg) {
+				buf.WriteRune(toChar(ch))
+			}
+		}
+	}
+	if unibyte {
+		for _, arg := range args {
+			if _, ok := arg.(Unibyte); ok {
+				return Unibyte(buf.String())
+			}
+		}
+	}
+	return buf.String()
+}
+
+func charsToString(chars []Object) string {
+	var buf strings.Builder
+	for _, ch := range chars {
+		buf.WriteRune(toChar(ch))
+	}
+	return buf.String()
+}
+
+func mapCase(x Object, mapChar func(rune) rune, mapString func(string) string) Object {
+	switch x := x.(type) {
+	case int64:
+		return int64(mapChar(toChar(x)))
+	case string:
+		return mapString(x)
+	case Unibyte:
+		return Unibyte(mapString(string(x)))
+	}
+	wrongType("char-or-string-p", x)
+	return nil
+}
+
+// byteOffset converts character index into byte offset.
+func byteOffset(s string, charIndex int) int {
+	if isASCII(s) {
+		return charIndex
+	}
+	for offset := range s {
+		if charIndex == 0 {
+			return offset
+		}
+		charIndex--
+	}
+	return len(s)
+}
+
+// charIndex converts byte offset into character index.
+func charIndex(s string, offset int) int {
+	return utf8.RuneCountInString(s[:offset])
+}
+
+func numberToString(x Object) string {
+	if n, ok := x.(int64); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	return formatFloat(x.(float64))
+}
+
+// stringToNumber parses the longest number prefix of s,
+// ignoring leading spaces. Returns 0 if s has no such prefix.
+func stringToNumber(s string, base int) Object {
+	s = strings.TrimLeft(s, " \t\n\r\f\v")
+	sign := int64(1)
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	digits := func(i int, base int) int {
+		for i < len(s) && digitValue(s[i]) < base {
+			i++
+		}
+		return i
+	}
+
+	if base != 10 {
+		end := digits(0, base)
+		n, _ := strconv.ParseInt(s[:end], base, 64)
+		return sign * n
+	}
+
+	intEnd := digits(0, 10)
+	end := intEnd
+	isFloat := false
+	if end < len(s) && s[end] == '.' {
+		if fracEnd := digits(end+1, 10); fracEnd > end+1 {
+			end, isFloat = fracEnd, true
+		} else {
+			end++ // "1." is integer
+		}
+	}
+	if end > 0 && (intEnd > 0 || isFloat) && end < len(s) && (s[end] == 'e' || s[end] == 'E') {
+		expStart := end + 1
+		if expStart < len(s) && (s[expStart] == '-' || s[expStart] == '+') {
+			expStart++
+		}
+		if expEnd := digits(expStart, 10); expEnd > expStart {
+			end, isFloat = expEnd, true
+		}
+	}
+	if isFloat {
+		x, _ := strconv.ParseFloat(s[:end], 64)
+		return float64(sign) * x
+	}
+	if intEnd == 0 {
+		return int64(0)
+	}
+	n, _ := strconv.ParseInt(s[:intEnd], 10, 64)
+	return sign * n
+}
+
+// format implements Emacs "format" function.
+func format(spec string, args []Object) string {
+	var buf bytes.Buffer
+	argIndex := 0
+	nextArg := func() Object {
+		if argIndex >= len(args) {
+			signalError("Not enough arguments for format string")
+		}
+		argIndex++
+		return args[argIndex-1]
+	}
+
+	for i := 0; i < len(spec); i++ {
+		if spec[i] != '%' {
+			buf.WriteByte(spec[i])
+			continue
+		}
+		// Parse "%[flags][width][.precision]verb".
+		start := i + 1
+		for i++; i < len(spec) && strings.IndexByte("-+ #0", spec[i]) != -1; i++ {
+		}
+		flags := spec[start:i]
+		for i < len(spec) && (spec[i] >= '0' && spec[i] <= '9' || spec[i] == '.') {
+			i++
+		}
+		if i == len(spec) {
+			signalError("Format string ends in middle of format specifier")
+		}
+		widthPrec, verb := spec[start+len(flags):i], spec[i]
+
+		switch verb {
+		case '%':
+			buf.WriteByte('%')
+		case 's', 'S':
+			var s string
+			if verb == 's' {
+				s = princToString(nextArg())
+			} else {
+				s = Prin1ToString(nextArg())
+			}
+			// Strings are always padded with spaces.
+			flags = strings.Replace(flags, "0", "", -1)
+			fmt.Fprintf(&buf, "%"+flags+widthPrec+"s", s)
+		case 'd', 'o', 'x', 'X':
+			n := nextArg()
+			if f, ok := n.(float64); ok {
+				n = int64(f)
+			}
+			fmt.Fprintf(&buf, "%"+flags+widthPrec+string(verb), toInt(n))
+		case 'c':
+			fmt.Fprintf(&buf, "%"+flags+widthPrec+"c", toChar(nextArg()))
+		case 'e', 'f', 'g':
+			if !strings.Contains(widthPrec, ".") {
+				widthPrec += ".6" // C default; Go %g uses shortest repr
+			}
+			x := toFloat(nextArg()).(float64)
+			fmt.Fprintf(&buf, "%"+flags+widthPrec+string(verb), x)
+		default:
+			signalError("Invalid format operation %" + string(verb))
+		}
+	}
+	return buf.String()
+}
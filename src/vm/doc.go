@@ -0,0 +1,24 @@
+// Package vm implements Emacs Lisp bytecode interpreter.
+//
+// Only a subset of Emacs VM is supported: instructions that
+// are emitted by lapc, and builtin functions that are called by
+// goism runtime and translated standard packages.
+// It makes it possible to run translated code without Emacs.
+//
+// Lisp objects are represented by Go values:
+//
+//	integer     int64
+//	float       float64
+//	string      string (multibyte) or Unibyte
+//	symbol      *Symbol
+//	cons        *Cons
+//	vector      *Vector
+//	hash table  *HashTable
+//	function    *Function, *Builtin or *Partial
+//	buffer      *Buffer
+//
+// Strings have no identity: eq compares their contents.
+//
+// Lisp errors are Go panics with *Signal value;
+// exported VM methods recover them and return as errors.
+package vm
@@ -0,0 +1,116 @@
+package vm
+
+// eval is a minimal Lisp interpreter.
+// It is used to run test expressions; translated code
+// never needs it, because it is compiled into bytecode.
+//
+// All bindings are dynamic; lambda forms are not supported.
+func (vm *VM) eval(form Object) Object {
+	switch form := form.(type) {
+	case *Symbol:
+		return vm.varRef(form)
+	case *Cons:
+		return vm.evalList(form)
+	default:
+		return form // Self-evaluating
+	}
+}
+
+func (vm *VM) evalList(form *Cons) Object {
+	args := listToSlice(form.Cdr)
+	if sym, ok := form.Car.(*Symbol); ok {
+		switch sym.Name {
+		case "quote", "function":
+			return args[0]
+		case "progn":
+			return vm.evalBody(args)
+		case "setq":
+			var res Object = Nil
+			for i := 0; i+1 < len(args); i += 2 {
+				res = vm.eval(args[i+1])
+				vm.varSet(args[i].(*Symbol), res)
+			}
+			return res
+		case "if":
+			if !IsNil(vm.eval(args[0])) {
+				return vm.eval(args[1])
+			}
+			return vm.evalBody(args[2:])
+		case "when":
+			if !IsNil(vm.eval(args[0])) {
+				return vm.evalBody(args[1:])
+			}
+			return Nil
+		case "while":
+			for !IsNil(vm.eval(args[0])) {
+				vm.evalBody(args[1:])
+			}
+			return Nil
+		case "and":
+			var res Object = T
+			for _, arg := range args {
+				if res = vm.eval(arg); IsNil(res) {
+					break
+				}
+			}
+			return res
+		case "or":
+			var res Object = Nil
+			for _, arg := range args {
+				if res = vm.eval(arg); !IsNil(res) {
+					break
+				}
+			}
+			return res
+		case "defvar":
+			sym := args[0].(*Symbol)
+			if len(args) > 1 && !vm.varBound(sym) {
+				setValue(sym, vm.eval(args[1]))
+			}
+			return sym
+		case "let", "let*":
+			return vm.evalLet(sym.Name == "let*", listToSlice(args[0]), args[1:])
+		}
+	}
+
+	for i, arg := range args {
+		args[i] = vm.eval(arg)
+	}
+	return vm.funcall(form.Car, args)
+}
+
+func (vm *VM) evalBody(body []Object) Object {
+	var res Object = Nil
+	for _, form := range body {
+		res = vm.eval(form)
+	}
+	return res
+}
+
+// evalLet binds variables dynamically; old values
+// are restored even if body exits non-locally.
+func (vm *VM) evalLet(sequential bool, bindings []Object, body []Object) Object {
+	defer vm.unbindTo(len(vm.specpdl))
+	syms := make([]*Symbol, len(bindings))
+	vals := make([]Object, len(bindings))
+	for i, binding := range bindings {
+		var init Object = Nil
+		switch binding := binding.(type) {
+		case *Symbol:
+			syms[i] = binding
+		case *Cons:
+			syms[i] = binding.Car.(*Symbol)
+			init = car(binding.Cdr)
+		}
+		vals[i] = vm.eval(init)
+		if sequential {
+			vm.specbind(syms[i], vals[i])
+		}
+	}
+	if !sequential {
+		for i, sym := range syms {
+			vm.specbind(sym, vals[i])
+		}
+	}
+	return vm.evalBody(body)
+}
@@ -0,0 +1,340 @@
+package vm
+
+// Emacs VM opcodes; see "bytecode.c" in Emacs sources.
+const (
+	opStackRef = 0
+	opVarRef   = 8
+	opVarSet   = 16
+	opVarBind  = 24
+	opCall     = 32
+	opUnbind   = 40
+
+	opSymbolp         = 57
+	opStringp         = 59
+	opEq              = 61
+	opMemq            = 62
+	opNot             = 63
+	opCar             = 64
+	opCdr             = 65
+	opCons            = 66
+	opList1           = 67
+	opList4           = 70
+	opLength          = 71
+	opAref            = 72
+	opAset            = 73
+	opSubstr          = 79
+	opConcat2         = 80
+	opConcat4         = 82
+	opSub1            = 83
+	opAdd1            = 84
+	opNumEq           = 85
+	opNumGt           = 86
+	opNumLt           = 87
+	opNumLte          = 88
+	opNumGte          = 89
+	opSub             = 90
+	opNeg             = 91
+	opAdd             = 92
+	opMin             = 94
+	opMul             = 95
+	opSaveCurBuffer   = 114
+	opConst2          = 129
+	opGoto            = 130
+	opGotoNil         = 131
+	opGotoNNil        = 132
+	opGotoNilElsePop  = 133
+	opGotoNNilElsePop = 134
+	opReturn          = 135
+	opDiscard         = 136
+	opDup             = 137
+	opSaveExcursion   = 138
+	opSaveRestriction = 140
+	opUnwindProtect   = 142
+	opStrEq           = 152
+	opStrLt           = 153
+	opEqual           = 154
+	opMember          = 157
+	opSetCar          = 160
+	opSetCdr          = 161
+	opQuo             = 165
+	opIntegerp        = 168
+	opListN           = 175
+	opConcatN         = 176
+	opStackSet        = 178
+	opStackSet2       = 179
+	opDiscardN        = 182
+	opSwitch          = 183
+	opConstant        = 192
+)
+
+// exec runs byte-code function.
+func (vm *VM) exec(fn *Function, args []Object) Object {
+	stack := make([]Object, 0, fn.MaxDepth+len(args)+1)
+	stack = pushArgs(stack, fn, args)
+	// Bindings are undone even if function exits non-locally.
+	defer vm.unbindTo(len(vm.specpdl))
+	code := fn.Code
+	consts := fn.Consts
+	pc := 0
+
+	fetch := func() int {
+		pc++
+		return int(code[pc-1])
+	}
+	fetch2 := func() int {
+		pc += 2
+		return int(code[pc-2]) | int(code[pc-1])<<8
+	}
+	pop := func() Object {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return x
+	}
+	// Binary operation: replaces 2 top elements with result.
+	binop := func(op func(x, y Object) Object) {
+		y := pop()
+		stack[len(stack)-1] = op(stack[len(stack)-1], y)
+	}
+	// Unary operation: replaces top element with result.
+	unop := func(op func(x Object) Object) {
+		stack[len(stack)-1] = op(stack[len(stack)-1])
+	}
+
+	for {
+		if pc >= len(code) {
+			signal(invalidByteCodeSym, "pc out of range")
+		}
+		// Emacs does not check stack bounds at run time,
+		// so wrong max depth would corrupt its memory.
+		if len(stack) > fn.MaxDepth {
+			signal(invalidByteCodeSym, "stack overflow")
+		}
+		op := fetch()
+
+		switch {
+		case op >= opConstant:
+			stack = append(stack, consts[op-opConstant])
+			continue
+		case op < opVarRef:
+			n := operand(op-opStackRef, fetch, fetch2)
+			stack = append(stack, stack[len(stack)-1-n])
+			continue
+		case op < opVarSet:
+			sym := consts[operand(op-opVarRef, fetch, fetch2)].(*Symbol)
+			stack = append(stack, vm.varRef(sym))
+			continue
+		case op < opVarSet+8:
+			sym := consts[operand(op-opVarSet, fetch, fetch2)].(*Symbol)
+			vm.varSet(sym, pop())
+			continue
+		case op < opVarBind+8:
+			sym := consts[operand(op-opVarBind, fetch, fetch2)].(*Symbol)
+			vm.specbind(sym, pop())
+			continue
+		case op >= opCall && op < opCall+8:
+			n := operand(op-opCall, fetch, fetch2)
+			base := len(stack) - n - 1
+			callArgs := make([]Object, n)
+			copy(callArgs, stack[base+1:])
+			res := vm.funcall(stack[base], callArgs)
+			stack = append(stack[:base], res)
+			continue
+		case op >= opUnbind && op < opUnbind+8:
+			vm.unbind(operand(op-opUnbind, fetch, fetch2))
+			continue
+		case op >= opList1 && op <= opList4:
+			stack = listN(stack, op-opList1+1)
+			continue
+		case op >= opConcat2 && op <= opConcat4:
+			stack = concatN(stack, op-opConcat2+2)
+			continue
+		}
+
+		switch op {
+		case opConst2:
+			stack = append(stack, consts[fetch2()])
+
+		case opGoto:
+			pc = fetch2()
+		case opGotoNil:
+			target := fetch2()
+			if IsNil(pop()) {
+				pc = target
+			}
+		case opGotoNNil:
+			target := fetch2()
+			if !IsNil(pop()) {
+				pc = target
+			}
+		case opGotoNilElsePop:
+			target := fetch2()
+			if IsNil(stack[len(stack)-1]) {
+				pc = target
+			} else {
+				pop()
+			}
+		case opGotoNNilElsePop:
+			target := fetch2()
+			if !IsNil(stack[len(stack)-1]) {
+				pc = target
+			} else {
+				pop()
+			}
+
+		case opSwitch:
+			table := toHashTable(pop())
+			if target := table.get(pop(), nil); target != nil {
+				pc = int(toInt(target))
+			}
+
+		case opReturn:
+			return stack[len(stack)-1]
+		case opDiscard:
+			pop()
+		case opDup:
+			stack = append(stack, stack[len(stack)-1])
+		case opDiscardN:
+			n := fetch()
+			if n&0x80 != 0 {
+				n &= 0x7f
+				stack[len(stack)-1-n] = stack[len(stack)-1]
+			}
+			stack = stack[:len(stack)-n]
+		case opStackSet:
+			n := fetch()
+			stack[len(stack)-1-n] = stack[len(stack)-1]
+			pop()
+		case opStackSet2:
+			n := fetch2()
+			stack[len(stack)-1-n] = stack[len(stack)-1]
+			pop()
+		case opSaveCurBuffer:
+			vm.saveCurrentBuffer()
+		case opSaveExcursion:
+			vm.saveExcursion()
+		case opSaveRestriction:
+			vm.saveRestriction()
+		case opUnwindProtect:
+			vm.unwindProtect(pop())
+
+		case opListN:
+			stack = listN(stack, fetch())
+		case opConcatN:
+			stack = concatN(stack, fetch())
+
+		case opSymbolp:
+			unop(func(x Object) Object { _, ok := x.(*Symbol); return Bool(ok) })
+		case opStringp:
+			unop(func(x Object) Object { return Bool(isString(x)) })
+		case opIntegerp:
+			unop(func(x Object) Object { _, ok := x.(int64); return Bool(ok) })
+		case opNot:
+			unop(func(x Object) Object { return Bool(IsNil(x)) })
+		case opEq:
+			binop(func(x, y Object) Object { return Bool(eq(x, y)) })
+		case opEqual:
+			binop(func(x, y Object) Object { return Bool(equal(x, y)) })
+		case opMemq:
+			binop(memq)
+		case opMember:
+			binop(member)
+
+		case opCar:
+			unop(car)
+		case opCdr:
+			unop(cdr)
+		case opCons:
+			binop(func(x, y Object) Object { return &Cons{Car: x, Cdr: y} })
+		case opSetCar:
+			binop(setcar)
+		case opSetCdr:
+			binop(setcdr)
+		case opLength:
+			unop(func(x Object) Object { return int64(length(x)) })
+		case opAref:
+			binop(aref)
+		case opAset:
+			val := pop()
+			binop(func(arr, idx Object) Object { return aset(arr, idx, val) })
+		case opSubstr:
+			to := pop()
+			binop(func(s, from Object) Object { return substring(s, from, to) })
+
+		case opAdd1:
+			unop(func(x Object) Object { return arith(opAdd, x, int64(1)) })
+		case opSub1:
+			unop(func(x Object) Object { return arith(opSub, x, int64(1)) })
+		case opNeg:
+			unop(func(x Object) Object { return arith(opSub, int64(0), x) })
+		case opAdd, opSub, opMul, opQuo:
+			binop(func(x, y Object) Object { return arith(op, x, y) })
+		case opMin:
+			binop(func(x, y Object) Object {
+				if numCompare(y, x) < 0 {
+					return y
+				}
+				return x
+			})
+		case opNumEq, opNumGt, opNumLt, opNumLte, opNumGte:
+			binop(func(x, y Object) Object { return Bool(numCompareOp(op, x, y)) })
+		case opStrEq:
+			binop(func(x, y Object) Object { return Bool(stringDesignator(x) == stringDesignator(y)) })
+		case opStrLt:
+			binop(func(x, y Object) Object { return Bool(stringLess(x, y)) })
+
+		default:
+			signal(unsupportedOpcodeSym, int64(op))
+		}
+	}
+}
+
+// operand decodes operand of stack-ref, varref, varset,
+// varbind, call and unbind.
+func operand(n int, fetch, fetch2 func() int) int {
+	switch n {
+	case 6:
+		return fetch()
+	case 7:
+		return fetch2()
+	default:
+		return n
+	}
+}
+
+// pushArgs binds function arguments according to its descriptor.
+func pushArgs(stack []Object, fn *Function, args []Object) []Object {
+	mandatory := fn.ArgDesc & 127
+	rest := fn.ArgDesc&128 != 0
+	nonrest := fn.ArgDesc >> 8
+	if len(args) < mandatory || (!rest && len(args) > nonrest) {
+		signal(wrongNumberOfArgsSym, List(int64(mandatory), int64(nonrest)), int64(len(args)))
+	}
+
+	for i := 0; i < nonrest; i++ {
+		if i < len(args) {
+			stack = append(stack, args[i])
+		} else {
+			stack = append(stack, Nil)
+		}
+	}
+	if rest {
+		if len(args) > nonrest {
+			stack = append(stack, List(args[nonrest:]...))
+		} else {
+			stack = append(stack, Nil)
+		}
+	}
+	return stack
+}
+
+func listN(stack []Object, n int) []Object {
+	base := len(stack) - n
+	res := List(stack[base:]...)
+	return append(stack[:base], res)
+}
+
+func concatN(stack []Object, n int) []Object {
+	base := len(stack) - n
+	res := concat(stack[base:])
+	return append(stack[:base], res)
+}
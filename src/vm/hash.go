@@ -0,0 +1,131 @@
+package vm
+
+import (
+	"bytes"
+)
+
+// HashTable is a Lisp hash table.
+// Iteration order is the insertion order.
+type HashTable struct {
+	test  *Symbol // One of eq, eql or equal
+	index map[interface{}]int
+	keys  []Object
+	vals  []Object
+}
+
+// equalKey is a hash key of a list or a vector in
+// "equal" test tables; it is a printed representation.
+type equalKey string
+
+// NewHashTable returns empty hash table that
+// compares keys with test (eq, eql or equal).
+func NewHashTable(test *Symbol, size int) *HashTable {
+	return &HashTable{
+		test:  test,
+		index: make(map[interface{}]int, size),
+		keys:  make([]Object, 0, size),
+		vals:  make([]Object, 0, size),
+	}
+}
+
+// Count returns the number of table entries.
+func (h *HashTable) Count() int {
+	return len(h.keys)
+}
+
+func (h *HashTable) hashKey(key Object) interface{} {
+	if h.test.Name != "equal" {
+		return key
+	}
+	switch key := key.(type) {
+	case *Cons, *Vector:
+		var buf bytes.Buffer
+		writeObject(&buf, key, true)
+		return equalKey(buf.String())
+	case Unibyte:
+		if isASCII(string(key)) {
+			return string(key)
+		}
+	}
+	return key
+}
+
+func (h *HashTable) get(key, dflt Object) Object {
+	if i, ok := h.index[h.hashKey(key)]; ok {
+		return h.vals[i]
+	}
+	return dflt
+}
+
+// Put associates val with key.
+func (h *HashTable) Put(key, val Object) {
+	k := h.hashKey(key)
+	if i, ok := h.index[k]; ok {
+		h.vals[i] = val
+		return
+	}
+	h.index[k] = len(h.keys)
+	h.keys = append(h.keys, key)
+	h.vals = append(h.vals, val)
+}
+
+func (h *HashTable) remove(key Object) {
+	k := h.hashKey(key)
+	i, ok := h.index[k]
+	if !ok {
+		return
+	}
+	delete(h.index, k)
+	h.keys = append(h.keys[:i], h.keys[i+1:]...)
+	h.vals = append(h.vals[:i], h.vals[i+1:]...)
+	for ; i < len(h.keys); i++ {
+		h.index[h.hashKey(h.keys[i])] = i
+	}
+}
+
+// Hash tables.
+func init() {
+	defBuiltins(
+		&Builtin{"make-hash-table", 0, many, func(vm *VM, args []Object) Object {
+			test, size := vm.Intern("eql"), 0
+			for i := 0; i+1 < len(args); i += 2 {
+				switch toSymbol(args[i]).Name {
+				case ":test":
+					test = toSymbol(args[i+1])
+					if test.Name != "eq" && test.Name != "eql" && test.Name != "equal" {
+						signalError("Invalid hash table test")
+					}
+				case ":size":
+					if !IsNil(args[i+1]) {
+						size = int(toInt(args[i+1]))
+					}
+				}
+			}
+			return NewHashTable(test, size)
+		}},
+		&Builtin{"gethash", 2, 3, func(vm *VM, args []Object) Object {
+			return toHashTable(args[1]).get(args[0], optArg(args, 2))
+		}},
+		&Builtin{"puthash", 3, 3, func(vm *VM, args []Object) Object {
+			toHashTable(args[2]).Put(args[0], args[1])
+			return args[1]
+		}},
+		&Builtin{"remhash", 2, 2, func(vm *VM, args []Object) Object {
+			toHashTable(args[1]).remove(args[0])
+			return Nil
+		}},
+		&Builtin{"hash-table-count", 1, 1, func(vm *VM, args []Object) Object {
+			return int64(toHashTable(args[0]).Count())
+		}},
+		&Builtin{"maphash", 2, 2, func(vm *VM, args []Object) Object {
+			h := toHashTable(args[1])
+			keys := append([]Object(nil), h.keys...)
+			for _, key := range keys {
+				if i, ok := h.index[h.hashKey(key)]; ok {
+					vm.funcall(args[0], []Object{key, h.vals[i]})
+				}
+			}
+			return Nil
+		}},
+	)
+}
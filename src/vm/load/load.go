@@ -0,0 +1,107 @@
+// Package load translates Go packages and defines them inside VM.
+//
+// It does the same job as "goism-load" Emacs command,
+// but without Emacs and IR package step: functions are
+// compiled straight into bytecode objects.
+package load
+
+import (
+	"backends/lapc"
+	"backends/lapc/compiler"
+	"backends/lapc/export"
+	"dt"
+	"exn"
+	"magic_pkg/emacs/lisp"
+	"sexp"
+	"sync"
+	tuload "tu/load"
+	"vm"
+)
+
+var runtimeOnce struct {
+	sync.Once
+	err error
+}
+
+// Package translates Go package that is located at "emacs/"+pkgPath
+// and loads it into m. Package init code is executed and
+// package feature is provided.
+func Package(m *vm.VM, pkgPath string) (err error) {
+	defer func() {
+		if e := exn.Catch(recover()); e != nil {
+			err = e
+		}
+	}()
+
+	runtimeOnce.Do(func() { runtimeOnce.err = tuload.Runtime() })
+	if runtimeOnce.err != nil {
+		return runtimeOnce.err
+	}
+	pkg, err := tuload.Package("emacs/"+pkgPath, true)
+	if err != nil {
+		return err
+	}
+
+	cl := compiler.New()
+	for _, name := range pkg.Vars {
+		m.Defvar(name, vm.Nil)
+	}
+	for _, fn := range pkg.Funcs {
+		if !fn.IsSubst() {
+			m.Defalias(fn.Name, newFunction(m, fn, compileFunc(cl, fn)))
+		}
+	}
+	if len(pkg.Init.Body) != 0 {
+		// Init is executed like top level "byte-code" form.
+		init := newFunction(m, pkg.Init, compileFunc(cl, pkg.Init))
+		init.ArgDesc, init.Doc = 0, ""
+		if _, err := m.Call(init); err != nil {
+			return err
+		}
+	}
+	m.Provide(pkg.Feature)
+	return nil
+}
+
+func compileFunc(cl *compiler.Compiler, fn *sexp.Func) *lapc.Object {
+	lapc.Simplify(fn.Body)
+	return cl.CompileFunc(fn)
+}
+
+// newFunction converts compiled object into VM function.
+// Object data is copied, because compiler reuses its buffers.
+func newFunction(m *vm.VM, fn *sexp.Func, obj *lapc.Object) *vm.Function {
+	consts := make([]vm.Object, obj.ConstVec.Len())
+	for i := range consts {
+		consts[i] = newConst(m, obj.ConstVec.Get(uint16(i)))
+	}
+	res := &vm.Function{
+		ArgDesc:  export.ArgsDescriptor(fn),
+		Code:     append([]byte(nil), obj.Bytecode...),
+		Consts:   consts,
+		MaxDepth: obj.StackUsage,
+		Doc:      export.RawDocString(fn),
+	}
+	if fn.IsInteractive() {
+		res.Interactive = vm.Nil
+		if fn.InteractiveSpec != "" {
+			res.Interactive = fn.InteractiveSpec
+		}
+	}
+	return res
+}
+
+func newConst(m *vm.VM, x interface{}) vm.Object {
+	switch x := x.(type) {
+	case lisp.Symbol:
+		return m.Intern(string(x))
+	case *dt.JumpTable:
+		table := vm.NewHashTable(m.Intern(string(x.Test)), len(x.Keys))
+		for i, key := range x.Keys {
+			table.Put(newConst(m, key), int64(x.Targets[i].PC))
+		}
+		return table
+	default:
+		return x // int64, float64 or string
+	}
+}
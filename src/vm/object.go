@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"strings"
+)
+
+// Object is any Lisp value; see package documentation
+// for the list of permitted dynamic types.
+type Object interface{}
+
+// Symbol is interned (or uninterned) Lisp symbol.
+type Symbol struct {
+	Name  string
+	Value Object // Nil if unbound
+	Func  Object // Nil if not fbound
+	Plist Object
+
+	bound    bool
+	constant bool
+	local    bool // Automatically buffer-local
+}
+
+// Cons is a Lisp cons cell.
+type Cons struct {
+	Car Object
+	Cdr Object
+}
+
+// Vector is a Lisp vector.
+type Vector struct {
+	Elems []Object
+}
+
+// Unibyte is a string of raw bytes.
+// Each byte is treated as a separate character.
+type Unibyte string
+
+// Function is a byte-code function object.
+type Function struct {
+	ArgDesc  int // Lexical argument descriptor
+	Code     []byte
+	Consts   []Object
+	MaxDepth int
+	Doc      string
+
+	// Interactive is an argument of "interactive" form;
+	// it is nil for functions that are not commands.
+	Interactive Object
+}
+
+// Builtin is a function implemented in Go.
+type Builtin struct {
+	Name    string
+	MinArgs int
+	MaxArgs int // -1 for "&rest"
+	Fn      func(vm *VM, args []Object) Object
+}
+
+// Partial is a function created by "apply-partially".
+type Partial struct {
+	Fn   Object
+	Args []Object
+}
+
+// Buffer is a minimal text buffer that can be used as an output stream.
+type Buffer struct {
+	Name string
+	Text strings.Builder
+	Live bool
+
+	pt      int       // Point
+	begv    int       // Start of accessible portion
+	zv      int       // End of accessible portion
+	markers []*marker // Positions that are adjusted by insertions
+
+	locals map[*Symbol]Object // Buffer-local variable values
+	keymap Object             // Local keymap; nil if none
+}
+
+// Nil and T are shared between all VM instances.
+// They are constant symbols.
+var (
+	Nil = &Symbol{Name: "nil", bound: true, constant: true}
+	T   = &Symbol{Name: "t", bound: true, constant: true}
+)
+
+func init() {
+	Nil.Value, Nil.Func, Nil.Plist = Nil, Nil, Nil
+	T.Value, T.Func, T.Plist = T, Nil, Nil
+}
+
+// newSymbol returns uninterned symbol that is void and not fbound.
+func newSymbol(name string) *Symbol {
+	return &Symbol{Name: name, Value: Nil, Func: Nil, Plist: Nil}
+}
+
+// Bool converts Go bool into Lisp boolean.
+func Bool(x bool) Object {
+	if x {
+		return T
+	}
+	return Nil
+}
+
+// List returns a proper list of given elements.
+func List(elems ...Object) Object {
+	var res Object = Nil
+	for i := len(elems) - 1; i >= 0; i-- {
+		res = &Cons{Car: elems[i], Cdr: res}
+	}
+	return res
+}
+
+// IsNil reports whether x is nil.
+func IsNil(x Object) bool {
+	return x == Object(Nil)
+}
+
+func isKeyword(sym *Symbol) bool {
+	return strings.HasPrefix(sym.Name, ":")
+}
@@ -0,0 +1,235 @@
+package vm
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Prin1ToString returns x printed representation,
+// like Emacs "prin1-to-string" does.
+func Prin1ToString(x Object) string {
+	var buf bytes.Buffer
+	writeObject(&buf, x, true)
+	return buf.String()
+}
+
+// princToString is like Prin1ToString, but strings are not quoted.
+func princToString(x Object) string {
+	var buf bytes.Buffer
+	writeObject(&buf, x, false)
+	return buf.String()
+}
+
+// writeObject writes x printed representation.
+// If escape is true, output is readable ("prin1" style),
+// otherwise it is intended for humans ("princ" style).
+func writeObject(buf *bytes.Buffer, x Object, escape bool) {
+	p := printer{buf: buf, escape: escape, seen: make(map[*Cons]bool)}
+	p.print(x)
+}
+
+type printer struct {
+	buf    *bytes.Buffer
+	escape bool
+	seen   map[*Cons]bool // Detects circular lists
+}
+
+func (p *printer) print(x Object) {
+	buf := p.buf
+	switch x := x.(type) {
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case float64:
+		buf.WriteString(formatFloat(x))
+	case string:
+		p.printString(x)
+	case Unibyte:
+		p.printUnibyte(x)
+	case *Symbol:
+		p.printSymbol(x)
+	case *Cons:
+		p.printList(x)
+	case *Vector:
+		p.printElems("[", x.Elems, "]")
+	case *HashTable:
+		p.printHashTable(x)
+	case *Function:
+		buf.WriteString("#[")
+		buf.WriteString(strconv.Itoa(x.ArgDesc))
+		buf.WriteString(" ")
+		p.print(Unibyte(x.Code))
+		buf.WriteString(" ")
+		p.printElems("[", x.Consts, "]")
+		buf.WriteString(" ")
+		buf.WriteString(strconv.Itoa(x.MaxDepth))
+		buf.WriteString("]")
+	case *Builtin:
+		buf.WriteString("#<subr " + x.Name + ">")
+	case *Partial:
+		buf.WriteString("#<apply-partially>")
+	case *Buffer:
+		if x.Live {
+			buf.WriteString("#<buffer " + x.Name + ">")
+		} else {
+			buf.WriteString("#<killed buffer>")
+		}
+	default:
+		buf.WriteString("#<goism-vm-unknown>")
+	}
+}
+
+// formatFloat mimics Emacs "float_to_string": "%.Ng" with the
+// smallest N (starting from 15) that reads back as the same float.
+// Result always contains "." or exponent.
+func formatFloat(x float64) string {
+	switch {
+	case math.IsNaN(x):
+		return "0.0e+NaN"
+	case math.IsInf(x, 1):
+		return "1.0e+INF"
+	case math.IsInf(x, -1):
+		return "-1.0e+INF"
+	}
+	var s string
+	for prec := 15; prec <= 17; prec++ {
+		s = strconv.FormatFloat(x, 'g', prec, 64)
+		if y, _ := strconv.ParseFloat(s, 64); y == x {
+			break
+		}
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func (p *printer) printString(s string) {
+	if !p.escape {
+		p.buf.WriteString(s)
+		return
+	}
+	p.buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			p.buf.WriteByte('\\')
+		}
+		p.buf.WriteByte(s[i])
+	}
+	p.buf.WriteByte('"')
+}
+
+// printUnibyte prints unibyte string; non-ASCII bytes
+// are written as octal escapes when escape flag is set.
+func (p *printer) printUnibyte(s Unibyte) {
+	if !p.escape {
+		p.buf.WriteString(string(s))
+		return
+	}
+	p.buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			p.buf.WriteByte('\\')
+			p.buf.WriteByte(c)
+		case c >= 0x80:
+			p.buf.WriteByte('\\')
+			p.buf.WriteString(strconv.FormatInt(int64(c), 8))
+		default:
+			p.buf.WriteByte(c)
+		}
+	}
+	p.buf.WriteByte('"')
+}
+
+func (p *printer) printSymbol(sym *Symbol) {
+	if !p.escape {
+		p.buf.WriteString(sym.Name)
+		return
+	}
+	if sym.Name == "" {
+		p.buf.WriteString("##")
+		return
+	}
+	if _, ok := parseNumber(sym.Name); ok {
+		p.buf.WriteByte('\\')
+	}
+	for i, ch := range sym.Name {
+		// "?" needs escaping only at start.
+		if strings.ContainsRune("()[]\"'`;#, \t\n\\", ch) || (ch == '?' && i == 0) {
+			p.buf.WriteByte('\\')
+		}
+		p.buf.WriteRune(ch)
+	}
+}
+
+func (p *printer) printList(lst *Cons) {
+	if p.seen[lst] {
+		signal(circularListSym, Nil)
+	}
+	p.seen[lst] = true
+	defer delete(p.seen, lst)
+
+	// (quote x) is printed as 'x.
+	if sym, ok := lst.Car.(*Symbol); ok && sym.Name == "quote" {
+		if rest, ok := lst.Cdr.(*Cons); ok && IsNil(rest.Cdr) {
+			p.buf.WriteByte('\'')
+			p.print(rest.Car)
+			return
+		}
+	}
+
+	p.buf.WriteByte('(')
+	var x Object = lst
+	for n := 0; ; n++ {
+		cons := x.(*Cons)
+		if n != 0 {
+			p.buf.WriteByte(' ')
+		}
+		p.print(cons.Car)
+		if IsNil(cons.Cdr) {
+			break
+		}
+		next, ok := cons.Cdr.(*Cons)
+		if !ok {
+			p.buf.WriteString(" . ")
+			p.print(cons.Cdr)
+			break
+		}
+		if next == lst {
+			signal(circularListSym, lst)
+		}
+		x = next
+	}
+	p.buf.WriteByte(')')
+}
+
+func (p *printer) printElems(open string, elems []Object, close string) {
+	p.buf.WriteString(open)
+	for i, elem := range elems {
+		if i != 0 {
+			p.buf.WriteByte(' ')
+		}
+		p.print(elem)
+	}
+	p.buf.WriteString(close)
+}
+
+func (p *printer) printHashTable(h *HashTable) {
+	p.buf.WriteString("#s(hash-table size ")
+	p.buf.WriteString(strconv.Itoa(h.Count()))
+	p.buf.WriteString(" test ")
+	p.buf.WriteString(h.test.Name)
+	p.buf.WriteString(" rehash-size 1.5 rehash-threshold 0.8125 data (")
+	for i, key := range h.keys {
+		if i != 0 {
+			p.buf.WriteByte(' ')
+		}
+		p.print(key)
+		p.buf.WriteByte(' ')
+		p.print(h.vals[i])
+	}
+	p.buf.WriteString("))")
+}
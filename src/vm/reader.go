@@ -0,0 +1,351 @@
+package vm
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Read parses the first Lisp form from src.
+// Only "read" subset that is needed to express test
+// inputs and ".elc" files is supported: numbers, strings,
+// characters, symbols, lists, vectors, quote, function quote,
+// byte-code function objects and hash tables.
+func Read(vm *VM, src string) (form Object, err error) {
+	defer vm.recoverError(&err, vm.depth)
+	r := &reader{vm: vm, src: src}
+	r.skipSpace()
+	if r.pos == len(r.src) {
+		signal(endOfFileSym)
+	}
+	return r.read(), nil
+}
+
+// ReadAll parses all Lisp forms from src.
+func ReadAll(vm *VM, src string) (forms []Object, err error) {
+	defer vm.recoverError(&err, vm.depth)
+	r := &reader{vm: vm, src: src}
+	for r.skipSpace(); r.pos < len(r.src); r.skipSpace() {
+		forms = append(forms, r.read())
+	}
+	return forms, nil
+}
+
+type reader struct {
+	vm  *VM
+	src string
+	pos int
+}
+
+func (r *reader) peek() byte {
+	if r.pos == len(r.src) {
+		signal(endOfFileSym)
+	}
+	return r.src[r.pos]
+}
+
+func (r *reader) next() rune {
+	if r.pos == len(r.src) {
+		signal(endOfFileSym)
+	}
+	ch, size := utf8.DecodeRuneInString(r.src[r.pos:])
+	r.pos += size
+	return ch
+}
+
+func (r *reader) skipSpace() {
+	for r.pos < len(r.src) {
+		switch ch := r.src[r.pos]; {
+		case ch == ';':
+			for r.pos < len(r.src) && r.src[r.pos] != '\n' {
+				r.pos++
+			}
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f':
+			r.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (r *reader) read() Object {
+	switch ch := r.peek(); ch {
+	case '(':
+		r.pos++
+		return r.readList(')')
+	case '[':
+		r.pos++
+		return &Vector{Elems: listToSlice(r.readList(']'))}
+	case ')', ']':
+		signal(invalidReadSyntaxSym, string(ch))
+	case '\'':
+		r.pos++
+		r.skipSpace()
+		return List(r.vm.Intern("quote"), r.read())
+	case '"':
+		r.pos++
+		return r.readString()
+	case '?':
+		r.pos++
+		return int64(r.readChar())
+	case '#':
+		r.pos++
+		return r.readHashSyntax()
+	}
+	return r.readAtom()
+}
+
+// readHashSyntax reads "#'" function quote, "#[...]" byte-code
+// function object or "#s(hash-table ...)" hash table;
+// "#" is already consumed.
+func (r *reader) readHashSyntax() Object {
+	switch {
+	case r.peek() == '\'':
+		r.pos++
+		r.skipSpace()
+		return List(r.vm.Intern("function"), r.read())
+	case r.peek() == '[':
+		r.pos++
+		return makeByteCode(listToSlice(r.readList(']')))
+	case strings.HasPrefix(r.src[r.pos:], "s("):
+		r.pos += 2
+		return r.readHashTable(listToSlice(r.readList(')')))
+	}
+	signal(invalidReadSyntaxSym, "#")
+	return nil
+}
+
+// readHashTable makes hash table of "#s(hash-table ...)" elements.
+// Only "test" and "data" properties are used.
+func (r *reader) readHashTable(elems []Object) Object {
+	if len(elems) == 0 || elems[0] != r.vm.Intern("hash-table") {
+		signal(invalidReadSyntaxSym, "#s")
+	}
+	test, data := r.vm.Intern("eql"), []Object(nil)
+	for i := 1; i+1 < len(elems); i += 2 {
+		switch elems[i] {
+		case r.vm.Intern("test"):
+			test = toSymbol(elems[i+1])
+		case r.vm.Intern("data"):
+			data = listToSlice(elems[i+1])
+		}
+	}
+	h := NewHashTable(test, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		h.Put(data[i], data[i+1])
+	}
+	return h
+}
+
+// readList reads list elements until closing delimiter.
+// Dotted pairs are only permitted inside parenthesis.
+func (r *reader) readList(end byte) Object {
+	var elems []Object
+	var tail Object = Nil
+	for {
+		r.skipSpace()
+		ch := r.peek()
+		if ch == end {
+			r.pos++
+			break
+		}
+		if ch == '.' && end == ')' && r.isDelimiter(r.pos+1) && len(elems) != 0 {
+			r.pos++
+			r.skipSpace()
+			tail = r.read()
+			r.skipSpace()
+			if r.peek() != ')' {
+				signal(invalidReadSyntaxSym, ". in wrong context")
+			}
+			r.pos++
+			break
+		}
+		elems = append(elems, r.read())
+	}
+	for i := len(elems) - 1; i >= 0; i-- {
+		tail = &Cons{Car: elems[i], Cdr: tail}
+	}
+	return tail
+}
+
+// readString reads string literal, opening quote is already consumed.
+// Like in Emacs, string is unibyte if it has octal or hex
+// escapes, but no multibyte characters.
+func (r *reader) readString() Object {
+	var buf strings.Builder
+	unibyte, multibyte := false, false
+	for {
+		ch := r.next()
+		switch ch {
+		case '"':
+			if unibyte && !multibyte {
+				return toUnibyte(buf.String())
+			}
+			return buf.String()
+		case '\\':
+			if r.peek() == '\n' || r.peek() == ' ' {
+				r.pos++ // Ignored escaped newline and space
+				continue
+			}
+			esc := r.peek()
+			ch = r.readEscape()
+			switch {
+			case ch >= utf8.RuneSelf && ch <= 0xff && (esc == 'x' || esc >= '0' && esc <= '7'):
+				unibyte = true
+			case ch >= utf8.RuneSelf:
+				multibyte = true
+			}
+			buf.WriteRune(ch)
+		default:
+			multibyte = multibyte || ch >= utf8.RuneSelf
+			buf.WriteRune(ch)
+		}
+	}
+}
+
+// toUnibyte converts string of [0, 255] characters to raw bytes.
+func toUnibyte(s string) Unibyte {
+	res := make([]byte, 0, len(s))
+	for _, ch := range s {
+		res = append(res, byte(ch))
+	}
+	return Unibyte(res)
+}
+
+// readChar reads character literal body, "?" is already consumed.
+func (r *reader) readChar() rune {
+	ch := r.next()
+	if ch == '\\' {
+		return r.readEscape()
+	}
+	return ch
+}
+
+func (r *reader) readEscape() rune {
+	ch := r.next()
+	switch ch {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case 'f':
+		return '\f'
+	case 'e':
+		return 27
+	case 'a':
+		return 7
+	case 'b':
+		return '\b'
+	case 'v':
+		return '\v'
+	case 's':
+		return ' '
+	case 'd':
+		return 127
+	case 'x':
+		return r.readCode(16, -1)
+	case 'u':
+		return r.readCode(16, 4)
+	case 'U':
+		return r.readCode(16, 8)
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		r.pos--
+		return r.readCode(8, 3)
+	}
+	return ch
+}
+
+// readCode reads character code in given base.
+// Negative n means "as many digits as possible".
+func (r *reader) readCode(base, n int) rune {
+	start := r.pos
+	for r.pos < len(r.src) && (n < 0 || r.pos-start < n) {
+		if digitValue(r.src[r.pos]) >= base {
+			break
+		}
+		r.pos++
+	}
+	code, err := strconv.ParseInt(r.src[start:r.pos], base, 32)
+	if err != nil {
+		signal(invalidReadSyntaxSym, "?")
+	}
+	return rune(code)
+}
+
+func digitValue(ch byte) int {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return int(ch - '0')
+	case ch >= 'a' && ch <= 'z':
+		return int(ch-'a') + 10
+	case ch >= 'A' && ch <= 'Z':
+		return int(ch-'A') + 10
+	}
+	return 99
+}
+
+func (r *reader) isDelimiter(pos int) bool {
+	if pos >= len(r.src) {
+		return true
+	}
+	return strings.IndexByte("()[]\"'; \t\n\r\f", r.src[pos]) != -1
+}
+
+func (r *reader) readAtom() Object {
+	var buf strings.Builder
+	escaped := false
+	for !r.isDelimiter(r.pos) {
+		ch := r.next()
+		if ch == '\\' {
+			ch = r.next()
+			escaped = true
+		}
+		buf.WriteRune(ch)
+	}
+	tok := buf.String()
+	if tok == "" {
+		signal(invalidReadSyntaxSym, r.src[r.pos:r.pos+1])
+	}
+	if !escaped {
+		if x, ok := parseNumber(tok); ok {
+			return x
+		}
+	}
+	return r.vm.Intern(tok)
+}
+
+// parseNumber parses integer or float literal in Lisp syntax.
+func parseNumber(tok string) (Object, bool) {
+	s := strings.TrimSuffix(tok, ".")
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, true
+	}
+	switch tok {
+	case "1.0e+INF", "+1.0e+INF":
+		return posInf, true
+	case "-1.0e+INF":
+		return negInf, true
+	case "0.0e+NaN", "+0.0e+NaN", "-0.0e+NaN":
+		return nan, true
+	}
+	// Lisp float must contain a digit and either "." or exponent.
+	if !strings.ContainsAny(tok, "0123456789") ||
+		!strings.ContainsAny(tok, ".eE") ||
+		strings.ContainsAny(tok, "xXpP_") {
+		return nil, false
+	}
+	if x, err := strconv.ParseFloat(tok, 64); err == nil {
+		return x, true
+	}
+	return nil, false
+}
+
+func listToSlice(lst Object) []Object {
+	var elems []Object
+	for cons, ok := lst.(*Cons); ok; cons, ok = cons.Cdr.(*Cons) {
+		elems = append(elems, cons.Car)
+	}
+	return elems
+}
@@ -0,0 +1,381 @@
+package vm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Regexp-based string functions.
+// Emacs regexps are translated into Go (RE2) syntax;
+// back references and syntax classes other than
+// words and whitespace are not supported.
+func init() {
+	defBuiltins(
+		&Builtin{"regexp-quote", 1, 1, func(vm *VM, args []Object) Object {
+			return regexpQuote(toString(args[0]))
+		}},
+		&Builtin{"regexp-opt-charset", 1, 1, func(vm *VM, args []Object) Object {
+			return regexpOptCharset(toSlice(args[0]))
+		}},
+		&Builtin{"string-match-p", 2, 3, func(vm *VM, args []Object) Object {
+			match := vm.stringMatch(args[0], args[1], optArg(args, 2))
+			if match == nil {
+				return Nil
+			}
+			return match[0]
+		}},
+		&Builtin{"string-match", 2, 4, func(vm *VM, args []Object) Object {
+			match := vm.stringMatch(args[0], args[1], optArg(args, 2))
+			if match == nil {
+				return Nil
+			}
+			if IsNil(optArg(args, 3)) {
+				vm.match = match
+			}
+			return match[0]
+		}},
+		&Builtin{"match-beginning", 1, 1, func(vm *VM, args []Object) Object {
+			return vm.matchPos(args[0], 0)
+		}},
+		&Builtin{"match-end", 1, 1, func(vm *VM, args []Object) Object {
+			return vm.matchPos(args[0], 1)
+		}},
+		&Builtin{"match-data", 0, 3, func(vm *VM, args []Object) Object {
+			return List(vm.match...)
+		}},
+		&Builtin{"set-match-data", 1, 2, func(vm *VM, args []Object) Object {
+			vm.match = toSlice(args[0])
+			return Nil
+		}},
+		&Builtin{"split-string", 1, 4, func(vm *VM, args []Object) Object {
+			return vm.splitString(args[0], optArg(args, 1), optArg(args, 2), optArg(args, 3))
+		}},
+		&Builtin{"string-trim-left", 1, 2, func(vm *VM, args []Object) Object {
+			return vm.trimLeft(toString(args[0]), optArg(args, 1))
+		}},
+		&Builtin{"string-trim-right", 1, 2, func(vm *VM, args []Object) Object {
+			return vm.trimRight(toString(args[0]), optArg(args, 1))
+		}},
+		&Builtin{"string-trim", 1, 3, func(vm *VM, args []Object) Object {
+			s := vm.trimLeft(toString(args[0]), optArg(args, 1))
+			return vm.trimRight(s, optArg(args, 2))
+		}},
+	)
+}
+
+// stringMatch returns match data of the first rx match
+// inside string s, starting from start index (if non-nil).
+// Groups that did not match have nil positions.
+// If there is no match, nil slice is returned.
+func (vm *VM) stringMatch(rx, s, start Object) []Object {
+	str := toString(s)
+	offset := 0
+	if !IsNil(start) {
+		offset = byteOffset(str, checkIndex(s, start, length(s)+1))
+	}
+	loc := vm.regexp(rx).FindStringSubmatchIndex(str[offset:])
+	if loc == nil {
+		return nil
+	}
+	match := make([]Object, len(loc))
+	for i, pos := range loc {
+		if pos < 0 {
+			match[i] = Nil
+		} else {
+			match[i] = int64(charIndex(str, offset+pos))
+		}
+	}
+	return match
+}
+
+// matchPos returns start (edge=0) or end (edge=1)
+// of last match group n.
+func (vm *VM) matchPos(n Object, edge int) Object {
+	i := toInt(n)
+	if i < 0 {
+		signal(argsOutOfRangeSym, n, int64(0))
+	}
+	if int(i)*2+edge >= len(vm.match) {
+		return Nil
+	}
+	return vm.match[i*2+int64(edge)]
+}
+
+const defaultTrimRegexp = "[ \t\n\r]+"
+
+func (vm *VM) trimLeft(s string, rx Object) string {
+	if IsNil(rx) {
+		rx = defaultTrimRegexp
+	}
+	re := vm.regexp(concat([]Object{`\` + "`" + `\(?:`, rx, `\)`}))
+	if loc := re.FindStringIndex(s); loc != nil {
+		return s[loc[1]:]
+	}
+	return s
+}
+
+func (vm *VM) trimRight(s string, rx Object) string {
+	if IsNil(rx) {
+		rx = defaultTrimRegexp
+	}
+	re := vm.regexp(concat([]Object{`\(?:`, rx, `\)\'`}))
+	if loc := re.FindStringIndex(s); loc != nil {
+		return s[:loc[0]]
+	}
+	return s
+}
+
+// splitString follows "split-string" algorithm from "subr.el".
+func (vm *VM) splitString(str, separators, omitNulls, trim Object) Object {
+	s := toString(str)
+	keepNulls := IsNil(separators) || IsNil(omitNulls)
+	if IsNil(separators) {
+		separators = "[ \f\t\n\r\v]+"
+		keepNulls = false
+	}
+	re := vm.regexp(separators)
+
+	var parts []Object
+	push := func(from, to int) {
+		part := s[from:to]
+		if !IsNil(trim) {
+			part = vm.trimRight(vm.trimLeft(part, trim), trim)
+		}
+		if keepNulls || part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	start := 0
+	notFirst := false
+	lastMatchStart := -1
+	for start < len(s) {
+		searchFrom := start
+		if notFirst && start == lastMatchStart {
+			// Empty match: skip one character to avoid looping.
+			_, size := utf8.DecodeRuneInString(s[start:])
+			searchFrom += size
+		}
+		if searchFrom > len(s) {
+			break
+		}
+		loc := re.FindStringIndex(s[searchFrom:])
+		if loc == nil {
+			break
+		}
+		notFirst = true
+		matchStart, matchEnd := searchFrom+loc[0], searchFrom+loc[1]
+		push(start, matchStart)
+		start, lastMatchStart = matchEnd, matchStart
+	}
+	push(start, len(s))
+	return List(parts...)
+}
+
+func regexpQuote(s string) string {
+	var buf strings.Builder
+	for _, ch := range s {
+		if strings.ContainsRune(`[*.\?+^$`, ch) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
+// regexpOptCharset returns Emacs regexp that matches any of chars.
+func regexpOptCharset(chars []Object) string {
+	set := make(map[rune]bool)
+	for _, ch := range chars {
+		set[toChar(ch)] = true
+	}
+	if len(set) == 1 {
+		for ch := range set {
+			return regexpQuote(string(ch))
+		}
+	}
+	runes := make([]rune, 0, len(set))
+	for ch := range set {
+		if ch != ']' && ch != '^' && ch != '-' {
+			runes = append(runes, ch)
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	// "]" must be the first, "^" must not be the first
+	// and "-" must be the last.
+	var buf strings.Builder
+	buf.WriteByte('[')
+	if set[']'] {
+		buf.WriteByte(']')
+	}
+	buf.WriteString(string(runes))
+	if set['^'] {
+		buf.WriteByte('^')
+	}
+	if set['-'] {
+		buf.WriteByte('-')
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+// regexp returns compiled Go regexp for Emacs regexp source.
+func (vm *VM) regexp(src Object) *regexp.Regexp {
+	s := toString(src)
+	if re := vm.regexps[s]; re != nil {
+		return re
+	}
+	re, err := regexp.Compile("(?m)" + translateRegexp(s))
+	if err != nil {
+		signal(invalidRegexpSym, err.Error())
+	}
+	if vm.regexps == nil {
+		vm.regexps = make(map[string]*regexp.Regexp)
+	}
+	vm.regexps[s] = re
+	return re
+}
+
+// translateRegexp converts Emacs regexp syntax into RE2 syntax.
+func translateRegexp(src string) string {
+	var buf strings.Builder
+	// atStart is true where "^" is a beginning of line anchor.
+	atStart := true
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		wasStart := atStart
+		atStart = false
+		switch ch {
+		case '\\':
+			i++
+			if i == len(src) {
+				signal(invalidRegexpSym, "Trailing backslash")
+			}
+			switch esc := src[i]; esc {
+			case '(':
+				if strings.HasPrefix(src[i+1:], "?:") {
+					i += 2
+					buf.WriteString("(?:")
+				} else {
+					buf.WriteByte('(')
+				}
+				atStart = true
+			case ')':
+				buf.WriteByte(')')
+			case '|':
+				buf.WriteByte('|')
+				atStart = true
+			case '{', '}':
+				buf.WriteByte(esc)
+			case '`':
+				buf.WriteString(`\A`)
+			case '\'':
+				buf.WriteString(`\z`)
+			case 'w', 'W', 'b', 'B':
+				buf.WriteByte('\\')
+				buf.WriteByte(esc)
+			case '<', '>':
+				buf.WriteString(`\b`)
+			case 's', 'S':
+				i++
+				if i == len(src) || (src[i] != '-' && src[i] != ' ') {
+					signal(unsupportedOpcodeSym, src)
+				}
+				buf.WriteByte('\\')
+				buf.WriteByte(esc)
+			default:
+				if esc >= '1' && esc <= '9' {
+					signal(unsupportedOpcodeSym, src)
+				}
+				buf.WriteString(regexp.QuoteMeta(string(esc)))
+			}
+		case '[':
+			i = translateCharset(&buf, src, i)
+		case '(', ')', '|', '{', '}':
+			buf.WriteByte('\\')
+			buf.WriteByte(ch)
+		case '^':
+			if wasStart {
+				buf.WriteByte('^')
+				atStart = true
+			} else {
+				buf.WriteString(`\^`)
+			}
+		case '$':
+			if i+1 == len(src) || strings.HasPrefix(src[i+1:], `\)`) || strings.HasPrefix(src[i+1:], `\|`) {
+				buf.WriteByte('$')
+			} else {
+				buf.WriteString(`\$`)
+			}
+		case '*', '+', '?':
+			if wasStart {
+				buf.WriteByte('\\') // Literal at the start
+			}
+			buf.WriteByte(ch)
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	return buf.String()
+}
+
+// translateCharset converts bracket expression that starts at src[i].
+// Returns index of its closing bracket.
+func translateCharset(buf *strings.Builder, src string, i int) int {
+	buf.WriteByte('[')
+	i++
+	if i < len(src) && src[i] == '^' {
+		buf.WriteByte('^')
+		i++
+	}
+	for first := true; i < len(src); first = false {
+		switch {
+		case src[i] == ']' && !first:
+			buf.WriteByte(']')
+			return i
+		case strings.HasPrefix(src[i:], "[:"):
+			end := strings.Index(src[i:], ":]")
+			if end == -1 {
+				signal(invalidRegexpSym, "Unmatched [ or [^")
+			}
+			buf.WriteString(src[i : i+end+2])
+			i += end + 2
+		default:
+			ch, size := utf8.DecodeRuneInString(src[i:])
+			buf.WriteString(quoteCharsetRune(ch))
+			i += size
+			// Range is "x-y", but "-" before "]" is literal.
+			if i+1 < len(src) && src[i] == '-' && src[i+1] != ']' {
+				ch, size := utf8.DecodeRuneInString(src[i+1:])
+				buf.WriteByte('-')
+				buf.WriteString(quoteCharsetRune(ch))
+				i += 1 + size
+			}
+		}
+	}
+	signal(invalidRegexpSym, "Unmatched [ or [^")
+	return 0
+}
+
+func quoteCharsetRune(ch rune) string {
+	switch ch {
+	case '\n':
+		return `\n`
+	case '\t':
+		return `\t`
+	case '\r':
+		return `\r`
+	case '\f':
+		return `\f`
+	case '\v':
+		return `\v`
+	}
+	// RE2 permits escaping of any ASCII punctuation.
+	if ch > ' ' && ch < 0x7f && !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9') {
+		return `\` + string(ch)
+	}
+	return string(ch)
+}
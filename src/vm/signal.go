@@ -0,0 +1,91 @@
+package vm
+
+import (
+	"bytes"
+)
+
+// Signal is a Lisp error: error symbol and its data.
+type Signal struct {
+	Sym  *Symbol
+	Data Object
+}
+
+// Error returns message that Emacs "error-message-string" would produce.
+func (sig *Signal) Error() string {
+	var buf bytes.Buffer
+	data := sig.Data
+	msg, _ := Get(sig.Sym, errorMessageSym).(string)
+	if sig.Sym == errorSym {
+		// Generic errors carry message as the first data element.
+		msg, _ = car(data).(string)
+		data = cdr(data)
+	}
+	if msg == "" {
+		msg = "peculiar error"
+	}
+
+	buf.WriteString(msg)
+	sep := ": "
+	for cons, ok := data.(*Cons); ok; cons, ok = cons.Cdr.(*Cons) {
+		buf.WriteString(sep)
+		writeObject(&buf, cons.Car, true)
+		sep = ", "
+	}
+	return buf.String()
+}
+
+// Standard error symbols.
+// They are shared, like nil and t, so builtins can signal
+// errors without VM reference.
+var (
+	errorMessageSym = newSymbol("error-message")
+
+	errorSym               = newErrorSym("error", "error")
+	wrongTypeArgumentSym   = newErrorSym("wrong-type-argument", "Wrong type argument")
+	argsOutOfRangeSym      = newErrorSym("args-out-of-range", "Args out of range")
+	voidFunctionSym        = newErrorSym("void-function", "Symbol’s function definition is void")
+	voidVariableSym        = newErrorSym("void-variable", "Symbol’s value as variable is void")
+	arithErrorSym          = newErrorSym("arith-error", "Arithmetic error")
+	wrongLengthArgumentSym = newErrorSym("wrong-length-argument", "Wrong length argument")
+	wrongNumberOfArgsSym   = newErrorSym("wrong-number-of-arguments", "Wrong number of arguments")
+	invalidFunctionSym     = newErrorSym("invalid-function", "Invalid function")
+	settingConstantSym     = newErrorSym("setting-constant", "Attempt to set a constant symbol")
+	invalidReadSyntaxSym   = newErrorSym("invalid-read-syntax", "Invalid read syntax")
+	endOfFileSym           = newErrorSym("end-of-file", "End of file during parsing")
+	invalidRegexpSym       = newErrorSym("invalid-regexp", "Invalid regexp")
+	noCatchSym             = newErrorSym("no-catch", "No catch for tag")
+	excessiveNestingSym    = newErrorSym("excessive-lisp-nesting", "Lisp nesting exceeds ‘max-lisp-eval-depth’")
+	unsupportedOpcodeSym   = newErrorSym("goism-vm-unsupported", "Unsupported by goism VM")
+	invalidByteCodeSym     = newErrorSym("invalid-byte-code", "Invalid byte code")
+	circularListSym        = newErrorSym("circular-list", "List contains a loop")
+
+	standardErrorSymbols []*Symbol
+)
+
+func newErrorSym(name, msg string) *Symbol {
+	sym := newSymbol(name)
+	sym.Plist = List(errorMessageSym, msg)
+	standardErrorSymbols = append(standardErrorSymbols, sym)
+	return sym
+}
+
+// throw is a "throw" non-local exit.
+// Not an error: it is caught by "catch" only.
+type throw struct {
+	tag Object
+	val Object
+}
+
+func signal(sym *Symbol, data ...Object) {
+	panic(&Signal{Sym: sym, Data: List(data...)})
+}
+
+func signalError(msg string) {
+	signal(errorSym, msg)
+}
+
+// wrongType signals "wrong-type-argument" error.
+// Predicate symbol is not interned: it is only used for error reporting.
+func wrongType(pred string, x Object) {
+	signal(wrongTypeArgumentSym, newSymbol(pred), x)
+}
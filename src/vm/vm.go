@@ -0,0 +1,236 @@
+package vm
+
+import (
+	"io"
+	"io/ioutil"
+	"regexp"
+)
+
+// maxDepth limits Lisp function call nesting,
+// like "max-lisp-eval-depth" does.
+const maxDepth = 1600
+
+// VM is an isolated Lisp environment.
+// It is not safe for concurrent use.
+type VM struct {
+	obarray map[string]*Symbol
+
+	// Stdout receives "standard-output" text.
+	Stdout io.Writer
+
+	depth   int
+	current *Buffer // Current buffer; nil if none
+	buffers int     // Number of created buffers
+	specpdl []specBinding
+	match   []Object // Match data of the last search
+
+	regexps map[string]*regexp.Regexp // Compiled regexps cache
+}
+
+// New returns VM with all builtin functions defined.
+func New() *VM {
+	vm := &VM{
+		obarray: make(map[string]*Symbol, 1024),
+		Stdout:  ioutil.Discard,
+	}
+	vm.obarray[Nil.Name] = Nil
+	vm.obarray[T.Name] = T
+	vm.obarray[errorMessageSym.Name] = errorMessageSym
+	for _, sym := range standardErrorSymbols {
+		vm.obarray[sym.Name] = sym
+	}
+	for _, b := range builtins {
+		vm.Intern(b.Name).Func = b
+	}
+	vm.defModeVars()
+	vm.Defvar("features", Nil)
+	return vm
+}
+
+// Intern returns symbol with specified name, creating it if needed.
+func (vm *VM) Intern(name string) *Symbol {
+	if sym := vm.obarray[name]; sym != nil {
+		return sym
+	}
+	sym := newSymbol(name)
+	if isKeyword(sym) {
+		sym.Value, sym.bound, sym.constant = sym, true, true
+	}
+	vm.obarray[name] = sym
+	return sym
+}
+
+// Defalias sets named symbol function definition.
+func (vm *VM) Defalias(name string, fn Object) {
+	vm.Intern(name).Func = fn
+}
+
+// Defvar sets named symbol value, unless it is already bound.
+func (vm *VM) Defvar(name string, val Object) {
+	if sym := vm.Intern(name); !sym.bound {
+		setValue(sym, val)
+	}
+}
+
+// Provide adds feature to "features" list, unless it is already there.
+func (vm *VM) Provide(feature string) {
+	sym := vm.Intern(feature)
+	if !vm.featurep(sym) {
+		features := vm.Intern("features")
+		setValue(features, &Cons{Car: sym, Cdr: symbolValue(features)})
+	}
+}
+
+// Load evaluates all Lisp forms from src, like "load" does
+// for ".el" and ".elc" files.
+// Lisp errors are returned as *Signal.
+func (vm *VM) Load(src string) error {
+	forms, err := ReadAll(vm, src)
+	if err != nil {
+		return err
+	}
+	for _, form := range forms {
+		if _, err := vm.Eval(form); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets named symbol value.
+func (vm *VM) Set(name string, val Object) {
+	setValue(vm.Intern(name), val)
+}
+
+// Call calls fn with given arguments.
+// Lisp errors are returned as *Signal.
+func (vm *VM) Call(fn Object, args ...Object) (res Object, err error) {
+	defer vm.recoverError(&err, vm.depth)
+	return vm.funcall(fn, args), nil
+}
+
+// Eval evaluates Lisp form.
+// Lisp errors are returned as *Signal.
+func (vm *VM) Eval(form Object) (res Object, err error) {
+	defer vm.recoverError(&err, vm.depth)
+	return vm.eval(form), nil
+}
+
+// EvalString reads the first form from src and evaluates it.
+// Result is returned in "prin1" representation.
+func (vm *VM) EvalString(src string) (string, error) {
+	form, err := Read(vm, src)
+	if err != nil {
+		return "", err
+	}
+	res, err := vm.Eval(form)
+	if err != nil {
+		return "", err
+	}
+	return Prin1ToString(res), nil
+}
+
+// recoverError converts Lisp non-local exit into error.
+// Call depth is restored to its value before the failed call.
+func (vm *VM) recoverError(err *error, depth int) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	vm.depth = depth
+	switch r := r.(type) {
+	case *Signal:
+		*err = r
+	case *throw:
+		*err = &Signal{Sym: noCatchSym, Data: List(r.tag, r.val)}
+	default:
+		panic(r)
+	}
+}
+
+func (vm *VM) funcall(fn Object, args []Object) Object {
+	vm.depth++
+	if vm.depth > maxDepth {
+		signal(excessiveNestingSym, int64(maxDepth))
+	}
+	res := vm.apply(fn, args)
+	vm.depth--
+	return res
+}
+
+func (vm *VM) apply(fn Object, args []Object) Object {
+	orig := fn
+	for {
+		sym, ok := fn.(*Symbol)
+		if !ok || sym == Nil {
+			break
+		}
+		fn = sym.Func
+	}
+
+	switch fn := fn.(type) {
+	case *Function:
+		return vm.exec(fn, args)
+	case *Builtin:
+		if len(args) < fn.MinArgs || (fn.MaxArgs >= 0 && len(args) > fn.MaxArgs) {
+			signal(wrongNumberOfArgsSym, fn, int64(len(args)))
+		}
+		return fn.Fn(vm, args)
+	case *Partial:
+		all := make([]Object, 0, len(fn.Args)+len(args))
+		all = append(append(all, fn.Args...), args...)
+		return vm.apply(fn.Fn, all)
+	}
+
+	if sym, ok := orig.(*Symbol); ok {
+		signal(voidFunctionSym, sym)
+	}
+	signal(invalidFunctionSym, orig)
+	return nil
+}
+
+func symbolValue(sym *Symbol) Object {
+	if !sym.bound {
+		signal(voidVariableSym, sym)
+	}
+	return sym.Value
+}
+
+func setValue(sym *Symbol, val Object) {
+	if sym.constant {
+		signal(settingConstantSym, sym)
+	}
+	sym.Value = val
+	sym.bound = true
+}
+
+// Get returns symbol property value.
+func Get(sym *Symbol, prop Object) Object {
+	for cons, ok := sym.Plist.(*Cons); ok; {
+		next, ok2 := cons.Cdr.(*Cons)
+		if !ok2 {
+			break
+		}
+		if cons.Car == prop {
+			return next.Car
+		}
+		cons, ok = next.Cdr.(*Cons)
+	}
+	return Nil
+}
+
+// Put sets symbol property value.
+func Put(sym *Symbol, prop, val Object) {
+	for cons, ok := sym.Plist.(*Cons); ok; {
+		next, ok2 := cons.Cdr.(*Cons)
+		if !ok2 {
+			break
+		}
+		if cons.Car == prop {
+			next.Car = val
+			return
+		}
+		cons, ok = next.Cdr.(*Cons)
+	}
+	sym.Plist = &Cons{Car: prop, Cdr: &Cons{Car: val, Cdr: sym.Plist}}
+}
@@ -6,6 +6,14 @@ func FuncIsThrowing(name string) bool {
 	return throwingFuncs[name]
 }
 
+// FuncIsPure returns true if function with given name has
+// no side effects and never signals an error for well-typed
+// arguments. Result of such function depends only on its arguments,
+// so its invariant calls can be moved out of loops.
+func FuncIsPure(name string) bool {
+	return pureFuncs[name]
+}
+
 // InstrCallCost returns special function call cost.
 // Zero value means that function has no dedicated instruction available.
 func InstrCallCost(name string) int {
@@ -19,6 +27,37 @@ var throwingFuncs = map[string]bool{
 	"signal":         true,
 }
 
+var pureFuncs = map[string]bool{
+	"=":                  true,
+	">":                  true,
+	"<":                  true,
+	"<=":                 true,
+	">=":                 true,
+	"+":                  true,
+	"-":                  true,
+	"*":                  true,
+	"1+":                 true,
+	"1-":                 true,
+	"min":                true,
+	"max":                true,
+	"lsh":                true,
+	"logand":             true,
+	"logior":             true,
+	"logxor":             true,
+	"not":                true,
+	"eq":                 true,
+	"string=":            true,
+	"string<":            true,
+	"string>":            true,
+	"string-bytes":       true,
+	"multibyte-string-p": true,
+	"integerp":           true,
+	"floatp":             true,
+	"stringp":            true,
+	"symbolp":            true,
+	"booleanp":           true,
+}
+
 var instrNameToCost = map[string]int{
 	"cons":      2,
 	"car":       1,
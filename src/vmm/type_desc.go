@@ -0,0 +1,97 @@
+package vmm
+
+import (
+	"exn"
+	"go/types"
+	"reflect"
+)
+
+// Runtime type descriptor is a vector; these constants
+// specify its layout (index of each attribute).
+//
+// Emacs Lisp side mirror is located inside "emacs/reflect" package.
+const (
+	// TypeDescKind - Go "reflect.Kind" value.
+	TypeDescKind = iota
+	// TypeDescString - type string (used by "%T" formatting).
+	TypeDescString
+	// TypeDescName - type name; empty string for unnamed types.
+	TypeDescName
+	// TypeDescPkgPath - import path of the package that defines type.
+	TypeDescPkgPath
+	// TypeDescFields - vector of (name . type) struct fields.
+	TypeDescFields
+	// TypeDescMethods - vector of (name . function) methods, sorted by name.
+	TypeDescMethods
+	// TypeDescElem - element type for composite types.
+	TypeDescElem
+	// TypeDescKey - map key type.
+	TypeDescKey
+	// TypeDescLen - array length.
+	TypeDescLen
+	// TypeDescMethodTypes - vector of method signature strings,
+	// in the same order as TypeDescMethods.
+	TypeDescMethodTypes
+
+	// TypeDescSize is a number of type descriptor attributes.
+	TypeDescSize
+)
+
+// KindOf returns kind that is stored inside runtime type descriptor.
+func KindOf(typ types.Type) reflect.Kind {
+	switch typ := typ.Underlying().(type) {
+	case *types.Basic:
+		switch typ.Kind() {
+		case types.Bool:
+			return reflect.Bool
+		case types.Int:
+			return reflect.Int
+		case types.Int8:
+			return reflect.Int8
+		case types.Int16:
+			return reflect.Int16
+		case types.Int32:
+			return reflect.Int32
+		case types.Int64:
+			return reflect.Int64
+		case types.Uint:
+			return reflect.Uint
+		case types.Uint8:
+			return reflect.Uint8
+		case types.Uint16:
+			return reflect.Uint16
+		case types.Uint32:
+			return reflect.Uint32
+		case types.Uint64:
+			return reflect.Uint64
+		case types.Uintptr:
+			return reflect.Uintptr
+		case types.Float32:
+			return reflect.Float32
+		case types.Float64:
+			return reflect.Float64
+		case types.String:
+			return reflect.String
+		case types.UnsafePointer:
+			return reflect.UnsafePointer
+		}
+	case *types.Array:
+		return reflect.Array
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Signature:
+		return reflect.Func
+	case *types.Interface:
+		return reflect.Interface
+	case *types.Map:
+		return reflect.Map
+	case *types.Pointer:
+		return reflect.Ptr
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Struct:
+		return reflect.Struct
+	}
+
+	panic(exn.NoImpl("runtime type info for `%s'", typ))
+}
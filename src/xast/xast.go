@@ -29,6 +29,7 @@ type Assign struct {
 // compile a function.
 type Func struct {
 	Pkg  *Package
+	Name string
 	Ret  *types.Tuple
 	Body *ast.BlockStmt
 }
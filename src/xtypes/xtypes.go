@@ -27,6 +27,8 @@ var (
 
 	TypString = types.Typ[types.String]
 	TypVoid   = types.Typ[types.Invalid]
+
+	TypError = types.Universe.Lookup("error").Type()
 )
 
 // AsNamedType tries to convert given type to "types.Named".